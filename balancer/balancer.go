@@ -3,63 +3,227 @@ package balancer
 import (
 	"context"
 	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/balancer/strategy"
 	"github.com/gone-io/goner/g"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var _ g.LoadBalancer = (*balancer)(nil)
+var _ g.LoadBalanceFeedback = (*balancer)(nil)
+var _ g.LoadBalanceRouter = (*balancer)(nil)
 
 type balancer struct {
 	gone.Flag
 	strategy  g.LoadBalanceStrategy `gone:"*"`
 	discovery g.ServiceDiscovery    `gone:"*"`
 	logger    gone.Logger           `gone:"*"`
+	probers   []g.InstanceProber    `gone:"*"`
 	m         sync.Map
+
+	// strategyNames 按服务指定的负载均衡策略，未指定的服务使用注入的默认策略，对应配置项为：`balancer.strategies`
+	strategyNames map[string]string `gone:"config,balancer.strategies"`
+
+	// zone 调用方所在的区域，供`zone-affinity`策略使用，对应配置项为：`balancer.zone.name`
+	zone string `gone:"config,balancer.zone.name,default="`
+
+	// zoneKey 实例元数据中表示区域的键，对应配置项为：`balancer.zone.key`
+	zoneKey string `gone:"config,balancer.zone.key,default=zone"`
+
+	strategies map[string]g.LoadBalanceStrategy
+
+	// routeRules 按元数据路由及按权重分流的规则，支持动态配置，对应配置项为：`balancer.routes`
+	routeRules []RouteRule      `gone:"config,balancer.routes"`
+	configure  gone.Configure   `gone:"configure"`
+	watcher    gone.ConfWatcher `gone:"*" option:"allowNil"`
+	routes     atomic.Pointer[map[string][]RouteRule]
+
+	// outlierEnabled 是否根据调用反馈摘除异常实例，对应配置项为：`balancer.outlier.enabled`
+	outlierEnabled bool `gone:"config,balancer.outlier.enabled,default=false"`
+
+	// consecutiveFailures 连续失败多少次后摘除实例，对应配置项为：`balancer.outlier.consecutive-failures`
+	consecutiveFailures int `gone:"config,balancer.outlier.consecutive-failures,default=5"`
+
+	// maxLatency 耗时超过该值的调用计为失败，0表示不限制，对应配置项为：`balancer.outlier.max-latency`
+	maxLatency time.Duration `gone:"config,balancer.outlier.max-latency,default=0s"`
+
+	// baseEjectionTime 摘除的基础时长，每次摘除的时长为其乘以摘除次数，对应配置项为：`balancer.outlier.base-ejection-time`
+	baseEjectionTime time.Duration `gone:"config,balancer.outlier.base-ejection-time,default=30s"`
+
+	// maxEjectionTime 摘除的最长时长，对应配置项为：`balancer.outlier.max-ejection-time`
+	maxEjectionTime time.Duration `gone:"config,balancer.outlier.max-ejection-time,default=300s"`
+
+	// maxEjectionPercent 同一服务最多摘除的实例百分比，对应配置项为：`balancer.outlier.max-ejection-percent`
+	maxEjectionPercent int `gone:"config,balancer.outlier.max-ejection-percent,default=50"`
+
+	// probeType 主动健康检查的类型：`tcp`、`http`、`grpc`，为空表示不检查，对应配置项为：`balancer.probe.type`
+	probeType string `gone:"config,balancer.probe.type,default="`
+
+	// probeInterval 主动健康检查的间隔，对应配置项为：`balancer.probe.interval`
+	probeInterval time.Duration `gone:"config,balancer.probe.interval,default=10s"`
+
+	// probeTimeout 单次健康检查的超时时间，对应配置项为：`balancer.probe.timeout`
+	probeTimeout time.Duration `gone:"config,balancer.probe.timeout,default=2s"`
+
+	// probePath http健康检查的路径，对应配置项为：`balancer.probe.path`
+	probePath string `gone:"config,balancer.probe.path,default=/health"`
+
+	// healthyThreshold 连续成功多少次后恢复实例，对应配置项为：`balancer.probe.healthy-threshold`
+	healthyThreshold int `gone:"config,balancer.probe.healthy-threshold,default=2"`
+
+	// unhealthyThreshold 连续失败多少次后标记实例不健康，对应配置项为：`balancer.probe.unhealthy-threshold`
+	unhealthyThreshold int `gone:"config,balancer.probe.unhealthy-threshold,default=3"`
+
+	// idleTimeout 服务多久未被使用后从缓存中移除并停止监听，0表示不移除，对应配置项为：`balancer.cache.idle-timeout`
+	idleTimeout time.Duration `gone:"config,balancer.cache.idle-timeout,default=30m"`
+
+	// keepLastKnownGood 服务发现返回空列表时是否保留上一次的实例列表，对应配置项为：`balancer.cache.keep-last-known-good`
+	keepLastKnownGood bool `gone:"config,balancer.cache.keep-last-known-good,default=true"`
+
+	// watchMinBackoff 监听失败后重新监听的最短等待时间，对应配置项为：`balancer.watch.min-backoff`
+	watchMinBackoff time.Duration `gone:"config,balancer.watch.min-backoff,default=1s"`
+
+	// watchMaxBackoff 监听失败后重新监听的最长等待时间，对应配置项为：`balancer.watch.max-backoff`
+	watchMaxBackoff time.Duration `gone:"config,balancer.watch.max-backoff,default=30s"`
+
+	watchLock sync.Mutex
+	watchers  map[string]*serviceWatcher
+
+	lock    sync.Mutex
+	states  map[string]map[string]*instanceState
+	now     func() time.Time
+	stop    chan struct{}
+	stopped bool
+	wg      sync.WaitGroup
 }
 
-func (b *balancer) GetInstance(ctx context.Context, serviceName string) (g.Service, error) {
-	var instances []g.Service
-	var err error
-	value, ok := b.m.Load(serviceName)
-	if !ok {
-		instances, err = b.GetInstancesWithCacheAndWatch(serviceName)
+// Init create the strategies configured by `balancer.strategies`, and the routing rules configured by `balancer.routes`
+func (b *balancer) Init() error {
+	routes, err := buildRoutes(b.routeRules)
+	if err != nil {
+		return err
+	}
+	b.routes.Store(&routes)
+	b.watchRoutes()
+
+	b.strategies = make(map[string]g.LoadBalanceStrategy, len(b.strategyNames))
+	for serviceName, name := range b.strategyNames {
+		s, err := strategy.New(name)
 		if err != nil {
-			return nil, gone.ToError(err)
+			return gone.ToErrorWithMsg(err, "balancer.strategies."+serviceName)
 		}
-	} else {
-		instances, ok = value.([]g.Service)
+		if z, ok := s.(*strategy.ZoneAffinityStrategy); ok {
+			z.Zone, z.ZoneKey = b.zone, b.zoneKey
+		}
+		b.strategies[serviceName] = s
 	}
+	return nil
+}
 
-	return b.strategy.Select(ctx, instances)
+// strategyOf return the strategy of service, which is the default one if not configured by `balancer.strategies`
+func (b *balancer) strategyOf(serviceName string) g.LoadBalanceStrategy {
+	if s, ok := b.strategies[serviceName]; ok {
+		return s
+	}
+	return b.strategy
 }
 
-func (b *balancer) GetInstancesWithCacheAndWatch(serviceName string) ([]g.Service, error) {
-	if value, ok := b.m.Load(serviceName); ok {
-		return value.([]g.Service), nil
+// Select choose an instance by the strategy of service, it's used by the clients picking instances themselves
+func (b *balancer) Select(ctx context.Context, serviceName string, instances []g.Service) (g.Service, error) {
+	return b.strategyOf(serviceName).Select(ctx, instances)
+}
+
+// Start run active health probes if `balancer.probe.type` is set, and evict idle services if
+// `balancer.cache.idle-timeout` is set
+func (b *balancer) Start() error {
+	var prober g.InstanceProber
+	if b.probeType != "" {
+		var err error
+		if prober, err = b.prober(); err != nil {
+			return err
+		}
+	}
+	if prober != nil {
+		b.every(b.probeInterval, func() { b.probeAll(prober) })
+	}
+	if b.idleTimeout > 0 {
+		b.every(evictInterval(b.idleTimeout), b.evictIdle)
 	}
+	return nil
+}
 
-	instances, err := b.discovery.GetInstances(serviceName)
-	if err != nil {
-		return nil, gone.ToError(err)
+// Stop stop probes and watchers of services, no watcher is started after balancer is stopped
+func (b *balancer) Stop() error {
+	b.lock.Lock()
+	if b.stopped {
+		b.lock.Unlock()
+		return nil
 	}
-	b.m.Store(serviceName, instances)
+	b.stopped = true
+	if b.stop != nil {
+		close(b.stop)
+	}
+	b.lock.Unlock()
+	b.wg.Wait()
+	return nil
+}
+
+// run start fn in a goroutine, done passed to fn is closed when balancer is stopped; fn is not started and false is
+// returned if balancer has been stopped.
+func (b *balancer) run(fn func(done <-chan struct{})) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.stopped {
+		return false
+	}
+	if b.stop == nil {
+		b.stop = make(chan struct{})
+	}
+	done := b.stop
+	b.wg.Add(1)
 	go func() {
-		defer g.Recover(b.logger)
+		defer b.wg.Done()
+		fn(done)
+	}()
+	return true
+}
 
-		ch, stop, err := b.discovery.Watch(serviceName)
-		if err != nil {
-			b.logger.Errorf("balancer watch %s err: %v", serviceName, err)
-			return
-		}
-		defer stop()
-		b.logger.Debugf("balancer watch %s", serviceName)
+// every run fn every interval until balancer is stopped
+func (b *balancer) every(interval time.Duration, fn func()) {
+	b.run(func(done <-chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
 		for {
 			select {
-			case instances = <-ch:
-				b.logger.Debugf("balancer watch %s update: %#v", serviceName, instances)
-				b.m.Store(serviceName, instances)
+			case <-ticker.C:
+				fn()
+			case <-done:
+				return
 			}
 		}
-	}()
-	return instances, nil
+	})
+}
+
+func (b *balancer) GetInstance(ctx context.Context, serviceName string) (g.Service, error) {
+	instances, err := b.GetInstancesWithCacheAndWatch(serviceName)
+	if err != nil {
+		return nil, gone.ToError(err)
+	}
+	return b.strategyOf(serviceName).Select(ctx, b.Route(ctx, serviceName, b.Available(serviceName, instances)))
+}
+
+// GetInstancesWithCacheAndWatch return the cached instances of service; instances are got from discovery at the first
+// time, and kept updated by watching discovery until the service is evicted or balancer is stopped.
+func (b *balancer) GetInstancesWithCacheAndWatch(serviceName string) ([]g.Service, error) {
+	if value, ok := b.m.Load(serviceName); ok {
+		b.touch(serviceName)
+		return value.([]g.Service), nil
+	}
+
+	instances, err := b.discovery.GetInstances(serviceName)
+	if err != nil {
+		return nil, gone.ToError(err)
+	}
+	return b.cache(serviceName, instances), nil
 }
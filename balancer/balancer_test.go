@@ -8,6 +8,7 @@ import (
 	"time"
 
 	mock "github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/balancer/strategy"
 	"github.com/gone-io/goner/g"
 	gMock "github.com/gone-io/goner/g/mock"
 	"github.com/stretchr/testify/assert"
@@ -29,6 +30,7 @@ func TestBalancer_GetInstance(t *testing.T) {
 	mockStrategy := gMock.NewMockLoadBalanceStrategy(ctrl)
 	mockLogger := mock.NewMockLogger(ctrl)
 	mockService := gMock.NewMockService(ctrl)
+	mockService.EXPECT().IsHealthy().Return(true).AnyTimes()
 
 	// 创建balancer实例
 	b := &balancer{
@@ -46,7 +48,11 @@ func TestBalancer_GetInstance(t *testing.T) {
 
 		// 设置模拟行为
 		mockDiscovery.EXPECT().GetInstances(serviceName).Return(instances, nil)
-		mockDiscovery.EXPECT().Watch(serviceName).Return(make(<-chan []g.Service), func() error { return nil }, nil)
+		watched := make(chan struct{})
+		mockDiscovery.EXPECT().Watch(serviceName).DoAndReturn(func(string) (<-chan []g.Service, func() error, error) {
+			close(watched)
+			return make(<-chan []g.Service), func() error { return nil }, nil
+		})
 		mockStrategy.EXPECT().Select(ctx, instances).Return(mockService, nil)
 		mockLogger.EXPECT().Debugf(gomock.Any(), gomock.Any()).AnyTimes()
 
@@ -56,6 +62,7 @@ func TestBalancer_GetInstance(t *testing.T) {
 		// 验证结果
 		assert.NoError(t, err)
 		assert.Equal(t, mockService, service)
+		<-watched
 	})
 
 	// 测试场景2: 缓存中已有服务实例
@@ -233,5 +240,41 @@ func TestBalancer_GetInstancesWithCacheAndWatch(t *testing.T) {
 		assert.Equal(t, instances, result)
 
 		time.Sleep(10 * time.Millisecond)
+		assert.NoError(t, b.Stop())
 	})
 }
+
+func TestBalancer_strategies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	defaultStrategy := gMock.NewMockLoadBalanceStrategy(ctrl)
+	b := &balancer{
+		strategy:      defaultStrategy,
+		logger:        mock.GetDefaultLogger(),
+		strategyNames: map[string]string{"user": strategy.LeastRequest, "order": strategy.ZoneAffinity},
+		zone:          "a",
+		zoneKey:       "zone",
+	}
+	assert.NoError(t, b.Init())
+	assert.Equal(t, defaultStrategy, b.strategyOf("goods"))
+	assert.Equal(t, &strategy.ZoneAffinityStrategy{Zone: "a", ZoneKey: "zone"}, b.strategyOf("order"))
+
+	// the strategy configured for service is used, and receives feedback
+	instance := createTestService("user", "127.0.0.1", 8080)
+	b.m.Store("user", []g.Service{instance})
+	service, err := b.GetInstance(context.Background(), "user")
+	assert.NoError(t, err)
+	assert.Equal(t, instance, service)
+	b.Feedback("user", "127.0.0.1:8080", nil, time.Millisecond)
+
+	// clients picking instances themselves select by the strategy of service
+	other := createTestService("goods", "127.0.0.1", 8081)
+	defaultStrategy.EXPECT().Select(gomock.Any(), []g.Service{other}).Return(other, nil)
+	service, err = b.Select(context.Background(), "goods", []g.Service{other})
+	assert.NoError(t, err)
+	assert.Equal(t, other, service)
+
+	b.strategyNames = map[string]string{"user": "unknown"}
+	assert.Error(t, b.Init())
+}
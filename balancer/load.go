@@ -23,6 +23,26 @@ func LoadWeightStrategy(loader gone.Loader) error {
 	return loader.Load(&strategy.WeightStrategy{}, gone.Name(Strategy), gone.ForceReplace())
 }
 
+func LoadLeastRequestStrategy(loader gone.Loader) error {
+	return loader.Load(&strategy.LeastRequestStrategy{}, gone.Name(Strategy), gone.ForceReplace())
+}
+
+func LoadP2CEWMAStrategy(loader gone.Loader) error {
+	return loader.Load(&strategy.P2CEWMAStrategy{}, gone.Name(Strategy), gone.ForceReplace())
+}
+
+func LoadRingHashStrategy(loader gone.Loader) error {
+	return loader.Load(&strategy.RingHashStrategy{}, gone.Name(Strategy), gone.ForceReplace())
+}
+
+func LoadMaglevStrategy(loader gone.Loader) error {
+	return loader.Load(&strategy.MaglevStrategy{}, gone.Name(Strategy), gone.ForceReplace())
+}
+
+func LoadZoneAffinityStrategy(loader gone.Loader) error {
+	return loader.Load(&strategy.ZoneAffinityStrategy{}, gone.Name(Strategy), gone.ForceReplace())
+}
+
 func LoadCustomerStrategy[T interface {
 	g.LoadBalanceStrategy
 	gone.Goner
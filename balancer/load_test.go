@@ -77,3 +77,32 @@ func TestLoadWeightStrategy(t *testing.T) {
 func TestLoadCustomerStrategy(t *testing.T) {
 	LoadCustomerStrategy(&strategy.RandomStrategy{})
 }
+
+func TestLoadStrategies(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	discovery := gMock.NewMockServiceDiscovery(controller)
+	provider := gone.WrapFunctionProvider(func(tagConf string, param struct{}) (g.ServiceDiscovery, error) {
+		return discovery, nil
+	})
+
+	tests := []struct {
+		load gone.LoadFunc
+		want g.LoadBalanceStrategy
+	}{
+		{load: LoadLeastRequestStrategy, want: &strategy.LeastRequestStrategy{}},
+		{load: LoadP2CEWMAStrategy, want: &strategy.P2CEWMAStrategy{}},
+		{load: LoadRingHashStrategy, want: &strategy.RingHashStrategy{}},
+		{load: LoadMaglevStrategy, want: &strategy.MaglevStrategy{}},
+		{load: LoadZoneAffinityStrategy, want: &strategy.ZoneAffinityStrategy{ZoneKey: "zone"}},
+	}
+	for _, tt := range tests {
+		gone.
+			NewApp(Load, tt.load).
+			Load(provider).
+			Run(func(s g.LoadBalanceStrategy) {
+				assert.IsType(t, tt.want, s)
+			})
+	}
+}
@@ -0,0 +1,148 @@
+package balancer
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/gone-io/goner/balancer/strategy"
+	"github.com/gone-io/goner/g"
+)
+
+// instanceState the health state of an instance tracked by balancer
+type instanceState struct {
+	// passive outlier ejection
+	failures     int
+	ejections    int
+	ejectedUntil time.Time
+
+	// active probe
+	probeFailures  int
+	probeSuccesses int
+	probeUnhealthy bool
+}
+
+func (s *instanceState) available(now time.Time) bool {
+	return !s.probeUnhealthy && !now.Before(s.ejectedUntil)
+}
+
+func address(instance g.Service) string {
+	return net.JoinHostPort(instance.GetIP(), strconv.Itoa(instance.GetPort()))
+}
+
+func (b *balancer) clock() time.Time {
+	if b.now != nil {
+		return b.now()
+	}
+	return time.Now()
+}
+
+// state return the state of instance, it must be called with lock held
+func (b *balancer) state(serviceName, address string) *instanceState {
+	if b.states == nil {
+		b.states = make(map[string]map[string]*instanceState)
+	}
+	states := b.states[serviceName]
+	if states == nil {
+		states = make(map[string]*instanceState)
+		b.states[serviceName] = states
+	}
+	s := states[address]
+	if s == nil {
+		s = &instanceState{}
+		states[address] = s
+	}
+	return s
+}
+
+// Available filter out the instances which are unhealthy reported by discovery, failed active probes or are ejected.
+// The services not resolved by balancer before, eg: the ones resolved by gRPC resolver, are cached and watched from
+// now on, so that their instances are probed; and they are marked as used, so that they are not evicted as idle.
+func (b *balancer) Available(serviceName string, instances []g.Service) []g.Service {
+	if _, ok := b.m.Load(serviceName); ok {
+		b.touch(serviceName)
+	} else if _, err := b.GetInstancesWithCacheAndWatch(serviceName); err != nil {
+		b.logger.Warnf("balancer cache %s err: %v", serviceName, err)
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	states := b.states[serviceName]
+	now := b.clock()
+	available := make([]g.Service, 0, len(instances))
+	for _, instance := range instances {
+		if !instance.IsHealthy() {
+			continue
+		}
+		if len(states) > 0 {
+			if s := states[address(instance)]; s != nil && !s.available(now) {
+				continue
+			}
+		}
+		available = append(available, instance)
+	}
+	return available
+}
+
+// Feedback count consecutive failures of the instance, and eject it when `balancer.outlier.consecutive-failures` is
+// reached; calls slower than `balancer.outlier.max-latency` are counted as failures. The ejection time grows with
+// the times the instance was ejected, up to `balancer.outlier.max-ejection-time`. The feedback is also forwarded to
+// the strategy of the service if it implements strategy.Feedback, so only the calls to the instances selected by
+// GetInstance or Select should be reported.
+func (b *balancer) Feedback(serviceName, address string, err error, latency time.Duration) {
+	if f, ok := b.strategyOf(serviceName).(strategy.Feedback); ok {
+		f.Feedback(serviceName, address, err, latency)
+	}
+	if !b.outlierEnabled {
+		return
+	}
+	failed := err != nil || b.maxLatency > 0 && latency > b.maxLatency
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	s := b.state(serviceName, address)
+	now := b.clock()
+	if !failed {
+		s.failures = 0
+		if s.ejections > 0 && now.Sub(s.ejectedUntil) >= b.maxEjectionTime {
+			s.ejections = 0
+		}
+		return
+	}
+
+	s.failures++
+	if s.failures < b.consecutiveFailures || now.Before(s.ejectedUntil) {
+		return
+	}
+	if !b.canEject(serviceName, now) {
+		b.logger.Warnf("balancer cannot eject %s of %s: max-ejection-percent(%d) reached", address, serviceName, b.maxEjectionPercent)
+		return
+	}
+
+	s.failures = 0
+	s.ejections++
+	ejection := b.baseEjectionTime * time.Duration(s.ejections)
+	if ejection > b.maxEjectionTime {
+		ejection = b.maxEjectionTime
+	}
+	s.ejectedUntil = now.Add(ejection)
+	b.logger.Warnf("balancer eject %s of %s for %v", address, serviceName, ejection)
+}
+
+// canEject return true if one more instance can be ejected without exceeding `balancer.outlier.max-ejection-percent`
+func (b *balancer) canEject(serviceName string, now time.Time) bool {
+	value, ok := b.m.Load(serviceName)
+	if !ok {
+		return true
+	}
+	total := len(value.([]g.Service))
+	ejected := 0
+	for _, s := range b.states[serviceName] {
+		if now.Before(s.ejectedUntil) {
+			ejected++
+		}
+	}
+	return (ejected+1)*100 <= b.maxEjectionPercent*total
+}
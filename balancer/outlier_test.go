@@ -0,0 +1,126 @@
+package balancer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	gMock "github.com/gone-io/goner/g/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newOutlierBalancer(instances ...g.Service) (*balancer, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	b := &balancer{
+		logger:              gone.GetDefaultLogger(),
+		outlierEnabled:      true,
+		consecutiveFailures: 2,
+		maxLatency:          time.Second,
+		baseEjectionTime:    10 * time.Second,
+		maxEjectionTime:     25 * time.Second,
+		maxEjectionPercent:  50,
+		now:                 func() time.Time { return now },
+	}
+	b.m.Store("user", instances)
+	return b, &now
+}
+
+func TestBalancer_Available(t *testing.T) {
+	healthy := g.NewService("user", "127.0.0.1", 8080, nil, true, 1)
+	unhealthy := g.NewService("user", "127.0.0.1", 8081, nil, false, 1)
+	b, _ := newOutlierBalancer(healthy, unhealthy)
+
+	assert.Equal(t, []g.Service{healthy}, b.Available("user", []g.Service{healthy, unhealthy}))
+
+	b.lock.Lock()
+	b.state("user", "127.0.0.1:8080").probeUnhealthy = true
+	b.lock.Unlock()
+	assert.Empty(t, b.Available("user", []g.Service{healthy, unhealthy}))
+}
+
+func TestBalancer_Available_Uncached(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	instance := g.NewService("order", "127.0.0.1", 8080, nil, true, 1)
+	discovery := gMock.NewMockServiceDiscovery(controller)
+	discovery.EXPECT().GetInstances("order").Return([]g.Service{instance}, nil)
+	discovery.EXPECT().Watch("order").Return(make(chan []g.Service), func() error { return nil }, nil).AnyTimes()
+	discovery.EXPECT().GetInstances("missing").Return(nil, errors.New("not found"))
+
+	b, now := newOutlierBalancer()
+	b.discovery = discovery
+	b.idleTimeout = time.Minute
+
+	// services filtered before resolved by balancer are cached to be probed
+	assert.Equal(t, []g.Service{instance}, b.Available("order", []g.Service{instance}))
+	_, ok := b.m.Load("order")
+	assert.True(t, ok)
+
+	// services used only through Available are not evicted as idle
+	for i := 0; i < 3; i++ {
+		*now = now.Add(40 * time.Second)
+		b.Available("order", []g.Service{instance})
+		b.evictIdle()
+	}
+	_, ok = b.m.Load("order")
+	assert.True(t, ok)
+
+	assert.Equal(t, []g.Service{instance}, b.Available("missing", []g.Service{instance}))
+}
+
+func TestBalancer_Feedback(t *testing.T) {
+	instances := []g.Service{
+		g.NewService("user", "127.0.0.1", 8080, nil, true, 1),
+		g.NewService("user", "127.0.0.1", 8081, nil, true, 1),
+		g.NewService("user", "127.0.0.1", 8082, nil, true, 1),
+		g.NewService("user", "127.0.0.1", 8083, nil, true, 1),
+	}
+	b, now := newOutlierBalancer(instances...)
+	err := errors.New("error")
+
+	// a success resets consecutive failures
+	b.Feedback("user", "127.0.0.1:8080", err, 0)
+	b.Feedback("user", "127.0.0.1:8080", nil, 0)
+	b.Feedback("user", "127.0.0.1:8080", err, 0)
+	assert.Len(t, b.Available("user", instances), 4)
+
+	// slow calls are failures
+	b.Feedback("user", "127.0.0.1:8080", nil, 2*time.Second)
+	assert.Equal(t, instances[1:], b.Available("user", instances))
+
+	// at most 50% instances are ejected
+	b.Feedback("user", "127.0.0.1:8081", err, 0)
+	b.Feedback("user", "127.0.0.1:8081", err, 0)
+	b.Feedback("user", "127.0.0.1:8082", err, 0)
+	b.Feedback("user", "127.0.0.1:8082", err, 0)
+	assert.Equal(t, instances[2:], b.Available("user", instances))
+
+	// the instance returns after ejection time, and is ejected longer next time
+	*now = now.Add(10 * time.Second)
+	assert.Len(t, b.Available("user", instances), 4)
+	b.Feedback("user", "127.0.0.1:8080", err, 0)
+	b.Feedback("user", "127.0.0.1:8080", err, 0)
+	*now = now.Add(10 * time.Second)
+	assert.Equal(t, instances[1:], b.Available("user", instances))
+	*now = now.Add(10 * time.Second)
+	assert.Len(t, b.Available("user", instances), 4)
+
+	// ejection time is capped
+	b.Feedback("user", "127.0.0.1:8080", err, 0)
+	b.Feedback("user", "127.0.0.1:8080", err, 0)
+	assert.Equal(t, *now, b.states["user"]["127.0.0.1:8080"].ejectedUntil.Add(-25*time.Second))
+
+	// ejections are reset after the instance keeps healthy long enough
+	*now = now.Add(50 * time.Second)
+	b.Feedback("user", "127.0.0.1:8080", nil, 0)
+	assert.Equal(t, 0, b.states["user"]["127.0.0.1:8080"].ejections)
+
+	// feedback is ignored when outlier ejection is disabled
+	b.outlierEnabled = false
+	b.Feedback("user", "127.0.0.1:8083", err, 0)
+	assert.Nil(t, b.states["user"]["127.0.0.1:8083"])
+}
@@ -0,0 +1,143 @@
+package balancer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+)
+
+const (
+	ProbeTCP  = "tcp"
+	ProbeHTTP = "http"
+	ProbeGRPC = "grpc"
+)
+
+var _ g.InstanceProber = (*tcpProber)(nil)
+var _ g.InstanceProber = (*httpProber)(nil)
+
+// tcpProber the instance is healthy if a TCP connection can be established
+type tcpProber struct{}
+
+func (p *tcpProber) ProbeType() string {
+	return ProbeTCP
+}
+
+func (p *tcpProber) Probe(ctx context.Context, instance g.Service) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address(instance))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// httpProber the instance is healthy if `GET http://{ip}:{port}{path}` responds 2xx or 3xx
+type httpProber struct {
+	path   string
+	client *http.Client
+}
+
+func (p *httpProber) ProbeType() string {
+	return ProbeHTTP
+}
+
+func (p *httpProber) Probe(ctx context.Context, instance g.Service) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s%s", address(instance), p.path), nil)
+	if err != nil {
+		return err
+	}
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode >= http.StatusBadRequest {
+		return gone.ToError(fmt.Sprintf("probe %s got status %d", req.URL, res.StatusCode))
+	}
+	return nil
+}
+
+// prober find the prober of `balancer.probe.type`, probers injected take precedence over builtin ones
+func (b *balancer) prober() (g.InstanceProber, error) {
+	for _, p := range b.probers {
+		if p.ProbeType() == b.probeType {
+			return p, nil
+		}
+	}
+	switch b.probeType {
+	case ProbeTCP:
+		return &tcpProber{}, nil
+	case ProbeHTTP:
+		return &httpProber{path: b.probePath, client: &http.Client{}}, nil
+	}
+	return nil, gone.NewInnerErrorWithParams(gone.ConfigError, "unsupported balancer.probe.type(%s)", b.probeType)
+}
+
+// probeAll probe all instances of the services cached concurrently, and update their states
+func (b *balancer) probeAll(prober g.InstanceProber) {
+	type result struct {
+		serviceName string
+		address     string
+		err         error
+	}
+	var results []*result
+	var wg sync.WaitGroup
+	services := make(map[string]map[string]bool)
+
+	b.m.Range(func(key, value any) bool {
+		serviceName := key.(string)
+		addresses := make(map[string]bool)
+		services[serviceName] = addresses
+		for _, instance := range value.([]g.Service) {
+			r := &result{serviceName: serviceName, address: address(instance)}
+			addresses[r.address] = true
+			results = append(results, r)
+			wg.Add(1)
+			go func(instance g.Service) {
+				defer wg.Done()
+				defer g.Recover(b.logger)
+				ctx, cancel := context.WithTimeout(context.Background(), b.probeTimeout)
+				defer cancel()
+				r.err = prober.Probe(ctx, instance)
+			}(instance)
+		}
+		return true
+	})
+	wg.Wait()
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	// forget the instances which are gone
+	for serviceName, states := range b.states {
+		for addr := range states {
+			if !services[serviceName][addr] {
+				delete(states, addr)
+			}
+		}
+	}
+
+	for _, r := range results {
+		s := b.state(r.serviceName, r.address)
+		if r.err == nil {
+			s.probeFailures = 0
+			s.probeSuccesses++
+			if s.probeUnhealthy && s.probeSuccesses >= b.healthyThreshold {
+				s.probeUnhealthy = false
+				b.logger.Infof("balancer probe %s of %s healthy", r.address, r.serviceName)
+			}
+			continue
+		}
+		s.probeSuccesses = 0
+		s.probeFailures++
+		if !s.probeUnhealthy && s.probeFailures >= b.unhealthyThreshold {
+			s.probeUnhealthy = true
+			b.logger.Warnf("balancer probe %s of %s unhealthy: %v", r.address, r.serviceName, r.err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package balancer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"github.com/stretchr/testify/assert"
+)
+
+func serviceOf(t *testing.T, addr string) g.Service {
+	host, port, err := net.SplitHostPort(addr)
+	assert.Nil(t, err)
+	p, _ := strconv.Atoi(port)
+	return g.NewService("user", host, p, nil, true, 1)
+}
+
+func TestProbers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	instance := serviceOf(t, server.Listener.Addr().String())
+	ctx := context.Background()
+
+	b := &balancer{probeType: ProbeHTTP, probePath: "/health"}
+	prober, err := b.prober()
+	assert.Nil(t, err)
+	assert.Nil(t, prober.Probe(ctx, instance))
+	assert.Error(t, (&httpProber{path: "/other", client: &http.Client{}}).Probe(ctx, instance))
+
+	b.probeType = ProbeTCP
+	prober, err = b.prober()
+	assert.Nil(t, err)
+	assert.Nil(t, prober.Probe(ctx, instance))
+
+	server.Close()
+	assert.Error(t, prober.Probe(ctx, instance))
+
+	b.probeType = ProbeGRPC
+	_, err = b.prober()
+	assert.Error(t, err)
+}
+
+type fakeProber struct {
+	fail atomic.Bool
+}
+
+func (p *fakeProber) ProbeType() string {
+	return ProbeGRPC
+}
+
+func (p *fakeProber) Probe(context.Context, g.Service) error {
+	if p.fail.Load() {
+		return errors.New("unhealthy")
+	}
+	return nil
+}
+
+func TestBalancer_probe(t *testing.T) {
+	prober := &fakeProber{}
+	instance := g.NewService("user", "127.0.0.1", 8080, nil, true, 1)
+	b := &balancer{
+		logger:             gone.GetDefaultLogger(),
+		probers:            []g.InstanceProber{prober},
+		probeType:          ProbeGRPC,
+		probeInterval:      time.Millisecond,
+		probeTimeout:       time.Second,
+		healthyThreshold:   2,
+		unhealthyThreshold: 2,
+	}
+	b.m.Store("user", []g.Service{instance})
+	instances := []g.Service{instance}
+
+	prober.fail.Store(true)
+	b.probeAll(prober)
+	assert.Len(t, b.Available("user", instances), 1)
+	b.probeAll(prober)
+	assert.Empty(t, b.Available("user", instances))
+
+	prober.fail.Store(false)
+	b.probeAll(prober)
+	assert.Empty(t, b.Available("user", instances))
+	b.probeAll(prober)
+	assert.Len(t, b.Available("user", instances), 1)
+
+	// states of instances removed from discovery are forgotten
+	b.m.Store("user", []g.Service{})
+	b.probeAll(prober)
+	assert.Empty(t, b.states["user"])
+
+	// probes run in background between Start and Stop
+	b.m.Store("user", instances)
+	prober.fail.Store(true)
+	assert.Nil(t, b.Start())
+	assert.Eventually(t, func() bool {
+		return len(b.Available("user", instances)) == 0
+	}, time.Second, time.Millisecond)
+	assert.Nil(t, b.Stop())
+	assert.Nil(t, b.Stop())
+
+	b.probeType = "icmp"
+	assert.Error(t, b.Start())
+}
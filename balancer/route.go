@@ -0,0 +1,155 @@
+package balancer
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+)
+
+// routesKey the config key of routing rules, which can be updated at runtime by dynamic configure
+const routesKey = "balancer.routes"
+
+// RouteRule route the requests to a service which match all attributes in Match to Destinations, rules of the same
+// service are evaluated in order and the first matched one is applied.
+type RouteRule struct {
+	// Service 规则适用的服务名
+	Service string `mapstructure:"service" json:"service" yaml:"service"`
+
+	// Match 请求属性需要全部匹配的值，键不区分大小写，为空时匹配所有请求
+	Match map[string]string `mapstructure:"match" json:"match" yaml:"match"`
+
+	// Destinations 按权重分配流量的实例子集
+	Destinations []RouteDestination `mapstructure:"destinations" json:"destinations" yaml:"destinations"`
+}
+
+// RouteDestination a subset of instances, whose metadata contain all entries of Subset
+type RouteDestination struct {
+	// Subset 实例元数据需要包含的键值
+	Subset g.Metadata `mapstructure:"subset" json:"subset" yaml:"subset"`
+
+	// Weight 流量权重，全部为0时平均分配
+	Weight int `mapstructure:"weight" json:"weight" yaml:"weight"`
+}
+
+func (r *RouteRule) init(idx int) error {
+	if r.Service == "" {
+		return gone.NewInnerErrorWithParams(gone.ConfigError, "%s[%d].service is empty", routesKey, idx)
+	}
+	if len(r.Destinations) == 0 {
+		return gone.NewInnerErrorWithParams(gone.ConfigError, "%s[%d].destinations is empty", routesKey, idx)
+	}
+	match := make(map[string]string, len(r.Match))
+	for k, v := range r.Match {
+		match[strings.ToLower(k)] = v
+	}
+	r.Match = match
+	for _, d := range r.Destinations {
+		if d.Weight < 0 {
+			return gone.NewInnerErrorWithParams(gone.ConfigError, "%s[%d].destinations has negative weight", routesKey, idx)
+		}
+	}
+	return nil
+}
+
+func (r *RouteRule) matches(attributes map[string]string) bool {
+	for k, v := range r.Match {
+		if attribute, ok := attributes[k]; !ok || attribute != v {
+			return false
+		}
+	}
+	return true
+}
+
+// destination pick a destination randomly by weight
+func (r *RouteRule) destination() *RouteDestination {
+	total := 0
+	for _, d := range r.Destinations {
+		total += d.Weight
+	}
+	if total == 0 {
+		return &r.Destinations[rand.IntN(len(r.Destinations))]
+	}
+	n := rand.IntN(total)
+	for i := range r.Destinations {
+		if n -= r.Destinations[i].Weight; n < 0 {
+			return &r.Destinations[i]
+		}
+	}
+	return &r.Destinations[len(r.Destinations)-1]
+}
+
+func (d *RouteDestination) contains(instance g.Service) bool {
+	metadata := instance.GetMetadata()
+	for k, v := range d.Subset {
+		if value, ok := metadata[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// buildRoutes validate rules and group them by service
+func buildRoutes(rules []RouteRule) (map[string][]RouteRule, error) {
+	routes := make(map[string][]RouteRule)
+	for i := range rules {
+		rule := rules[i]
+		if err := rule.init(i); err != nil {
+			return nil, err
+		}
+		routes[rule.Service] = append(routes[rule.Service], rule)
+	}
+	return routes, nil
+}
+
+// watchRoutes reload routing rules when `balancer.routes` changes, invalid rules are ignored and the old ones are kept
+func (b *balancer) watchRoutes() {
+	if b.watcher == nil {
+		return
+	}
+	b.watcher(routesKey, func(_, _ any) {
+		var rules []RouteRule
+		if err := b.configure.Get(routesKey, &rules, ""); err != nil {
+			b.logger.Errorf("balancer reload %s err: %v", routesKey, err)
+			return
+		}
+		routes, err := buildRoutes(rules)
+		if err != nil {
+			b.logger.Errorf("balancer reload %s err: %v", routesKey, err)
+			return
+		}
+		b.routes.Store(&routes)
+		b.logger.Infof("balancer reload %s: %d rules", routesKey, len(rules))
+	})
+}
+
+// Route select the instances by the first rule of service matching the request attributes in ctx; all instances are
+// returned if no rule matches, or no instance is in the subset of destination picked.
+func (b *balancer) Route(ctx context.Context, serviceName string, instances []g.Service) []g.Service {
+	routes := b.routes.Load()
+	if routes == nil || len((*routes)[serviceName]) == 0 {
+		return instances
+	}
+	attributes := g.RouteAttributesFrom(ctx)
+	rules := (*routes)[serviceName]
+	for i := range rules {
+		if !rules[i].matches(attributes) {
+			continue
+		}
+		destination := rules[i].destination()
+		subset := make([]g.Service, 0, len(instances))
+		for _, instance := range instances {
+			if destination.contains(instance) {
+				subset = append(subset, instance)
+			}
+		}
+		if len(subset) == 0 {
+			b.logger.Debugf("balancer route %s: no instance in subset %v, fallback to all instances", serviceName, destination.Subset)
+			return instances
+		}
+		return subset
+	}
+	return instances
+}
@@ -0,0 +1,122 @@
+package balancer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBalancer_route(t *testing.T) {
+	v1 := g.NewService("user", "127.0.0.1", 8080, g.Metadata{"version": "v1"}, true, 1)
+	v2 := g.NewService("user", "127.0.0.1", 8081, g.Metadata{"version": "v2", "lane": "gray"}, true, 1)
+	instances := []g.Service{v1, v2}
+
+	b := &balancer{
+		logger: gone.GetDefaultLogger(),
+		routeRules: []RouteRule{
+			{
+				Service:      "user",
+				Match:        map[string]string{"X-Lane": "gray"},
+				Destinations: []RouteDestination{{Subset: g.Metadata{"lane": "gray"}}},
+			},
+			{
+				Service: "user",
+				Destinations: []RouteDestination{
+					{Subset: g.Metadata{"version": "v1"}, Weight: 90},
+					{Subset: g.Metadata{"version": "v2"}, Weight: 10},
+				},
+			},
+			{
+				Service:      "order",
+				Destinations: []RouteDestination{{Subset: g.Metadata{"version": "v3"}}},
+			},
+		},
+	}
+	assert.NoError(t, b.Init())
+
+	// requests are routed by attributes
+	ctx := g.WithRouteAttributes(context.Background(), map[string]string{"x-lane": "gray"})
+	assert.Equal(t, []g.Service{v2}, b.Route(ctx, "user", instances))
+
+	// traffic is split by weight
+	counts := make(map[g.Service]int)
+	for i := 0; i < 1000; i++ {
+		subset := b.Route(context.Background(), "user", instances)
+		assert.Len(t, subset, 1)
+		counts[subset[0]]++
+	}
+	assert.Greater(t, counts[v1], 800)
+	assert.Greater(t, counts[v2], 40)
+
+	// all instances are used if no instance is in the subset or no rule is configured
+	assert.Equal(t, instances, b.Route(context.Background(), "order", instances))
+	assert.Equal(t, instances, b.Route(context.Background(), "goods", instances))
+}
+
+func TestRouteRule_init(t *testing.T) {
+	tests := []RouteRule{
+		{Destinations: []RouteDestination{{}}},
+		{Service: "user"},
+		{Service: "user", Destinations: []RouteDestination{{Weight: -1}}},
+	}
+	for _, rule := range tests {
+		_, err := buildRoutes([]RouteRule{rule})
+		assert.Error(t, err)
+	}
+
+	// destinations are picked evenly when all weights are zero
+	rule := RouteRule{Service: "user", Destinations: []RouteDestination{{}, {}}}
+	picked := make(map[*RouteDestination]bool)
+	for i := 0; i < 100; i++ {
+		picked[rule.destination()] = true
+	}
+	assert.Len(t, picked, 2)
+}
+
+func TestBalancer_watchRoutes(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	var callback gone.ConfWatchFunc
+	configure := gone.NewMockConfigure(controller)
+	b := &balancer{
+		logger:    gone.GetDefaultLogger(),
+		configure: configure,
+		watcher: func(key string, fn gone.ConfWatchFunc) {
+			assert.Equal(t, routesKey, key)
+			callback = fn
+		},
+	}
+	assert.NoError(t, b.Init())
+	assert.NotNil(t, callback)
+
+	v1 := g.NewService("user", "127.0.0.1", 8080, g.Metadata{"version": "v1"}, true, 1)
+	v2 := g.NewService("user", "127.0.0.1", 8081, g.Metadata{"version": "v2"}, true, 1)
+	instances := []g.Service{v1, v2}
+
+	// shift all traffic to v2 at runtime
+	configure.EXPECT().Get(routesKey, gomock.Any(), "").DoAndReturn(func(_ string, v any, _ string) error {
+		*v.(*[]RouteRule) = []RouteRule{{
+			Service:      "user",
+			Destinations: []RouteDestination{{Subset: g.Metadata{"version": "v2"}}},
+		}}
+		return nil
+	})
+	callback(nil, nil)
+	assert.Equal(t, []g.Service{v2}, b.Route(context.Background(), "user", instances))
+
+	// invalid rules are ignored
+	configure.EXPECT().Get(routesKey, gomock.Any(), "").DoAndReturn(func(_ string, v any, _ string) error {
+		*v.(*[]RouteRule) = []RouteRule{{Service: "user"}}
+		return nil
+	})
+	callback(nil, nil)
+	configure.EXPECT().Get(routesKey, gomock.Any(), "").Return(errors.New("error"))
+	callback(nil, nil)
+	assert.Equal(t, []g.Service{v2}, b.Route(context.Background(), "user", instances))
+}
@@ -0,0 +1,222 @@
+package strategy
+
+import (
+	"container/list"
+	"context"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+)
+
+const (
+	// ringReplicas the virtual nodes of each instance on the hash ring
+	ringReplicas = 160
+
+	// maglevTableSize the size of maglev lookup table, which must be a prime much larger than the number of instances
+	maglevTableSize = 65537
+
+	// hashTablesSize the number of lookup tables cached by each strategy, so that the instance subsets switching between
+	// calls, e.g. by weighted routes or outlier ejection, do not rebuild the tables each time
+	hashTablesSize = 32
+)
+
+type hashKey struct{}
+
+// WithHashKey set the key used by consistent hash strategies to select instance, the requests with the same key are
+// sent to the same instance as long as the instances do not change.
+func WithHashKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, hashKey{}, key)
+}
+
+// HashKeyFrom get the key set by WithHashKey
+func HashKeyFrom(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(hashKey{}).(string)
+	return key, ok
+}
+
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	// fnv does not avalanche well for similar keys, mix the bits as the finalizer of murmur3
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb3fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// lookup the table built from instances to find the instance by hash of key
+type lookup interface {
+	get(h uint64) int
+}
+
+// hashTables cache the lookup tables by service name and the signature of instances, the least recently used table
+// is evicted when the cache is full
+type hashTables struct {
+	lock   sync.Mutex
+	tables map[string]*list.Element
+	recent list.List
+}
+
+type hashTable struct {
+	key       string
+	addresses []string
+	lookup    lookup
+}
+
+// get the cached table of key and mark it as recently used
+func (t *hashTables) get(key string) *hashTable {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	e := t.tables[key]
+	if e == nil {
+		return nil
+	}
+	t.recent.MoveToFront(e)
+	return e.Value.(*hashTable)
+}
+
+// put the table into cache, the table cached by others meanwhile is kept
+func (t *hashTables) put(table *hashTable) *hashTable {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if e := t.tables[table.key]; e != nil {
+		t.recent.MoveToFront(e)
+		return e.Value.(*hashTable)
+	}
+	if t.tables == nil {
+		t.tables = make(map[string]*list.Element)
+	}
+	t.tables[table.key] = t.recent.PushFront(table)
+	if t.recent.Len() > hashTablesSize {
+		oldest := t.recent.Back()
+		t.recent.Remove(oldest)
+		delete(t.tables, oldest.Value.(*hashTable).key)
+	}
+	return table
+}
+
+// selectByKey select the instance for the key from context, the instance is picked randomly if no key is set
+func (t *hashTables) selectByKey(ctx context.Context, instances []g.Service, build func(addresses []string) lookup) (g.Service, error) {
+	if len(instances) == 0 {
+		return nil, gone.ToError("no available service instances")
+	}
+	key, ok := HashKeyFrom(ctx)
+	if !ok {
+		return instances[rand.IntN(len(instances))], nil
+	}
+
+	addresses := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		addresses = append(addresses, address(instance))
+	}
+	sorted := slices.Clone(addresses)
+	sort.Strings(sorted)
+	signature := instances[0].GetName() + "/" + strings.Join(sorted, ",")
+
+	// the table is built without holding the lock, which costs much for maglev
+	table := t.get(signature)
+	if table == nil {
+		table = t.put(&hashTable{key: signature, addresses: sorted, lookup: build(sorted)})
+	}
+	return instances[slices.Index(addresses, table.addresses[table.lookup.get(hash(key))])], nil
+}
+
+var _ g.LoadBalanceStrategy = (*RingHashStrategy)(nil)
+
+// RingHashStrategy select instance by consistent hashing on a ring with virtual nodes, the key is set by WithHashKey;
+// only about 1/n keys are remapped when an instance is added or removed.
+type RingHashStrategy struct {
+	gone.Flag
+	tables hashTables
+}
+
+func (r *RingHashStrategy) Select(ctx context.Context, instances []g.Service) (g.Service, error) {
+	return r.tables.selectByKey(ctx, instances, newRing)
+}
+
+type ringNode struct {
+	hash  uint64
+	index int
+}
+
+type ring []ringNode
+
+func newRing(addresses []string) lookup {
+	nodes := make(ring, 0, len(addresses)*ringReplicas)
+	for i, addr := range addresses {
+		for j := 0; j < ringReplicas; j++ {
+			nodes = append(nodes, ringNode{hash: hash(addr + "#" + strconv.Itoa(j)), index: i})
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].hash < nodes[j].hash })
+	return nodes
+}
+
+func (r ring) get(h uint64) int {
+	i := sort.Search(len(r), func(i int) bool { return r[i].hash >= h })
+	if i == len(r) {
+		i = 0
+	}
+	return r[i].index
+}
+
+var _ g.LoadBalanceStrategy = (*MaglevStrategy)(nil)
+
+// MaglevStrategy select instance by Maglev consistent hashing, the key is set by WithHashKey; it spreads keys more
+// evenly and looks up faster than RingHashStrategy, at the cost of slightly more keys remapped when instances change.
+type MaglevStrategy struct {
+	gone.Flag
+	tables hashTables
+}
+
+func (m *MaglevStrategy) Select(ctx context.Context, instances []g.Service) (g.Service, error) {
+	return m.tables.selectByKey(ctx, instances, newMaglev)
+}
+
+type maglev []int
+
+// newMaglev populate the lookup table as described in the paper "Maglev: A Fast and Reliable Software Network Load
+// Balancer": each instance fills its preferred slots in turn until the table is full.
+func newMaglev(addresses []string) lookup {
+	n := len(addresses)
+	offsets := make([]uint64, n)
+	skips := make([]uint64, n)
+	for i, addr := range addresses {
+		offsets[i] = hash(addr) % maglevTableSize
+		skips[i] = hash(addr+"#skip")%(maglevTableSize-1) + 1
+	}
+
+	table := make(maglev, maglevTableSize)
+	for i := range table {
+		table[i] = -1
+	}
+	next := make([]uint64, n)
+	for filled := 0; ; {
+		for i := 0; i < n; i++ {
+			slot := (offsets[i] + next[i]*skips[i]) % maglevTableSize
+			for table[slot] >= 0 {
+				next[i]++
+				slot = (offsets[i] + next[i]*skips[i]) % maglevTableSize
+			}
+			table[slot] = i
+			next[i]++
+			if filled++; filled == maglevTableSize {
+				return table
+			}
+		}
+	}
+}
+
+func (m maglev) get(h uint64) int {
+	return m[h%maglevTableSize]
+}
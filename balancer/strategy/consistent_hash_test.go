@@ -0,0 +1,113 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gone-io/goner/g"
+	"github.com/stretchr/testify/assert"
+)
+
+func testConsistentHash(t *testing.T, strategy g.LoadBalanceStrategy) {
+	var instances []g.Service
+	for i := 0; i < 5; i++ {
+		instances = append(instances, createTestService("test-service", "127.0.0.1", 8080+i, 1.0))
+	}
+
+	selected := make(map[string]g.Service)
+	counts := make(map[g.Service]int)
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("user-%d", i)
+		service, err := strategy.Select(WithHashKey(context.Background(), key), instances)
+		assert.NoError(t, err)
+		selected[key] = service
+		counts[service]++
+	}
+
+	// keys are spread across all instances
+	assert.Len(t, counts, len(instances))
+	for _, count := range counts {
+		assert.Greater(t, count, 100)
+	}
+
+	// the same key is mapped to the same instance regardless of the order of instances
+	reversed := []g.Service{instances[4], instances[3], instances[2], instances[1], instances[0]}
+	service, err := strategy.Select(WithHashKey(context.Background(), "user-1"), reversed)
+	assert.NoError(t, err)
+	assert.Equal(t, selected["user-1"], service)
+
+	// only the keys of the removed instance are remapped mostly
+	remapped := 0
+	for key, want := range selected {
+		service, err := strategy.Select(WithHashKey(context.Background(), key), instances[:4])
+		assert.NoError(t, err)
+		if want != instances[4] && service != want {
+			remapped++
+		}
+	}
+	assert.Less(t, remapped, 100)
+
+	// instance is picked randomly without key
+	service, err = strategy.Select(context.Background(), instances)
+	assert.NoError(t, err)
+	assert.Contains(t, instances, service)
+
+	_, err = strategy.Select(context.Background(), nil)
+	assert.Error(t, err)
+}
+
+func TestRingHashStrategy_Select(t *testing.T) {
+	testConsistentHash(t, &RingHashStrategy{})
+}
+
+func TestMaglevStrategy_Select(t *testing.T) {
+	testConsistentHash(t, &MaglevStrategy{})
+}
+
+func TestHashKeyFrom(t *testing.T) {
+	_, ok := HashKeyFrom(context.Background())
+	assert.False(t, ok)
+
+	key, ok := HashKeyFrom(WithHashKey(context.Background(), "user-1"))
+	assert.True(t, ok)
+	assert.Equal(t, "user-1", key)
+}
+
+func TestHashTables_cache(t *testing.T) {
+	var tables hashTables
+	builds := 0
+	build := func(addresses []string) lookup {
+		builds++
+		return newRing(addresses)
+	}
+
+	var instances []g.Service
+	for i := 0; i < hashTablesSize+1; i++ {
+		instances = append(instances, createTestService("test-service", "127.0.0.1", 8080+i, 1.0))
+	}
+	ctx := WithHashKey(context.Background(), "user-1")
+
+	// the tables of instance subsets switching between calls are reused
+	for i := 0; i < 3; i++ {
+		_, err := tables.selectByKey(ctx, instances[:2], build)
+		assert.NoError(t, err)
+		_, err = tables.selectByKey(ctx, instances[1:3], build)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, builds)
+
+	// the least recently used table is evicted when the cache is full
+	for i := 0; i < hashTablesSize; i++ {
+		_, err := tables.selectByKey(ctx, instances[i:i+1], build)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, hashTablesSize, tables.recent.Len())
+	assert.Len(t, tables.tables, hashTablesSize)
+	builds = 0
+	_, err := tables.selectByKey(ctx, instances[:2], build)
+	assert.NoError(t, err)
+	_, err = tables.selectByKey(ctx, instances[hashTablesSize-1:hashTablesSize], build)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, builds)
+}
@@ -0,0 +1,50 @@
+package strategy
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+)
+
+var _ g.LoadBalanceStrategy = (*LeastRequestStrategy)(nil)
+var _ Feedback = (*LeastRequestStrategy)(nil)
+
+// LeastRequestStrategy pick two instances randomly and select the one with fewer outstanding requests. Requests are
+// outstanding from selected until their feedback is reported, so it works with clients reporting feedback, eg: urllib.
+type LeastRequestStrategy struct {
+	gone.Flag
+	tracker tracker
+}
+
+func (l *LeastRequestStrategy) Select(ctx context.Context, instances []g.Service) (g.Service, error) {
+	if len(instances) == 0 {
+		return nil, gone.ToError("no available service instances")
+	}
+	instance := instances[0]
+	if len(instances) > 1 {
+		a, b := pickTwo(len(instances))
+		instance = instances[a]
+		if l.tracker.stats(address(instances[b])).inflight.Load() < l.tracker.stats(address(instance)).inflight.Load() {
+			instance = instances[b]
+		}
+	}
+	l.tracker.stats(address(instance)).inflight.Add(1)
+	return instance, nil
+}
+
+func (l *LeastRequestStrategy) Feedback(_, address string, _ error, _ time.Duration) {
+	l.tracker.done(address)
+}
+
+// pickTwo return two different random index less than n, n must be greater than 1
+func pickTwo(n int) (int, int) {
+	a := rand.IntN(n)
+	b := rand.IntN(n - 1)
+	if b >= a {
+		b++
+	}
+	return a, b
+}
@@ -0,0 +1,44 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gone-io/goner/g"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeastRequestStrategy_Select(t *testing.T) {
+	strategy := &LeastRequestStrategy{}
+	service1 := createTestService("test-service", "127.0.0.1", 8080, 1.0)
+	service2 := createTestService("test-service", "127.0.0.1", 8081, 1.0)
+	instances := []g.Service{service1, service2}
+	ctx := context.Background()
+
+	// requests are spread across instances while outstanding
+	first, err := strategy.Select(ctx, instances)
+	assert.NoError(t, err)
+	second, err := strategy.Select(ctx, instances)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	// the instance whose requests completed is preferred
+	strategy.Feedback("test-service", address(second), nil, 0)
+	for i := 0; i < 10; i++ {
+		service, err := strategy.Select(ctx, instances)
+		assert.NoError(t, err)
+		assert.Equal(t, second, service)
+		strategy.Feedback("test-service", address(second), nil, 0)
+	}
+
+	// feedback of requests not selected by the strategy is ignored
+	strategy.Feedback("test-service", "127.0.0.1:9090", nil, 0)
+	assert.Equal(t, int64(0), strategy.tracker.stats("127.0.0.1:9090").inflight.Load())
+
+	service, err := strategy.Select(ctx, instances[:1])
+	assert.NoError(t, err)
+	assert.Equal(t, service1, service)
+
+	_, err = strategy.Select(ctx, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package strategy
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+)
+
+const (
+	// ewmaDecay the time constant of the exponentially weighted moving average of latency
+	ewmaDecay = 10 * time.Second
+
+	// failurePenalty the latency counted for failed calls which returned faster
+	failurePenalty = time.Second
+)
+
+var _ g.LoadBalanceStrategy = (*P2CEWMAStrategy)(nil)
+var _ Feedback = (*P2CEWMAStrategy)(nil)
+
+// P2CEWMAStrategy pick two instances randomly and select the one with lower cost, which is the moving average of
+// latency multiplied by outstanding requests. Instances without latency observed yet are preferred, so that new
+// instances warm up quickly.
+type P2CEWMAStrategy struct {
+	gone.Flag
+	tracker tracker
+	now     func() time.Time
+}
+
+func (p *P2CEWMAStrategy) clock() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+func (p *P2CEWMAStrategy) cost(s *stats, now time.Time) float64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	// the latency observed long ago is less reliable, so that the instance gets chances to be selected again
+	ewma := s.ewma * math.Exp(-float64(now.Sub(s.updated))/float64(ewmaDecay))
+	return ewma * float64(s.inflight.Load()+1)
+}
+
+func (p *P2CEWMAStrategy) Select(ctx context.Context, instances []g.Service) (g.Service, error) {
+	if len(instances) == 0 {
+		return nil, gone.ToError("no available service instances")
+	}
+	instance := instances[0]
+	if len(instances) > 1 {
+		now := p.clock()
+		a, b := pickTwo(len(instances))
+		instance = instances[a]
+		if p.cost(p.tracker.stats(address(instances[b])), now) < p.cost(p.tracker.stats(address(instance)), now) {
+			instance = instances[b]
+		}
+	}
+	p.tracker.stats(address(instance)).inflight.Add(1)
+	return instance, nil
+}
+
+func (p *P2CEWMAStrategy) Feedback(_, address string, err error, latency time.Duration) {
+	if err != nil && latency < failurePenalty {
+		latency = failurePenalty
+	}
+	s := p.tracker.done(address)
+	now := p.clock()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.updated.IsZero() {
+		s.ewma = float64(latency)
+	} else {
+		w := math.Exp(-float64(now.Sub(s.updated)) / float64(ewmaDecay))
+		s.ewma = s.ewma*w + float64(latency)*(1-w)
+	}
+	s.updated = now
+}
@@ -0,0 +1,48 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gone-io/goner/g"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestP2CEWMAStrategy_Select(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	strategy := &P2CEWMAStrategy{now: func() time.Time { return now }}
+	fast := createTestService("test-service", "127.0.0.1", 8080, 1.0)
+	slow := createTestService("test-service", "127.0.0.1", 8081, 1.0)
+	instances := []g.Service{fast, slow}
+	ctx := context.Background()
+
+	strategy.Feedback("test-service", address(fast), nil, 10*time.Millisecond)
+	strategy.Feedback("test-service", address(slow), nil, 100*time.Millisecond)
+	for i := 0; i < 10; i++ {
+		service, err := strategy.Select(ctx, instances)
+		assert.NoError(t, err)
+		assert.Equal(t, fast, service)
+		strategy.Feedback("test-service", address(fast), nil, 10*time.Millisecond)
+	}
+
+	// failures are penalized
+	now = now.Add(time.Second)
+	strategy.Feedback("test-service", address(fast), errors.New("error"), time.Millisecond)
+	assert.Greater(t, strategy.tracker.stats(address(fast)).ewma, float64(10*time.Millisecond))
+
+	// outstanding requests increase the cost
+	strategy.Feedback("test-service", address(slow), nil, 10*time.Millisecond)
+	strategy.tracker.stats(address(fast)).inflight.Store(100)
+	service, err := strategy.Select(ctx, instances)
+	assert.NoError(t, err)
+	assert.Equal(t, slow, service)
+
+	service, err = strategy.Select(ctx, instances[:1])
+	assert.NoError(t, err)
+	assert.Equal(t, fast, service)
+
+	_, err = strategy.Select(ctx, nil)
+	assert.Error(t, err)
+}
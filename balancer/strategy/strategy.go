@@ -0,0 +1,90 @@
+package strategy
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+)
+
+// names of the builtin strategies, which can be used in `balancer.strategies` to select strategy per service
+const (
+	RoundRobin   = "round-robin"
+	Random       = "random"
+	Weight       = "weight"
+	LeastRequest = "least-request"
+	P2CEWMA      = "p2c-ewma"
+	RingHash     = "ring-hash"
+	Maglev       = "maglev"
+	ZoneAffinity = "zone-affinity"
+)
+
+// Feedback is implemented by the strategies which select instances by the outcome of calls, eg: LeastRequestStrategy
+// and P2CEWMAStrategy; the balancer forwards the feedback reported by clients to them.
+type Feedback interface {
+	Feedback(serviceName, address string, err error, latency time.Duration)
+}
+
+// New create a builtin strategy by name
+func New(name string) (g.LoadBalanceStrategy, error) {
+	switch name {
+	case RoundRobin:
+		return &RoundRobinStrategy{}, nil
+	case Random:
+		return &RandomStrategy{}, nil
+	case Weight:
+		return &WeightStrategy{}, nil
+	case LeastRequest:
+		return &LeastRequestStrategy{}, nil
+	case P2CEWMA:
+		return &P2CEWMAStrategy{}, nil
+	case RingHash:
+		return &RingHashStrategy{}, nil
+	case Maglev:
+		return &MaglevStrategy{}, nil
+	case ZoneAffinity:
+		return &ZoneAffinityStrategy{}, nil
+	}
+	return nil, gone.NewInnerErrorWithParams(gone.ConfigError, "unsupported load balance strategy(%s)", name)
+}
+
+func address(instance g.Service) string {
+	return net.JoinHostPort(instance.GetIP(), strconv.Itoa(instance.GetPort()))
+}
+
+// stats the statistics of calls to an instance
+type stats struct {
+	inflight atomic.Int64
+
+	lock    sync.Mutex
+	ewma    float64 // in nanoseconds
+	updated time.Time
+}
+
+// tracker track the statistics of instances by address
+type tracker struct {
+	m sync.Map
+}
+
+func (t *tracker) stats(address string) *stats {
+	if s, ok := t.m.Load(address); ok {
+		return s.(*stats)
+	}
+	s, _ := t.m.LoadOrStore(address, &stats{})
+	return s.(*stats)
+}
+
+// done decrease the in-flight requests of the instance, the requests not selected by the strategy are ignored
+func (t *tracker) done(address string) *stats {
+	s := t.stats(address)
+	for {
+		n := s.inflight.Load()
+		if n <= 0 || s.inflight.CompareAndSwap(n, n-1) {
+			return s
+		}
+	}
+}
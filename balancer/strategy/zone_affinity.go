@@ -0,0 +1,58 @@
+package strategy
+
+import (
+	"context"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+)
+
+var _ g.LoadBalanceStrategy = (*ZoneAffinityStrategy)(nil)
+var _ Feedback = (*ZoneAffinityStrategy)(nil)
+
+// ZoneAffinityStrategy prefer the instances in the same zone as the caller, which is read from g.Metadata of instances
+// by ZoneKey; all instances are used when there is no instance in the zone. The instance is selected from the
+// candidates by Next, which is RoundRobinStrategy if not set.
+type ZoneAffinityStrategy struct {
+	gone.Flag
+
+	// Zone 调用方所在的区域，为空时不区分区域，对应配置项为：`balancer.zone.name`
+	Zone string `gone:"config,balancer.zone.name,default="`
+
+	// ZoneKey 实例元数据中表示区域的键，对应配置项为：`balancer.zone.key`
+	ZoneKey string `gone:"config,balancer.zone.key,default=zone"`
+
+	Next g.LoadBalanceStrategy
+
+	roundRobin RoundRobinStrategy
+}
+
+func (z *ZoneAffinityStrategy) next() g.LoadBalanceStrategy {
+	if z.Next != nil {
+		return z.Next
+	}
+	return &z.roundRobin
+}
+
+func (z *ZoneAffinityStrategy) Select(ctx context.Context, instances []g.Service) (g.Service, error) {
+	if z.Zone == "" {
+		return z.next().Select(ctx, instances)
+	}
+	local := make([]g.Service, 0, len(instances))
+	for _, instance := range instances {
+		if instance.GetMetadata()[z.ZoneKey] == z.Zone {
+			local = append(local, instance)
+		}
+	}
+	if len(local) == 0 {
+		return z.next().Select(ctx, instances)
+	}
+	return z.next().Select(ctx, local)
+}
+
+func (z *ZoneAffinityStrategy) Feedback(serviceName, address string, err error, latency time.Duration) {
+	if f, ok := z.next().(Feedback); ok {
+		f.Feedback(serviceName, address, err, latency)
+	}
+}
@@ -0,0 +1,58 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gone-io/goner/g"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZoneAffinityStrategy_Select(t *testing.T) {
+	local := g.NewService("test-service", "127.0.0.1", 8080, g.Metadata{"zone": "a"}, true, 1)
+	remote := g.NewService("test-service", "127.0.0.1", 8081, g.Metadata{"zone": "b"}, true, 1)
+	instances := []g.Service{local, remote}
+	ctx := context.Background()
+
+	strategy := &ZoneAffinityStrategy{Zone: "a", ZoneKey: "zone"}
+	for i := 0; i < 4; i++ {
+		service, err := strategy.Select(ctx, instances)
+		assert.NoError(t, err)
+		assert.Equal(t, local, service)
+	}
+
+	// fall back to all instances when there is no instance in the zone
+	service, err := strategy.Select(ctx, []g.Service{remote})
+	assert.NoError(t, err)
+	assert.Equal(t, remote, service)
+
+	// zone is not distinguished if not set
+	strategy.Zone = ""
+	seen := make(map[g.Service]bool)
+	for i := 0; i < 4; i++ {
+		service, err := strategy.Select(ctx, instances)
+		assert.NoError(t, err)
+		seen[service] = true
+	}
+	assert.Len(t, seen, 2)
+
+	// feedback is forwarded to the next strategy
+	next := &LeastRequestStrategy{}
+	strategy = &ZoneAffinityStrategy{Zone: "a", ZoneKey: "zone", Next: next}
+	_, err = strategy.Select(ctx, instances)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), next.tracker.stats(address(local)).inflight.Load())
+	strategy.Feedback("test-service", address(local), nil, time.Millisecond)
+	assert.Equal(t, int64(0), next.tracker.stats(address(local)).inflight.Load())
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range []string{RoundRobin, Random, Weight, LeastRequest, P2CEWMA, RingHash, Maglev, ZoneAffinity} {
+		s, err := New(name)
+		assert.NoError(t, err)
+		assert.NotNil(t, s)
+	}
+	_, err := New("unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,186 @@
+package balancer
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gone-io/goner/g"
+)
+
+// serviceWatcher the watcher keeping the cached instances of a service updated
+type serviceWatcher struct {
+	stop     chan struct{}
+	lastUsed atomic.Int64
+}
+
+// evictInterval the interval to check idle services
+func evictInterval(idleTimeout time.Duration) time.Duration {
+	interval := idleTimeout / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	return interval
+}
+
+// touch record the time service is used
+func (b *balancer) touch(serviceName string) {
+	b.watchLock.Lock()
+	w := b.watchers[serviceName]
+	b.watchLock.Unlock()
+	if w != nil {
+		w.lastUsed.Store(b.clock().UnixNano())
+	}
+}
+
+// cache store the instances got from discovery and start watching the service, if the service has been cached
+// concurrently, the cached instances are returned. Nothing is cached after balancer is stopped.
+func (b *balancer) cache(serviceName string, instances []g.Service) []g.Service {
+	b.watchLock.Lock()
+	defer b.watchLock.Unlock()
+	if value, ok := b.m.Load(serviceName); ok {
+		return value.([]g.Service)
+	}
+
+	w := &serviceWatcher{stop: make(chan struct{})}
+	w.lastUsed.Store(b.clock().UnixNano())
+	if !b.run(func(done <-chan struct{}) { b.watch(serviceName, w, done) }) {
+		// balancer is stopped, the instances are not cached as no watcher would keep them updated
+		return instances
+	}
+	b.m.Store(serviceName, instances)
+	if b.watchers == nil {
+		b.watchers = make(map[string]*serviceWatcher)
+	}
+	b.watchers[serviceName] = w
+	return instances
+}
+
+// update replace the cached instances of service, the empty list is ignored if `balancer.cache.keep-last-known-good`
+// is true, so that calls are not broken by discovery failures. Instances from a watcher which has been evicted are
+// dropped, otherwise they would be cached again with no watcher keeping them updated.
+func (b *balancer) update(serviceName string, w *serviceWatcher, instances []g.Service) {
+	b.watchLock.Lock()
+	defer b.watchLock.Unlock()
+	if b.watchers[serviceName] != w {
+		return
+	}
+	if len(instances) == 0 && b.keepLastKnownGood {
+		if value, ok := b.m.Load(serviceName); ok && len(value.([]g.Service)) > 0 {
+			b.logger.Warnf("balancer watch %s got no instance, keep the last %d instances", serviceName, len(value.([]g.Service)))
+			return
+		}
+	}
+	b.m.Store(serviceName, instances)
+}
+
+// watch watch the service until it is evicted or balancer is stopped; the watch is re-established with exponential
+// backoff if it fails or the channel is closed by discovery, and instances are refreshed once it is re-established.
+func (b *balancer) watch(serviceName string, w *serviceWatcher, done <-chan struct{}) {
+	defer g.Recover(b.logger)
+
+	minBackoff, maxBackoff := b.watchMinBackoff, b.watchMaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = time.Second
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+	backoff := minBackoff
+	wait := func() bool {
+		timer := time.NewTimer(backoff)
+		defer timer.Stop()
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+		select {
+		case <-timer.C:
+			return true
+		case <-done:
+		case <-w.stop:
+		}
+		return false
+	}
+
+	for rewatch := false; ; rewatch = true {
+		ch, stop, err := b.discovery.Watch(serviceName)
+		if err != nil {
+			b.logger.Errorf("balancer watch %s err: %v, retry in %v", serviceName, err, backoff)
+			if !wait() {
+				return
+			}
+			continue
+		}
+		b.logger.Debugf("balancer watch %s", serviceName)
+		if rewatch {
+			if instances, err := b.discovery.GetInstances(serviceName); err != nil {
+				b.logger.Warnf("balancer refresh %s err: %v, keep the last instances", serviceName, err)
+			} else {
+				b.update(serviceName, w, instances)
+			}
+		}
+		healthy := time.Now()
+
+		exit := b.receive(serviceName, ch, w, done)
+		if stop != nil {
+			if err := stop(); err != nil {
+				b.logger.Warnf("balancer stop watching %s err: %v", serviceName, err)
+			}
+		}
+		if exit {
+			return
+		}
+		// the watch lasted long enough is considered healthy, so backoff starts over
+		if time.Since(healthy) > maxBackoff {
+			backoff = minBackoff
+		}
+		b.logger.Warnf("balancer watch %s closed, rewatch in %v", serviceName, backoff)
+		if !wait() {
+			return
+		}
+	}
+}
+
+// receive update instances from ch, it returns true if the watcher should exit, or false if ch is closed
+func (b *balancer) receive(serviceName string, ch <-chan []g.Service, w *serviceWatcher, done <-chan struct{}) bool {
+	for {
+		select {
+		case instances, ok := <-ch:
+			if !ok {
+				return false
+			}
+			b.logger.Debugf("balancer watch %s update: %#v", serviceName, instances)
+			b.update(serviceName, w, instances)
+		case <-w.stop:
+			return true
+		case <-done:
+			return true
+		}
+	}
+}
+
+// evictIdle remove the services not used for `balancer.cache.idle-timeout` from cache, and stop watching them
+func (b *balancer) evictIdle() {
+	deadline := b.clock().Add(-b.idleTimeout).UnixNano()
+
+	b.watchLock.Lock()
+	var evicted []string
+	for serviceName, w := range b.watchers {
+		if w.lastUsed.Load() < deadline {
+			close(w.stop)
+			delete(b.watchers, serviceName)
+			b.m.Delete(serviceName)
+			evicted = append(evicted, serviceName)
+		}
+	}
+	b.watchLock.Unlock()
+
+	if len(evicted) == 0 {
+		return
+	}
+	b.lock.Lock()
+	for _, serviceName := range evicted {
+		delete(b.states, serviceName)
+	}
+	b.lock.Unlock()
+	b.logger.Debugf("balancer evict idle services: %v", evicted)
+}
@@ -0,0 +1,194 @@
+package balancer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	gMock "github.com/gone-io/goner/g/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newWatchBalancer(discovery g.ServiceDiscovery) *balancer {
+	return &balancer{
+		discovery:         discovery,
+		logger:            gone.GetDefaultLogger(),
+		keepLastKnownGood: true,
+		watchMinBackoff:   time.Millisecond,
+		watchMaxBackoff:   10 * time.Millisecond,
+	}
+}
+
+func cached(b *balancer, serviceName string) []g.Service {
+	value, ok := b.m.Load(serviceName)
+	if !ok {
+		return nil
+	}
+	return value.([]g.Service)
+}
+
+func TestBalancer_watch(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	v1 := []g.Service{g.NewService("user", "127.0.0.1", 8080, nil, true, 1)}
+	v2 := []g.Service{g.NewService("user", "127.0.0.1", 8081, nil, true, 1)}
+	v3 := []g.Service{g.NewService("user", "127.0.0.1", 8082, nil, true, 1)}
+	first := make(chan []g.Service)
+	second := make(chan []g.Service)
+	stopped := make(chan struct{}, 2)
+	stop := func() error {
+		stopped <- struct{}{}
+		return nil
+	}
+
+	discovery := gMock.NewMockServiceDiscovery(controller)
+	gomock.InOrder(
+		discovery.EXPECT().GetInstances("user").Return(v1, nil),
+		discovery.EXPECT().Watch("user").Return(first, stop, nil),
+		// the watch is re-established after the channel is closed, and instances are refreshed
+		discovery.EXPECT().Watch("user").Return(nil, nil, errors.New("error")),
+		discovery.EXPECT().Watch("user").Return(second, stop, nil),
+		discovery.EXPECT().GetInstances("user").Return(v3, nil),
+	)
+
+	b := newWatchBalancer(discovery)
+	instances, err := b.GetInstancesWithCacheAndWatch("user")
+	assert.NoError(t, err)
+	assert.Equal(t, v1, instances)
+
+	first <- v2
+	// empty list is ignored to keep the last known good instances
+	first <- []g.Service{}
+	assert.Equal(t, v2, cached(b, "user"))
+
+	close(first)
+	<-stopped
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(v3, cached(b, "user"))
+	}, time.Second, time.Millisecond)
+
+	// empty list is accepted if keep-last-known-good is false
+	b.keepLastKnownGood = false
+	second <- []g.Service{}
+	second <- v1
+	second <- []g.Service{}
+	assert.Eventually(t, func() bool {
+		return len(cached(b, "user")) == 0
+	}, time.Second, time.Millisecond)
+
+	// watchers are stopped with balancer
+	assert.NoError(t, b.Stop())
+	<-stopped
+}
+
+func TestBalancer_watch_refreshError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	v1 := []g.Service{g.NewService("user", "127.0.0.1", 8080, nil, true, 1)}
+	first := make(chan []g.Service)
+	rewatched := make(chan struct{})
+
+	discovery := gMock.NewMockServiceDiscovery(controller)
+	gomock.InOrder(
+		discovery.EXPECT().GetInstances("user").Return(v1, nil),
+		discovery.EXPECT().Watch("user").Return(first, nil, nil),
+		discovery.EXPECT().Watch("user").DoAndReturn(func(string) (<-chan []g.Service, func() error, error) {
+			close(rewatched)
+			return make(chan []g.Service), func() error { return errors.New("error") }, nil
+		}),
+		discovery.EXPECT().GetInstances("user").Return(nil, errors.New("error")),
+	)
+
+	b := newWatchBalancer(discovery)
+	_, err := b.GetInstancesWithCacheAndWatch("user")
+	assert.NoError(t, err)
+	close(first)
+	<-rewatched
+
+	assert.NoError(t, b.Stop())
+	assert.Equal(t, v1, cached(b, "user"))
+}
+
+func TestBalancer_evictIdle(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	instances := []g.Service{g.NewService("user", "127.0.0.1", 8080, nil, true, 1)}
+	stopped := make(chan struct{})
+	discovery := gMock.NewMockServiceDiscovery(controller)
+	discovery.EXPECT().GetInstances(gomock.Any()).Return(instances, nil).Times(3)
+	discovery.EXPECT().Watch("user").Return(make(chan []g.Service), func() error {
+		close(stopped)
+		return nil
+	}, nil)
+	discovery.EXPECT().Watch(gomock.Any()).Return(make(chan []g.Service), func() error { return nil }, nil).Times(2)
+
+	now := time.Unix(1700000000, 0)
+	b := newWatchBalancer(discovery)
+	b.idleTimeout = time.Minute
+	b.now = func() time.Time { return now }
+
+	_, err := b.GetInstancesWithCacheAndWatch("user")
+	assert.NoError(t, err)
+	_, err = b.GetInstancesWithCacheAndWatch("order")
+	assert.NoError(t, err)
+	b.lock.Lock()
+	b.state("user", "127.0.0.1:8080").probeUnhealthy = true
+	b.lock.Unlock()
+
+	now = now.Add(40 * time.Second)
+	_, err = b.GetInstancesWithCacheAndWatch("order")
+	assert.NoError(t, err)
+
+	// the service not used for idle-timeout is evicted and its watcher stopped
+	b.watchLock.Lock()
+	evicted := b.watchers["user"]
+	b.watchLock.Unlock()
+	now = now.Add(40 * time.Second)
+	b.evictIdle()
+	<-stopped
+	assert.Nil(t, cached(b, "user"))
+	assert.Nil(t, b.states["user"])
+	assert.Equal(t, instances, cached(b, "order"))
+
+	// the instances received by the evicted watcher are not cached again
+	b.update("user", evicted, instances)
+	assert.Nil(t, cached(b, "user"))
+
+	// it is watched again when used
+	_, err = b.GetInstancesWithCacheAndWatch("user")
+	assert.NoError(t, err)
+	assert.Equal(t, instances, cached(b, "user"))
+
+	assert.NoError(t, b.Start())
+	assert.NoError(t, b.Stop())
+	assert.Equal(t, time.Second, evictInterval(time.Second))
+}
+
+func TestBalancer_noWatchAfterStop(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	instances := []g.Service{g.NewService("user", "127.0.0.1", 8080, nil, true, 1)}
+	discovery := gMock.NewMockServiceDiscovery(controller)
+	discovery.EXPECT().GetInstances("user").Return(instances, nil).Times(2)
+
+	b := newWatchBalancer(discovery)
+	assert.NoError(t, b.Start())
+	assert.NoError(t, b.Stop())
+
+	// the instances are got from discovery each time, no watcher is started after balancer is stopped
+	for i := 0; i < 2; i++ {
+		got, err := b.GetInstancesWithCacheAndWatch("user")
+		assert.NoError(t, err)
+		assert.Equal(t, instances, got)
+	}
+	assert.Nil(t, cached(b, "user"))
+	assert.Empty(t, b.watchers)
+	assert.NoError(t, b.Stop())
+}
@@ -1,6 +1,7 @@
 package cmux
 
 import (
+	"crypto/tls"
 	"fmt"
 	"github.com/gone-io/gone/v2"
 	"github.com/gone-io/goner/g"
@@ -29,6 +30,9 @@ type server struct {
 	serviceName      string `gone:"config,server.service-name"`
 	serviceUseSubNet string `gone:"config,server.service-use-subnet,default=0.0.0.0/0"`
 
+	// tlsConfig TLS配置，开启后所有复用端口的协议都使用TLS，对应配置项为：`server.tls`
+	tlsConfig g.TLSConfig `gone:"config,server.tls"`
+
 	stopFlag bool
 	lock     sync.Mutex
 	listener net.Listener
@@ -56,6 +60,14 @@ func (s *server) Init() error {
 			if err != nil {
 				return
 			}
+			if s.tlsConfig.Enabled {
+				var config *tls.Config
+				if config, err = s.tlsConfig.ServerTLSConfig(s.logger); err != nil {
+					_ = s.listener.Close()
+					return
+				}
+				s.listener = tls.NewListener(s.listener, config)
+			}
 			s.cMux = cmux.New(s.listener)
 		})
 	}
@@ -1,7 +1,9 @@
 package cmux
 
 import (
+	"io"
 	"net"
+	"net/http"
 	"sync"
 	"testing"
 	"time"
@@ -196,3 +198,59 @@ func TestServer_Stop(t *testing.T) {
 		assert.True(t, s.stopFlag)
 	})
 }
+
+// TestServer_TLS 测试TLS
+func TestServer_TLS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockLogger := mock.NewMockLogger(ctrl)
+	mockLogger.EXPECT().Infof(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warnf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	s := &server{
+		logger:   mockLogger,
+		network:  "tcp",
+		address:  "127.0.0.1:0",
+		metadata: make(g.Metadata),
+		listen:   net.Listen,
+		tlsConfig: g.TLSConfig{
+			Enabled:  true,
+			CertFile: "testdata/tls/server.crt",
+			KeyFile:  "testdata/tls/server.key",
+		},
+	}
+	assert.NoError(t, s.Init())
+
+	httpServer := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})}
+	// matchers must be registered before cmux serves
+	listener := s.MatchFor(g.HTTP1)
+	go func() {
+		_ = httpServer.Serve(listener)
+	}()
+	go func() {
+		_ = s.cMux.Serve()
+	}()
+	defer s.cMux.Close()
+
+	clientConfig, err := (&g.TLSConfig{CAFile: "testdata/tls/ca.crt"}).ClientTLSConfig(nil)
+	assert.NoError(t, err)
+	transport := &http.Transport{TLSClientConfig: clientConfig}
+	defer transport.CloseIdleConnections()
+
+	res, err := (&http.Client{Transport: transport}).Get("https://" + s.GetAddress())
+	if assert.NoError(t, err) {
+		body, _ := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		assert.Equal(t, "ok", string(body))
+	}
+
+	s = &server{
+		network:   "tcp",
+		address:   "127.0.0.1:0",
+		listen:    net.Listen,
+		tlsConfig: g.TLSConfig{Enabled: true},
+	}
+	assert.Error(t, s.Init())
+}
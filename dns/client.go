@@ -0,0 +1,341 @@
+package dns
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const resolvConf = "/etc/resolv.conf"
+
+// errNotFound the name has no record of the queried type
+var errNotFound = errors.New("no such record")
+
+// client a minimal stub resolver, which queries the nameservers over UDP and falls back to TCP if the response is
+// truncated; unlike net.Resolver, it reports TTL of records, which drives the refresh of discovery.
+type client struct {
+	servers []string
+	search  []string
+	ndots   int
+	timeout time.Duration
+}
+
+// newClient create a client using the servers given, or the nameservers of /etc/resolv.conf if servers is empty
+func newClient(servers []string, timeout time.Duration) (*client, error) {
+	c := &client{ndots: 1, timeout: timeout}
+	if len(servers) == 0 {
+		if err := c.readConfig(resolvConf); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if len(c.servers) == 0 {
+			c.servers = []string{"127.0.0.1:53"}
+		}
+		return c, nil
+	}
+	for _, server := range servers {
+		c.servers = append(c.servers, serverAddress(server))
+	}
+	return c, nil
+}
+
+// serverAddress append the default port 53 to server if it has no port
+func serverAddress(server string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	return net.JoinHostPort(strings.Trim(server, "[]"), "53")
+}
+
+// readConfig read nameserver, search and ndots from resolv.conf
+func (c *client) readConfig(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			if net.ParseIP(fields[1]) != nil {
+				c.servers = append(c.servers, serverAddress(fields[1]))
+			}
+		case "domain", "search":
+			c.search = fields[1:]
+		case "options":
+			for _, option := range fields[1:] {
+				if value, ok := strings.CutPrefix(option, "ndots:"); ok {
+					if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+						c.ndots = n
+					}
+				}
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// names return the fully qualified names to query in order, following the search rules of resolv.conf
+func (c *client) names(name string) []string {
+	if strings.HasSuffix(name, ".") {
+		return []string{name}
+	}
+	var names []string
+	for _, domain := range c.search {
+		names = append(names, name+"."+strings.Trim(domain, ".")+".")
+	}
+	if strings.Count(name, ".") >= c.ndots {
+		return append([]string{name + "."}, names...)
+	}
+	return append(names, name+".")
+}
+
+// lookup query the record of type qtype, trying the names of search list in order until one has answers
+func (c *client) lookup(ctx context.Context, name string, qtype dnsmessage.Type) (*dnsmessage.Message, error) {
+	var lastErr error = errNotFound
+	for _, fqdn := range c.names(name) {
+		msg, err := c.exchange(ctx, fqdn, qtype)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, answer := range msg.Answers {
+			if answer.Header.Type == qtype {
+				return msg, nil
+			}
+		}
+	}
+	return nil, lastErr
+}
+
+// exchange send the query to the nameservers in order until one responds
+func (c *client) exchange(ctx context.Context, name string, qtype dnsmessage.Type) (*dnsmessage.Message, error) {
+	n, err := dnsmessage.NewName(name)
+	if err != nil {
+		return nil, err
+	}
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: uint16(rand.Uint32()), RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: n, Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, server := range c.servers {
+		msg, err := c.udp(ctx, server, query.Header.ID, packed)
+		if err == nil && msg.Truncated {
+			msg, err = c.tcp(ctx, server, query.Header.ID, packed)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		switch msg.RCode {
+		case dnsmessage.RCodeSuccess, dnsmessage.RCodeNameError:
+			return msg, nil
+		default:
+			lastErr = fmt.Errorf("query %s from %s: %s", name, server, msg.RCode)
+		}
+	}
+	return nil, lastErr
+}
+
+func (c *client) dial(ctx context.Context, network, server string) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(c.timeout))
+	return conn, nil
+}
+
+func (c *client) udp(ctx context.Context, server string, id uint16, query []byte) (*dnsmessage.Message, error) {
+	conn, err := c.dial(ctx, "udp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	if _, err = conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		// responses not matching the query are dropped, which may be late responses of previous queries
+		var msg dnsmessage.Message
+		if msg.Unpack(buf[:n]) == nil && msg.Response && msg.ID == id {
+			return &msg, nil
+		}
+	}
+}
+
+func (c *client) tcp(ctx context.Context, server string, id uint16, query []byte) (*dnsmessage.Message, error) {
+	conn, err := c.dial(ctx, "tcp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	buf := binary.BigEndian.AppendUint16(make([]byte, 0, len(query)+2), uint16(len(query)))
+	if _, err = conn.Write(append(buf, query...)); err != nil {
+		return nil, err
+	}
+	var length [2]byte
+	if _, err = io.ReadFull(conn, length[:]); err != nil {
+		return nil, err
+	}
+	buf = make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err = io.ReadFull(conn, buf); err != nil {
+		return nil, err
+	}
+	var msg dnsmessage.Message
+	if err = msg.Unpack(buf); err != nil {
+		return nil, err
+	}
+	if !msg.Response || msg.ID != id {
+		return nil, fmt.Errorf("query from %s: mismatched response", server)
+	}
+	return &msg, nil
+}
+
+// lookupIP resolve the A and AAAA records of host, it returns the addresses and the minimum TTL of records;
+// IP literal is returned as is with TTL 0.
+func (c *client) lookupIP(ctx context.Context, host string) ([]net.IP, uint32, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, 0, nil
+	}
+
+	var ips []net.IP
+	var ttl uint32
+	found := false
+	for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		msg, err := c.lookup(ctx, host, qtype)
+		if errors.Is(err, errNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, answer := range msg.Answers {
+			switch body := answer.Body.(type) {
+			case *dnsmessage.AResource:
+				ips = append(ips, body.A[:])
+			case *dnsmessage.AAAAResource:
+				ips = append(ips, body.AAAA[:])
+			default:
+				continue
+			}
+			ttl, found = minTTL(ttl, answer.Header.TTL, found), true
+		}
+	}
+	return ips, ttl, nil
+}
+
+type srvRecord struct {
+	target   string
+	port     int
+	priority uint16
+	weight   uint16
+	ip       net.IP
+}
+
+// lookupSRV resolve the SRV records of name with the lowest priority, the targets are resolved from the additional
+// section of response, or by lookupIP if absent; it returns the minimum TTL of records resolved.
+func (c *client) lookupSRV(ctx context.Context, name string) ([]srvRecord, uint32, error) {
+	msg, err := c.lookup(ctx, name, dnsmessage.TypeSRV)
+	if errors.Is(err, errNotFound) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	additional := make(map[string][]net.IP)
+	for _, resource := range msg.Additionals {
+		key := strings.ToLower(resource.Header.Name.String())
+		switch body := resource.Body.(type) {
+		case *dnsmessage.AResource:
+			additional[key] = append(additional[key], body.A[:])
+		case *dnsmessage.AAAAResource:
+			additional[key] = append(additional[key], body.AAAA[:])
+		}
+	}
+
+	var records []srvRecord
+	var ttl uint32
+	found := false
+	for _, answer := range msg.Answers {
+		body, ok := answer.Body.(*dnsmessage.SRVResource)
+		if !ok {
+			continue
+		}
+		ttl, found = minTTL(ttl, answer.Header.TTL, found), true
+		// only the targets with the lowest priority are used, the others are backups by definition of SRV
+		switch {
+		case len(records) > 0 && body.Priority > records[0].priority:
+			continue
+		case len(records) > 0 && body.Priority < records[0].priority:
+			records = records[:0]
+		}
+		records = append(records, srvRecord{
+			target:   body.Target.String(),
+			port:     int(body.Port),
+			priority: body.Priority,
+			weight:   body.Weight,
+		})
+	}
+
+	var resolved []srvRecord
+	for _, record := range records {
+		ips, ok := additional[strings.ToLower(record.target)]
+		if !ok {
+			var ipTTL uint32
+			if ips, ipTTL, err = c.lookupIP(ctx, record.target); err != nil {
+				return nil, 0, err
+			}
+			if len(ips) > 0 {
+				ttl = minTTL(ttl, ipTTL, true)
+			}
+		}
+		for _, ip := range ips {
+			record.ip = ip
+			resolved = append(resolved, record)
+		}
+	}
+	return resolved, ttl, nil
+}
+
+func minTTL(ttl, other uint32, found bool) uint32 {
+	if !found || other < ttl {
+		return other
+	}
+	return ttl
+}
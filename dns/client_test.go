@@ -0,0 +1,161 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestClient_readConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "resolv.conf")
+	assert.Nil(t, os.WriteFile(file, []byte(`# comment
+nameserver 10.96.0.10
+nameserver fd00::10
+nameserver invalid
+search default.svc.cluster.local svc.cluster.local
+options ndots:5 timeout:1
+`), 0o600))
+
+	c := &client{ndots: 1}
+	assert.Nil(t, c.readConfig(file))
+	assert.Equal(t, []string{"10.96.0.10:53", "[fd00::10]:53"}, c.servers)
+	assert.Equal(t, []string{"default.svc.cluster.local", "svc.cluster.local"}, c.search)
+	assert.Equal(t, 5, c.ndots)
+
+	assert.Error(t, c.readConfig(filepath.Join(t.TempDir(), "absent")))
+}
+
+func TestNewClient(t *testing.T) {
+	c, err := newClient([]string{"10.0.0.1", "10.0.0.2:5353", "::1", "[::1]:5353"}, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.0.0.1:53", "10.0.0.2:5353", "[::1]:53", "[::1]:5353"}, c.servers)
+
+	c, err = newClient(nil, time.Second)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, c.servers)
+}
+
+func TestClient_names(t *testing.T) {
+	c := &client{ndots: 2, search: []string{"default.svc.cluster.local", "cluster.local."}}
+	assert.Equal(t, []string{"user.default.svc.cluster.local.", "user.cluster.local.", "user."}, c.names("user"))
+	assert.Equal(t, []string{"user.default."}, c.names("user.default."))
+	assert.Equal(t, []string{"a.b.c.", "a.b.c.default.svc.cluster.local.", "a.b.c.cluster.local."}, c.names("a.b.c"))
+}
+
+func TestClient_lookupIP(t *testing.T) {
+	s := newFakeServer(t)
+	s.add(
+		a("user.default.svc.", "10.0.0.2", 30),
+		a("user.default.svc.", "10.0.0.1", 10),
+		aaaa("user.default.svc.", "fd00::1", 20),
+	)
+	c := &client{servers: []string{s.addr}, search: []string{"default.svc"}, ndots: 1, timeout: time.Second}
+	ctx := context.Background()
+
+	ips, ttl, err := c.lookupIP(ctx, "user")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.0.0.2", "10.0.0.1", "fd00::1"}, toStrings(ips))
+	assert.Equal(t, uint32(10), ttl)
+
+	ips, ttl, err = c.lookupIP(ctx, "10.0.0.9")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.0.0.9"}, toStrings(ips))
+	assert.Equal(t, uint32(0), ttl)
+
+	ips, _, err = c.lookupIP(ctx, "absent")
+	assert.Nil(t, err)
+	assert.Empty(t, ips)
+
+	// truncated responses are queried again over TCP
+	s.set(func(s *fakeServer) {
+		s.truncate = true
+		s.queries = nil
+	})
+	ips, _, err = c.lookupIP(ctx, "user.default.svc.")
+	assert.Nil(t, err)
+	assert.Len(t, ips, 3)
+	assert.Equal(t, []string{
+		"udp:user.default.svc./TypeA", "tcp:user.default.svc./TypeA",
+		"udp:user.default.svc./TypeAAAA", "tcp:user.default.svc./TypeAAAA",
+	}, s.queries)
+
+	s.set(func(s *fakeServer) {
+		s.truncate = false
+		s.rcode = dnsmessage.RCodeServerFailure
+	})
+	_, _, err = c.lookupIP(ctx, "user")
+	assert.Error(t, err)
+}
+
+func TestClient_exchange(t *testing.T) {
+	s := newFakeServer(t)
+	s.add(a("user.", "10.0.0.1", 10))
+
+	// unreachable servers are skipped
+	closed, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	_ = closed.Close()
+	c := &client{servers: []string{closed.LocalAddr().String(), s.addr}, timeout: time.Second}
+	ips, _, err := c.lookupIP(context.Background(), "user.")
+	assert.Nil(t, err)
+	assert.Len(t, ips, 1)
+
+	c.servers = c.servers[:1]
+	_, _, err = c.lookupIP(context.Background(), "user.")
+	assert.Error(t, err)
+
+	_, err = c.exchange(context.Background(), string(make([]byte, 300)), dnsmessage.TypeA)
+	assert.Error(t, err)
+}
+
+func TestClient_lookupSRV(t *testing.T) {
+	s := newFakeServer(t)
+	s.add(
+		srv("_grpc._tcp.user.", "user-0.user.", 9090, 10, 0, 30),
+		srv("_grpc._tcp.user.", "user-1.user.", 9091, 10, 5, 20),
+		srv("_grpc._tcp.user.", "backup.user.", 9092, 20, 5, 5),
+		srv("_grpc._tcp.order.", "order-0.order.", 9090, 10, 5, 30),
+		srv("_grpc._tcp.order.", "order-1.order.", 9090, 0, 5, 30),
+		a("user-1.user.", "10.0.0.2", 8),
+	)
+	s.addAdditional("_grpc._tcp.user.", dnsmessage.TypeSRV, a("user-0.user.", "10.0.0.1", 30))
+	c := &client{servers: []string{s.addr}, timeout: time.Second}
+	ctx := context.Background()
+
+	records, ttl, err := c.lookupSRV(ctx, "_grpc._tcp.user.")
+	assert.Nil(t, err)
+	assert.Equal(t, []srvRecord{
+		{target: "user-0.user.", port: 9090, priority: 10, weight: 0, ip: net.ParseIP("10.0.0.1").To4()},
+		{target: "user-1.user.", port: 9091, priority: 10, weight: 5, ip: net.ParseIP("10.0.0.2").To4()},
+	}, records)
+	assert.Equal(t, uint32(5), ttl)
+
+	// targets not resolved are skipped
+	records, _, err = c.lookupSRV(ctx, "_grpc._tcp.order.")
+	assert.Nil(t, err)
+	assert.Empty(t, records)
+
+	records, _, err = c.lookupSRV(ctx, "_grpc._tcp.absent.")
+	assert.Nil(t, err)
+	assert.Empty(t, records)
+
+	s.set(func(s *fakeServer) {
+		s.rcode = dnsmessage.RCodeRefused
+	})
+	_, _, err = c.lookupSRV(ctx, "_grpc._tcp.user.")
+	assert.Error(t, err)
+}
+
+func toStrings(ips []net.IP) []string {
+	var list []string
+	for _, ip := range ips {
+		list = append(list, ip.String())
+	}
+	return list
+}
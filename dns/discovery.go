@@ -0,0 +1,217 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+)
+
+const (
+	// TypeA resolve the A and AAAA records of host, instances use the port configured
+	TypeA = "a"
+	// TypeSRV resolve the SRV records of host, instances use the ports and weights of records
+	TypeSRV = "srv"
+)
+
+var _ g.ServiceDiscovery = (*Discovery)(nil)
+
+// Target the DNS name of a service
+type Target struct {
+	// Service 服务名
+	Service string `mapstructure:"service" json:"service" yaml:"service"`
+
+	// Host 需要解析的域名，如：`user.default.svc.cluster.local` 或 `_grpc._tcp.user.default.svc.cluster.local`
+	Host string `mapstructure:"host" json:"host" yaml:"host"`
+
+	// Type 解析的记录类型，可选值为：a、srv，默认为a
+	Type string `mapstructure:"type" json:"type" yaml:"type"`
+
+	// Port 类型为a时实例的端口，默认为 `discovery.dns.port`
+	Port int `mapstructure:"port" json:"port" yaml:"port"`
+}
+
+// Discovery the g.ServiceDiscovery resolving instances from DNS, the A/AAAA records or SRV records are resolved again
+// when their TTL expires, and changes are pushed to watchers.
+type Discovery struct {
+	gone.Flag
+	logger gone.Logger `gone:"*"`
+
+	// targets 服务对应的域名，对应配置项为：`discovery.dns.targets`；未配置的服务名按 `host` 或 `host:port` 解析A记录，以`_`开头的服务名解析SRV记录
+	targets []Target `gone:"config,discovery.dns.targets"`
+
+	// port 未指定端口时实例的默认端口，对应配置项为：`discovery.dns.port`
+	port int `gone:"config,discovery.dns.port=80"`
+
+	// servers DNS服务器地址列表，为空时使用`/etc/resolv.conf`中的配置，对应配置项为：`discovery.dns.servers`
+	servers []string `gone:"config,discovery.dns.servers"`
+
+	// timeout 单次DNS查询的超时时间，对应配置项为：`discovery.dns.timeout`
+	timeout time.Duration `gone:"config,discovery.dns.timeout=2s"`
+
+	// minRefresh 最小刷新间隔，TTL小于该值或查询失败时按该间隔刷新，对应配置项为：`discovery.dns.min-refresh`
+	minRefresh time.Duration `gone:"config,discovery.dns.min-refresh=5s"`
+
+	// maxRefresh 最大刷新间隔，TTL大于该值时按该间隔刷新，对应配置项为：`discovery.dns.max-refresh`
+	maxRefresh time.Duration `gone:"config,discovery.dns.max-refresh=5m"`
+
+	client  *client
+	targetM map[string]Target
+}
+
+func (d *Discovery) Init() error {
+	d.targetM = make(map[string]Target, len(d.targets))
+	for i, target := range d.targets {
+		if target.Type == "" {
+			target.Type = TypeA
+		}
+		target.Type = strings.ToLower(target.Type)
+		if target.Service == "" || target.Host == "" || (target.Type != TypeA && target.Type != TypeSRV) {
+			return gone.NewInnerErrorWithParams(gone.ConfigError, "discovery.dns.targets[%d] is invalid: %#v", i, target)
+		}
+		if target.Port == 0 {
+			target.Port = d.port
+		}
+		d.targetM[target.Service] = target
+	}
+	if d.maxRefresh < d.minRefresh {
+		d.maxRefresh = d.minRefresh
+	}
+
+	c, err := newClient(d.servers, d.timeout)
+	if err != nil {
+		return gone.ToErrorWithMsg(err, "read dns config failed")
+	}
+	d.client = c
+	return nil
+}
+
+// target return the target of service, which is configured in `discovery.dns.targets`, or parsed from service name
+func (d *Discovery) target(serviceName string) Target {
+	if target, ok := d.targetM[serviceName]; ok {
+		return target
+	}
+	if strings.HasPrefix(serviceName, "_") {
+		return Target{Service: serviceName, Host: serviceName, Type: TypeSRV}
+	}
+	target := Target{Service: serviceName, Host: serviceName, Type: TypeA, Port: d.port}
+	if host, port, err := net.SplitHostPort(serviceName); err == nil {
+		if p, err := strconv.Atoi(port); err == nil {
+			target.Host, target.Port = host, p
+		}
+	}
+	return target
+}
+
+// resolve the instances of service sorted by address, and the TTL of records
+func (d *Discovery) resolve(serviceName string) ([]g.Service, time.Duration, error) {
+	target := d.target(serviceName)
+	ctx := context.Background()
+	var instances []g.Service
+	var ttl uint32
+
+	if target.Type == TypeSRV {
+		records, t, err := d.client.lookupSRV(ctx, target.Host)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, record := range records {
+			weight := float64(record.weight)
+			if weight == 0 {
+				weight = 1
+			}
+			metadata := g.Metadata{
+				"target":   strings.TrimSuffix(record.target, "."),
+				"priority": strconv.Itoa(int(record.priority)),
+			}
+			instances = append(instances, g.NewService(serviceName, record.ip.String(), record.port, metadata, true, weight))
+		}
+		ttl = t
+	} else {
+		ips, t, err := d.client.lookupIP(ctx, target.Host)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, ip := range ips {
+			instances = append(instances, g.NewService(serviceName, ip.String(), target.Port, nil, true, 1))
+		}
+		ttl = t
+	}
+
+	sort.Slice(instances, func(i, j int) bool {
+		if c := bytes.Compare(net.ParseIP(instances[i].GetIP()), net.ParseIP(instances[j].GetIP())); c != 0 {
+			return c < 0
+		}
+		return instances[i].GetPort() < instances[j].GetPort()
+	})
+	return instances, time.Duration(ttl) * time.Second, nil
+}
+
+// refreshInterval clamp ttl to [`discovery.dns.min-refresh`, `discovery.dns.max-refresh`]
+func (d *Discovery) refreshInterval(ttl time.Duration) time.Duration {
+	return min(max(ttl, d.minRefresh), d.maxRefresh)
+}
+
+func (d *Discovery) GetInstances(serviceName string) ([]g.Service, error) {
+	instances, _, err := d.resolve(serviceName)
+	if err != nil {
+		return nil, gone.ToErrorWithMsg(err, "resolve "+serviceName+" failed")
+	}
+	return instances, nil
+}
+
+func (d *Discovery) Watch(serviceName string) (<-chan []g.Service, func() error, error) {
+	ch := make(chan []g.Service, 1)
+	stop := make(chan struct{})
+	var once sync.Once
+	go d.watch(serviceName, ch, stop)
+	return ch, func() error {
+		once.Do(func() {
+			close(stop)
+		})
+		return nil
+	}, nil
+}
+
+// watch resolve the service again when TTL expires until stopped, the instances are sent to ch if changed; if the
+// resolution fails, the last instances are kept and it is retried after `discovery.dns.min-refresh`.
+func (d *Discovery) watch(serviceName string, ch chan []g.Service, stop <-chan struct{}) {
+	defer g.Recover(d.logger)
+
+	var last []g.Service
+	for first := true; ; first = false {
+		interval := d.minRefresh
+		instances, ttl, err := d.resolve(serviceName)
+		if err != nil {
+			d.logger.Warnf("dns discovery resolve %s err: %v, retry in %v", serviceName, err, interval)
+		} else {
+			interval = d.refreshInterval(ttl)
+			if first || !reflect.DeepEqual(last, instances) {
+				d.logger.Debugf("dns discovery %s changed: %d instances, refresh in %v", serviceName, len(instances), interval)
+				last = instances
+				// the instances not received yet are replaced by the latest ones
+				select {
+				case <-ch:
+				default:
+				}
+				ch <- instances
+			}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-stop:
+			timer.Stop()
+			return
+		}
+	}
+}
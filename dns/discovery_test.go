@@ -0,0 +1,137 @@
+package dns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func newDiscovery(t *testing.T, s *fakeServer, targets ...Target) *Discovery {
+	d := &Discovery{
+		logger:     gone.GetDefaultLogger(),
+		targets:    targets,
+		port:       80,
+		servers:    []string{s.addr},
+		timeout:    time.Second,
+		minRefresh: 10 * time.Millisecond,
+		maxRefresh: time.Minute,
+	}
+	assert.Nil(t, d.Init())
+	return d
+}
+
+func addresses(instances []g.Service) []string {
+	var list []string
+	for _, instance := range instances {
+		list = append(list, instance.GetIP()+"|"+instance.GetMetadata()["target"])
+	}
+	return list
+}
+
+func TestDiscovery_Init(t *testing.T) {
+	d := &Discovery{port: 8080, minRefresh: time.Minute, maxRefresh: time.Second, targets: []Target{
+		{Service: "user", Host: "user.default.svc.cluster.local"},
+		{Service: "order", Host: "_grpc._tcp.order", Type: "SRV"},
+	}}
+	assert.Nil(t, d.Init())
+	assert.Equal(t, Target{Service: "user", Host: "user.default.svc.cluster.local", Type: TypeA, Port: 8080}, d.target("user"))
+	assert.Equal(t, Target{Service: "order", Host: "_grpc._tcp.order", Type: TypeSRV, Port: 8080}, d.target("order"))
+	assert.Equal(t, Target{Service: "pay", Host: "pay", Type: TypeA, Port: 8080}, d.target("pay"))
+	assert.Equal(t, Target{Service: "pay:9090", Host: "pay", Type: TypeA, Port: 9090}, d.target("pay:9090"))
+	assert.Equal(t, Target{Service: "_http._tcp.pay", Host: "_http._tcp.pay", Type: TypeSRV}, d.target("_http._tcp.pay"))
+	assert.Equal(t, time.Minute, d.maxRefresh)
+
+	for _, target := range []Target{
+		{Host: "user"},
+		{Service: "user"},
+		{Service: "user", Host: "user", Type: "cname"},
+	} {
+		d = &Discovery{targets: []Target{target}}
+		assert.Error(t, d.Init())
+	}
+}
+
+func TestDiscovery_GetInstances(t *testing.T) {
+	s := newFakeServer(t)
+	s.add(
+		a("user.", "10.0.0.2", 30),
+		a("user.", "10.0.0.1", 30),
+		srv("_grpc._tcp.order.", "order-0.order.", 9090, 0, 3, 30),
+	)
+	s.addAdditional("_grpc._tcp.order.", dnsmessage.TypeSRV, a("order-0.order.", "10.0.1.1", 30))
+	d := newDiscovery(t, s, Target{Service: "order", Host: "_grpc._tcp.order.", Type: TypeSRV})
+
+	instances, err := d.GetInstances("user.:8080")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.0.0.1|", "10.0.0.2|"}, addresses(instances))
+	assert.Equal(t, 8080, instances[0].GetPort())
+	assert.Equal(t, "user.:8080", instances[0].GetName())
+	assert.True(t, instances[0].IsHealthy())
+
+	instances, err = d.GetInstances("order")
+	assert.Nil(t, err)
+	assert.Len(t, instances, 1)
+	assert.Equal(t, "order", instances[0].GetName())
+	assert.Equal(t, 9090, instances[0].GetPort())
+	assert.Equal(t, float64(3), instances[0].GetWeight())
+	assert.Equal(t, g.Metadata{"target": "order-0.order", "priority": "0"}, instances[0].GetMetadata())
+
+	s.set(func(s *fakeServer) {
+		s.rcode = dnsmessage.RCodeServerFailure
+	})
+	_, err = d.GetInstances("user.")
+	assert.Error(t, err)
+	_, err = d.GetInstances("order")
+	assert.Error(t, err)
+}
+
+func TestDiscovery_refreshInterval(t *testing.T) {
+	d := &Discovery{minRefresh: 5 * time.Second, maxRefresh: time.Minute}
+	assert.Equal(t, 5*time.Second, d.refreshInterval(0))
+	assert.Equal(t, 30*time.Second, d.refreshInterval(30*time.Second))
+	assert.Equal(t, time.Minute, d.refreshInterval(time.Hour))
+}
+
+func TestDiscovery_Watch(t *testing.T) {
+	s := newFakeServer(t)
+	s.add(a("user.", "10.0.0.1", 0))
+	d := newDiscovery(t, s)
+
+	ch, stop, err := d.Watch("user.")
+	assert.Nil(t, err)
+	receive := func() []string {
+		select {
+		case instances := <-ch:
+			return addresses(instances)
+		case <-time.After(time.Second):
+			return []string{"timeout"}
+		}
+	}
+	assert.Equal(t, []string{"10.0.0.1|"}, receive())
+
+	// failures keep the last instances and changes are pushed once resolved
+	s.set(func(s *fakeServer) {
+		s.rcode = dnsmessage.RCodeServerFailure
+	})
+	time.Sleep(30 * time.Millisecond)
+	assert.Empty(t, ch)
+	s.reset()
+	s.set(func(s *fakeServer) {
+		s.rcode = dnsmessage.RCodeSuccess
+	})
+	s.add(a("user.", "10.0.0.1", 0), a("user.", "10.0.0.2", 0))
+	assert.Equal(t, []string{"10.0.0.1|", "10.0.0.2|"}, receive())
+
+	s.reset()
+	assert.Nil(t, receive())
+
+	assert.Nil(t, stop())
+	assert.Nil(t, stop())
+	s.add(a("user.", "10.0.0.3", 0))
+	time.Sleep(30 * time.Millisecond)
+	assert.Empty(t, ch)
+}
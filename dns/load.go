@@ -0,0 +1,11 @@
+package dns
+
+import (
+	"github.com/gone-io/gone/v2"
+)
+
+// DiscoveryLoad load the DNS service discovery
+func DiscoveryLoad(loader gone.Loader) error {
+	loader.MustLoad(&Discovery{})
+	return nil
+}
@@ -0,0 +1,27 @@
+package dns
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoveryLoad(t *testing.T) {
+	_ = os.Setenv("GONE_DISCOVERY_DNS_SERVERS", `["127.0.0.1:5353"]`)
+	_ = os.Setenv("GONE_DISCOVERY_DNS_TARGETS", `[{"service":"user","host":"_grpc._tcp.user","type":"srv"}]`)
+	defer func() {
+		_ = os.Unsetenv("GONE_DISCOVERY_DNS_SERVERS")
+		_ = os.Unsetenv("GONE_DISCOVERY_DNS_TARGETS")
+	}()
+
+	gone.
+		NewApp(DiscoveryLoad).
+		Run(func(discovery g.ServiceDiscovery, d *Discovery) {
+			assert.Equal(t, d, discovery)
+			assert.Equal(t, []string{"127.0.0.1:5353"}, d.client.servers)
+			assert.Equal(t, TypeSRV, d.target("user").Type)
+		})
+}
@@ -0,0 +1,169 @@
+package dns
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// fakeServer a DNS server answering the records set, over UDP and TCP on the same port
+type fakeServer struct {
+	addr string
+
+	lock        sync.Mutex
+	answers     map[string][]dnsmessage.Resource
+	additionals map[string][]dnsmessage.Resource
+	rcode       dnsmessage.RCode
+	truncate    bool
+	queries     []string
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	s := &fakeServer{answers: map[string][]dnsmessage.Resource{}, additionals: map[string][]dnsmessage.Resource{}}
+	var conn net.PacketConn
+	var listener net.Listener
+	for i := 0; listener == nil; i++ {
+		var err error
+		conn, err = net.ListenPacket("udp", "127.0.0.1:0")
+		assert.Nil(t, err)
+		listener, err = net.Listen("tcp", conn.LocalAddr().String())
+		if err != nil {
+			_ = conn.Close()
+			assert.Less(t, i, 10)
+		}
+	}
+	s.addr = conn.LocalAddr().String()
+	t.Cleanup(func() {
+		_ = conn.Close()
+		_ = listener.Close()
+	})
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteTo(s.answer(buf[:n], false), addr)
+		}
+	}()
+	go func() {
+		for {
+			c, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			var length [2]byte
+			if _, err = io.ReadFull(c, length[:]); err == nil {
+				query := make([]byte, binary.BigEndian.Uint16(length[:]))
+				if _, err = io.ReadFull(c, query); err == nil {
+					resp := s.answer(query, true)
+					_, _ = c.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(resp))), resp...))
+				}
+			}
+			_ = c.Close()
+		}
+	}()
+	return s
+}
+
+func key(name string, qtype dnsmessage.Type) string {
+	return strings.ToLower(name) + "/" + qtype.String()
+}
+
+func (s *fakeServer) add(resources ...dnsmessage.Resource) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, r := range resources {
+		k := key(r.Header.Name.String(), r.Header.Type)
+		s.answers[k] = append(s.answers[k], r)
+	}
+}
+
+func (s *fakeServer) addAdditional(name string, qtype dnsmessage.Type, resources ...dnsmessage.Resource) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.additionals[key(name, qtype)] = append(s.additionals[key(name, qtype)], resources...)
+}
+
+func (s *fakeServer) reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.answers = map[string][]dnsmessage.Resource{}
+	s.additionals = map[string][]dnsmessage.Resource{}
+	s.queries = nil
+}
+
+func (s *fakeServer) set(f func(s *fakeServer)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	f(s)
+}
+
+func (s *fakeServer) answer(query []byte, tcp bool) []byte {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var msg dnsmessage.Message
+	if err := msg.Unpack(query); err != nil || len(msg.Questions) != 1 {
+		return nil
+	}
+	q := msg.Questions[0]
+	k := key(q.Name.String(), q.Type)
+	protocol := "udp"
+	if tcp {
+		protocol = "tcp"
+	}
+	s.queries = append(s.queries, protocol+":"+k)
+
+	resp := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: msg.ID, Response: true, RCode: s.rcode},
+		Questions: msg.Questions,
+	}
+	if s.truncate && !tcp {
+		resp.Truncated = true
+	} else if answers, ok := s.answers[k]; ok {
+		resp.Answers = answers
+		resp.Additionals = s.additionals[k]
+	} else if s.rcode == dnsmessage.RCodeSuccess {
+		resp.RCode = dnsmessage.RCodeNameError
+	}
+	packed, _ := resp.Pack()
+	return packed
+}
+
+func name(n string) dnsmessage.Name {
+	return dnsmessage.MustNewName(n)
+}
+
+func a(n, ip string, ttl uint32) dnsmessage.Resource {
+	var body dnsmessage.AResource
+	copy(body.A[:], net.ParseIP(ip).To4())
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: name(n), Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &body,
+	}
+}
+
+func aaaa(n, ip string, ttl uint32) dnsmessage.Resource {
+	var body dnsmessage.AAAAResource
+	copy(body.AAAA[:], net.ParseIP(ip))
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: name(n), Type: dnsmessage.TypeAAAA, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &body,
+	}
+}
+
+func srv(n, target string, port, priority, weight uint16, ttl uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: name(n), Type: dnsmessage.TypeSRV, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.SRVResource{Target: name(target), Port: port, Priority: priority, Weight: weight},
+	}
+}
@@ -40,6 +40,7 @@ require (
 	github.com/gone-io/goner/otel/meter v1.3.6 // indirect
 	github.com/gone-io/goner/otel/meter/prometheus v1.3.6 // indirect
 	github.com/google/uuid v1.6.0 // indirect
+	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/grafana/regexp v0.0.0-20250905093917-f7b3be9d1853 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/klauspost/cpuid/v2 v2.3.0 // indirect
@@ -41,6 +41,7 @@ require (
 	github.com/gone-io/goner/otel v1.3.6 // indirect
 	github.com/gone-io/goner/otel/tracer v1.3.6 // indirect
 	github.com/google/uuid v1.6.0 // indirect
+	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/grpc-ecosystem/grpc-gateway/v2 v2.27.2 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/klauspost/cpuid/v2 v2.3.0 // indirect
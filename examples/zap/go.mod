@@ -6,7 +6,6 @@ replace (
 	github.com/gone-io/goner/g => ../../g
 	github.com/gone-io/goner/tracer/gid => ../../tracer/gid
 	github.com/gone-io/goner/zap => ../../zap
-
 )
 
 require (
@@ -1,6 +1,10 @@
 package g
 
-import "context"
+import (
+	"context"
+	"strings"
+	"time"
+)
 
 // LoadBalancer provides load balancing functionality for service instances
 // It selects an appropriate instance from available service instances
@@ -17,3 +21,59 @@ type LoadBalanceStrategy interface {
 	// Returns an error if selection fails or no suitable instance is found
 	Select(ctx context.Context, instances []Service) (Service, error)
 }
+
+// LoadBalanceFeedback receives the outcome of calls to service instances, eg: reported by urllib and gRPC clients
+// It is implemented by the load balancer to eject outliers
+type LoadBalanceFeedback interface {
+	// Feedback reports the result of a call to the instance listening on address(ip:port) of the service, which is
+	// selected by LoadBalancer or LoadBalanceSelector
+	Feedback(serviceName, address string, err error, latency time.Duration)
+
+	// Available filters out the instances which are unhealthy or ejected
+	Available(serviceName string, instances []Service) []Service
+}
+
+// LoadBalanceRouter chooses the instances for a request by routing rules, eg: lanes or canary releases
+// It is implemented by the load balancer, and used by the clients picking instances themselves, eg: gRPC clients
+type LoadBalanceRouter interface {
+	// Route returns the subset of instances for the request whose attributes are carried by ctx, see WithRouteAttributes
+	Route(ctx context.Context, serviceName string, instances []Service) []Service
+}
+
+// LoadBalanceSelector selects an instance by the strategy configured for the service, eg: `balancer.strategies`
+// It is implemented by the load balancer, and used by the clients picking instances themselves, eg: gRPC clients
+type LoadBalanceSelector interface {
+	// Select chooses an instance of the service from the instances routed for the request carried by ctx
+	Select(ctx context.Context, serviceName string, instances []Service) (Service, error)
+}
+
+// InstanceProber actively checks the health of a service instance, eg: by TCP, HTTP or gRPC
+type InstanceProber interface {
+	// ProbeType returns the type of probe, eg: `tcp`, `http`, `grpc`
+	ProbeType() string
+
+	// Probe returns nil if the instance is healthy; ctx is canceled when the probe timeout is reached
+	Probe(ctx context.Context, instance Service) error
+}
+
+type routeAttributesKey struct{}
+
+// WithRouteAttributes returns a context carrying the attributes of the request used by routing rules of the load
+// balancer, eg: headers of HTTP requests or metadata of gRPC calls. Keys are case-insensitive, and the attributes are
+// merged with the ones already in ctx.
+func WithRouteAttributes(ctx context.Context, attributes map[string]string) context.Context {
+	merged := make(map[string]string, len(attributes))
+	for k, v := range RouteAttributesFrom(ctx) {
+		merged[k] = v
+	}
+	for k, v := range attributes {
+		merged[strings.ToLower(k)] = v
+	}
+	return context.WithValue(ctx, routeAttributesKey{}, merged)
+}
+
+// RouteAttributesFrom returns the attributes set by WithRouteAttributes, keys are in lower case
+func RouteAttributesFrom(ctx context.Context) map[string]string {
+	attributes, _ := ctx.Value(routeAttributesKey{}).(map[string]string)
+	return attributes
+}
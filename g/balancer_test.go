@@ -0,0 +1,19 @@
+package g
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRouteAttributes(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, RouteAttributesFrom(ctx))
+
+	ctx = WithRouteAttributes(ctx, map[string]string{"X-Lane": "gray", "region": "hz"})
+	child := WithRouteAttributes(ctx, map[string]string{"x-lane": "blue"})
+
+	assert.Equal(t, map[string]string{"x-lane": "gray", "region": "hz"}, RouteAttributesFrom(ctx))
+	assert.Equal(t, map[string]string{"x-lane": "blue", "region": "hz"}, RouteAttributesFrom(child))
+}
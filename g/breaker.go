@@ -0,0 +1,414 @@
+package g
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// CircuitState the state of a circuit
+type CircuitState int
+
+const (
+	// CircuitClosed calls are allowed, and their results are counted
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen calls are rejected until OpenTimeout is reached
+	CircuitOpen
+
+	// CircuitHalfOpen a limited number of trial calls are allowed, the circuit is closed if all of them succeed,
+	// otherwise it is opened again
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+const circuitBuckets = 10
+
+// ErrCircuitOpen is returned when a call is rejected by an open circuit
+var ErrCircuitOpen = gone.NewError(http.StatusServiceUnavailable, "circuit breaker is open", http.StatusServiceUnavailable)
+
+// CircuitBreakerConfig the configuration of client-side circuit breaker, eg: urllib and gRPC client.
+type CircuitBreakerConfig struct {
+	// Enabled whether the circuit breaker is enabled
+	Enabled bool `properties:"enabled,default=false" mapstructure:"enabled" json:"enabled"`
+
+	// KeyBy how calls are grouped into circuits, the supported values depend on the client, eg: `host` for urllib
+	// and `method` for gRPC client
+	KeyBy string `properties:"key-by,default=" mapstructure:"key-by" json:"key-by"`
+
+	// Window the sliding window in which the results of calls are counted, default is 10s
+	Window time.Duration `properties:"window,default=10s" mapstructure:"window" json:"window"`
+
+	// MinRequests the minimum number of calls in Window before the circuit can be opened, default is 20
+	MinRequests int `properties:"min-requests,default=20" mapstructure:"min-requests" json:"min-requests"`
+
+	// ErrorRate the circuit is opened when the rate of failed calls reaches it, 0~1, default is 0.5
+	ErrorRate float64 `properties:"error-rate,default=0.5" mapstructure:"error-rate" json:"error-rate"`
+
+	// SlowCallDuration calls taking longer are counted as slow, 0 disables slow-call detection
+	SlowCallDuration time.Duration `properties:"slow-call-duration,default=0s" mapstructure:"slow-call-duration" json:"slow-call-duration"`
+
+	// SlowCallRate the circuit is opened when the rate of slow calls reaches it, 0~1, default is 1
+	SlowCallRate float64 `properties:"slow-call-rate,default=1" mapstructure:"slow-call-rate" json:"slow-call-rate"`
+
+	// OpenTimeout how long the circuit stays open before trial calls are allowed, default is 30s
+	OpenTimeout time.Duration `properties:"open-timeout,default=30s" mapstructure:"open-timeout" json:"open-timeout"`
+
+	// HalfOpenRequests the number of trial calls in half-open state, default is 5
+	HalfOpenRequests int `properties:"half-open-requests,default=5" mapstructure:"half-open-requests" json:"half-open-requests"`
+}
+
+// NewCircuitBreaker create a circuit breaker by the configuration, zero values are replaced by the defaults;
+// onStateChange is called after the state of a circuit changed, it can be nil.
+func (c *CircuitBreakerConfig) NewCircuitBreaker(onStateChange func(key string, from, to CircuitState)) (*CircuitBreaker, error) {
+	config := *c
+	if config.Window == 0 {
+		config.Window = 10 * time.Second
+	}
+	if config.MinRequests == 0 {
+		config.MinRequests = 20
+	}
+	if config.ErrorRate == 0 {
+		config.ErrorRate = 0.5
+	}
+	if config.SlowCallRate == 0 {
+		config.SlowCallRate = 1
+	}
+	if config.OpenTimeout == 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenRequests == 0 {
+		config.HalfOpenRequests = 5
+	}
+	if config.Window < 0 || config.MinRequests < 0 || config.OpenTimeout < 0 || config.HalfOpenRequests < 0 ||
+		config.SlowCallDuration < 0 || config.ErrorRate < 0 || config.ErrorRate > 1 ||
+		config.SlowCallRate < 0 || config.SlowCallRate > 1 {
+		return nil, gone.NewInnerError("invalid circuit breaker config", gone.ConfigError)
+	}
+	return &CircuitBreaker{
+		config:        config,
+		onStateChange: onStateChange,
+		circuits:      make(map[string]*circuit),
+		now:           time.Now,
+	}, nil
+}
+
+type circuitBucket struct {
+	epoch    int64
+	total    int
+	failures int
+	slow     int
+}
+
+type circuit struct {
+	state      CircuitState
+	openedAt   time.Time
+	generation uint64
+	buckets    [circuitBuckets]circuitBucket
+
+	// trial calls in half-open state
+	inFlight  int
+	successes int
+}
+
+type circuitChange struct {
+	key      string
+	from, to CircuitState
+}
+
+// CircuitBreaker a set of circuits identified by keys, eg: host, service or method. It is safe for concurrent use.
+type CircuitBreaker struct {
+	config        CircuitBreakerConfig
+	onStateChange func(key string, from, to CircuitState)
+	now           func() time.Time
+
+	lock     sync.Mutex
+	circuits map[string]*circuit
+}
+
+// Allow check whether a call of key is allowed, ErrCircuitOpen is returned if it is rejected; otherwise done must be
+// called with the result of the call when it finishes.
+func (b *CircuitBreaker) Allow(key string) (done func(failed bool), err error) {
+	b.lock.Lock()
+	c := b.circuits[key]
+	if c == nil {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+	now := b.now()
+	change := b.refresh(key, c, now)
+
+	switch {
+	case c.state == CircuitOpen, c.state == CircuitHalfOpen && c.inFlight >= b.config.HalfOpenRequests:
+		err = ErrCircuitOpen
+	case c.state == CircuitHalfOpen:
+		c.inFlight++
+	}
+	generation := c.generation
+	b.lock.Unlock()
+	b.notify(change)
+
+	if err != nil {
+		return nil, err
+	}
+	return func(failed bool) {
+		b.done(key, c, generation, now, failed)
+	}, nil
+}
+
+// State return the current state of the circuit of key
+func (b *CircuitBreaker) State(key string) CircuitState {
+	b.lock.Lock()
+	c := b.circuits[key]
+	if c == nil {
+		b.lock.Unlock()
+		return CircuitClosed
+	}
+	change := b.refresh(key, c, b.now())
+	state := c.state
+	b.lock.Unlock()
+	b.notify(change)
+	return state
+}
+
+// Range call fn with the state of every circuit
+func (b *CircuitBreaker) Range(fn func(key string, state CircuitState)) {
+	b.lock.Lock()
+	states := make(map[string]CircuitState, len(b.circuits))
+	var changes []*circuitChange
+	now := b.now()
+	for key, c := range b.circuits {
+		if change := b.refresh(key, c, now); change != nil {
+			changes = append(changes, change)
+		}
+		states[key] = c.state
+	}
+	b.lock.Unlock()
+
+	b.notify(changes...)
+	for key, state := range states {
+		fn(key, state)
+	}
+}
+
+func (b *CircuitBreaker) done(key string, c *circuit, generation uint64, start time.Time, failed bool) {
+	b.lock.Lock()
+	now := b.now()
+	if c.generation != generation {
+		// the call was started before the state changed
+		b.lock.Unlock()
+		return
+	}
+	slow := b.config.SlowCallDuration > 0 && now.Sub(start) >= b.config.SlowCallDuration
+
+	var change *circuitChange
+	switch c.state {
+	case CircuitHalfOpen:
+		c.inFlight--
+		if failed || slow {
+			change = b.transition(key, c, CircuitOpen, now)
+		} else if c.successes++; c.successes >= b.config.HalfOpenRequests {
+			change = b.transition(key, c, CircuitClosed, now)
+		}
+	case CircuitClosed:
+		total, failures, slows := c.record(now, b.config.Window, failed, slow)
+		if total >= b.config.MinRequests && (float64(failures) >= b.config.ErrorRate*float64(total) ||
+			b.config.SlowCallDuration > 0 && float64(slows) >= b.config.SlowCallRate*float64(total)) {
+			change = b.transition(key, c, CircuitOpen, now)
+		}
+	}
+	b.lock.Unlock()
+	b.notify(change)
+}
+
+// record count the result in the bucket of now, return the counts in the window
+func (c *circuit) record(now time.Time, window time.Duration, failed, slow bool) (total, failures, slows int) {
+	size := int64(window / circuitBuckets)
+	if size <= 0 {
+		size = 1
+	}
+	epoch := now.UnixNano() / size
+	bucket := &c.buckets[epoch%circuitBuckets]
+	if bucket.epoch != epoch {
+		*bucket = circuitBucket{epoch: epoch}
+	}
+	bucket.total++
+	if failed {
+		bucket.failures++
+	}
+	if slow {
+		bucket.slow++
+	}
+
+	for _, b := range c.buckets {
+		if epoch-b.epoch < circuitBuckets {
+			total += b.total
+			failures += b.failures
+			slows += b.slow
+		}
+	}
+	return
+}
+
+// refresh move an open circuit to half-open when OpenTimeout is reached
+func (b *CircuitBreaker) refresh(key string, c *circuit, now time.Time) *circuitChange {
+	if c.state == CircuitOpen && now.Sub(c.openedAt) >= b.config.OpenTimeout {
+		return b.transition(key, c, CircuitHalfOpen, now)
+	}
+	return nil
+}
+
+func (b *CircuitBreaker) transition(key string, c *circuit, to CircuitState, now time.Time) *circuitChange {
+	change := &circuitChange{key: key, from: c.state, to: to}
+	c.state = to
+	c.generation++
+	c.buckets = [circuitBuckets]circuitBucket{}
+	c.inFlight, c.successes = 0, 0
+	if to == CircuitOpen {
+		c.openedAt = now
+	}
+	return change
+}
+
+// notify call onStateChange outside the lock
+func (b *CircuitBreaker) notify(changes ...*circuitChange) {
+	if b.onStateChange == nil {
+		return
+	}
+	for _, change := range changes {
+		if change != nil {
+			b.onStateChange(change.key, change.from, change.to)
+		}
+	}
+}
+
+// ClientCircuitBreaker the circuit breaker of a client, eg: urllib and gRPC client. Its circuits are keyed by KeyBy,
+// the state changes are logged, and the calls rejected are counted for the metrics.
+type ClientCircuitBreaker struct {
+	*CircuitBreaker
+	KeyBy        string
+	metricPrefix string
+
+	rejectedLock sync.Mutex
+	rejected     map[string]int64
+}
+
+// CircuitMetric the name, description and unit of a metric of circuits
+type CircuitMetric struct {
+	Name        string
+	Description string
+	Unit        string
+}
+
+// NewClientCircuitBreaker create a ClientCircuitBreaker:
+//   - name is the configuration key of the circuit breaker, which is used in the logs and errors, eg: `urllib.circuit-breaker`;
+//   - metricPrefix is the prefix of the metrics, eg: `http.client`;
+//   - keyBys are the supported values of KeyBy, the first of which is the default.
+func (c *CircuitBreakerConfig) NewClientCircuitBreaker(name, metricPrefix string, logger gone.Logger, keyBys ...string) (*ClientCircuitBreaker, error) {
+	b := &ClientCircuitBreaker{
+		KeyBy:        c.KeyBy,
+		metricPrefix: metricPrefix,
+		rejected:     make(map[string]int64),
+	}
+	switch {
+	case b.KeyBy == "" && len(keyBys) > 0:
+		b.KeyBy = keyBys[0]
+	case !slices.Contains(keyBys, b.KeyBy):
+		return nil, gone.NewInnerErrorWithParams(gone.ConfigError, "unsupported %s.key-by(%s)", name, c.KeyBy)
+	}
+
+	var err error
+	b.CircuitBreaker, err = c.NewCircuitBreaker(func(key string, from, to CircuitState) {
+		if to == CircuitOpen {
+			logger.Warnf("%s: circuit(%s) changed from %s to %s", name, key, from, to)
+		} else {
+			logger.Infof("%s: circuit(%s) changed from %s to %s", name, key, from, to)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Allow same as CircuitBreaker.Allow, and the call rejected is counted
+func (b *ClientCircuitBreaker) Allow(key string) (done func(failed bool), err error) {
+	done, err = b.CircuitBreaker.Allow(key)
+	if err != nil {
+		b.rejectedLock.Lock()
+		b.rejected[key]++
+		b.rejectedLock.Unlock()
+	}
+	return done, err
+}
+
+// Metrics return the metrics of circuits named with the prefix: rejected is a counter of the calls rejected by open
+// circuits, state is a gauge of the states of circuits; both of them are labeled by `circuit`.
+func (b *ClientCircuitBreaker) Metrics() (rejected, state CircuitMetric) {
+	rejected = CircuitMetric{
+		Name:        b.metricPrefix + ".circuit_breaker.rejected",
+		Description: "Number of calls rejected by open circuits.",
+		Unit:        "{call}",
+	}
+	state = CircuitMetric{
+		Name:        b.metricPrefix + ".circuit_breaker.state",
+		Description: "State of circuits: 0 closed, 1 open, 2 half-open.",
+	}
+	return rejected, state
+}
+
+// Observe call fn with the state and the number of rejected calls of every circuit
+func (b *ClientCircuitBreaker) Observe(fn func(key string, state CircuitState, rejected int64)) {
+	b.rejectedLock.Lock()
+	rejected := maps.Clone(b.rejected)
+	b.rejectedLock.Unlock()
+
+	b.Range(func(key string, state CircuitState) {
+		fn(key, state, rejected[key])
+	})
+}
+
+// ObserveMetrics report the metrics of circuits by the OpenTelemetry meter named meterName, see Metrics.
+func (b *ClientCircuitBreaker) ObserveMetrics(meterName string) error {
+	rejected, state := b.Metrics()
+	meter := otel.Meter(meterName)
+	rejectedCounter, err := meter.Int64ObservableCounter(
+		rejected.Name,
+		metric.WithDescription(rejected.Description),
+		metric.WithUnit(rejected.Unit),
+	)
+	if err != nil {
+		return err
+	}
+	stateGauge, err := meter.Int64ObservableGauge(state.Name, metric.WithDescription(state.Description))
+	if err != nil {
+		return err
+	}
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		b.Observe(func(key string, state CircuitState, rejected int64) {
+			attributes := metric.WithAttributes(attribute.String("circuit", key))
+			o.ObserveInt64(rejectedCounter, rejected, attributes)
+			o.ObserveInt64(stateGauge, int64(state), attributes)
+		})
+		return nil
+	}, rejectedCounter, stateGauge)
+	return err
+}
@@ -0,0 +1,206 @@
+package g
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeClock struct {
+	lock sync.Mutex
+	t    time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) add(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func newTestBreaker(t *testing.T, config CircuitBreakerConfig) (*CircuitBreaker, *fakeClock, *[]string) {
+	var changes []string
+	b, err := config.NewCircuitBreaker(func(key string, from, to CircuitState) {
+		changes = append(changes, key+":"+from.String()+"->"+to.String())
+	})
+	assert.Nil(t, err)
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	b.now = clock.now
+	return b, clock, &changes
+}
+
+func call(b *CircuitBreaker, key string, failed bool) error {
+	done, err := b.Allow(key)
+	if err != nil {
+		return err
+	}
+	done(failed)
+	return nil
+}
+
+func TestCircuitBreakerConfig_NewCircuitBreaker(t *testing.T) {
+	b, err := (&CircuitBreakerConfig{}).NewCircuitBreaker(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, CircuitBreakerConfig{
+		Window:           10 * time.Second,
+		MinRequests:      20,
+		ErrorRate:        0.5,
+		SlowCallRate:     1,
+		OpenTimeout:      30 * time.Second,
+		HalfOpenRequests: 5,
+	}, b.config)
+
+	for _, config := range []CircuitBreakerConfig{
+		{ErrorRate: 1.5},
+		{SlowCallRate: -1},
+		{Window: -time.Second},
+		{HalfOpenRequests: -1},
+	} {
+		_, err = config.NewCircuitBreaker(nil)
+		assert.Error(t, err)
+	}
+}
+
+func TestCircuitBreaker_errorRate(t *testing.T) {
+	b, clock, changes := newTestBreaker(t, CircuitBreakerConfig{
+		MinRequests:      4,
+		ErrorRate:        0.5,
+		OpenTimeout:      time.Second,
+		HalfOpenRequests: 2,
+	})
+
+	assert.Nil(t, call(b, "a", false))
+	assert.Nil(t, call(b, "a", true))
+	assert.Nil(t, call(b, "a", false))
+	assert.Equal(t, CircuitClosed, b.State("a"))
+	assert.Nil(t, call(b, "a", true))
+	assert.Equal(t, CircuitOpen, b.State("a"))
+	assert.Equal(t, ErrCircuitOpen, call(b, "a", false))
+
+	// other circuits are not affected
+	assert.Nil(t, call(b, "b", false))
+
+	// half-open allows limited trial calls
+	clock.add(time.Second)
+	done1, err := b.Allow("a")
+	assert.Nil(t, err)
+	done2, err := b.Allow("a")
+	assert.Nil(t, err)
+	_, err = b.Allow("a")
+	assert.Equal(t, ErrCircuitOpen, err)
+	done1(false)
+	assert.Equal(t, CircuitHalfOpen, b.State("a"))
+	done2(false)
+	assert.Equal(t, CircuitClosed, b.State("a"))
+
+	// failed trial call opens the circuit again
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, call(b, "a", true))
+	}
+	clock.add(time.Second)
+	assert.Nil(t, call(b, "a", true))
+	assert.Equal(t, CircuitOpen, b.State("a"))
+
+	assert.Equal(t, []string{
+		"a:closed->open",
+		"a:open->half-open",
+		"a:half-open->closed",
+		"a:closed->open",
+		"a:open->half-open",
+		"a:half-open->open",
+	}, *changes)
+}
+
+func TestCircuitBreaker_window(t *testing.T) {
+	b, clock, _ := newTestBreaker(t, CircuitBreakerConfig{Window: 10 * time.Second, MinRequests: 2})
+
+	assert.Nil(t, call(b, "a", true))
+	// the failure slides out of the window
+	clock.add(11 * time.Second)
+	assert.Nil(t, call(b, "a", true))
+	assert.Equal(t, CircuitClosed, b.State("a"))
+	clock.add(time.Second)
+	assert.Nil(t, call(b, "a", false))
+	assert.Equal(t, CircuitOpen, b.State("a"))
+}
+
+func TestCircuitBreaker_slowCall(t *testing.T) {
+	b, clock, _ := newTestBreaker(t, CircuitBreakerConfig{MinRequests: 2, SlowCallDuration: time.Second, SlowCallRate: 0.5})
+
+	done, err := b.Allow("a")
+	assert.Nil(t, err)
+	clock.add(2 * time.Second)
+	done(false)
+	assert.Equal(t, CircuitClosed, b.State("a"))
+	assert.Nil(t, call(b, "a", false))
+	assert.Equal(t, CircuitOpen, b.State("a"))
+}
+
+func TestCircuitBreaker_staleResult(t *testing.T) {
+	b, clock, _ := newTestBreaker(t, CircuitBreakerConfig{MinRequests: 1, OpenTimeout: time.Second, HalfOpenRequests: 1})
+
+	stale, err := b.Allow("a")
+	assert.Nil(t, err)
+	assert.Nil(t, call(b, "a", true))
+	assert.Equal(t, CircuitOpen, b.State("a"))
+
+	clock.add(time.Second)
+	assert.Equal(t, CircuitHalfOpen, b.State("a"))
+	// the result of call started before the circuit was opened is ignored
+	stale(true)
+	assert.Equal(t, CircuitHalfOpen, b.State("a"))
+
+	states := make(map[string]CircuitState)
+	b.Range(func(key string, state CircuitState) {
+		states[key] = state
+	})
+	assert.Equal(t, map[string]CircuitState{"a": CircuitHalfOpen}, states)
+	assert.Equal(t, CircuitClosed, b.State("missing"))
+}
+
+func TestCircuitBreakerConfig_NewClientCircuitBreaker(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	logger := gone.NewMockLogger(controller)
+
+	b, err := (&CircuitBreakerConfig{MinRequests: 1}).NewClientCircuitBreaker("x.circuit-breaker", "x.client", logger, "host", "path")
+	assert.Nil(t, err)
+	assert.Equal(t, "host", b.KeyBy)
+
+	b, err = (&CircuitBreakerConfig{KeyBy: "path", MinRequests: 1}).NewClientCircuitBreaker("x.circuit-breaker", "x.client", logger, "host", "path")
+	assert.Nil(t, err)
+	assert.Equal(t, "path", b.KeyBy)
+
+	rejected, state := b.Metrics()
+	assert.Equal(t, "x.client.circuit_breaker.rejected", rejected.Name)
+	assert.Equal(t, "x.client.circuit_breaker.state", state.Name)
+	assert.Nil(t, b.ObserveMetrics("x"))
+
+	logger.EXPECT().Warnf("%s: circuit(%s) changed from %s to %s", "x.circuit-breaker", "a", CircuitClosed, CircuitOpen)
+	assert.Nil(t, call(b.CircuitBreaker, "a", true))
+	_, err = b.Allow("a")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	_, err = b.Allow("a")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Nil(t, call(b.CircuitBreaker, "b", false))
+
+	observed := make(map[string][2]int64)
+	b.Observe(func(key string, state CircuitState, rejected int64) {
+		observed[key] = [2]int64{int64(state), rejected}
+	})
+	assert.Equal(t, map[string][2]int64{"a": {int64(CircuitOpen), 2}, "b": {int64(CircuitClosed), 0}}, observed)
+
+	_, err = (&CircuitBreakerConfig{KeyBy: "method"}).NewClientCircuitBreaker("x.circuit-breaker", "x.client", logger, "host", "path")
+	assert.Error(t, err)
+	_, err = (&CircuitBreakerConfig{ErrorRate: 2}).NewClientCircuitBreaker("x.circuit-breaker", "x.client", logger, "host")
+	assert.Error(t, err)
+}
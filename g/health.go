@@ -0,0 +1,13 @@
+package g
+
+import "context"
+
+// HealthChecker reports the status of a dependency, eg: database, redis, message queue.
+// Goners implementing it are checked by the readiness probe of the http server.
+type HealthChecker interface {
+	// HealthCheckName name of the dependency, which is shown in the result of readiness probe
+	HealthCheckName() string
+
+	// CheckHealth return nil if the dependency is available; ctx is canceled when the check timeout is reached
+	CheckHealth(ctx context.Context) error
+}
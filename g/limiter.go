@@ -0,0 +1,247 @@
+package g
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+)
+
+const (
+	// TokenBucket token bucket algorithm, Limit is the rate of tokens per second and Burst is the size of bucket
+	TokenBucket = "token-bucket"
+
+	// SlidingWindow sliding window algorithm, Limit is the max requests in Window
+	SlidingWindow = "sliding-window"
+)
+
+// RateLimitRule describes the limit applied to the requests sharing the same key
+type RateLimitRule struct {
+	Algorithm string
+	Limit     float64
+	Burst     int
+	Window    time.Duration
+}
+
+// RateLimitResult the result of RateLimiter.Allow
+type RateLimitResult struct {
+	Allowed bool
+
+	// Limit the max requests allowed, it is Burst for token bucket and Limit for sliding window
+	Limit int
+
+	// Remaining requests allowed currently
+	Remaining int
+
+	// RetryAfter the duration to wait before retrying, only set when not allowed
+	RetryAfter time.Duration
+
+	// ResetAfter the duration until the limit is fully reset
+	ResetAfter time.Duration
+}
+
+// RateLimiter checks whether a request identified by key is allowed under the rule.
+// Implementations backed by a shared store (eg: redis) make limits hold across replicas.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, rule RateLimitRule) (RateLimitResult, error)
+}
+
+// Init set the defaults of rule and check it, name is used in the error messages: Algorithm is TokenBucket by default,
+// Burst is Limit rounded up for token bucket, and Window is 1s for sliding window.
+func (r *RateLimitRule) Init(name string) error {
+	if r.Algorithm == "" {
+		r.Algorithm = TokenBucket
+	}
+	if r.Limit <= 0 {
+		return gone.NewInnerErrorWithParams(gone.ConfigError, "limit of rate limit rule(%s) must be greater than 0", name)
+	}
+
+	switch r.Algorithm {
+	case TokenBucket:
+		if r.Burst <= 0 {
+			r.Burst = int(math.Ceil(r.Limit))
+		}
+	case SlidingWindow:
+		if r.Window <= 0 {
+			r.Window = time.Second
+		}
+	default:
+		return gone.NewInnerErrorWithParams(gone.ConfigError, "unsupported rate limit algorithm(%s) of rule(%s)", r.Algorithm, name)
+	}
+	return nil
+}
+
+// LimitRule the rate limit rule configured for servers, such as gin and gRPC; servers embed it with
+// `mapstructure:",squash"` and add the fields matching their requests.
+type LimitRule struct {
+	// Name of the rule, which is a part of limit key; default is the index of rule
+	Name string `mapstructure:"name" json:"name"`
+
+	// KeyBy how to group requests, whose values are defined by the server; empty means all requests matched share one limit
+	KeyBy string `mapstructure:"key-by" json:"key-by"`
+
+	// Algorithm `token-bucket`(default) or `sliding-window`
+	Algorithm string        `mapstructure:"algorithm" json:"algorithm"`
+	Limit     float64       `mapstructure:"limit" json:"limit"`
+	Burst     int           `mapstructure:"burst" json:"burst"`
+	Window    time.Duration `mapstructure:"window" json:"window"`
+}
+
+// Init set the name of rule to its index i if it's empty, and set the defaults of RateLimitRule.
+func (r *LimitRule) Init(i int) error {
+	if r.Name == "" {
+		r.Name = strconv.Itoa(i)
+	}
+	rule := r.RateLimitRule()
+	if err := rule.Init(r.Name); err != nil {
+		return err
+	}
+	r.Algorithm, r.Burst, r.Window = rule.Algorithm, rule.Burst, rule.Window
+	return nil
+}
+
+// RateLimitRule return the rule passed to RateLimiter
+func (r *LimitRule) RateLimitRule() RateLimitRule {
+	return RateLimitRule{
+		Algorithm: r.Algorithm,
+		Limit:     r.Limit,
+		Burst:     r.Burst,
+		Window:    r.Window,
+	}
+}
+
+// NewMemoryRateLimiter create an in memory RateLimiter, whose limits only hold in the current process;
+// it is used by gin and gRPC server when no RateLimiter is loaded.
+func NewMemoryRateLimiter() RateLimiter {
+	return newMemoryRateLimiter()
+}
+
+// memoryRateLimiter in memory implementation of RateLimiter, the state of keys not seen for idle is removed.
+type memoryRateLimiter struct {
+	lock      sync.Mutex
+	buckets   map[string]*bucket
+	windows   map[string]*window
+	lastSeen  map[string]time.Time
+	lastSweep time.Time
+	idle      time.Duration
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type window struct {
+	start time.Time
+	prev  int
+	curr  int
+}
+
+func newMemoryRateLimiter() *memoryRateLimiter {
+	return &memoryRateLimiter{
+		buckets:  make(map[string]*bucket),
+		windows:  make(map[string]*window),
+		lastSeen: make(map[string]time.Time),
+		idle:     10 * time.Minute,
+		now:      time.Now,
+	}
+}
+
+func (l *memoryRateLimiter) Allow(_ context.Context, key string, rule RateLimitRule) (RateLimitResult, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+	l.lastSeen[key] = now
+
+	if rule.Algorithm == SlidingWindow {
+		return l.slidingWindow(now, key, rule), nil
+	}
+	return l.tokenBucket(now, key, rule), nil
+}
+
+// sweep remove the state of keys which have not been seen for a long time
+func (l *memoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.idle {
+		return
+	}
+	l.lastSweep = now
+	for key, t := range l.lastSeen {
+		if now.Sub(t) >= l.idle {
+			delete(l.lastSeen, key)
+			delete(l.buckets, key)
+			delete(l.windows, key)
+		}
+	}
+}
+
+// tokenBucket the bucket is full at first, and refilled at the rate of Limit tokens per second up to Burst
+func (l *memoryRateLimiter) tokenBucket(now time.Time, key string, rule RateLimitRule) RateLimitResult {
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rule.Burst), last: now}
+		l.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(float64(rule.Burst), b.tokens+elapsed.Seconds()*rule.Limit)
+		b.last = now
+	}
+
+	result := RateLimitResult{Limit: rule.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = tokensDuration(1-b.tokens, rule.Limit)
+	}
+	result.Remaining = int(math.Max(0, math.Floor(b.tokens)))
+	result.ResetAfter = tokensDuration(float64(rule.Burst)-b.tokens, rule.Limit)
+	return result
+}
+
+// tokensDuration the duration to refill tokens at the rate of limit per second
+func tokensDuration(tokens, limit float64) time.Duration {
+	return time.Duration(tokens / limit * float64(time.Second))
+}
+
+// slidingWindow sliding window counter, the count of previous window is weighted by its overlap with the sliding window
+func (l *memoryRateLimiter) slidingWindow(now time.Time, key string, rule RateLimitRule) RateLimitResult {
+	w, ok := l.windows[key]
+	start := now.Truncate(rule.Window)
+	if !ok {
+		w = &window{start: start}
+		l.windows[key] = w
+	}
+	switch {
+	case start.Sub(w.start) == rule.Window:
+		w.prev, w.curr, w.start = w.curr, 0, start
+	case start.Sub(w.start) > rule.Window:
+		w.prev, w.curr, w.start = 0, 0, start
+	}
+
+	elapsed := now.Sub(start)
+	weight := float64(rule.Window-elapsed) / float64(rule.Window)
+	estimate := float64(w.prev)*weight + float64(w.curr)
+	limit := int(rule.Limit)
+
+	result := RateLimitResult{Limit: limit, ResetAfter: rule.Window - elapsed}
+	if estimate+1 <= rule.Limit {
+		w.curr++
+		result.Allowed = true
+		result.Remaining = int(math.Max(0, math.Floor(rule.Limit-estimate-1)))
+		return result
+	}
+
+	// wait until the weighted count of previous window decreases enough, or the next window when current window is full
+	result.RetryAfter = rule.Window - elapsed
+	if w.prev > 0 && float64(w.curr)+1 <= rule.Limit {
+		need := 1 - (rule.Limit-float64(w.curr)-1)/float64(w.prev)
+		result.RetryAfter = time.Duration(need*float64(rule.Window)) - elapsed
+	}
+	return result
+}
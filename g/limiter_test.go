@@ -0,0 +1,127 @@
+package g
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitRule_Init(t *testing.T) {
+	rule := RateLimitRule{Limit: 2.5}
+	assert.Nil(t, rule.Init("r"))
+	assert.Equal(t, TokenBucket, rule.Algorithm)
+	assert.Equal(t, 3, rule.Burst)
+
+	rule = RateLimitRule{Limit: 10, Algorithm: SlidingWindow}
+	assert.Nil(t, rule.Init("r"))
+	assert.Equal(t, time.Second, rule.Window)
+
+	rule = RateLimitRule{}
+	assert.Error(t, rule.Init("r"))
+
+	rule = RateLimitRule{Limit: 1, Algorithm: "unknown"}
+	assert.Error(t, rule.Init("r"))
+}
+
+func TestLimitRule_Init(t *testing.T) {
+	rule := LimitRule{Limit: 2.5}
+	assert.Nil(t, rule.Init(3))
+	assert.Equal(t, "3", rule.Name)
+	assert.Equal(t, RateLimitRule{Algorithm: TokenBucket, Limit: 2.5, Burst: 3}, rule.RateLimitRule())
+
+	rule = LimitRule{Name: "search", Algorithm: SlidingWindow, Limit: 10}
+	assert.Nil(t, rule.Init(0))
+	assert.Equal(t, "search", rule.Name)
+	assert.Equal(t, time.Second, rule.Window)
+
+	assert.Error(t, (&LimitRule{}).Init(0))
+	assert.Error(t, (&LimitRule{Limit: 1, Algorithm: "leaky"}).Init(0))
+}
+
+func Test_memoryRateLimiter_tokenBucket(t *testing.T) {
+	l := newMemoryRateLimiter()
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	rule := RateLimitRule{Algorithm: TokenBucket, Limit: 1, Burst: 2}
+	for i := 0; i < 2; i++ {
+		result, err := l.Allow(context.Background(), "k", rule)
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1-i, result.Remaining)
+	}
+
+	result, _ := l.Allow(context.Background(), "k", rule)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 2*time.Second, result.ResetAfter)
+
+	result, _ = l.Allow(context.Background(), "other", rule)
+	assert.True(t, result.Allowed)
+
+	now = now.Add(500 * time.Millisecond)
+	result, _ = l.Allow(context.Background(), "k", rule)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+	now = now.Add(500 * time.Millisecond)
+	result, _ = l.Allow(context.Background(), "k", rule)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// tokens are refilled up to burst
+	now = now.Add(time.Hour)
+	result, _ = l.Allow(context.Background(), "k", rule)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+}
+
+func Test_memoryRateLimiter_slidingWindow(t *testing.T) {
+	l := newMemoryRateLimiter()
+	now := time.Now().Truncate(time.Second)
+	l.now = func() time.Time { return now }
+
+	rule := RateLimitRule{Algorithm: SlidingWindow, Limit: 2, Window: time.Second}
+	for i := 0; i < 2; i++ {
+		result, _ := l.Allow(context.Background(), "k", rule)
+		assert.True(t, result.Allowed)
+	}
+	result, _ := l.Allow(context.Background(), "k", rule)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+
+	// half of previous window is still counted
+	now = now.Add(1500 * time.Millisecond)
+	result, _ = l.Allow(context.Background(), "k", rule)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	result, _ = l.Allow(context.Background(), "k", rule)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+	now = now.Add(time.Hour)
+	result, _ = l.Allow(context.Background(), "k", rule)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+	assert.Equal(t, 1, len(l.windows))
+}
+
+func Test_memoryRateLimiter_sweep(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := newMemoryRateLimiter()
+	l.now = func() time.Time { return now }
+
+	bucket := RateLimitRule{Algorithm: TokenBucket, Limit: 1, Burst: 2}
+	window := RateLimitRule{Algorithm: SlidingWindow, Limit: 2, Window: time.Second}
+	_, _ = l.Allow(context.Background(), "a", bucket)
+	_, _ = l.Allow(context.Background(), "b", window)
+
+	now = now.Add(time.Hour)
+	_, _ = l.Allow(context.Background(), "c", bucket)
+	assert.Len(t, l.lastSeen, 1)
+	assert.Len(t, l.buckets, 1)
+	assert.Len(t, l.windows, 0)
+}
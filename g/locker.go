@@ -5,3 +5,9 @@ import "time"
 type DoLocker interface {
 	LockAndDo(key string, fn func(), lockTime, checkPeriod time.Duration) (err error)
 }
+
+// TryLocker locks a key without waiting, eg: the distributed lock of redis.
+type TryLocker interface {
+	// TryLock lock key for ttl, return error if key is locked by others; call unlock to release the lock.
+	TryLock(key string, ttl time.Duration) (unlock func(), err error)
+}
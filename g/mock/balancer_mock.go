@@ -12,6 +12,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	g "github.com/gone-io/goner/g"
 	gomock "go.uber.org/mock/gomock"
@@ -94,3 +95,182 @@ func (mr *MockLoadBalanceStrategyMockRecorder) Select(ctx, instances any) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Select", reflect.TypeOf((*MockLoadBalanceStrategy)(nil).Select), ctx, instances)
 }
+
+// MockLoadBalanceFeedback is a mock of LoadBalanceFeedback interface.
+type MockLoadBalanceFeedback struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoadBalanceFeedbackMockRecorder
+	isgomock struct{}
+}
+
+// MockLoadBalanceFeedbackMockRecorder is the mock recorder for MockLoadBalanceFeedback.
+type MockLoadBalanceFeedbackMockRecorder struct {
+	mock *MockLoadBalanceFeedback
+}
+
+// NewMockLoadBalanceFeedback creates a new mock instance.
+func NewMockLoadBalanceFeedback(ctrl *gomock.Controller) *MockLoadBalanceFeedback {
+	mock := &MockLoadBalanceFeedback{ctrl: ctrl}
+	mock.recorder = &MockLoadBalanceFeedbackMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoadBalanceFeedback) EXPECT() *MockLoadBalanceFeedbackMockRecorder {
+	return m.recorder
+}
+
+// Available mocks base method.
+func (m *MockLoadBalanceFeedback) Available(serviceName string, instances []g.Service) []g.Service {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Available", serviceName, instances)
+	ret0, _ := ret[0].([]g.Service)
+	return ret0
+}
+
+// Available indicates an expected call of Available.
+func (mr *MockLoadBalanceFeedbackMockRecorder) Available(serviceName, instances any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Available", reflect.TypeOf((*MockLoadBalanceFeedback)(nil).Available), serviceName, instances)
+}
+
+// Feedback mocks base method.
+func (m *MockLoadBalanceFeedback) Feedback(serviceName, address string, err error, latency time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Feedback", serviceName, address, err, latency)
+}
+
+// Feedback indicates an expected call of Feedback.
+func (mr *MockLoadBalanceFeedbackMockRecorder) Feedback(serviceName, address, err, latency any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Feedback", reflect.TypeOf((*MockLoadBalanceFeedback)(nil).Feedback), serviceName, address, err, latency)
+}
+
+// MockLoadBalanceRouter is a mock of LoadBalanceRouter interface.
+type MockLoadBalanceRouter struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoadBalanceRouterMockRecorder
+	isgomock struct{}
+}
+
+// MockLoadBalanceRouterMockRecorder is the mock recorder for MockLoadBalanceRouter.
+type MockLoadBalanceRouterMockRecorder struct {
+	mock *MockLoadBalanceRouter
+}
+
+// NewMockLoadBalanceRouter creates a new mock instance.
+func NewMockLoadBalanceRouter(ctrl *gomock.Controller) *MockLoadBalanceRouter {
+	mock := &MockLoadBalanceRouter{ctrl: ctrl}
+	mock.recorder = &MockLoadBalanceRouterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoadBalanceRouter) EXPECT() *MockLoadBalanceRouterMockRecorder {
+	return m.recorder
+}
+
+// Route mocks base method.
+func (m *MockLoadBalanceRouter) Route(ctx context.Context, serviceName string, instances []g.Service) []g.Service {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Route", ctx, serviceName, instances)
+	ret0, _ := ret[0].([]g.Service)
+	return ret0
+}
+
+// Route indicates an expected call of Route.
+func (mr *MockLoadBalanceRouterMockRecorder) Route(ctx, serviceName, instances any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Route", reflect.TypeOf((*MockLoadBalanceRouter)(nil).Route), ctx, serviceName, instances)
+}
+
+// MockLoadBalanceSelector is a mock of LoadBalanceSelector interface.
+type MockLoadBalanceSelector struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoadBalanceSelectorMockRecorder
+	isgomock struct{}
+}
+
+// MockLoadBalanceSelectorMockRecorder is the mock recorder for MockLoadBalanceSelector.
+type MockLoadBalanceSelectorMockRecorder struct {
+	mock *MockLoadBalanceSelector
+}
+
+// NewMockLoadBalanceSelector creates a new mock instance.
+func NewMockLoadBalanceSelector(ctrl *gomock.Controller) *MockLoadBalanceSelector {
+	mock := &MockLoadBalanceSelector{ctrl: ctrl}
+	mock.recorder = &MockLoadBalanceSelectorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoadBalanceSelector) EXPECT() *MockLoadBalanceSelectorMockRecorder {
+	return m.recorder
+}
+
+// Select mocks base method.
+func (m *MockLoadBalanceSelector) Select(ctx context.Context, serviceName string, instances []g.Service) (g.Service, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Select", ctx, serviceName, instances)
+	ret0, _ := ret[0].(g.Service)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Select indicates an expected call of Select.
+func (mr *MockLoadBalanceSelectorMockRecorder) Select(ctx, serviceName, instances any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Select", reflect.TypeOf((*MockLoadBalanceSelector)(nil).Select), ctx, serviceName, instances)
+}
+
+// MockInstanceProber is a mock of InstanceProber interface.
+type MockInstanceProber struct {
+	ctrl     *gomock.Controller
+	recorder *MockInstanceProberMockRecorder
+	isgomock struct{}
+}
+
+// MockInstanceProberMockRecorder is the mock recorder for MockInstanceProber.
+type MockInstanceProberMockRecorder struct {
+	mock *MockInstanceProber
+}
+
+// NewMockInstanceProber creates a new mock instance.
+func NewMockInstanceProber(ctrl *gomock.Controller) *MockInstanceProber {
+	mock := &MockInstanceProber{ctrl: ctrl}
+	mock.recorder = &MockInstanceProberMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInstanceProber) EXPECT() *MockInstanceProberMockRecorder {
+	return m.recorder
+}
+
+// Probe mocks base method.
+func (m *MockInstanceProber) Probe(ctx context.Context, instance g.Service) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Probe", ctx, instance)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Probe indicates an expected call of Probe.
+func (mr *MockInstanceProberMockRecorder) Probe(ctx, instance any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Probe", reflect.TypeOf((*MockInstanceProber)(nil).Probe), ctx, instance)
+}
+
+// ProbeType mocks base method.
+func (m *MockInstanceProber) ProbeType() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProbeType")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ProbeType indicates an expected call of ProbeType.
+func (mr *MockInstanceProberMockRecorder) ProbeType() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProbeType", reflect.TypeOf((*MockInstanceProber)(nil).ProbeType))
+}
@@ -53,3 +53,42 @@ func (mr *MockDoLockerMockRecorder) LockAndDo(key, fn, lockTime, checkPeriod any
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockAndDo", reflect.TypeOf((*MockDoLocker)(nil).LockAndDo), key, fn, lockTime, checkPeriod)
 }
+
+// MockTryLocker is a mock of TryLocker interface.
+type MockTryLocker struct {
+	ctrl     *gomock.Controller
+	recorder *MockTryLockerMockRecorder
+	isgomock struct{}
+}
+
+// MockTryLockerMockRecorder is the mock recorder for MockTryLocker.
+type MockTryLockerMockRecorder struct {
+	mock *MockTryLocker
+}
+
+// NewMockTryLocker creates a new mock instance.
+func NewMockTryLocker(ctrl *gomock.Controller) *MockTryLocker {
+	mock := &MockTryLocker{ctrl: ctrl}
+	mock.recorder = &MockTryLockerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTryLocker) EXPECT() *MockTryLockerMockRecorder {
+	return m.recorder
+}
+
+// TryLock mocks base method.
+func (m *MockTryLocker) TryLock(key string, ttl time.Duration) (func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TryLock", key, ttl)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TryLock indicates an expected call of TryLock.
+func (mr *MockTryLockerMockRecorder) TryLock(key, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryLock", reflect.TypeOf((*MockTryLocker)(nil).TryLock), key, ttl)
+}
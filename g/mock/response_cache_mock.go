@@ -0,0 +1,91 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../response_cache.go
+//
+// Generated by this command:
+//
+//	mockgen -package=mock -source=../response_cache.go -destination=./response_cache_mock.go
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	g "github.com/gone-io/goner/g"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockResponseCacheStore is a mock of ResponseCacheStore interface.
+type MockResponseCacheStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockResponseCacheStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockResponseCacheStoreMockRecorder is the mock recorder for MockResponseCacheStore.
+type MockResponseCacheStoreMockRecorder struct {
+	mock *MockResponseCacheStore
+}
+
+// NewMockResponseCacheStore creates a new mock instance.
+func NewMockResponseCacheStore(ctrl *gomock.Controller) *MockResponseCacheStore {
+	mock := &MockResponseCacheStore{ctrl: ctrl}
+	mock.recorder = &MockResponseCacheStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockResponseCacheStore) EXPECT() *MockResponseCacheStoreMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockResponseCacheStore) Get(ctx context.Context, key string) (*g.CachedResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].(*g.CachedResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockResponseCacheStoreMockRecorder) Get(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockResponseCacheStore)(nil).Get), ctx, key)
+}
+
+// InvalidateTags mocks base method.
+func (m *MockResponseCacheStore) InvalidateTags(ctx context.Context, tags ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range tags {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "InvalidateTags", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateTags indicates an expected call of InvalidateTags.
+func (mr *MockResponseCacheStoreMockRecorder) InvalidateTags(ctx any, tags ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, tags...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateTags", reflect.TypeOf((*MockResponseCacheStore)(nil).InvalidateTags), varargs...)
+}
+
+// Set mocks base method.
+func (m *MockResponseCacheStore) Set(ctx context.Context, key string, response *g.CachedResponse, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, key, response, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockResponseCacheStoreMockRecorder) Set(ctx, key, response, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockResponseCacheStore)(nil).Set), ctx, key, response, ttl)
+}
@@ -0,0 +1,38 @@
+package g
+
+import (
+	"context"
+	"time"
+)
+
+// CachedResponse a http response stored by ResponseCacheStore
+type CachedResponse struct {
+	Status int                 `json:"status"`
+	Header map[string][]string `json:"header"`
+	Body   []byte              `json:"body"`
+
+	// StoredAt when the response is stored, which is used to compute the age of response
+	StoredAt time.Time `json:"storedAt"`
+
+	// TTL how long the response is fresh; after that it is stale, and can be served while revalidating
+	TTL time.Duration `json:"ttl"`
+
+	// Tags the response is invalidated when any of its tags is invalidated
+	Tags []string `json:"tags"`
+
+	// Fingerprint hash of the request the response belongs to, eg: the body of an idempotent request
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// ResponseCacheStore stores the responses cached by http response cache.
+// Implementations backed by a shared store (eg: redis) make cached responses and invalidation shared across replicas.
+type ResponseCacheStore interface {
+	// Get return nil without error if the response is not found, expired, or invalidated by tags
+	Get(ctx context.Context, key string) (*CachedResponse, error)
+
+	// Set store the response, which is kept for ttl (not less than CachedResponse.TTL)
+	Set(ctx context.Context, key string, response *CachedResponse, ttl time.Duration) error
+
+	// InvalidateTags invalidate all responses with any of the tags
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
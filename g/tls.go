@@ -0,0 +1,276 @@
+package g
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+)
+
+const (
+	TLSClientAuthNone             = "none"
+	TLSClientAuthRequest          = "request"
+	TLSClientAuthRequireAny       = "require-any"
+	TLSClientAuthVerifyIfGiven    = "verify-if-given"
+	TLSClientAuthRequireAndVerify = "require-and-verify"
+
+	defaultTLSReloadInterval = 30 * time.Second
+)
+
+// TLSConfig the TLS configuration block shared by servers and clients, eg: gin, cmux and gRPC.
+// Certificate files are checked for changes every ReloadInterval on handshake, and reloaded when they are modified,
+// so rotating certificates does not require a restart; the CA bundle of client is loaded only once.
+type TLSConfig struct {
+	// Enabled whether TLS is enabled
+	Enabled bool `properties:"enabled,default=false" mapstructure:"enabled" json:"enabled"`
+
+	// CertFile KeyFile the certificate and private key in PEM; required by server, and used as client certificate by client
+	CertFile string `properties:"cert-file,default=" mapstructure:"cert-file" json:"cert-file"`
+	KeyFile  string `properties:"key-file,default=" mapstructure:"key-file" json:"key-file"`
+
+	// CAFile the CA bundle in PEM: server uses it to verify client certificates, client uses it to verify server
+	// certificates; client uses the system roots if it is empty
+	CAFile string `properties:"ca-file,default=" mapstructure:"ca-file" json:"ca-file"`
+
+	// ClientAuth server only, the policy of client certificate: `none`(default), `request`, `require-any`,
+	// `verify-if-given` and `require-and-verify`; use `require-and-verify` with CAFile for mTLS
+	ClientAuth string `properties:"client-auth,default=none" mapstructure:"client-auth" json:"client-auth"`
+
+	// MinVersion minimum TLS version: `1.0`, `1.1`, `1.2`(default) or `1.3`
+	MinVersion string `properties:"min-version,default=1.2" mapstructure:"min-version" json:"min-version"`
+
+	// CipherSuites names of cipher suites for TLS 1.0-1.2, eg: `TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256`; empty means
+	// the default of Go; cipher suites of TLS 1.3 are not configurable
+	CipherSuites []string `properties:"cipher-suites,default=" mapstructure:"cipher-suites" json:"cipher-suites"`
+
+	// ServerName client only, used to verify the hostname of server certificate, default is the host of address
+	ServerName string `properties:"server-name,default=" mapstructure:"server-name" json:"server-name"`
+
+	// InsecureSkipVerify client only, do not verify server certificate, for test only
+	InsecureSkipVerify bool `properties:"insecure-skip-verify,default=false" mapstructure:"insecure-skip-verify" json:"insecure-skip-verify"`
+
+	// ReloadInterval the interval of checking certificate files for changes, default is 30s, negative disables reload
+	ReloadInterval time.Duration `properties:"reload-interval,default=30s" mapstructure:"reload-interval" json:"reload-interval"`
+}
+
+var tlsVersions = map[string]uint16{
+	"":    tls.VersionTLS12,
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var tlsClientAuthTypes = map[string]tls.ClientAuthType{
+	"":                            tls.NoClientCert,
+	TLSClientAuthNone:             tls.NoClientCert,
+	TLSClientAuthRequest:          tls.RequestClientCert,
+	TLSClientAuthRequireAny:       tls.RequireAnyClientCert,
+	TLSClientAuthVerifyIfGiven:    tls.VerifyClientCertIfGiven,
+	TLSClientAuthRequireAndVerify: tls.RequireAndVerifyClientCert,
+}
+
+func (c *TLSConfig) base() (*tls.Config, error) {
+	version, ok := tlsVersions[c.MinVersion]
+	if !ok {
+		return nil, gone.NewInnerErrorWithParams(gone.ConfigError, "unsupported tls min-version(%s)", c.MinVersion)
+	}
+	config := &tls.Config{MinVersion: version}
+
+	if len(c.CipherSuites) > 0 {
+		suites := make(map[string]uint16)
+		for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+			suites[suite.Name] = suite.ID
+		}
+		for _, name := range c.CipherSuites {
+			id, ok := suites[name]
+			if !ok {
+				return nil, gone.NewInnerErrorWithParams(gone.ConfigError, "unsupported tls cipher suite(%s)", name)
+			}
+			config.CipherSuites = append(config.CipherSuites, id)
+		}
+	}
+	return config, nil
+}
+
+func (c *TLSConfig) newReloader(logger gone.Logger) (*certReloader, error) {
+	interval := c.ReloadInterval
+	if interval == 0 {
+		interval = defaultTLSReloadInterval
+	}
+	r := &certReloader{
+		certFile: c.CertFile,
+		keyFile:  c.KeyFile,
+		caFile:   c.CAFile,
+		interval: interval,
+		logger:   logger,
+		now:      time.Now,
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	r.checkedAt = r.now()
+	return r, nil
+}
+
+// ServerTLSConfig create the tls.Config for server, which supports both HTTP/2 and HTTP/1.1 by ALPN.
+func (c *TLSConfig) ServerTLSConfig(logger gone.Logger) (*tls.Config, error) {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, gone.NewInnerError("cert-file and key-file are required by tls server", gone.ConfigError)
+	}
+	clientAuth, ok := tlsClientAuthTypes[c.ClientAuth]
+	if !ok {
+		return nil, gone.NewInnerErrorWithParams(gone.ConfigError, "unsupported tls client-auth(%s)", c.ClientAuth)
+	}
+	if clientAuth >= tls.VerifyClientCertIfGiven && c.CAFile == "" {
+		return nil, gone.NewInnerErrorWithParams(gone.ConfigError, "ca-file is required by tls client-auth(%s)", c.ClientAuth)
+	}
+
+	config, err := c.base()
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.newReloader(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	config.ClientAuth = clientAuth
+	config.NextProtos = []string{"h2", "http/1.1"}
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cert, pool := r.get()
+		conf := config.Clone()
+		conf.GetConfigForClient = nil
+		conf.Certificates = []tls.Certificate{*cert}
+		conf.ClientCAs = pool
+		return conf, nil
+	}
+	return config, nil
+}
+
+// ClientTLSConfig create the tls.Config for client.
+func (c *TLSConfig) ClientTLSConfig(logger gone.Logger) (*tls.Config, error) {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return nil, gone.NewInnerError("cert-file and key-file must be set together", gone.ConfigError)
+	}
+	config, err := c.base()
+	if err != nil {
+		return nil, err
+	}
+	config.ServerName = c.ServerName
+	config.InsecureSkipVerify = c.InsecureSkipVerify
+	if c.CertFile == "" && c.CAFile == "" {
+		return config, nil
+	}
+
+	r, err := c.newReloader(logger)
+	if err != nil {
+		return nil, err
+	}
+	if c.CertFile != "" {
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := r.get()
+			return cert, nil
+		}
+	}
+	if c.CAFile != "" {
+		// the CA bundle is loaded once: the host to verify is unknown in VerifyConnection when dialing an IP address
+		_, config.RootCAs = r.get()
+	}
+	return config, nil
+}
+
+// certReloader holds the certificate and CA bundle loaded from files, and reloads them when the files are modified.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	interval time.Duration
+	logger   gone.Logger
+	now      func() time.Time
+
+	lock      sync.Mutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	modTimes  map[string]time.Time
+	checkedAt time.Time
+}
+
+func (r *certReloader) files() []string {
+	var files []string
+	for _, f := range []string{r.certFile, r.keyFile, r.caFile} {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func (r *certReloader) load() error {
+	modTimes := make(map[string]time.Time)
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			return gone.ToErrorWithMsg(err, "stat tls file")
+		}
+		modTimes[f] = info.ModTime()
+	}
+
+	var cert *tls.Certificate
+	if r.certFile != "" {
+		c, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return gone.ToErrorWithMsg(err, "load tls certificate")
+		}
+		cert = &c
+	}
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		data, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return gone.ToErrorWithMsg(err, "read tls ca-file")
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return gone.NewInnerErrorWithParams(gone.ConfigError, "no certificate found in tls ca-file(%s)", r.caFile)
+		}
+	}
+
+	r.cert, r.pool, r.modTimes = cert, pool, modTimes
+	return nil
+}
+
+// modified return true if any file is modified since last load
+func (r *certReloader) modified() bool {
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil || !info.ModTime().Equal(r.modTimes[f]) {
+			return true
+		}
+	}
+	return false
+}
+
+// get return the certificate and CA bundle, reload them first if the check interval is reached and files are
+// modified; the previous ones are kept if reloading failed, eg: files are being written.
+func (r *certReloader) get() (*tls.Certificate, *x509.CertPool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if now := r.now(); r.interval > 0 && now.Sub(r.checkedAt) >= r.interval {
+		r.checkedAt = now
+		if r.modified() {
+			if err := r.load(); err != nil {
+				if r.logger != nil {
+					r.logger.Warnf("reload tls certificate failed, keep the previous one: %v", err)
+				}
+			} else if r.logger != nil {
+				r.logger.Infof("tls certificate reloaded: %v", r.files())
+			}
+		}
+	}
+	return r.cert, r.pool
+}
@@ -0,0 +1,178 @@
+package g
+
+import (
+	"crypto/tls"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const tlsTestdata = "testdata/tls/"
+
+// handshake serve one TLS connection with server config and dial it with client config, return the serial number of
+// server certificate
+func handshake(t *testing.T, server, client *tls.Config) (*big.Int, error) {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", server)
+	assert.Nil(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.(*tls.Conn).Handshake()
+		_, _ = conn.Read(make([]byte, 1))
+	}()
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), client)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	// the error of client certificate verified by server is returned on reading under TLS 1.3
+	_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, err = conn.Read(make([]byte, 1)); err != nil && !os.IsTimeout(err) {
+		return nil, err
+	}
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber, nil
+}
+
+func TestTLSConfig_config(t *testing.T) {
+	config, err := (&TLSConfig{MinVersion: "1.3"}).ClientTLSConfig(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+
+	config, err = (&TLSConfig{CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}}).ClientTLSConfig(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, config.CipherSuites)
+
+	tests := []struct {
+		name   string
+		config TLSConfig
+		server bool
+	}{
+		{name: "bad version", config: TLSConfig{MinVersion: "2.0"}},
+		{name: "bad cipher suite", config: TLSConfig{CipherSuites: []string{"TLS_X"}}},
+		{name: "client key missing", config: TLSConfig{CertFile: tlsTestdata + "client.crt"}},
+		{name: "client file missing", config: TLSConfig{CertFile: "missing.crt", KeyFile: "missing.key"}},
+		{name: "server cert missing", config: TLSConfig{}, server: true},
+		{name: "bad client auth", config: TLSConfig{CertFile: tlsTestdata + "server.crt", KeyFile: tlsTestdata + "server.key", ClientAuth: "x"}, server: true},
+		{name: "client auth without ca", config: TLSConfig{CertFile: tlsTestdata + "server.crt", KeyFile: tlsTestdata + "server.key", ClientAuth: TLSClientAuthRequireAndVerify}, server: true},
+		{name: "bad ca", config: TLSConfig{CertFile: tlsTestdata + "server.crt", KeyFile: tlsTestdata + "server.key", CAFile: tlsTestdata + "server.key"}, server: true},
+		{name: "bad server cert", config: TLSConfig{CertFile: tlsTestdata + "server.crt", KeyFile: tlsTestdata + "client.key"}, server: true},
+		{name: "bad server version", config: TLSConfig{CertFile: tlsTestdata + "server.crt", KeyFile: tlsTestdata + "server.key", MinVersion: "x"}, server: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.server {
+				_, err = tt.config.ServerTLSConfig(nil)
+			} else {
+				_, err = tt.config.ClientTLSConfig(nil)
+			}
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTLSConfig_handshake(t *testing.T) {
+	server, err := (&TLSConfig{
+		CertFile:   tlsTestdata + "server.crt",
+		KeyFile:    tlsTestdata + "server.key",
+		CAFile:     tlsTestdata + "ca.crt",
+		ClientAuth: TLSClientAuthRequireAndVerify,
+	}).ServerTLSConfig(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"h2", "http/1.1"}, server.NextProtos)
+
+	client, err := (&TLSConfig{
+		CertFile: tlsTestdata + "client.crt",
+		KeyFile:  tlsTestdata + "client.key",
+		CAFile:   tlsTestdata + "ca.crt",
+	}).ClientTLSConfig(nil)
+	assert.Nil(t, err)
+	serial, err := handshake(t, server, client)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), serial.Int64())
+
+	// client without certificate is rejected by mTLS
+	client, err = (&TLSConfig{CAFile: tlsTestdata + "ca.crt"}).ClientTLSConfig(nil)
+	assert.Nil(t, err)
+	_, err = handshake(t, server, client)
+	assert.Error(t, err)
+
+	// server certificate is not trusted by system roots
+	client, err = (&TLSConfig{CertFile: tlsTestdata + "client.crt", KeyFile: tlsTestdata + "client.key"}).ClientTLSConfig(nil)
+	assert.Nil(t, err)
+	_, err = handshake(t, server, client)
+	assert.Error(t, err)
+
+	// server name does not match the certificate
+	client, err = (&TLSConfig{
+		CertFile:   tlsTestdata + "client.crt",
+		KeyFile:    tlsTestdata + "client.key",
+		CAFile:     tlsTestdata + "ca.crt",
+		ServerName: "example.com",
+	}).ClientTLSConfig(nil)
+	assert.Nil(t, err)
+	_, err = handshake(t, server, client)
+	assert.Error(t, err)
+}
+
+func copyFile(t *testing.T, src, dst string, modTime time.Time) {
+	data, err := os.ReadFile(src)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(dst, data, 0600))
+	assert.Nil(t, os.Chtimes(dst, modTime, modTime))
+}
+
+func TestTLSConfig_reload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	modTime := time.Now().Add(-time.Hour)
+	copyFile(t, tlsTestdata+"server.crt", certFile, modTime)
+	copyFile(t, tlsTestdata+"server.key", keyFile, modTime)
+
+	server, err := (&TLSConfig{CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Nanosecond}).ServerTLSConfig(nil)
+	assert.Nil(t, err)
+	client := &tls.Config{InsecureSkipVerify: true}
+
+	serial, err := handshake(t, server, client)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), serial.Int64())
+
+	// a broken certificate is not loaded, the previous one is kept
+	modTime = modTime.Add(time.Minute)
+	copyFile(t, tlsTestdata+"server2.crt", certFile, modTime)
+	serial, err = handshake(t, server, client)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), serial.Int64())
+
+	copyFile(t, tlsTestdata+"server2.key", keyFile, modTime)
+	serial, err = handshake(t, server, client)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), serial.Int64())
+}
+
+func TestTLSConfig_reloadDisabled(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	modTime := time.Now().Add(-time.Hour)
+	copyFile(t, tlsTestdata+"server.crt", certFile, modTime)
+	copyFile(t, tlsTestdata+"server.key", keyFile, modTime)
+
+	server, err := (&TLSConfig{CertFile: certFile, KeyFile: keyFile, ReloadInterval: -1}).ServerTLSConfig(nil)
+	assert.Nil(t, err)
+
+	copyFile(t, tlsTestdata+"server2.crt", certFile, time.Now())
+	copyFile(t, tlsTestdata+"server2.key", keyFile, time.Now())
+	serial, err := handshake(t, server, &tls.Config{InsecureSkipVerify: true})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), serial.Int64())
+}
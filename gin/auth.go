@@ -0,0 +1,75 @@
+package gin
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin/auth"
+)
+
+// authMiddleware authenticates the token carried by request, and set the principal into gin.Context.
+// Requests without token pass through, use `auth.RequireAuth()` or `auth.RequireScopes(...)` to guard route groups.
+type authMiddleware struct {
+	gone.Flag
+	logger        gone.Logger        `gone:"*"`
+	responser     Responser          `gone:"*"`
+	authenticator auth.Authenticator `gone:"*"`
+
+	// enabled 是否开启认证，对应配置项为：`server.auth.enabled`
+	enabled bool `gone:"config,server.auth.enabled,default=false"`
+
+	// header 携带令牌的请求头，对应配置项为：`server.auth.header`
+	header string `gone:"config,server.auth.header,default=Authorization"`
+
+	// scheme 令牌的认证方案，对应配置项为：`server.auth.scheme`；为空时请求头的值即为令牌
+	scheme string `gone:"config,server.auth.scheme,default=Bearer"`
+
+	// queryParam 携带令牌的查询参数，对应配置项为：`server.auth.query-param`；浏览器中的WebSocket无法设置请求头时使用
+	queryParam string `gone:"config,server.auth.query-param"`
+}
+
+func (m *authMiddleware) token(ctx *gin.Context) string {
+	value := ctx.GetHeader(m.header)
+	if value == "" {
+		if m.queryParam != "" {
+			return ctx.Query(m.queryParam)
+		}
+		return ""
+	}
+	if m.scheme == "" {
+		return value
+	}
+	scheme, token, ok := strings.Cut(value, " ")
+	if !ok || !strings.EqualFold(scheme, m.scheme) {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+func (m *authMiddleware) Process(ctx *gin.Context) {
+	if !m.enabled {
+		return
+	}
+	token := m.token(ctx)
+	if token == "" {
+		return
+	}
+
+	principal, err := m.authenticator.Authenticate(ctx.Request.Context(), token)
+	if err != nil {
+		msg := http.StatusText(http.StatusUnauthorized)
+		if errors.Is(err, auth.ErrInvalidToken) {
+			msg = err.Error()
+		} else {
+			m.logger.Warnf("authenticate token failed: %v", err)
+		}
+		ctx.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		m.responser.Failed(ctx, gone.NewError(http.StatusUnauthorized, msg, http.StatusUnauthorized))
+		ctx.Abort()
+		return
+	}
+	auth.SetPrincipal(ctx, principal)
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+)
+
+// Principal the authenticated caller of request, which is set into gin.Context by the auth middleware;
+// handlers can inject it by `gone:"http,principal"`.
+type Principal struct {
+	// Subject the `sub` claim of JWT, or the `sub` (`username` if empty) of introspection response
+	Subject string `json:"sub"`
+
+	// Scopes parsed from the scope claim, which can be a space separated string or an array
+	Scopes []string `json:"scopes"`
+
+	// Claims all claims of JWT, or all fields of introspection response
+	Claims map[string]any `json:"claims"`
+
+	// ExpiresAt zero if the token never expires
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// TokenType TokenTypeJWT or TokenTypeOpaque
+	TokenType string `json:"tokenType"`
+	Token     string `json:"-"`
+}
+
+const (
+	TokenTypeJWT    = "jwt"
+	TokenTypeOpaque = "opaque"
+)
+
+// HasScopes return true if the principal has all the scopes
+func (p *Principal) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		found := false
+		for _, s := range p.Scopes {
+			if s == scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Claim return the claim by name, nil if not exists
+func (p *Principal) Claim(name string) any {
+	return p.Claims[name]
+}
+
+// Introspector validates opaque tokens, which are not JWT. The builtin one implements OAuth2 token introspection (RFC 7662)
+// configured by `server.auth.introspection.*`; load a goner implementing Introspector to validate tokens in your own way.
+// Return an error wrapping ErrInvalidToken if the token is invalid or inactive.
+type Introspector interface {
+	Introspect(ctx context.Context, token string) (*Principal, error)
+}
+
+// Authenticator authenticates requests, the principal is set into gin.Context when the request carries a valid token.
+// Inject default Authenticator using Id: gone-gin-auth (`auth.IdGoneGinAuth`)
+type Authenticator interface {
+	// Authenticate validate the token, which is a JWT or an opaque token
+	Authenticate(ctx context.Context, token string) (*Principal, error)
+}
+
+const (
+	IdGoneGinAuth             = "gone-gin-auth"
+	IdGoneGinAuthIntrospector = "gone-gin-auth-introspector"
+
+	// principalKey the key of principal in gin.Context
+	principalKey = "gone-gin-auth-principal"
+)
+
+var (
+	// ErrInvalidToken the token is malformed, expired, or its signature is invalid
+	ErrInvalidToken = errors.New("invalid token")
+
+	Unauthorized = gone.NewError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	Forbidden    = gone.NewError(http.StatusForbidden, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+)
+
+// FromContext return the principal of request, false if the request is not authenticated
+func FromContext(ctx *gin.Context) (*Principal, bool) {
+	v, ok := ctx.Get(principalKey)
+	if !ok {
+		return nil, false
+	}
+	p, ok := v.(*Principal)
+	return p, ok && p != nil
+}
+
+// SetPrincipal set the principal of request, which is useful for testing or custom authentication middleware
+func SetPrincipal(ctx *gin.Context, p *Principal) {
+	ctx.Set(principalKey, p)
+}
+
+// RequireAuth guard rejects requests not authenticated with 401, eg: `router.Group("/api", auth.RequireAuth())`
+func RequireAuth() func(ctx *gin.Context) error {
+	return func(ctx *gin.Context) error {
+		if _, ok := FromContext(ctx); !ok {
+			challenge(ctx)
+			return Unauthorized
+		}
+		return nil
+	}
+}
+
+// RequireScopes guard rejects requests not authenticated with 401, and requests without all the scopes with 403,
+// eg: `router.Group("/admin", auth.RequireScopes("admin"))`
+func RequireScopes(scopes ...string) func(ctx *gin.Context) error {
+	return func(ctx *gin.Context) error {
+		p, ok := FromContext(ctx)
+		if !ok {
+			challenge(ctx)
+			return Unauthorized
+		}
+		if !p.HasScopes(scopes...) {
+			ctx.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+strings.Join(scopes, " ")+`"`)
+			return Forbidden
+		}
+		return nil
+	}
+}
+
+// challenge set `WWW-Authenticate` header of 401 response
+func challenge(ctx *gin.Context) {
+	ctx.Header("WWW-Authenticate", "Bearer")
+}
+
+// splitList split comma separated config value, blank items are ignored
+func splitList(s string) (list []string) {
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_guards(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	_, ok := FromContext(ctx)
+	assert.False(t, ok)
+	assert.True(t, errors.Is(RequireAuth()(ctx), Unauthorized))
+	assert.True(t, errors.Is(RequireScopes("admin")(ctx), Unauthorized))
+	assert.Equal(t, "Bearer", ctx.Writer.Header().Get("WWW-Authenticate"))
+
+	SetPrincipal(ctx, &Principal{Subject: "u1", Scopes: []string{"read"}, Claims: map[string]any{"email": "a@b.c"}})
+	p, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "a@b.c", p.Claim("email"))
+	assert.Nil(t, RequireAuth()(ctx))
+	assert.Nil(t, RequireScopes("read")(ctx))
+
+	err := RequireScopes("read", "admin")(ctx)
+	assert.Equal(t, http.StatusForbidden, Forbidden.GetStatusCode())
+	assert.True(t, errors.Is(err, Forbidden))
+	assert.Equal(t, `Bearer error="insufficient_scope", scope="read admin"`, ctx.Writer.Header().Get("WWW-Authenticate"))
+}
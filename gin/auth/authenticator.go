@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+)
+
+// authenticator the builtin Authenticator, which verifies JWT with the keys configured by `server.auth.jwt.*`,
+// and validates opaque tokens by Introspector.
+type authenticator struct {
+	gone.Flag
+	introspector Introspector `gone:"*"`
+
+	// algorithms 允许的签名算法，多个以逗号分隔，对应配置项为：`server.auth.jwt.algorithms`；
+	// 默认为空，允许所有支持的算法，算法必须与密钥类型匹配
+	algorithms string `gone:"config,server.auth.jwt.algorithms"`
+
+	// secret HS256/HS384/HS512 的密钥，对应配置项为：`server.auth.jwt.secret`
+	secret string `gone:"config,server.auth.jwt.secret"`
+
+	// publicKeyFile PEM格式的公钥或证书文件，对应配置项为：`server.auth.jwt.public-key-file`
+	publicKeyFile string `gone:"config,server.auth.jwt.public-key-file"`
+
+	// jwksFile 本地的JWKS文件，文件修改后自动重新加载，对应配置项为：`server.auth.jwt.jwks-file`
+	jwksFile string `gone:"config,server.auth.jwt.jwks-file"`
+
+	// jwksURL JWKS地址，对应配置项为：`server.auth.jwt.jwks-url`
+	jwksURL string `gone:"config,server.auth.jwt.jwks-url"`
+
+	// jwksRefreshInterval 定期重新加载JWKS的间隔，对应配置项为：`server.auth.jwt.jwks-refresh-interval`
+	jwksRefreshInterval time.Duration `gone:"config,server.auth.jwt.jwks-refresh-interval,default=1h"`
+
+	// jwksMinRefreshInterval 遇到未知kid时重新加载JWKS的最小间隔，对应配置项为：`server.auth.jwt.jwks-min-refresh-interval`
+	jwksMinRefreshInterval time.Duration `gone:"config,server.auth.jwt.jwks-min-refresh-interval,default=1m"`
+
+	issuer   string        `gone:"config,server.auth.jwt.issuer"`
+	audience string        `gone:"config,server.auth.jwt.audience"`
+	leeway   time.Duration `gone:"config,server.auth.jwt.leeway,default=30s"`
+
+	// scopeClaim 权限范围的claim，值可以是空格分隔的字符串或数组，对应配置项为：`server.auth.scope-claim`
+	scopeClaim string `gone:"config,server.auth.scope-claim,default=scope"`
+
+	keys    *keySet
+	allowed map[string]bool
+	now     func() time.Time
+}
+
+var supportedAlgorithms = []string{
+	"HS256", "HS384", "HS512",
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+func (a *authenticator) GonerName() string {
+	return IdGoneGinAuth
+}
+
+func (a *authenticator) Init() error {
+	if a.now == nil {
+		a.now = time.Now
+	}
+
+	a.allowed = make(map[string]bool)
+	for _, alg := range supportedAlgorithms {
+		a.allowed[alg] = a.algorithms == ""
+	}
+	for _, alg := range splitList(a.algorithms) {
+		if _, ok := a.allowed[alg]; !ok {
+			return gone.NewInnerErrorWithParams(gone.ConfigError, "unsupported jwt algorithm(%s)", alg)
+		}
+		a.allowed[alg] = true
+	}
+
+	a.keys = &keySet{
+		jwksFile:           a.jwksFile,
+		jwksURL:            a.jwksURL,
+		client:             &http.Client{Timeout: 10 * time.Second},
+		refreshInterval:    a.jwksRefreshInterval,
+		minRefreshInterval: a.jwksMinRefreshInterval,
+		now:                a.now,
+	}
+	if a.secret != "" {
+		a.keys.static = append(a.keys.static, jwk{key: []byte(a.secret)})
+	}
+	if a.publicKeyFile != "" {
+		data, err := os.ReadFile(a.publicKeyFile)
+		if err != nil {
+			return gone.NewInnerErrorWithParams(gone.ConfigError, "read jwt public key file(%s) failed: %v", a.publicKeyFile, err)
+		}
+		key, err := parsePublicKey(data)
+		if err != nil {
+			return gone.NewInnerErrorWithParams(gone.ConfigError, "parse jwt public key file(%s) failed: %v", a.publicKeyFile, err)
+		}
+		a.keys.static = append(a.keys.static, jwk{key: key})
+	}
+	return nil
+}
+
+func (a *authenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	if !isJWT(token) {
+		return a.introspector.Introspect(ctx, token)
+	}
+
+	t, err := parseJWT(token)
+	if err != nil {
+		return nil, err
+	}
+	if !a.allowed[t.header.Alg] {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, t.header.Alg)
+	}
+
+	keys, err := a.keys.lookup(ctx, t.header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	verified := false
+	for _, k := range keys {
+		if k.alg != "" && k.alg != t.header.Alg {
+			continue
+		}
+		if verified = verifySignature(t.header.Alg, k.key, t.signingInput, t.signature); verified {
+			break
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("%w: signature is invalid", ErrInvalidToken)
+	}
+
+	if err = validateClaims(t.claims, a.now(), a.leeway, a.issuer, a.audience); err != nil {
+		return nil, err
+	}
+
+	p := &Principal{
+		Scopes:    stringList(t.claims[a.scopeClaim]),
+		Claims:    t.claims,
+		TokenType: TokenTypeJWT,
+		Token:     token,
+	}
+	p.Subject, _ = t.claims["sub"].(string)
+	if exp, ok := numericDate(t.claims["exp"]); ok {
+		p.ExpiresAt = exp
+	}
+	return p, nil
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type funcIntrospector func(ctx context.Context, token string) (*Principal, error)
+
+func (f funcIntrospector) Introspect(ctx context.Context, token string) (*Principal, error) {
+	return f(ctx, token)
+}
+
+func Test_authenticator_Authenticate(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	file := filepath.Join(t.TempDir(), "pub.pem")
+	assert.Nil(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	now := time.Unix(1000, 0)
+	a := &authenticator{
+		secret:        "secret",
+		publicKeyFile: file,
+		issuer:        "https://issuer",
+		scopeClaim:    "scope",
+		now:           func() time.Time { return now },
+		introspector: funcIntrospector(func(ctx context.Context, token string) (*Principal, error) {
+			return &Principal{Subject: "opaque-user", TokenType: TokenTypeOpaque}, nil
+		}),
+	}
+	assert.Nil(t, a.Init())
+
+	claims := map[string]any{"sub": "u1", "iss": "https://issuer", "scope": "read write", "exp": 2000}
+	p, err := a.Authenticate(context.Background(), signJWT(t, "ES256", "", ecKey, claims))
+	assert.Nil(t, err)
+	assert.Equal(t, "u1", p.Subject)
+	assert.Equal(t, []string{"read", "write"}, p.Scopes)
+	assert.Equal(t, time.Unix(2000, 0), p.ExpiresAt)
+	assert.Equal(t, TokenTypeJWT, p.TokenType)
+	assert.True(t, p.HasScopes("read"))
+	assert.False(t, p.HasScopes("read", "admin"))
+
+	p, err = a.Authenticate(context.Background(), signJWT(t, "HS256", "", []byte("secret"), claims))
+	assert.Nil(t, err)
+	assert.Equal(t, "u1", p.Subject)
+
+	_, err = a.Authenticate(context.Background(), signJWT(t, "HS256", "", []byte("other"), claims))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	claims["iss"] = "https://other"
+	_, err = a.Authenticate(context.Background(), signJWT(t, "HS256", "", []byte("secret"), claims))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	p, err = a.Authenticate(context.Background(), "opaque")
+	assert.Nil(t, err)
+	assert.Equal(t, "opaque-user", p.Subject)
+
+	// algorithms not allowed
+	a.algorithms = "ES256"
+	assert.Nil(t, a.Init())
+	claims["iss"] = "https://issuer"
+	_, err = a.Authenticate(context.Background(), signJWT(t, "HS256", "", []byte("secret"), claims))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	a.algorithms = "none"
+	assert.Error(t, a.Init())
+
+	a.algorithms = ""
+	a.publicKeyFile = file + ".x"
+	assert.Error(t, a.Init())
+}
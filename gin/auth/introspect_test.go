@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_introspector_Introspect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		assert.Equal(t, "client", id)
+		assert.Equal(t, "pass", secret)
+		switch r.PostFormValue("token") {
+		case "good":
+			_, _ = w.Write([]byte(`{"active":true,"username":"u1","scope":"read","exp":2000}`))
+		case "error":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			_, _ = w.Write([]byte(`{"active":false}`))
+		}
+	}))
+	defer server.Close()
+
+	i := &introspector{url: server.URL, clientId: "client", clientSecret: "pass", scopeClaim: "scope"}
+	i.Init()
+
+	p, err := i.Introspect(context.Background(), "good")
+	assert.Nil(t, err)
+	assert.Equal(t, "u1", p.Subject)
+	assert.Equal(t, []string{"read"}, p.Scopes)
+	assert.Equal(t, TokenTypeOpaque, p.TokenType)
+	assert.Equal(t, int64(2000), p.ExpiresAt.Unix())
+
+	_, err = i.Introspect(context.Background(), "bad")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = i.Introspect(context.Background(), "error")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidToken)
+
+	_, err = (&introspector{}).Introspect(context.Background(), "good")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
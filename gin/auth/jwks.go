@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// jwk a verification key, key is []byte for HMAC, *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey
+type jwk struct {
+	kid string
+	alg string
+	key any
+}
+
+type jwkJSON struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// parseJWKS parse JSON Web Key Set, keys not for signature or of unsupported type are ignored
+func parseJWKS(data []byte) ([]jwk, error) {
+	var set struct {
+		Keys []jwkJSON `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	var keys []jwk
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("parse jwk(kid=%s) failed: %w", k.Kid, err)
+		}
+		if key != nil {
+			keys = append(keys, jwk{kid: k.Kid, alg: k.Alg, key: key})
+		}
+	}
+	return keys, nil
+}
+
+func (k *jwkJSON) publicKey() (any, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		curve, ok := map[string]elliptic.Curve{
+			"P-256": elliptic.P256(),
+			"P-384": elliptic.P384(),
+			"P-521": elliptic.P521(),
+		}[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		return decode(k.K)
+	default:
+		return nil, nil
+	}
+}
+
+// parsePublicKey parse PEM encoded public key or certificate
+func parsePublicKey(data []byte) (any, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+}
+
+// keySet the keys for verifying JWT. Keys from JWKS file or URL are reloaded every refreshInterval, and when a token
+// signed by an unknown key arrives, so that rotated keys are picked up; the latter is throttled by minRefreshInterval.
+type keySet struct {
+	static []jwk
+
+	jwksFile string
+	jwksURL  string
+	client   *http.Client
+
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+	now                func() time.Time
+
+	lock        sync.RWMutex
+	remote      []jwk
+	lastRefresh time.Time
+	fileModTime time.Time
+}
+
+func (s *keySet) hasRemote() bool {
+	return s.jwksFile != "" || s.jwksURL != ""
+}
+
+// lookup return the keys with kid, all keys if kid is empty
+func (s *keySet) lookup(ctx context.Context, kid string) ([]jwk, error) {
+	if s.hasRemote() {
+		s.lock.RLock()
+		stale := s.now().Sub(s.lastRefresh) >= s.refreshInterval
+		s.lock.RUnlock()
+		if stale {
+			if err := s.refresh(ctx, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	keys := s.find(kid)
+	if len(keys) == 0 && kid != "" && s.hasRemote() {
+		if err := s.refresh(ctx, true); err != nil {
+			return nil, err
+		}
+		keys = s.find(kid)
+	}
+	return keys, nil
+}
+
+func (s *keySet) find(kid string) (keys []jwk) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for _, list := range [][]jwk{s.static, s.remote} {
+		for _, k := range list {
+			if kid == "" || k.kid == "" || k.kid == kid {
+				keys = append(keys, k)
+			}
+		}
+	}
+	return keys
+}
+
+// refresh reload keys from JWKS file or URL; force is used when the kid is unknown, which is throttled.
+// If reloading fails, the keys loaded before are kept and the error is returned only when there is no key.
+func (s *keySet) refresh(ctx context.Context, force bool) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.now()
+	if force && now.Sub(s.lastRefresh) < s.minRefreshInterval ||
+		!force && now.Sub(s.lastRefresh) < s.refreshInterval {
+		return nil
+	}
+	s.lastRefresh = now
+
+	keys, err := s.load(ctx)
+	if err != nil {
+		if len(s.remote) == 0 {
+			return err
+		}
+		return nil
+	}
+	if keys != nil {
+		s.remote = keys
+	}
+	return nil
+}
+
+// load keys, nil without error means the file is not changed
+func (s *keySet) load(ctx context.Context) ([]jwk, error) {
+	if s.jwksFile != "" {
+		info, err := os.Stat(s.jwksFile)
+		if err != nil {
+			return nil, err
+		}
+		if info.ModTime().Equal(s.fileModTime) && s.remote != nil {
+			return nil, nil
+		}
+		data, err := os.ReadFile(s.jwksFile)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := parseJWKS(data)
+		if err == nil {
+			s.fileModTime = info.ModTime()
+		}
+		return keys, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.jwksURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks from %s failed: %s", s.jwksURL, res.Status)
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	return parseJWKS(data)
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func jwksJSON(keys ...map[string]string) []byte {
+	b, _ := json.Marshal(map[string]any{"keys": keys})
+	return b
+}
+
+func Test_parseJWKS(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	keys, err := parseJWKS(jwksJSON(
+		rsaJWK("r1", &rsaKey.PublicKey),
+		map[string]string{
+			"kty": "EC", "kid": "e1", "crv": "P-256",
+			"x": base64.RawURLEncoding.EncodeToString(ecKey.X.Bytes()),
+			"y": base64.RawURLEncoding.EncodeToString(ecKey.Y.Bytes()),
+		},
+		map[string]string{"kty": "oct", "kid": "o1", "k": base64.RawURLEncoding.EncodeToString([]byte("secret"))},
+		map[string]string{"kty": "RSA", "kid": "enc", "use": "enc"},
+		map[string]string{"kty": "unknown"},
+	))
+	assert.Nil(t, err)
+	assert.Len(t, keys, 3)
+	assert.True(t, rsaKey.PublicKey.Equal(keys[0].key))
+	assert.True(t, ecKey.PublicKey.Equal(keys[1].key))
+	assert.Equal(t, []byte("secret"), keys[2].key)
+
+	_, err = parseJWKS(jwksJSON(map[string]string{"kty": "EC", "crv": "P-1"}))
+	assert.Error(t, err)
+}
+
+func Test_parsePublicKey(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	der, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	key, err := parsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	assert.Nil(t, err)
+	assert.True(t, rsaKey.PublicKey.Equal(key))
+
+	key, err = parsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)}))
+	assert.Nil(t, err)
+	assert.True(t, rsaKey.PublicKey.Equal(key))
+
+	_, err = parsePublicKey([]byte("x"))
+	assert.Error(t, err)
+}
+
+func Test_keySet_url(t *testing.T) {
+	k1, _ := rsa.GenerateKey(rand.Reader, 2048)
+	k2, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	var current atomic.Value
+	current.Store(jwksJSON(rsaJWK("k1", &k1.PublicKey)))
+	var fetched atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched.Add(1)
+		_, _ = w.Write(current.Load().([]byte))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	s := &keySet{
+		jwksURL:            server.URL,
+		client:             server.Client(),
+		refreshInterval:    time.Hour,
+		minRefreshInterval: time.Minute,
+		now:                func() time.Time { return now },
+	}
+
+	keys, err := s.lookup(context.Background(), "k1")
+	assert.Nil(t, err)
+	assert.Len(t, keys, 1)
+	assert.Equal(t, int32(1), fetched.Load())
+
+	// key rotated, unknown kid triggers refresh, which is throttled
+	current.Store(jwksJSON(rsaJWK("k2", &k2.PublicKey)))
+	keys, _ = s.lookup(context.Background(), "k2")
+	assert.Len(t, keys, 0)
+	assert.Equal(t, int32(1), fetched.Load())
+
+	now = now.Add(2 * time.Minute)
+	keys, _ = s.lookup(context.Background(), "k2")
+	assert.Len(t, keys, 1)
+	assert.Equal(t, int32(2), fetched.Load())
+
+	// keys loaded before are kept when refresh fails
+	server.Config.Handler = http.NotFoundHandler()
+	now = now.Add(2 * time.Hour)
+	keys, err = s.lookup(context.Background(), "k2")
+	assert.Nil(t, err)
+	assert.Len(t, keys, 1)
+}
+
+func Test_keySet_file(t *testing.T) {
+	k1, _ := rsa.GenerateKey(rand.Reader, 2048)
+	k2, _ := rsa.GenerateKey(rand.Reader, 2048)
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	assert.Nil(t, os.WriteFile(file, jwksJSON(rsaJWK("k1", &k1.PublicKey)), 0600))
+
+	now := time.Now()
+	s := &keySet{
+		jwksFile:           file,
+		refreshInterval:    time.Hour,
+		minRefreshInterval: time.Minute,
+		now:                func() time.Time { return now },
+	}
+	keys, err := s.lookup(context.Background(), "k1")
+	assert.Nil(t, err)
+	assert.Len(t, keys, 1)
+
+	assert.Nil(t, os.WriteFile(file, jwksJSON(rsaJWK("k2", &k2.PublicKey)), 0600))
+	assert.Nil(t, os.Chtimes(file, now, now.Add(time.Second)))
+	now = now.Add(time.Hour)
+	keys, _ = s.lookup(context.Background(), "")
+	assert.Len(t, keys, 1)
+	assert.Equal(t, "k2", keys[0].kid)
+
+	_, err = (&keySet{jwksFile: file + ".x", now: time.Now}).lookup(context.Background(), "")
+	assert.Error(t, err)
+}
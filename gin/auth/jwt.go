@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+type jwtToken struct {
+	header       jwtHeader
+	claims       map[string]any
+	signingInput string
+	signature    []byte
+}
+
+// isJWT return true if the token looks like a JWS in compact serialization
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+func parseJWT(token string) (*jwtToken, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed jwt", ErrInvalidToken)
+	}
+
+	t := &jwtToken{signingInput: parts[0] + "." + parts[1]}
+	if err := decodeSegment(parts[0], &t.header); err != nil {
+		return nil, fmt.Errorf("%w: malformed jwt header", ErrInvalidToken)
+	}
+	if err := decodeSegment(parts[1], &t.claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed jwt claims", ErrInvalidToken)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed jwt signature", ErrInvalidToken)
+	}
+	t.signature = signature
+	return t, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// verifySignature verify the signature with the key, the type of key must match the algorithm,
+// so that a public key can not be used as HMAC secret.
+func verifySignature(alg string, key any, signingInput string, signature []byte) bool {
+	hash, ok := map[string]crypto.Hash{
+		"256": crypto.SHA256,
+		"384": crypto.SHA384,
+		"512": crypto.SHA512,
+	}[strings.TrimLeft(alg, "HSRPE")]
+
+	switch {
+	case alg == "EdDSA":
+		k, ok := key.(ed25519.PublicKey)
+		return ok && ed25519.Verify(k, []byte(signingInput), signature)
+	case !ok:
+		return false
+	case strings.HasPrefix(alg, "HS"):
+		k, ok := key.([]byte)
+		if !ok || len(k) == 0 {
+			return false
+		}
+		mac := hmac.New(hash.New, k)
+		mac.Write([]byte(signingInput))
+		return hmac.Equal(mac.Sum(nil), signature)
+	}
+
+	h := hash.New()
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+
+	switch {
+	case strings.HasPrefix(alg, "RS"):
+		k, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(k, hash, digest, signature) == nil
+	case strings.HasPrefix(alg, "PS"):
+		k, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPSS(k, hash, digest, signature, nil) == nil
+	case strings.HasPrefix(alg, "ES"):
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return false
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(k, digest, r, s)
+	default:
+		return false
+	}
+}
+
+// validateClaims check `exp`, `nbf`, `iss` and `aud`
+func validateClaims(claims map[string]any, now time.Time, leeway time.Duration, issuer, audience string) error {
+	if exp, ok := numericDate(claims["exp"]); ok && now.After(exp.Add(leeway)) {
+		return fmt.Errorf("%w: token is expired", ErrInvalidToken)
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(leeway).Before(nbf) {
+		return fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+	if issuer != "" && claims["iss"] != issuer {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if audience != "" && !containsString(claims["aud"], audience) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+	return nil
+}
+
+func numericDate(v any) (time.Time, bool) {
+	var seconds float64
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		seconds = f
+	case float64:
+		seconds = n
+	default:
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
+
+// containsString claim is a string equal to s, or an array containing s
+func containsString(claim any, s string) bool {
+	switch v := claim.(type) {
+	case string:
+		return v == s
+	case []any:
+		for _, item := range v {
+			if item == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stringList parse claim which is a space separated string or an array of strings
+func stringList(claim any) (list []string) {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+	case []string:
+		return v
+	}
+	return list
+}
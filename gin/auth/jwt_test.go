@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// signJWT sign claims with the key for testing
+func signJWT(t *testing.T, alg, kid string, key any, claims map[string]any) string {
+	header, _ := json.Marshal(jwtHeader{Alg: alg, Kid: kid, Typ: "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(input))
+	var signature []byte
+	var err error
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		if alg == "PS256" {
+			signature, err = rsa.SignPSS(rand.Reader, k, crypto.SHA256, digest[:], nil)
+		} else {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		}
+	case *ecdsa.PrivateKey:
+		r, s, e := ecdsa.Sign(rand.Reader, k, digest[:])
+		err = e
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(k, []byte(input))
+	}
+	assert.Nil(t, err)
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func Test_verifySignature(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	secret := []byte("secret")
+
+	tests := []struct {
+		alg     string
+		signKey any
+		key     any
+	}{
+		{"HS256", secret, secret},
+		{"RS256", rsaKey, &rsaKey.PublicKey},
+		{"PS256", rsaKey, &rsaKey.PublicKey},
+		{"ES256", ecKey, &ecKey.PublicKey},
+		{"EdDSA", edKey, edPub},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			token, err := parseJWT(signJWT(t, tt.alg, "", tt.signKey, map[string]any{"sub": "u"}))
+			assert.Nil(t, err)
+			assert.True(t, verifySignature(tt.alg, tt.key, token.signingInput, token.signature))
+			assert.False(t, verifySignature(tt.alg, tt.key, token.signingInput+"x", token.signature))
+		})
+	}
+
+	// key type must match algorithm
+	token, _ := parseJWT(signJWT(t, "HS256", "", secret, nil))
+	assert.False(t, verifySignature("HS256", &rsaKey.PublicKey, token.signingInput, token.signature))
+	assert.False(t, verifySignature("none", secret, token.signingInput, token.signature))
+}
+
+func Test_parseJWT(t *testing.T) {
+	_, err := parseJWT("a.b")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = parseJWT("!.b.c")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	assert.True(t, isJWT("a.b.c"))
+	assert.False(t, isJWT("opaque"))
+}
+
+func Test_validateClaims(t *testing.T) {
+	now := time.Unix(1000, 0)
+	claims := func(s string) map[string]any {
+		var m map[string]any
+		_ = decodeSegment(base64.RawURLEncoding.EncodeToString([]byte(s)), &m)
+		return m
+	}
+
+	assert.Nil(t, validateClaims(claims(`{"exp":1010,"nbf":990,"iss":"a","aud":["x","y"]}`), now, 0, "a", "y"))
+	assert.ErrorIs(t, validateClaims(claims(`{"exp":990}`), now, 0, "", ""), ErrInvalidToken)
+	assert.Nil(t, validateClaims(claims(`{"exp":990}`), now, 30*time.Second, "", ""))
+	assert.ErrorIs(t, validateClaims(claims(`{"nbf":1010}`), now, 0, "", ""), ErrInvalidToken)
+	assert.ErrorIs(t, validateClaims(claims(`{"iss":"b"}`), now, 0, "a", ""), ErrInvalidToken)
+	assert.ErrorIs(t, validateClaims(claims(`{"aud":"x"}`), now, 0, "", "y"), ErrInvalidToken)
+
+	assert.Equal(t, []string{"a", "b"}, stringList("a b"))
+	assert.Equal(t, []string{"a", "b"}, stringList([]any{"a", "b", 1}))
+	assert.Nil(t, stringList(nil))
+}
@@ -0,0 +1,11 @@
+package auth
+
+import "github.com/gone-io/gone/v2"
+
+// Load the builtin Authenticator and Introspector
+func Load(loader gone.Loader) error {
+	loader.
+		MustLoad(&introspector{}, gone.IsDefault(new(Introspector))).
+		MustLoad(&authenticator{}, gone.IsDefault(new(Authenticator)))
+	return nil
+}
@@ -0,0 +1,114 @@
+package gin
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin/auth"
+	"github.com/gone-io/goner/gin/internal/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func hs256(secret string, claims map[string]any) string {
+	enc := base64.RawURLEncoding.EncodeToString
+	payload, _ := json.Marshal(claims)
+	input := enc([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + enc(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+	return input + "." + enc(mac.Sum(nil))
+}
+
+type authCtr struct {
+	gone.Flag
+	r IRouter `gone:"*"`
+}
+
+func (c *authCtr) Mount() MountError {
+	c.r.GET("/public", func(in struct {
+		p *auth.Principal `gone:"http,principal"`
+	}) string {
+		if in.p == nil {
+			return "anonymous"
+		}
+		return in.p.Subject
+	})
+
+	admin := c.r.Group("/admin", auth.RequireScopes("admin"))
+	admin.GET("/me", func(in struct {
+		email string `gone:"http,principal=email"`
+	}) string {
+		return in.email
+	})
+	return nil
+}
+
+func Test_authMiddleware(t *testing.T) {
+	t.Setenv("GONE_SERVER_PORT", "0")
+	t.Setenv("GONE_SERVER_RETURN_WRAPPED-DATA", "false")
+	t.Setenv("GONE_SERVER_AUTH_ENABLED", "true")
+	t.Setenv("GONE_SERVER_AUTH_JWT_SECRET", "secret")
+
+	gone.
+		NewApp(Load).
+		Load(&authCtr{}).
+		Run(func(s *server) {
+			get := func(path, token string) (int, string, http.Header) {
+				req, _ := http.NewRequest(http.MethodGet, "http://"+s.getAddress()+path, nil)
+				if token != "" {
+					req.Header.Set("Authorization", "Bearer "+token)
+				}
+				res, err := http.DefaultClient.Do(req)
+				assert.Nil(t, err)
+				defer res.Body.Close()
+				body, _ := io.ReadAll(res.Body)
+				return res.StatusCode, string(body), res.Header
+			}
+
+			code, body, _ := get("/public", "")
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, "anonymous", body)
+
+			user := hs256("secret", map[string]any{"sub": "u1", "scope": "read", "email": "u1@x.com"})
+			code, body, _ = get("/public", user)
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, "u1", body)
+
+			code, _, header := get("/public", hs256("other", map[string]any{"sub": "u1"}))
+			assert.Equal(t, http.StatusUnauthorized, code)
+			assert.Equal(t, `Bearer error="invalid_token"`, header.Get("WWW-Authenticate"))
+
+			code, _, _ = get("/admin/me", "")
+			assert.Equal(t, http.StatusUnauthorized, code)
+
+			code, _, _ = get("/admin/me", user)
+			assert.Equal(t, http.StatusForbidden, code)
+
+			admin := hs256("secret", map[string]any{"sub": "u2", "scope": []string{"admin"}, "email": "u2@x.com"})
+			code, body, _ = get("/admin/me", admin)
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, "u2@x.com", body)
+		})
+}
+
+func Test_authMiddleware_token(t *testing.T) {
+	m := &authMiddleware{header: "Authorization", scheme: "Bearer", queryParam: "access_token"}
+	ctx := func(header, url string) *Context {
+		c := &Context{Request: &http.Request{Header: http.Header{}}}
+		c.Request, _ = http.NewRequest(http.MethodGet, url, nil)
+		if header != "" {
+			c.Request.Header.Set("Authorization", header)
+		}
+		return c
+	}
+	assert.Equal(t, "t1", m.token(ctx("bearer t1", "/")))
+	assert.Equal(t, "", m.token(ctx("Basic t1", "/")))
+	assert.Equal(t, "t2", m.token(ctx("", "/ws?access_token=t2")))
+
+	m.scheme = ""
+	assert.Equal(t, "raw", m.token(ctx("raw", "/")))
+}
@@ -0,0 +1,217 @@
+package codec
+
+import (
+	"encoding"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin/internal/json"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+type jsonCodec struct {
+	gone.Flag
+}
+
+func (c *jsonCodec) builtin() {}
+
+func (c *jsonCodec) MediaTypes() []string {
+	return []string{MIMEJSON}
+}
+
+func (c *jsonCodec) Encodable(any) bool {
+	return true
+}
+
+// Encode encode JSON in the same way as gin render
+func (c *jsonCodec) Encode(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Decode decode JSON in the same way as gin binding, `binding.EnableDecoderUseNumber` and
+// `binding.EnableDecoderDisallowUnknownFields` are respected.
+func (c *jsonCodec) Decode(r io.Reader, v any) error {
+	decoder := json.NewDecoder(r)
+	if binding.EnableDecoderUseNumber {
+		decoder.UseNumber()
+	}
+	if binding.EnableDecoderDisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	return decoder.Decode(v)
+}
+
+type xmlCodec struct {
+	gone.Flag
+}
+
+func (c *xmlCodec) builtin() {}
+
+func (c *xmlCodec) MediaTypes() []string {
+	return []string{MIMEXML, MIMEXML2}
+}
+
+// Encodable encoding/xml does not support map, channel, function and complex number, which is checked by reflect
+// instead of marshalling the value.
+func (c *xmlCodec) Encodable(v any) bool {
+	return xmlEncodable(reflect.ValueOf(v), false)
+}
+
+var (
+	xmlMarshalerType  = reflect.TypeFor[xml.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// xmlEncodable check the types of v and the values it contains, the types implementing xml.Marshaler or
+// encoding.TextMarshaler are encodable; struct fields which are unexported or tagged with `xml:"-"` are skipped.
+// named is true if the element name is given by struct field, otherwise byte slices and anonymous structs, which have
+// no element name, are not encodable.
+func xmlEncodable(v reflect.Value, named bool) bool {
+	if !v.IsValid() {
+		return true
+	}
+	for _, t := range []reflect.Type{v.Type(), reflect.PointerTo(v.Type())} {
+		if t.Implements(xmlMarshalerType) || t.Implements(textMarshalerType) {
+			return true
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Map, reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return false
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil() || xmlEncodable(v.Elem(), named)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return named
+		}
+		for i := 0; i < v.Len(); i++ {
+			if !xmlEncodable(v.Index(i), named) {
+				return false
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		if !named && t.Name() == "" {
+			return false
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); !f.IsExported() && !f.Anonymous || f.Tag.Get("xml") == "-" {
+				continue
+			}
+			if !xmlEncodable(v.Field(i), true) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (c *xmlCodec) Encode(w io.Writer, v any) error {
+	return xml.NewEncoder(w).Encode(v)
+}
+
+func (c *xmlCodec) Decode(r io.Reader, v any) error {
+	return xml.NewDecoder(r).Decode(v)
+}
+
+type yamlCodec struct {
+	gone.Flag
+}
+
+func (c *yamlCodec) builtin() {}
+
+func (c *yamlCodec) MediaTypes() []string {
+	return []string{MIMEYAML, MIMEYAML2}
+}
+
+func (c *yamlCodec) Encodable(any) bool {
+	return true
+}
+
+func (c *yamlCodec) Encode(w io.Writer, v any) error {
+	encoder := yaml.NewEncoder(w)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func (c *yamlCodec) Decode(r io.Reader, v any) error {
+	return yaml.NewDecoder(r).Decode(v)
+}
+
+type msgpackCodec struct {
+	gone.Flag
+	handle codec.MsgpackHandle
+}
+
+func (c *msgpackCodec) builtin() {}
+
+func (c *msgpackCodec) MediaTypes() []string {
+	return []string{MIMEMsgPack, MIMEMsgPack2}
+}
+
+func (c *msgpackCodec) Encodable(any) bool {
+	return true
+}
+
+func (c *msgpackCodec) Encode(w io.Writer, v any) error {
+	return codec.NewEncoder(w, &c.handle).Encode(v)
+}
+
+func (c *msgpackCodec) Decode(r io.Reader, v any) error {
+	return codec.NewDecoder(r, &c.handle).Decode(v)
+}
+
+type protobufCodec struct {
+	gone.Flag
+}
+
+func (c *protobufCodec) builtin() {}
+
+func (c *protobufCodec) MediaTypes() []string {
+	return []string{MIMEProtobuf, MIMEProtobuf2}
+}
+
+// Encodable only proto.Message can be encoded
+func (c *protobufCodec) Encodable(v any) bool {
+	_, ok := v.(proto.Message)
+	return ok
+}
+
+func (c *protobufCodec) Encode(w io.Writer, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T is not proto.Message", v)
+	}
+	data, err := proto.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (c *protobufCodec) Decode(r io.Reader, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T is not proto.Message", v)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(data, m)
+}
@@ -0,0 +1,95 @@
+package codec
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type user struct {
+	Name string `json:"name" xml:"name" yaml:"name"`
+	Age  int    `json:"age" xml:"age" yaml:"age"`
+}
+
+func Test_builtin_roundTrip(t *testing.T) {
+	in := user{Name: "gone", Age: 3}
+	for _, c := range []Codec{&jsonCodec{}, &xmlCodec{}, &yamlCodec{}, &msgpackCodec{}} {
+		t.Run(c.MediaTypes()[0], func(t *testing.T) {
+			assert.True(t, c.Encodable(in))
+
+			var buf bytes.Buffer
+			assert.Nil(t, c.Encode(&buf, in))
+
+			var out user
+			assert.Nil(t, c.Decode(&buf, &out))
+			assert.Equal(t, in, out)
+		})
+	}
+}
+
+func Test_jsonCodec_Encode(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Nil(t, (&jsonCodec{}).Encode(&buf, map[string]string{"a": "<b>"}))
+	// the same as gin render, html characters are escaped
+	assert.Equal(t, `{"a":"\u003cb\u003e"}`, buf.String())
+}
+
+func Test_xmlCodec_Encodable(t *testing.T) {
+	type item struct {
+		Name   string
+		Raw    []byte
+		Tags   map[string]string `xml:"-"`
+		hidden func()
+		At     time.Time
+	}
+	type page struct {
+		Items []*item
+		Data  any
+	}
+
+	c := &xmlCodec{}
+	for _, v := range []any{
+		nil,
+		"a",
+		[]int{1},
+		&page{Items: []*item{{Name: "a"}, nil}, Data: item{}},
+		page{Data: []any{1, "a"}},
+	} {
+		assert.True(t, c.Encodable(v), "%#v", v)
+		assert.Nil(t, c.Encode(io.Discard, v), "%#v", v)
+	}
+
+	for _, v := range []any{
+		map[string]any{"a": 1},
+		[]map[string]any{{"a": 1}},
+		page{Data: map[string]int{"a": 1}},
+		&page{Data: []any{1, make(chan int)}},
+		complex(1, 2),
+		[]byte("a"),
+		struct{ A int }{},
+	} {
+		assert.False(t, c.Encodable(v), "%#v", v)
+		assert.Error(t, c.Encode(io.Discard, v), "%#v", v)
+	}
+}
+
+func Test_protobufCodec(t *testing.T) {
+	c := &protobufCodec{}
+	assert.False(t, c.Encodable(user{}))
+	assert.Error(t, c.Encode(&bytes.Buffer{}, user{}))
+	assert.Error(t, c.Decode(&bytes.Buffer{}, &user{}))
+
+	in := wrapperspb.String("gone")
+	assert.True(t, c.Encodable(in))
+
+	var buf bytes.Buffer
+	assert.Nil(t, c.Encode(&buf, in))
+
+	out := &wrapperspb.StringValue{}
+	assert.Nil(t, c.Decode(&buf, out))
+	assert.Equal(t, "gone", out.GetValue())
+}
@@ -0,0 +1,219 @@
+package codec
+
+import (
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gone-io/gone/v2"
+)
+
+// Codec encodes response data and decodes request body for media types.
+// Goners implementing Codec are used for content negotiation of responses and for parsing request body,
+// a Codec loaded by user takes the place of the builtin one with the same media type.
+type Codec interface {
+	// MediaTypes the media types supported, eg: `application/json`; the first one is used as Content-Type of response
+	MediaTypes() []string
+
+	// Encodable return false if the value can not be encoded, eg: protobuf codec only encodes proto.Message
+	Encodable(v any) bool
+
+	Encode(w io.Writer, v any) error
+	Decode(r io.Reader, v any) error
+}
+
+// Negotiator selects Codec by `Accept` and `Content-Type` header.
+// Inject default Negotiator using Id: gone-gin-codec-negotiator (`codec.IdGoneGinCodecNegotiator`)
+type Negotiator interface {
+	// Accepted return the codecs acceptable for `Accept` header in the order of preference;
+	// the default codec (JSON) comes first if `Accept` is empty, or contains `text/html` (browser navigation).
+	Accepted(accept string) []Codec
+
+	// ForContentType return the codec for `Content-Type` of request body, return nil if no codec supports it
+	ForContentType(contentType string) Codec
+}
+
+const (
+	IdGoneGinCodecNegotiator = "gone-gin-codec-negotiator"
+
+	MIMEJSON      = "application/json"
+	MIMEXML       = "application/xml"
+	MIMEXML2      = "text/xml"
+	MIMEYAML      = "application/yaml"
+	MIMEYAML2     = "application/x-yaml"
+	MIMEMsgPack   = "application/msgpack"
+	MIMEMsgPack2  = "application/x-msgpack"
+	MIMEProtobuf  = "application/x-protobuf"
+	MIMEProtobuf2 = "application/protobuf"
+)
+
+// builtin is implemented by the builtin codecs, which can be replaced by codecs loaded by user
+type builtin interface {
+	builtin()
+}
+
+type negotiator struct {
+	gone.Flag
+	codecs []Codec `gone:"*"`
+
+	byMediaType map[string]Codec
+	ordered     []Codec
+	defaultOne  Codec
+}
+
+func (n *negotiator) GonerName() string {
+	return IdGoneGinCodecNegotiator
+}
+
+func (n *negotiator) Init() {
+	n.byMediaType = make(map[string]Codec)
+	n.ordered = nil
+	for _, c := range n.codecs {
+		for _, t := range c.MediaTypes() {
+			t = strings.ToLower(t)
+			if exist, ok := n.byMediaType[t]; ok {
+				if _, isBuiltin := c.(builtin); isBuiltin {
+					continue
+				}
+				if _, isBuiltin := exist.(builtin); !isBuiltin {
+					continue
+				}
+			}
+			n.byMediaType[t] = c
+		}
+	}
+
+	// the codecs in the order of loading, each codec once
+	seen := make(map[Codec]bool)
+	for _, c := range n.codecs {
+		for _, t := range c.MediaTypes() {
+			if c2 := n.byMediaType[strings.ToLower(t)]; c2 == c && !seen[c] {
+				seen[c] = true
+				n.ordered = append(n.ordered, c)
+			}
+		}
+	}
+	n.defaultOne = n.byMediaType[MIMEJSON]
+}
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+	index     int
+}
+
+func (r acceptRange) specificity() int {
+	switch {
+	case r.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(r.mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// parseAccept parse `Accept` header into media ranges sorted by quality and specificity
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for i, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q, index: i})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return ranges[i].specificity() > ranges[j].specificity()
+	})
+	return ranges
+}
+
+func (n *negotiator) Accepted(accept string) []Codec {
+	if strings.TrimSpace(accept) == "" || strings.Contains(accept, "text/html") {
+		return n.defaults()
+	}
+
+	var list []Codec
+	seen := make(map[Codec]bool)
+	add := func(c Codec) {
+		if c != nil && !seen[c] {
+			seen[c] = true
+			list = append(list, c)
+		}
+	}
+
+	ranges := parseAccept(accept)
+	// media types explicitly refused with q=0
+	refused := make(map[string]bool)
+	for _, r := range ranges {
+		if r.q <= 0 {
+			refused[r.mediaType] = true
+		}
+	}
+	acceptable := func(c Codec) bool {
+		return !refused[strings.ToLower(c.MediaTypes()[0])]
+	}
+
+	for _, r := range ranges {
+		if r.q <= 0 {
+			continue
+		}
+		switch r.specificity() {
+		case 2:
+			if c := n.byMediaType[r.mediaType]; c != nil && acceptable(c) {
+				add(c)
+			}
+		case 1:
+			prefix := strings.TrimSuffix(r.mediaType, "*")
+			for _, c := range n.ordered {
+				for _, t := range c.MediaTypes() {
+					if strings.HasPrefix(strings.ToLower(t), prefix) && acceptable(c) {
+						add(c)
+						break
+					}
+				}
+			}
+		default:
+			for _, c := range n.defaults() {
+				if acceptable(c) {
+					add(c)
+				}
+			}
+		}
+	}
+	return list
+}
+
+// defaults return the default codec first, then the others
+func (n *negotiator) defaults() []Codec {
+	list := make([]Codec, 0, len(n.ordered))
+	if n.defaultOne != nil {
+		list = append(list, n.defaultOne)
+	}
+	for _, c := range n.ordered {
+		if c != n.defaultOne {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+func (n *negotiator) ForContentType(contentType string) Codec {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	return n.byMediaType[mediaType]
+}
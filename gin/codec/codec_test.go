@@ -0,0 +1,80 @@
+package codec
+
+import (
+	"io"
+	"testing"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func newNegotiator(codecs ...Codec) *negotiator {
+	n := &negotiator{codecs: append([]Codec{
+		&jsonCodec{}, &xmlCodec{}, &yamlCodec{}, &msgpackCodec{}, &protobufCodec{},
+	}, codecs...)}
+	n.Init()
+	return n
+}
+
+func mediaTypes(codecs []Codec) (list []string) {
+	for _, c := range codecs {
+		list = append(list, c.MediaTypes()[0])
+	}
+	return
+}
+
+func Test_negotiator_Accepted(t *testing.T) {
+	n := newNegotiator()
+	all := []string{MIMEJSON, MIMEXML, MIMEYAML, MIMEMsgPack, MIMEProtobuf}
+
+	tests := []struct {
+		accept string
+		want   []string
+	}{
+		{"", all},
+		{"*/*", all},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", all},
+		{"application/xml", []string{MIMEXML}},
+		{"text/xml", []string{MIMEXML}},
+		{"application/x-yaml, application/json;q=0.5", []string{MIMEYAML, MIMEJSON}},
+		{"application/json;q=0.5, application/msgpack", []string{MIMEMsgPack, MIMEJSON}},
+		{"application/*;q=0.9, application/x-protobuf", []string{MIMEProtobuf, MIMEJSON, MIMEXML, MIMEYAML, MIMEMsgPack}},
+		{"*/*, application/json;q=0", []string{MIMEXML, MIMEYAML, MIMEMsgPack, MIMEProtobuf}},
+		{"image/png", nil},
+		{"invalid;;", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			assert.Equal(t, tt.want, mediaTypes(n.Accepted(tt.accept)))
+		})
+	}
+}
+
+func Test_negotiator_ForContentType(t *testing.T) {
+	n := newNegotiator()
+	assert.IsType(t, &jsonCodec{}, n.ForContentType("application/json; charset=utf-8"))
+	assert.IsType(t, &yamlCodec{}, n.ForContentType("application/x-yaml"))
+	assert.Nil(t, n.ForContentType("multipart/form-data; boundary=x"))
+	assert.Nil(t, n.ForContentType(""))
+}
+
+type customJson struct {
+	gone.Flag
+}
+
+func (c *customJson) MediaTypes() []string { return []string{MIMEJSON, "application/vnd.api+json"} }
+func (c *customJson) Encodable(any) bool   { return true }
+func (c *customJson) Encode(w io.Writer, _ any) error {
+	_, err := w.Write([]byte("custom"))
+	return err
+}
+func (c *customJson) Decode(io.Reader, any) error { return nil }
+
+func Test_negotiator_customCodec(t *testing.T) {
+	custom := &customJson{}
+	n := newNegotiator(custom)
+	assert.Equal(t, custom, n.ForContentType(MIMEJSON))
+	assert.Equal(t, custom, n.ForContentType("application/vnd.api+json"))
+	assert.Equal(t, Codec(custom), n.Accepted("")[0])
+	assert.Len(t, n.Accepted(""), 5)
+}
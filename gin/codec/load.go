@@ -0,0 +1,15 @@
+package codec
+
+import "github.com/gone-io/gone/v2"
+
+// Load the builtin codecs (JSON, XML, YAML, MessagePack, Protobuf) and the Negotiator
+func Load(loader gone.Loader) error {
+	loader.
+		MustLoad(&jsonCodec{}).
+		MustLoad(&xmlCodec{}).
+		MustLoad(&yamlCodec{}).
+		MustLoad(&msgpackCodec{}).
+		MustLoad(&protobufCodec{}).
+		MustLoad(&negotiator{}, gone.IsDefault(new(Negotiator)))
+	return nil
+}
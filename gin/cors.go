@@ -0,0 +1,236 @@
+package gin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+)
+
+// CorsPolicy cross-origin resource sharing policy
+type CorsPolicy struct {
+	// Path route pattern of rule configured by `server.cors.rules`, see LimitRule.Path
+	Path string `mapstructure:"path" json:"path"`
+
+	// AllowOrigins origins allowed, `*` allows all, and `https://*.example.com` allows all subdomains
+	AllowOrigins []string `mapstructure:"allow-origins" json:"allow-origins"`
+
+	// AllowMethods methods allowed, default is GET, POST, PUT, PATCH, DELETE and HEAD
+	AllowMethods []string `mapstructure:"allow-methods" json:"allow-methods"`
+
+	// AllowHeaders request headers allowed, empty allows the headers requested by preflight
+	AllowHeaders []string `mapstructure:"allow-headers" json:"allow-headers"`
+
+	// ExposeHeaders response headers which can be read by browser script
+	ExposeHeaders []string `mapstructure:"expose-headers" json:"expose-headers"`
+
+	// AllowCredentials allow cookies; the request origin instead of `*` is responded when it is true
+	AllowCredentials bool `mapstructure:"allow-credentials" json:"allow-credentials"`
+
+	// MaxAge how long the result of preflight can be cached by browser, 0 means not set
+	MaxAge time.Duration `mapstructure:"max-age" json:"max-age"`
+}
+
+var defaultCorsMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodHead,
+}
+
+func (p *CorsPolicy) init() {
+	if len(p.AllowMethods) == 0 {
+		p.AllowMethods = defaultCorsMethods
+	}
+	methods := make([]string, 0, len(p.AllowMethods))
+	for _, m := range p.AllowMethods {
+		methods = append(methods, strings.ToUpper(m))
+	}
+	p.AllowMethods = methods
+}
+
+func (p *CorsPolicy) allowOrigin(origin string) bool {
+	for _, allowed := range p.AllowOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		if prefix, suffix, ok := strings.Cut(allowed, "*"); ok &&
+			len(origin) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(origin, prefix) &&
+			strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *CorsPolicy) allowAnyOrigin() bool {
+	for _, allowed := range p.AllowOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *CorsPolicy) allowMethod(method string) bool {
+	for _, m := range p.AllowMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *CorsPolicy) allowHeaders(requested string) (string, bool) {
+	if len(p.AllowHeaders) == 0 {
+		return requested, true
+	}
+	for _, header := range splitList(requested) {
+		allowed := false
+		for _, h := range p.AllowHeaders {
+			if h == "*" || strings.EqualFold(h, header) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return "", false
+		}
+	}
+	return strings.Join(p.AllowHeaders, ", "), true
+}
+
+type corsMiddleware struct {
+	gone.Flag
+
+	// enabled 是否对所有路由开启CORS，对应配置项为：`server.cors.enabled`；
+	// 未开启时，只有匹配`server.cors.rules`或通过`Override`设置了策略的路由会处理跨域请求
+	enabled bool `gone:"config,server.cors.enabled,default=false"`
+
+	// allowOrigins 允许的Origin，多个以逗号分隔，对应配置项为：`server.cors.allow-origins`
+	allowOrigins string `gone:"config,server.cors.allow-origins,default=*"`
+
+	// allowMethods 允许的请求方法，多个以逗号分隔，对应配置项为：`server.cors.allow-methods`；默认为GET、POST、PUT、PATCH、DELETE和HEAD
+	allowMethods string `gone:"config,server.cors.allow-methods"`
+
+	// allowHeaders 允许的请求头，多个以逗号分隔，对应配置项为：`server.cors.allow-headers`；默认为空，允许预检请求中的所有请求头
+	allowHeaders string `gone:"config,server.cors.allow-headers"`
+
+	// exposeHeaders 允许浏览器脚本读取的响应头，多个以逗号分隔，对应配置项为：`server.cors.expose-headers`
+	exposeHeaders string `gone:"config,server.cors.expose-headers"`
+
+	allowCredentials bool `gone:"config,server.cors.allow-credentials,default=false"`
+
+	// maxAge 预检请求结果的缓存时间，对应配置项为：`server.cors.max-age`
+	maxAge time.Duration `gone:"config,server.cors.max-age,default=10m"`
+
+	// rules 按路由覆盖的CORS策略，对应配置项为：`server.cors.rules`
+	rules []CorsPolicy `gone:"config,server.cors.rules"`
+
+	global    CorsPolicy
+	overrides routePolicies[*CorsPolicy]
+}
+
+func (c *corsMiddleware) GonerName() string {
+	return IdGoneGinCors
+}
+
+func (c *corsMiddleware) Init() error {
+	c.global = CorsPolicy{
+		AllowOrigins:     splitList(c.allowOrigins),
+		AllowMethods:     splitList(c.allowMethods),
+		AllowHeaders:     splitList(c.allowHeaders),
+		ExposeHeaders:    splitList(c.exposeHeaders),
+		AllowCredentials: c.allowCredentials,
+		MaxAge:           c.maxAge,
+	}
+	c.global.init()
+
+	for i := range c.rules {
+		rule := c.rules[i]
+		if rule.Path == "" {
+			return gone.NewInnerErrorWithParams(gone.ConfigError, "path of cors rule(%d) is empty", i)
+		}
+		c.add(rule.Path, rule)
+	}
+	return nil
+}
+
+func (c *corsMiddleware) add(pattern string, policy CorsPolicy) {
+	policy.init()
+	c.overrides.add(pattern, &policy)
+}
+
+func (c *corsMiddleware) Override(group RouteGroup, policy CorsPolicy) {
+	c.add(groupPattern(group), policy)
+}
+
+func (c *corsMiddleware) policyOf(ctx *gin.Context) *CorsPolicy {
+	if policy, ok := c.overrides.match(ctx); ok {
+		return policy
+	}
+	if c.enabled {
+		return &c.global
+	}
+	return nil
+}
+
+func (c *corsMiddleware) Process(ctx *gin.Context) {
+	policy := c.policyOf(ctx)
+	origin := ctx.GetHeader("Origin")
+	if policy == nil || origin == "" {
+		return
+	}
+
+	header := ctx.Writer.Header()
+	preflight := ctx.Request.Method == http.MethodOptions && ctx.GetHeader("Access-Control-Request-Method") != ""
+	header.Add("Vary", "Origin")
+	if preflight {
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+	}
+
+	if !policy.allowOrigin(origin) {
+		if preflight {
+			ctx.AbortWithStatus(http.StatusForbidden)
+		}
+		return
+	}
+
+	if policy.allowAnyOrigin() && !policy.AllowCredentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+	if policy.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if !preflight {
+		if len(policy.ExposeHeaders) > 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposeHeaders, ", "))
+		}
+		return
+	}
+
+	method := strings.ToUpper(ctx.GetHeader("Access-Control-Request-Method"))
+	headers, ok := policy.allowHeaders(ctx.GetHeader("Access-Control-Request-Headers"))
+	if !policy.allowMethod(method) || !ok {
+		ctx.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	header.Set("Access-Control-Allow-Methods", strings.Join(policy.AllowMethods, ", "))
+	if headers != "" {
+		header.Set("Access-Control-Allow-Headers", headers)
+	}
+	if policy.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+	}
+	ctx.AbortWithStatus(http.StatusNoContent)
+}
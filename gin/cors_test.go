@@ -0,0 +1,153 @@
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCorsEngine(c *corsMiddleware) (*gin.Engine, *router) {
+	engine := gin.New()
+	engine.Use(c.Process)
+	r := &router{Engine: engine, HandleProxyToGin: &proxy{}}
+	engine.GET("/api/users/:id", func(ctx *gin.Context) { ctx.String(http.StatusOK, "ok") })
+	engine.POST("/open/hook", func(ctx *gin.Context) { ctx.String(http.StatusOK, "ok") })
+	return engine, r
+}
+
+func corsRequest(engine *gin.Engine, method, path, origin string, headers map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func Test_corsMiddleware(t *testing.T) {
+	c := &corsMiddleware{
+		enabled:       true,
+		allowOrigins:  "https://a.com, https://*.b.com",
+		allowHeaders:  "Content-Type, Authorization",
+		exposeHeaders: "X-Total",
+		maxAge:        10 * time.Minute,
+	}
+	assert.Nil(t, c.Init())
+	engine, _ := newCorsEngine(c)
+
+	t.Run("simple request", func(t *testing.T) {
+		w := corsRequest(engine, http.MethodGet, "/api/users/1", "https://a.com", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://a.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "X-Total", w.Header().Get("Access-Control-Expose-Headers"))
+		assert.Equal(t, "Origin", w.Header().Get("Vary"))
+
+		w = corsRequest(engine, http.MethodGet, "/api/users/1", "https://x.b.com", nil)
+		assert.Equal(t, "https://x.b.com", w.Header().Get("Access-Control-Allow-Origin"))
+
+		w = corsRequest(engine, http.MethodGet, "/api/users/1", "https://c.com", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+		w = corsRequest(engine, http.MethodGet, "/api/users/1", "", nil)
+		assert.Empty(t, w.Header().Get("Vary"))
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		w := corsRequest(engine, http.MethodOptions, "/api/users/1", "https://a.com", map[string]string{
+			"Access-Control-Request-Method":  "PUT",
+			"Access-Control-Request-Headers": "content-type",
+		})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "GET, POST, PUT, PATCH, DELETE, HEAD", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+
+		w = corsRequest(engine, http.MethodOptions, "/api/users/1", "https://a.com", map[string]string{
+			"Access-Control-Request-Method":  "PUT",
+			"Access-Control-Request-Headers": "X-Unknown",
+		})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = corsRequest(engine, http.MethodOptions, "/api/users/1", "https://c.com", map[string]string{
+			"Access-Control-Request-Method": "GET",
+		})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func Test_corsMiddleware_Override(t *testing.T) {
+	c := &corsMiddleware{
+		allowOrigins: "*",
+		rules: []CorsPolicy{
+			{Path: "/api/users/:id", AllowOrigins: []string{"*"}, AllowMethods: []string{"get"}},
+		},
+	}
+	assert.Nil(t, c.Init())
+	engine, r := newCorsEngine(c)
+	c.Override(r.Group("/open"), CorsPolicy{AllowOrigins: []string{"https://partner.com"}, AllowCredentials: true})
+
+	// disabled globally
+	w := corsRequest(engine, http.MethodGet, "/other", "https://a.com", nil)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	// rule matched with route pattern, preflight included
+	w = corsRequest(engine, http.MethodGet, "/api/users/1", "https://a.com", nil)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	w = corsRequest(engine, http.MethodOptions, "/api/users/1", "https://a.com", map[string]string{
+		"Access-Control-Request-Method": "GET",
+	})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "GET", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Empty(t, w.Header().Get("Access-Control-Max-Age"))
+
+	// group override
+	w = corsRequest(engine, http.MethodOptions, "/open/hook", "https://partner.com", map[string]string{
+		"Access-Control-Request-Method": "POST",
+	})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://partner.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+
+	assert.Error(t, (&corsMiddleware{rules: []CorsPolicy{{}}}).Init())
+}
+
+type corsCtr struct {
+	gone.Flag
+	r    IRouter `gone:"*"`
+	cors Cors    `gone:"*"`
+}
+
+func (c *corsCtr) Mount() MountError {
+	open := c.r.Group("/open")
+	c.cors.Override(open, CorsPolicy{AllowOrigins: []string{"https://partner.com"}})
+	open.POST("/hook", func() string { return "ok" })
+	return nil
+}
+
+func Test_corsMiddleware_withLoad(t *testing.T) {
+	t.Setenv("GONE_SERVER_PORT", "0")
+
+	gone.
+		NewApp(Load).
+		Load(&corsCtr{}).
+		Run(func(s *server) {
+			req, _ := http.NewRequest(http.MethodOptions, "http://"+s.getAddress()+"/open/hook", nil)
+			req.Header.Set("Origin", "https://partner.com")
+			req.Header.Set("Access-Control-Request-Method", "POST")
+			res, err := http.DefaultClient.Do(req)
+			assert.Nil(t, err)
+			defer res.Body.Close()
+			assert.Equal(t, http.StatusNoContent, res.StatusCode)
+			assert.Equal(t, "https://partner.com", res.Header.Get("Access-Control-Allow-Origin"))
+		})
+}
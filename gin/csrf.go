@@ -0,0 +1,243 @@
+package gin
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+)
+
+const (
+	// CsrfModeCookie the token is issued in cookie, and the client sends it back in header or form field (double-submit cookie)
+	CsrfModeCookie = "cookie"
+
+	// CsrfModeHeader the token is issued in response header, and the client sends it back in header or form field;
+	// the token is signed with `server.csrf.secret` and bound to a random session id kept in a HttpOnly cookie,
+	// so no server side state is needed, and a token issued to one client is rejected for others.
+	CsrfModeHeader = "header"
+
+	InvalidCsrfToken = "Invalid CSRF Token"
+
+	csrfTokenKey = "gone-gin-csrf-token"
+)
+
+type csrfMiddleware struct {
+	gone.Flag
+	responser Responser `gone:"*"`
+
+	// enabled 是否开启CSRF校验，对应配置项为：`server.csrf.enabled`
+	enabled bool `gone:"config,server.csrf.enabled,default=false"`
+
+	// mode 令牌的下发方式，`cookie`或`header`，对应配置项为：`server.csrf.mode`
+	mode string `gone:"config,server.csrf.mode,default=cookie"`
+
+	// secret 令牌签名密钥，对应配置项为：`server.csrf.secret`；`header`模式下必须配置，`cookie`模式下配置后令牌会被签名
+	secret string `gone:"config,server.csrf.secret"`
+
+	// maxAge 令牌有效期，对应配置项为：`server.csrf.max-age`
+	maxAge time.Duration `gone:"config,server.csrf.max-age,default=12h"`
+
+	headerName string `gone:"config,server.csrf.header-name,default=X-CSRF-Token"`
+	formField  string `gone:"config,server.csrf.form-field,default=_csrf"`
+
+	// cookieName 保存令牌（`cookie`模式）或令牌所绑定会话ID（`header`模式）的Cookie名，对应配置项为：`server.csrf.cookie-name`
+	cookieName     string `gone:"config,server.csrf.cookie-name,default=csrf_token"`
+	cookiePath     string `gone:"config,server.csrf.cookie-path,default=/"`
+	cookieDomain   string `gone:"config,server.csrf.cookie-domain"`
+	cookieSecure   bool   `gone:"config,server.csrf.cookie-secure,default=false"`
+	cookieSameSite string `gone:"config,server.csrf.cookie-same-site,default=lax"`
+
+	// exemptPaths 不校验CSRF令牌的路由，多个以逗号分隔，规则同`LimitRule.Path`；对应配置项为：`server.csrf.exempt-paths`
+	exemptPaths string `gone:"config,server.csrf.exempt-paths"`
+
+	sameSite http.SameSite
+	exempts  routePolicies[bool]
+	now      func() time.Time
+}
+
+func (m *csrfMiddleware) GonerName() string {
+	return IdGoneGinCsrf
+}
+
+func (m *csrfMiddleware) Init() error {
+	switch m.mode {
+	case CsrfModeCookie:
+	case CsrfModeHeader:
+		if m.secret == "" {
+			return gone.NewInnerErrorWithParams(gone.ConfigError, "server.csrf.secret is required in csrf mode(%s)", m.mode)
+		}
+	default:
+		return gone.NewInnerErrorWithParams(gone.ConfigError, "unsupported csrf mode(%s)", m.mode)
+	}
+
+	switch strings.ToLower(m.cookieSameSite) {
+	case "strict":
+		m.sameSite = http.SameSiteStrictMode
+	case "none":
+		m.sameSite = http.SameSiteNoneMode
+	default:
+		m.sameSite = http.SameSiteLaxMode
+	}
+
+	for _, path := range splitList(m.exemptPaths) {
+		m.exempts.add(path, true)
+	}
+	if m.now == nil {
+		m.now = time.Now
+	}
+	return nil
+}
+
+func (m *csrfMiddleware) Exempt(group RouteGroup) {
+	m.exempts.add(groupPattern(group), true)
+}
+
+func (m *csrfMiddleware) Token(ctx *gin.Context) string {
+	return ctx.GetString(csrfTokenKey)
+}
+
+func (m *csrfMiddleware) Process(ctx *gin.Context) {
+	if !m.enabled {
+		return
+	}
+	if exempt, _ := m.exempts.match(ctx); exempt {
+		return
+	}
+
+	binding := m.binding(ctx, isSafeMethod(ctx.Request.Method))
+	issued := m.issued(ctx, binding)
+	if isSafeMethod(ctx.Request.Method) {
+		if issued == "" {
+			issued = m.issue(ctx, binding)
+		}
+		ctx.Set(csrfTokenKey, issued)
+		return
+	}
+
+	submitted := ctx.GetHeader(m.headerName)
+	if submitted == "" {
+		submitted = ctx.PostForm(m.formField)
+	}
+
+	var valid bool
+	if m.mode == CsrfModeCookie {
+		valid = issued != "" && subtle.ConstantTimeCompare([]byte(issued), []byte(submitted)) == 1
+	} else {
+		valid = binding != "" && m.verify(submitted, binding)
+	}
+	if !valid {
+		m.responser.Failed(ctx, gone.NewError(http.StatusForbidden, InvalidCsrfToken, http.StatusForbidden))
+		ctx.Abort()
+		return
+	}
+	ctx.Set(csrfTokenKey, submitted)
+}
+
+// binding return the session id which the tokens of header mode are bound to, it is kept in a HttpOnly cookie which
+// scripts of other sites can neither read nor set; a new one is issued for safe requests without it.
+func (m *csrfMiddleware) binding(ctx *gin.Context, issue bool) string {
+	if m.mode != CsrfModeHeader {
+		return ""
+	}
+	if id, _ := ctx.Cookie(m.cookieName); id != "" {
+		return id
+	}
+	if !issue {
+		return ""
+	}
+	id := randomToken()
+	m.setCookie(ctx, id, true)
+	return id
+}
+
+// issued return the valid token issued before: the token in cookie for cookie mode, or the token in request header for header mode
+func (m *csrfMiddleware) issued(ctx *gin.Context, binding string) string {
+	var token string
+	if m.mode == CsrfModeCookie {
+		token, _ = ctx.Cookie(m.cookieName)
+	} else {
+		token = ctx.GetHeader(m.headerName)
+	}
+	if token == "" || m.secret != "" && !m.verify(token, binding) {
+		return ""
+	}
+	return token
+}
+
+func (m *csrfMiddleware) issue(ctx *gin.Context, binding string) string {
+	token := m.generate(binding)
+	if m.mode == CsrfModeCookie {
+		m.setCookie(ctx, token, false)
+	} else {
+		ctx.Header(m.headerName, token)
+	}
+	return token
+}
+
+func (m *csrfMiddleware) setCookie(ctx *gin.Context, value string, httpOnly bool) {
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     m.cookieName,
+		Value:    value,
+		Path:     m.cookiePath,
+		Domain:   m.cookieDomain,
+		MaxAge:   int(m.maxAge.Seconds()),
+		Secure:   m.cookieSecure,
+		HttpOnly: httpOnly,
+		SameSite: m.sameSite,
+	})
+}
+
+func randomToken() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// generate token: `<random>` without secret, or `<random>.<expire unix>.<hmac>` with secret;
+// the hmac covers the binding, so the token is only valid for the session it is issued to.
+func (m *csrfMiddleware) generate(binding string) string {
+	token := randomToken()
+	if m.secret == "" {
+		return token
+	}
+	payload := token + "." + strconv.FormatInt(m.now().Add(m.maxAge).Unix(), 10)
+	return payload + "." + m.sign(payload, binding)
+}
+
+func (m *csrfMiddleware) verify(token, binding string) bool {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return false
+	}
+	payload, signature := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(m.sign(payload, binding))) {
+		return false
+	}
+	_, expire, _ := strings.Cut(payload, ".")
+	unix, err := strconv.ParseInt(expire, 10, 64)
+	return err == nil && m.now().Unix() < unix
+}
+
+func (m *csrfMiddleware) sign(payload, binding string) string {
+	mac := hmac.New(sha256.New, []byte(m.secret))
+	mac.Write([]byte(payload))
+	mac.Write([]byte{0})
+	mac.Write([]byte(binding))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
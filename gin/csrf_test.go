@@ -0,0 +1,159 @@
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCsrfEngine(m *csrfMiddleware) (*gin.Engine, *router) {
+	engine := gin.New()
+	engine.Use(m.Process)
+	r := &router{Engine: engine, HandleProxyToGin: &proxy{}}
+	handler := func(ctx *gin.Context) { ctx.String(http.StatusOK, m.Token(ctx)) }
+	engine.GET("/form", handler)
+	engine.POST("/submit", handler)
+	engine.POST("/hooks/pay", handler)
+	return engine, r
+}
+
+func newCsrfMiddleware(mode, secret string) *csrfMiddleware {
+	return &csrfMiddleware{
+		responser:  &responser{wrappedDataFunc: wrapFunc, returnWrappedData: true},
+		enabled:    true,
+		mode:       mode,
+		secret:     secret,
+		maxAge:     time.Hour,
+		headerName: "X-CSRF-Token",
+		formField:  "_csrf",
+		cookieName: "csrf_token",
+		cookiePath: "/",
+	}
+}
+
+func Test_csrfMiddleware_cookie(t *testing.T) {
+	m := newCsrfMiddleware(CsrfModeCookie, "")
+	m.exemptPaths = "/hooks/*"
+	assert.Nil(t, m.Init())
+	engine, _ := newCsrfEngine(m)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/form", nil))
+	cookies := w.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	token := cookies[0].Value
+	assert.Equal(t, token, w.Body.String())
+	assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	assert.False(t, cookies[0].HttpOnly)
+
+	// token is not issued again
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/form", nil)
+	req.AddCookie(cookies[0])
+	engine.ServeHTTP(w, req)
+	assert.Empty(t, w.Result().Cookies())
+	assert.Equal(t, token, w.Body.String())
+
+	post := func(header, form string, withCookie bool) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(url.Values{"_csrf": {form}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if header != "" {
+			req.Header.Set("X-CSRF-Token", header)
+		}
+		if withCookie {
+			req.AddCookie(cookies[0])
+		}
+		engine.ServeHTTP(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusOK, post(token, "", true))
+	assert.Equal(t, http.StatusOK, post("", token, true))
+	assert.Equal(t, http.StatusForbidden, post("", "", true))
+	assert.Equal(t, http.StatusForbidden, post("other", "", true))
+	assert.Equal(t, http.StatusForbidden, post(token, "", false))
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/hooks/pay", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func Test_csrfMiddleware_header(t *testing.T) {
+	m := newCsrfMiddleware(CsrfModeHeader, "secret")
+	assert.Nil(t, m.Init())
+	engine, r := newCsrfEngine(m)
+	m.Exempt(r.Group("/hooks"))
+
+	issue := func() (string, *http.Cookie) {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/form", nil))
+		token := w.Header().Get("X-CSRF-Token")
+		assert.NotEmpty(t, token)
+		cookies := w.Result().Cookies()
+		assert.Len(t, cookies, 1)
+		assert.True(t, cookies[0].HttpOnly)
+		return token, cookies[0]
+	}
+	token, session := issue()
+	otherToken, otherSession := issue()
+
+	// the token is not issued again for the same session
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/form", nil)
+	req.Header.Set("X-CSRF-Token", token)
+	req.AddCookie(session)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, token, w.Body.String())
+	assert.Empty(t, w.Header().Get("X-CSRF-Token"))
+	assert.Empty(t, w.Result().Cookies())
+
+	post := func(token string, session *http.Cookie) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/submit", nil)
+		req.Header.Set("X-CSRF-Token", token)
+		if session != nil {
+			req.AddCookie(session)
+		}
+		engine.ServeHTTP(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusOK, post(token, session))
+	assert.Equal(t, http.StatusOK, post(otherToken, otherSession))
+	assert.Equal(t, http.StatusForbidden, post(token+"x", session))
+	assert.Equal(t, http.StatusForbidden, post("", session))
+
+	// the token is bound to the session it is issued to
+	assert.Equal(t, http.StatusForbidden, post(token, nil))
+	assert.Equal(t, http.StatusForbidden, post(otherToken, session))
+	assert.Equal(t, http.StatusForbidden, post(token, otherSession))
+
+	m.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	assert.Equal(t, http.StatusForbidden, post(token, session))
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/hooks/pay", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func Test_csrfMiddleware_Init(t *testing.T) {
+	assert.Error(t, (&csrfMiddleware{mode: CsrfModeHeader}).Init())
+	assert.Error(t, (&csrfMiddleware{mode: "x"}).Init())
+
+	m := &csrfMiddleware{mode: CsrfModeCookie, cookieSameSite: "Strict"}
+	assert.Nil(t, m.Init())
+	assert.Equal(t, http.SameSiteStrictMode, m.sameSite)
+
+	// disabled
+	engine := gin.New()
+	engine.Use(m.Process)
+	engine.POST("/submit", func(ctx *gin.Context) {})
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/submit", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
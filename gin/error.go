@@ -2,6 +2,7 @@ package gin
 
 import (
 	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin/parser"
 )
 
 // NewInnerError 新建`内部错误`
@@ -15,3 +16,12 @@ var NewBusinessError = gone.NewBusinessError
 
 // ToError 转为错误
 var ToError = gone.ToError
+
+// ValidationError `参数错误`，包含结构化的字段校验错误
+type ValidationError = parser.ValidationError
+
+// FieldError 字段校验错误
+type FieldError = parser.FieldError
+
+// NewValidationError 新建`参数校验错误`
+var NewValidationError = parser.NewValidationError
@@ -0,0 +1,218 @@
+package gintest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gone-io/gone/v2"
+)
+
+// BaseURL the url of the in-memory server, the host is only used by the cookie jar.
+const BaseURL = "http://gintest.local"
+
+// Client sends requests to the gin server of the application in memory, the requests go through the same middlewares,
+// proxy, parsers and responser as in production. Headers set by SetHeader are sent with every request, and cookies
+// set by responses are kept in the jar of client.
+type Client struct {
+	gone.Flag
+	testFlag  gone.TestFlag `gone:"*" option:"allowNil"`
+	transport *transport    `gone:"*"`
+
+	client *http.Client
+	lock   sync.RWMutex
+	header http.Header
+}
+
+func (c *Client) Init() error {
+	if c.testFlag == nil {
+		return gone.NewInnerError("gintest.Client works in test mode only, run the application by `Test`", gone.NotSupport)
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return gone.ToError(err)
+	}
+	c.client = &http.Client{
+		Transport: &http.Transport{DialContext: c.transport.DialContext},
+		Jar:       jar,
+	}
+	c.header = make(http.Header)
+	return nil
+}
+
+// SetHeader set a header sent with every request.
+func (c *Client) SetHeader(key, value string) *Client {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.header.Set(key, value)
+	return c
+}
+
+// Cookies return the cookies in the jar.
+func (c *Client) Cookies() []*http.Cookie {
+	return c.client.Jar.Cookies(baseURL)
+}
+
+// SetCookie put cookies into the jar.
+func (c *Client) SetCookie(cookies ...*http.Cookie) *Client {
+	c.client.Jar.SetCookies(baseURL, cookies)
+	return c
+}
+
+// Do send the request to the in-memory server as is.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	return c.client.Do(req)
+}
+
+func (c *Client) Get(path string) *Request {
+	return c.Request(http.MethodGet, path)
+}
+
+func (c *Client) Post(path string) *Request {
+	return c.Request(http.MethodPost, path)
+}
+
+func (c *Client) Put(path string) *Request {
+	return c.Request(http.MethodPut, path)
+}
+
+func (c *Client) Patch(path string) *Request {
+	return c.Request(http.MethodPatch, path)
+}
+
+func (c *Client) Delete(path string) *Request {
+	return c.Request(http.MethodDelete, path)
+}
+
+func (c *Client) Head(path string) *Request {
+	return c.Request(http.MethodHead, path)
+}
+
+func (c *Client) Options(path string) *Request {
+	return c.Request(http.MethodOptions, path)
+}
+
+// Request create a request of method to path, which is sent by Do or Stream.
+func (c *Client) Request(method, path string) *Request {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return &Request{
+		client: c,
+		method: method,
+		path:   path,
+		header: c.header.Clone(),
+		query:  make(url.Values),
+	}
+}
+
+var baseURL, _ = url.Parse(BaseURL)
+
+// Request a request built fluently, eg: `client.Post("/users").WithJSON(user).Do(t).ExpectStatus(http.StatusOK)`
+type Request struct {
+	client *Client
+	method string
+	path   string
+	header http.Header
+	query  url.Values
+	body   io.Reader
+	err    error
+}
+
+func (r *Request) WithHeader(key, value string) *Request {
+	r.header.Add(key, value)
+	return r
+}
+
+func (r *Request) WithQuery(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// WithCookie send the cookie with the request only, use Client.SetCookie to put it into the jar.
+func (r *Request) WithCookie(cookie *http.Cookie) *Request {
+	r.header.Add("Cookie", cookie.String())
+	return r
+}
+
+// WithJSON send v encoded as JSON.
+func (r *Request) WithJSON(v any) *Request {
+	data, err := json.Marshal(v)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	return r.WithBody("application/json", bytes.NewReader(data))
+}
+
+// WithForm send values as url encoded form.
+func (r *Request) WithForm(values url.Values) *Request {
+	return r.WithBody("application/x-www-form-urlencoded", strings.NewReader(values.Encode()))
+}
+
+func (r *Request) WithBody(contentType string, body io.Reader) *Request {
+	r.header.Set("Content-Type", contentType)
+	r.body = body
+	return r
+}
+
+func (r *Request) build() (*http.Request, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	target := BaseURL + r.path
+	if len(r.query) > 0 {
+		if strings.Contains(target, "?") {
+			target += "&" + r.query.Encode()
+		} else {
+			target += "?" + r.query.Encode()
+		}
+	}
+	req, err := http.NewRequest(r.method, target, r.body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = r.header
+	return req, nil
+}
+
+// Do send the request and read the whole response, t fails at once if the request can not be sent.
+func (r *Request) Do(t testing.TB) *Response {
+	t.Helper()
+	req, err := r.build()
+	if err != nil {
+		t.Fatalf("build request %s %s failed: %v", r.method, r.path, err)
+	}
+	res, err := r.client.Do(req)
+	if err != nil {
+		t.Fatalf("request %s %s failed: %v", r.method, r.path, err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read response of %s %s failed: %v", r.method, r.path, err)
+	}
+	return &Response{t: t, res: res, body: body}
+}
+
+// Stream send the request and return the response as a stream of Server-Sent Events, which should be closed after reading.
+func (r *Request) Stream(t testing.TB) *EventStream {
+	t.Helper()
+	if r.header.Get("Accept") == "" {
+		r.header.Set("Accept", "text/event-stream")
+	}
+	req, err := r.build()
+	if err != nil {
+		t.Fatalf("build request %s %s failed: %v", r.method, r.path, err)
+	}
+	res, err := r.client.Do(req)
+	if err != nil {
+		t.Fatalf("request %s %s failed: %v", r.method, r.path, err)
+	}
+	return newEventStream(t, res)
+}
@@ -0,0 +1,96 @@
+package gintest
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	Id   int    `json:"id"`
+	Name string `json:"name" binding:"required"`
+}
+
+type userCtr struct {
+	gone.Flag
+	r gin.IRouter `gone:"*"`
+}
+
+func (c *userCtr) Mount() gin.MountError {
+	c.r.GET("/users/:id", func(in struct {
+		id    int    `gone:"http,param"`
+		token string `gone:"http,header=X-Token"`
+	}) (*user, error) {
+		if in.token != "secret" {
+			return nil, gone.NewError(http.StatusUnauthorized, "unauthorized", http.StatusUnauthorized)
+		}
+		return &user{Id: in.id, Name: "gone"}, nil
+	})
+	c.r.POST("/users", func(in gin.RequestBody[user]) user {
+		u := in.Get()
+		u.Id = 2
+		return u
+	})
+	c.r.POST("/login", func(ctx *gin.Context, in struct {
+		name string `gone:"http,form"`
+	}) string {
+		ctx.SetCookie("session", in.name, 3600, "/", "", false, true)
+		return "hello " + in.name
+	})
+	c.r.GET("/me", func(in struct {
+		session string `gone:"http,cookie"`
+		lang    string `gone:"http,query"`
+	}) string {
+		return in.session + in.lang
+	})
+	return nil
+}
+
+func TestClient(t *testing.T) {
+	gone.
+		NewApp(Load).
+		Load(&userCtr{}).
+		Test(func(c *Client) {
+			c.Get("/users/1").Do(t).ExpectStatus(http.StatusUnauthorized)
+
+			c.SetHeader("X-Token", "secret")
+			var u user
+			c.Get("/users/1").Do(t).
+				ExpectStatus(http.StatusOK).
+				ExpectHeader("Content-Type", "application/json; charset=utf-8").
+				ExpectCode(0).
+				ExpectData(user{Id: 1, Name: "gone"}).
+				ExpectJSON(`{"code":0,"data":{"id":1,"name":"gone"}}`).
+				DecodeData(&u)
+			assert.Equal(t, "gone", u.Name)
+
+			c.Post("/users").WithJSON(user{Name: "goner"}).Do(t).
+				ExpectStatus(http.StatusOK).
+				ExpectData(map[string]any{"id": 2, "name": "goner"})
+			c.Post("/users").WithJSON(user{}).Do(t).ExpectStatus(http.StatusBadRequest)
+
+			c.Get("/me").Do(t).ExpectStatus(http.StatusBadRequest)
+			res := c.Post("/login").WithForm(url.Values{"name": {"dapeng"}}).Do(t).
+				ExpectStatus(http.StatusOK).
+				ExpectBodyContains("hello dapeng")
+			assert.Equal(t, "dapeng", res.Cookies()[0].Value)
+			assert.Equal(t, "session", c.Cookies()[0].Name)
+			c.Get("/me").WithQuery("lang", "-en").Do(t).ExpectData("dapeng-en")
+
+			c.Get("/me").WithCookie(&http.Cookie{Name: "session", Value: "other"}).Do(t).ExpectData("other")
+			c.SetCookie(&http.Cookie{Name: "session", Value: "another"})
+			c.Get("/me").Do(t).ExpectData("another")
+
+			c.Get("/not-found").Do(t).ExpectStatus(http.StatusNotFound)
+		})
+}
+
+func TestClient_notTestMode(t *testing.T) {
+	assert.Panics(t, func() {
+		gone.NewApp(Load).Run(func(c *Client) {})
+	})
+}
@@ -0,0 +1,19 @@
+package gintest
+
+import (
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin"
+)
+
+// Load loads goner/gin with the in-memory transport and the Client, the application should be run by `Test`, eg:
+//
+//	gone.NewApp(gintest.Load).Load(&userCtr{}).Test(func(c *gintest.Client) {
+//		c.Get("/users/1").Do(t).ExpectStatus(http.StatusOK).ExpectData(user)
+//	})
+func Load(loader gone.Loader) error {
+	loader.
+		MustLoad(&transport{}, gone.IsDefault(new(gin.TestTransport))).
+		MustLoad(&Client{}).
+		MustLoadX(gin.Load)
+	return nil
+}
@@ -0,0 +1,122 @@
+package gintest
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Response the response read by Request.Do, the Expect methods report failures to t and return the response for chaining.
+type Response struct {
+	t    testing.TB
+	res  *http.Response
+	body []byte
+}
+
+func (r *Response) StatusCode() int {
+	return r.res.StatusCode
+}
+
+func (r *Response) Header() http.Header {
+	return r.res.Header
+}
+
+func (r *Response) Cookies() []*http.Cookie {
+	return r.res.Cookies()
+}
+
+func (r *Response) Body() []byte {
+	return r.body
+}
+
+func (r *Response) String() string {
+	return string(r.body)
+}
+
+func (r *Response) ExpectStatus(code int) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, code, r.res.StatusCode, "status code, body: %s", r.body)
+	return r
+}
+
+func (r *Response) ExpectHeader(key, value string) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, value, r.res.Header.Get(key), "header %s", key)
+	return r
+}
+
+func (r *Response) ExpectBodyContains(s string) *Response {
+	r.t.Helper()
+	assert.Contains(r.t, string(r.body), s)
+	return r
+}
+
+// ExpectJSON assert the body equals expected as JSON; expected is a JSON string, []byte, or a value encoded as JSON.
+func (r *Response) ExpectJSON(expected any) *Response {
+	r.t.Helper()
+	assert.JSONEq(r.t, toJSON(r.t, expected), string(r.body))
+	return r
+}
+
+// ExpectData assert the `data` field of the body wrapped by Responser equals expected encoded as JSON, use
+// json.RawMessage for a JSON text.
+func (r *Response) ExpectData(expected any) *Response {
+	r.t.Helper()
+	data, err := json.Marshal(expected)
+	assert.NoError(r.t, err)
+	assert.JSONEq(r.t, string(data), string(r.data()))
+	return r
+}
+
+// ExpectCode assert the `code` field of the body wrapped by Responser.
+func (r *Response) ExpectCode(code int) *Response {
+	r.t.Helper()
+	var wrapped struct {
+		Code int `json:"code"`
+	}
+	if assert.NoError(r.t, json.Unmarshal(r.body, &wrapped), "decode body: %s", r.body) {
+		assert.Equal(r.t, code, wrapped.Code, "code, body: %s", r.body)
+	}
+	return r
+}
+
+// DecodeJSON decode the body into v.
+func (r *Response) DecodeJSON(v any) *Response {
+	r.t.Helper()
+	assert.NoError(r.t, json.Unmarshal(r.body, v), "decode body: %s", r.body)
+	return r
+}
+
+// DecodeData decode the `data` field of the body wrapped by Responser into v.
+func (r *Response) DecodeData(v any) *Response {
+	r.t.Helper()
+	assert.NoError(r.t, json.Unmarshal(r.data(), v), "decode data of body: %s", r.body)
+	return r
+}
+
+func (r *Response) data() json.RawMessage {
+	r.t.Helper()
+	var wrapped struct {
+		Data json.RawMessage `json:"data"`
+	}
+	assert.NoError(r.t, json.Unmarshal(r.body, &wrapped), "decode body: %s", r.body)
+	if wrapped.Data == nil {
+		return json.RawMessage("null")
+	}
+	return wrapped.Data
+}
+
+func toJSON(t testing.TB, v any) string {
+	t.Helper()
+	switch s := v.(type) {
+	case string:
+		return s
+	case []byte:
+		return string(s)
+	}
+	data, err := json.Marshal(v)
+	assert.NoError(t, err)
+	return string(data)
+}
@@ -0,0 +1,116 @@
+package gintest
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Event an event read from a stream of Server-Sent Events.
+type Event struct {
+	Id    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+// EventStream reads the events of a Server-Sent Events response one by one, as a browser does.
+type EventStream struct {
+	t      testing.TB
+	res    *http.Response
+	reader *bufio.Reader
+
+	// lastEventId the id of the last event, which is kept by events without id
+	lastEventId string
+}
+
+func newEventStream(t testing.TB, res *http.Response) *EventStream {
+	return &EventStream{t: t, res: res, reader: bufio.NewReader(res.Body)}
+}
+
+func (s *EventStream) StatusCode() int {
+	return s.res.StatusCode
+}
+
+func (s *EventStream) Header() http.Header {
+	return s.res.Header
+}
+
+// LastEventId return the id of the last event read, which should be sent in header `Last-Event-ID` when reconnecting.
+func (s *EventStream) LastEventId() string {
+	return s.lastEventId
+}
+
+// Next read the next event, comments and blocks without data are skipped; io.EOF is returned at the end of stream.
+func (s *EventStream) Next() (*Event, error) {
+	var event Event
+	var data []string
+	hasData := false
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line != "" {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if !hasData {
+				event = Event{}
+				continue
+			}
+			event.Id = s.lastEventId
+			event.Data = strings.Join(data, "\n")
+			return &event, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			s.lastEventId = value
+		case "event":
+			event.Event = value
+		case "data":
+			hasData = true
+			data = append(data, value)
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil {
+				event.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// Events read events until the end of stream or the `done` event sent by SSE.End, which is not included.
+func (s *EventStream) Events() []Event {
+	s.t.Helper()
+	var events []Event
+	for {
+		event, err := s.Next()
+		if err == io.EOF {
+			return events
+		}
+		if err != nil {
+			s.t.Errorf("read event stream failed: %v", err)
+			return events
+		}
+		if event.Event == "done" {
+			return events
+		}
+		events = append(events, *event)
+	}
+}
+
+func (s *EventStream) Close() error {
+	return s.res.Body.Close()
+}
@@ -0,0 +1,69 @@
+package gintest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type streamCtr struct {
+	gone.Flag
+	r gin.IRouter `gone:"*"`
+}
+
+func (c *streamCtr) Mount() gin.MountError {
+	c.r.GET("/events", func(ctx *gin.Context) <-chan any {
+		ch := make(chan any)
+		go func() {
+			defer close(ch)
+			ch <- gin.SseEvent{Id: "1", Event: "greeting", Data: "hello\nworld", Retry: time.Second}
+			ch <- map[string]int{"n": 2}
+			ch <- gin.SseEvent{Id: ctx.GetHeader(gin.LastEventIdHeader)}
+		}()
+		return ch
+	})
+	return nil
+}
+
+func TestEventStream(t *testing.T) {
+	gone.
+		NewApp(Load).
+		Load(&streamCtr{}).
+		Test(func(c *Client) {
+			stream := c.Get("/events").Stream(t)
+			defer stream.Close()
+			assert.Equal(t, http.StatusOK, stream.StatusCode())
+			assert.Contains(t, stream.Header().Get("Content-Type"), "text/event-stream")
+
+			event, err := stream.Next()
+			assert.Nil(t, err)
+			assert.Equal(t, Event{Id: "1", Event: "greeting", Data: "hello\nworld", Retry: time.Second}, *event)
+
+			events := stream.Events()
+			assert.Equal(t, []Event{{Id: "1", Event: "data", Data: `{"n":2}`}}, events)
+
+			resumed := c.Get("/events").WithHeader(gin.LastEventIdHeader, "7").Stream(t)
+			defer resumed.Close()
+			resumed.Events()
+			assert.Equal(t, "7", resumed.LastEventId())
+		})
+}
+
+func TestEventStream_Next(t *testing.T) {
+	stream := newEventStream(t, &http.Response{Body: io.NopCloser(strings.NewReader(
+		": comment\n\nid: 1\n\ndata: a\r\ndata\r\n\r\nretry: x\ndata:b",
+	))})
+
+	event, err := stream.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, Event{Id: "1", Data: "a\n"}, *event)
+
+	_, err = stream.Next()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
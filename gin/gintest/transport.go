@@ -0,0 +1,78 @@
+package gintest
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin"
+)
+
+var _ gin.TestTransport = (*transport)(nil)
+
+// transport serves the gin server through in-memory connections instead of a TCP listener.
+type transport struct {
+	gone.Flag
+	listener *listener
+}
+
+func (t *transport) Init() {
+	t.listener = newListener()
+}
+
+func (t *transport) Listener() net.Listener {
+	return t.listener
+}
+
+func (t *transport) DialContext(ctx context.Context, _, _ string) (net.Conn, error) {
+	return t.listener.dial(ctx)
+}
+
+var memoryAddr = &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}
+
+// listener in-memory net.Listener, connections are created by dial with net.Pipe.
+type listener struct {
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newListener() *listener {
+	return &listener{
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *listener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.done)
+	})
+	return nil
+}
+
+func (l *listener) Addr() net.Addr {
+	return memoryAddr
+}
+
+func (l *listener) dial(ctx context.Context) (net.Conn, error) {
+	client, server := net.Pipe()
+	select {
+	case l.conns <- server:
+		return client, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,43 @@
+package gintest
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_transport(t *testing.T) {
+	tr := &transport{}
+	tr.Init()
+	l := tr.Listener()
+	assert.Equal(t, "127.0.0.1:0", l.Addr().String())
+
+	go func() {
+		conn, err := l.Accept()
+		if assert.Nil(t, err) {
+			_, _ = conn.Write([]byte("hi"))
+			_ = conn.Close()
+		}
+	}()
+	conn, err := tr.DialContext(context.Background(), "tcp", "gintest.local:80")
+	assert.Nil(t, err)
+	buf := make([]byte, 2)
+	_, err = conn.Read(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "hi", string(buf))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = tr.DialContext(ctx, "tcp", "")
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	assert.Nil(t, l.Close())
+	assert.Nil(t, l.Close())
+	_, err = l.Accept()
+	assert.Equal(t, net.ErrClosed, err)
+	_, err = tr.DialContext(context.Background(), "tcp", "")
+	assert.Equal(t, net.ErrClosed, err)
+}
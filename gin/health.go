@@ -0,0 +1,150 @@
+package gin
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+)
+
+const (
+	HealthStatusUp       = "UP"
+	HealthStatusDown     = "DOWN"
+	HealthStatusDraining = "DRAINING"
+)
+
+// HealthCheckResult the result of a HealthChecker
+type HealthCheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HealthReport the result of liveness or readiness probe
+type HealthReport struct {
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+// IsUp return true if the status is UP
+func (r HealthReport) IsUp() bool {
+	return r.Status == HealthStatusUp
+}
+
+type healthProbe struct {
+	gone.Flag
+	logger   gone.Logger       `gone:"*"`
+	checkers []g.HealthChecker `gone:"*"`
+
+	// livenessPath 存活探针路径，对应配置项为：`server.health.liveness-path`，默认为空，不开启
+	livenessPath string `gone:"config,server.health.liveness-path"`
+
+	// readinessPath 就绪探针路径，对应配置项为：`server.health.readiness-path`，默认为空，不开启
+	readinessPath string `gone:"config,server.health.readiness-path"`
+
+	// checkTimeout 单个依赖检查的超时时间，对应配置项为：`server.health.check-timeout`
+	checkTimeout time.Duration `gone:"config,server.health.check-timeout,default=3s"`
+
+	draining atomic.Bool
+}
+
+func (p *healthProbe) GonerName() string {
+	return IdGoneGinHealthProbe
+}
+
+func (p *healthProbe) Liveness() HealthReport {
+	return HealthReport{Status: HealthStatusUp}
+}
+
+func (p *healthProbe) Readiness(ctx context.Context) HealthReport {
+	report := HealthReport{Status: HealthStatusUp}
+	if p.draining.Load() {
+		report.Status = HealthStatusDraining
+	}
+	if len(p.checkers) == 0 {
+		return report
+	}
+
+	results := make([]HealthCheckResult, len(p.checkers))
+	var wg sync.WaitGroup
+	for i, checker := range p.checkers {
+		wg.Add(1)
+		go func(i int, checker g.HealthChecker) {
+			defer wg.Done()
+			results[i] = p.check(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	report.Checks = make(map[string]HealthCheckResult, len(p.checkers))
+	for i, checker := range p.checkers {
+		report.Checks[checker.HealthCheckName()] = results[i]
+		if results[i].Status != HealthStatusUp && report.Status == HealthStatusUp {
+			report.Status = HealthStatusDown
+		}
+	}
+	return report
+}
+
+// check run the checker with timeout, the checker is treated as failed if it does not return in time
+func (p *healthProbe) check(ctx context.Context, checker g.HealthChecker) HealthCheckResult {
+	if p.checkTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.checkTimeout)
+		defer cancel()
+	}
+
+	ch := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				ch <- gone.NewInnerErrorWithParams(gone.PanicError, "health check panic: %v", r)
+			}
+		}()
+		ch <- checker.CheckHealth(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-ch:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warnf("health check of %s failed: %v", checker.HealthCheckName(), err)
+		}
+		return HealthCheckResult{Status: HealthStatusDown, Error: err.Error()}
+	}
+	return HealthCheckResult{Status: HealthStatusUp}
+}
+
+func (p *healthProbe) SetDraining(draining bool) {
+	p.draining.Store(draining)
+}
+
+// serve respond to the request if its path is the liveness or readiness path, return true if responded
+func (p *healthProbe) serve(ctx *gin.Context) bool {
+	var report HealthReport
+	switch ctx.Request.URL.Path {
+	case "":
+		return false
+	case p.livenessPath:
+		report = p.Liveness()
+	case p.readinessPath:
+		report = p.Readiness(ctx.Request.Context())
+	default:
+		return false
+	}
+
+	status := http.StatusOK
+	if !report.IsUp() {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.AbortWithStatusJSON(status, report)
+	return true
+}
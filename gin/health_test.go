@@ -0,0 +1,121 @@
+package gin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	gMock "github.com/gone-io/goner/g/mock"
+	"github.com/gone-io/goner/gin/internal/json"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type funcChecker struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (c *funcChecker) HealthCheckName() string {
+	return c.name
+}
+
+func (c *funcChecker) CheckHealth(ctx context.Context) error {
+	return c.fn(ctx)
+}
+
+func Test_healthProbe_Readiness(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	logger := gone.NewMockLogger(controller)
+	logger.EXPECT().Warnf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	t.Run("no checker", func(t *testing.T) {
+		p := &healthProbe{}
+		assert.True(t, p.Readiness(context.Background()).IsUp())
+		assert.True(t, p.Liveness().IsUp())
+
+		p.SetDraining(true)
+		assert.Equal(t, HealthStatusDraining, p.Readiness(context.Background()).Status)
+		assert.True(t, p.Liveness().IsUp())
+	})
+
+	t.Run("checkers", func(t *testing.T) {
+		db := gMock.NewMockHealthChecker(controller)
+		db.EXPECT().HealthCheckName().Return("db").AnyTimes()
+		db.EXPECT().CheckHealth(gomock.Any()).Return(nil).Times(2)
+
+		p := &healthProbe{logger: logger, checkTimeout: 50 * time.Millisecond}
+		p.checkers = append(p.checkers, db)
+		report := p.Readiness(context.Background())
+		assert.True(t, report.IsUp())
+		assert.Equal(t, HealthCheckResult{Status: HealthStatusUp}, report.Checks["db"])
+
+		p.checkers = append(p.checkers,
+			&funcChecker{name: "redis", fn: func(ctx context.Context) error { return errors.New("refused") }},
+			&funcChecker{name: "mq", fn: func(ctx context.Context) error { time.Sleep(time.Second); return nil }},
+			&funcChecker{name: "panic", fn: func(ctx context.Context) error { panic("x") }},
+		)
+		report = p.Readiness(context.Background())
+		assert.Equal(t, HealthStatusDown, report.Status)
+		assert.Equal(t, HealthStatusUp, report.Checks["db"].Status)
+		assert.Equal(t, HealthCheckResult{Status: HealthStatusDown, Error: "refused"}, report.Checks["redis"])
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["mq"].Error)
+		assert.Equal(t, HealthStatusDown, report.Checks["panic"].Status)
+	})
+}
+
+func Test_SysMiddleware_healthProbe(t *testing.T) {
+	var err error
+	probe := &healthProbe{
+		livenessPath:  "/livez",
+		readinessPath: "/readyz",
+		checkers: []HealthChecker{&funcChecker{name: "db", fn: func(ctx context.Context) error {
+			return err
+		}}},
+	}
+	m := &SysMiddleware{healthProbe: probe}
+	assert.Nil(t, m.Init())
+
+	engine := gin.New()
+	engine.Use(m.Process)
+	engine.GET("/api", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "ok")
+	})
+
+	request := func(path string) (int, HealthReport) {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var report HealthReport
+		_ = json.Unmarshal(w.Body.Bytes(), &report)
+		return w.Code, report
+	}
+
+	code, report := request("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthStatusUp, report.Checks["db"].Status)
+
+	err = errors.New("db down")
+	code, report = request("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "db down", report.Checks["db"].Error)
+
+	code, report = request("/livez")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthStatusUp, report.Status)
+
+	err = nil
+	probe.SetDraining(true)
+	code, report = request("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthStatusDraining, report.Status)
+
+	code, _ = request("/api")
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -14,3 +14,6 @@ func LoadGinHttpInjector(loader gone.Loader) error {
 		MustLoadX(parser.Load)
 	return nil
 }
+
+// FileStream a file of multipart form read as stream, which is injected by `gone:"http,file=<name>"`
+type FileStream = parser.FileStream
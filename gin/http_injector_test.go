@@ -0,0 +1,83 @@
+package gin
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type uploadForm struct {
+	Title string `form:"title" binding:"required"`
+}
+
+type uploadCtr struct {
+	gone.Flag
+	r IRouter `gone:"*"`
+}
+
+func (c *uploadCtr) Mount() MountError {
+	c.r.POST("/upload", func(form Form[uploadForm], in struct {
+		photo *multipart.FileHeader `gone:"http,file,max-size=16,types=image/*" binding:"required"`
+	}) string {
+		return form.Get().Title + ":" + in.photo.Filename
+	})
+	c.r.POST("/stream", func(in struct {
+		video *FileStream `gone:"http,file=video"`
+	}) (string, error) {
+		all, err := io.ReadAll(in.video)
+		return in.video.Filename + ":" + string(all), err
+	})
+	return nil
+}
+
+func Test_multipartInjection(t *testing.T) {
+	t.Setenv("GONE_SERVER_PORT", "0")
+
+	post := func(s *server, path, title, field, filename string, content []byte) (int, string) {
+		body := &bytes.Buffer{}
+		w := multipart.NewWriter(body)
+		if title != "" {
+			_ = w.WriteField("title", title)
+		}
+		f, _ := w.CreateFormFile(field, filename)
+		_, _ = f.Write(content)
+		_ = w.Close()
+
+		res, err := http.Post("http://"+s.getAddress()+path, w.FormDataContentType(), body)
+		assert.Nil(t, err)
+		defer res.Body.Close()
+		all, _ := io.ReadAll(res.Body)
+		return res.StatusCode, string(all)
+	}
+	gif := []byte("GIF89a")
+
+	gone.
+		NewApp(Load).
+		Load(&uploadCtr{}).
+		Run(func(s *server) {
+			code, body := post(s, "/upload", "cat", "photo", "cat.gif", gif)
+			assert.Equal(t, http.StatusOK, code)
+			assert.Contains(t, body, "cat:cat.gif")
+
+			code, _ = post(s, "/upload", "", "photo", "cat.gif", gif)
+			assert.Equal(t, http.StatusBadRequest, code)
+
+			code, _ = post(s, "/upload", "cat", "other", "cat.gif", gif)
+			assert.Equal(t, http.StatusBadRequest, code)
+
+			code, _ = post(s, "/upload", "cat", "photo", "cat.txt", []byte("text"))
+			assert.Equal(t, http.StatusUnsupportedMediaType, code)
+
+			code, _ = post(s, "/upload", "cat", "photo", "cat.gif", append(gif, make([]byte, 16)...))
+			assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+
+			code, body = post(s, "/stream", "", "video", "a.mp4", []byte("frames"))
+			assert.Equal(t, http.StatusOK, code)
+			assert.Contains(t, body, "a.mp4:frames")
+		})
+}
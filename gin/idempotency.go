@@ -0,0 +1,259 @@
+package gin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"github.com/gone-io/goner/gin/auth"
+)
+
+const (
+	idempotencyKeyPrefix = "gone-gin-idempotency#"
+	idempotencyReplayed  = "Idempotent-Replayed"
+	maxIdempotencyKeyLen = 255
+)
+
+// idempotencyMiddleware makes retries of unsafe requests carrying the same `Idempotency-Key` executed only once:
+// the first request locks the key and its response is stored, retries get the stored response replayed,
+// and requests arriving while the first one is in flight are rejected with 409. The response is stored with
+// the fingerprint of request body, so reusing a key with a different body is rejected with 422.
+type idempotencyMiddleware struct {
+	gone.Flag
+	logger    gone.Logger          `gone:"*"`
+	responser Responser            `gone:"*"`
+	locker    g.TryLocker          `gone:"*" option:"allowNil"`
+	store     g.ResponseCacheStore `gone:"*" option:"allowNil"`
+
+	// enabled 是否开启幂等键检查，对应配置项为：`server.idempotency.enabled`
+	enabled bool `gone:"config,server.idempotency.enabled,default=false"`
+
+	// header 携带幂等键的请求头，对应配置项为：`server.idempotency.header`
+	header string `gone:"config,server.idempotency.header,default=Idempotency-Key"`
+
+	// routes 需要幂等键的路由，多个以逗号分隔，以`*`结尾时按前缀匹配，对应配置项为：`server.idempotency.routes`
+	routes string `gone:"config,server.idempotency.routes"`
+
+	// methods 需要幂等键的请求方法，多个以逗号分隔，对应配置项为：`server.idempotency.methods`
+	methods string `gone:"config,server.idempotency.methods,default=POST"`
+
+	// required 请求未携带幂等键时是否返回400，对应配置项为：`server.idempotency.required`
+	required bool `gone:"config,server.idempotency.required,default=false"`
+
+	// ttl 响应保存的时长，在此期间重试的请求将重放保存的响应，对应配置项为：`server.idempotency.ttl`
+	ttl time.Duration `gone:"config,server.idempotency.ttl,default=24h"`
+
+	// lockTimeout 请求处理期间锁定幂等键的最长时间，对应配置项为：`server.idempotency.lock-timeout`
+	lockTimeout time.Duration `gone:"config,server.idempotency.lock-timeout,default=1m"`
+
+	// maxBodySize 可保存的最大响应体，超出时不保存，对应配置项为：`server.idempotency.max-body-size`
+	maxBodySize int `gone:"config,server.idempotency.max-body-size,default=1048576"`
+
+	// maxRequestBodySize 计算请求体指纹时可读取的最大请求体，超出时返回413，对应配置项为：`server.idempotency.max-request-body-size`
+	maxRequestBodySize int64 `gone:"config,server.idempotency.max-request-body-size,default=10485760"`
+
+	// memoryMaxEntries 未加载`g.ResponseCacheStore`时，内存中保存响应的最大条目数，对应配置项为：`server.idempotency.memory.max-entries`
+	memoryMaxEntries int `gone:"config,server.idempotency.memory.max-entries,default=10000"`
+
+	methodList []string
+	protected  routePolicies[bool]
+	now        func() time.Time
+}
+
+func (m *idempotencyMiddleware) GonerName() string {
+	return IdGoneGinIdempotency
+}
+
+func (m *idempotencyMiddleware) Init() {
+	m.methodList = splitList(strings.ToUpper(m.methods))
+	for _, path := range splitList(m.routes) {
+		m.protected.add(path, true)
+	}
+	if m.locker == nil {
+		m.locker = &memoryLocker{locks: make(map[string]time.Time)}
+	}
+	if m.store == nil {
+		m.store = newMemoryCacheStore(m.memoryMaxEntries)
+	}
+	if m.now == nil {
+		m.now = time.Now
+	}
+}
+
+func (m *idempotencyMiddleware) Protect(group RouteGroup) {
+	m.protected.add(groupPattern(group), true)
+}
+
+func (m *idempotencyMiddleware) Process(ctx *gin.Context) {
+	if !m.enabled || !slices.Contains(m.methodList, ctx.Request.Method) {
+		return
+	}
+	if _, ok := m.protected.match(ctx); !ok {
+		return
+	}
+
+	idempotencyKey := ctx.GetHeader(m.header)
+	if idempotencyKey == "" {
+		if m.required {
+			m.fail(ctx, http.StatusBadRequest, m.header+" is required")
+		}
+		return
+	}
+	if len(idempotencyKey) > maxIdempotencyKeyLen {
+		m.fail(ctx, http.StatusBadRequest, m.header+" is too long")
+		return
+	}
+
+	fingerprint, err := m.fingerprint(ctx)
+	if err != nil {
+		m.fail(ctx, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+
+	key := m.key(ctx, idempotencyKey)
+	if m.replay(ctx, key, fingerprint) {
+		return
+	}
+
+	unlock, err := m.locker.TryLock(key+"#lock", m.lockTimeout)
+	if err != nil {
+		m.fail(ctx, http.StatusConflict, "a request with the same "+m.header+" is in progress")
+		return
+	}
+	defer unlock()
+
+	// the first request may finish between the replay check and locking
+	if m.replay(ctx, key, fingerprint) {
+		return
+	}
+	m.capture(ctx, key, fingerprint)
+}
+
+var errRequestBodyTooLarge = errors.New("request body is too large")
+
+// fingerprint hash the content type and body of request, the body is restored so handlers can read it again
+func (m *idempotencyMiddleware) fingerprint(ctx *gin.Context) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(ctx.ContentType() + "\n"))
+	if ctx.Request.Body != nil && ctx.Request.Body != http.NoBody {
+		body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, m.maxRequestBodySize+1))
+		if err != nil {
+			return "", err
+		}
+		if int64(len(body)) > m.maxRequestBodySize {
+			return "", errRequestBodyTooLarge
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+		hash.Write(body)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// key of the stored response, idempotency keys are scoped by the authenticated subject, method and route
+func (m *idempotencyMiddleware) key(ctx *gin.Context, idempotencyKey string) string {
+	var subject string
+	if p, ok := auth.FromContext(ctx); ok {
+		subject = p.Subject
+	}
+	sum := sha256.Sum256([]byte(subject + "\n" + ctx.Request.Method + " " + ctx.Request.URL.Path + "\n" + idempotencyKey))
+	return idempotencyKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+// replay the stored response of key, a request whose fingerprint differs from the stored one is rejected with 422
+// (responses stored without fingerprint are replayed to any request); it returns true if the request is handled.
+func (m *idempotencyMiddleware) replay(ctx *gin.Context, key, fingerprint string) bool {
+	response, err := m.store.Get(ctx, key)
+	if err != nil {
+		m.logger.Warnf("get stored response of idempotency key failed: %v", err)
+		return false
+	}
+	if response == nil {
+		return false
+	}
+	if response.Fingerprint != "" && response.Fingerprint != fingerprint {
+		m.fail(ctx, http.StatusUnprocessableEntity, m.header+" has been used with a different request")
+		return true
+	}
+
+	header := ctx.Writer.Header()
+	for k, v := range response.Header {
+		header[k] = slices.Clone(v)
+	}
+	header.Set(idempotencyReplayed, "true")
+	ctx.Status(response.Status)
+	if _, err = ctx.Writer.Write(response.Body); err != nil {
+		m.logger.Warnf("write stored response failed: %v", err)
+	}
+	ctx.Abort()
+	return true
+}
+
+// capture the response of request and store it; server errors and throttled responses are not stored,
+// so the request can be retried with the same key.
+func (m *idempotencyMiddleware) capture(ctx *gin.Context, key, fingerprint string) {
+	before := ctx.Writer.Header().Clone()
+	w := &cacheWriter{ResponseWriter: ctx.Writer, limit: m.maxBodySize}
+	ctx.Writer = w
+	ctx.Next()
+	captured := !w.streaming
+	w.flush()
+	ctx.Writer = w.ResponseWriter
+
+	status := w.Status()
+	if !captured || status >= http.StatusInternalServerError || status == http.StatusTooManyRequests || status == http.StatusConflict {
+		return
+	}
+	response := &g.CachedResponse{
+		Status:      status,
+		Header:      changedHeader(before, w.Header()),
+		Body:        w.body.Bytes(),
+		StoredAt:    m.now(),
+		TTL:         m.ttl,
+		Fingerprint: fingerprint,
+	}
+	if err := m.store.Set(ctx, key, response, m.ttl); err != nil {
+		m.logger.Warnf("store response of idempotency key failed: %v", err)
+	}
+}
+
+func (m *idempotencyMiddleware) fail(ctx *gin.Context, statusCode int, msg string) {
+	m.responser.Failed(ctx, gone.NewError(statusCode, msg, statusCode))
+	ctx.Abort()
+}
+
+var errKeyLocked = errors.New("key is locked")
+
+// memoryLocker in memory implementation of g.TryLocker, which is used when no g.TryLocker is loaded.
+type memoryLocker struct {
+	lock  sync.Mutex
+	locks map[string]time.Time
+}
+
+func (l *memoryLocker) TryLock(key string, ttl time.Duration) (func(), error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	if expireAt, ok := l.locks[key]; ok && now.Before(expireAt) {
+		return nil, errKeyLocked
+	}
+	expireAt := now.Add(ttl)
+	l.locks[key] = expireAt
+	return func() {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		if l.locks[key] == expireAt {
+			delete(l.locks, key)
+		}
+	}, nil
+}
@@ -0,0 +1,261 @@
+package gin
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	gMock "github.com/gone-io/goner/g/mock"
+	"github.com/gone-io/goner/gin/auth"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newIdempotencyMiddleware(t *testing.T) *idempotencyMiddleware {
+	controller := gomock.NewController(t)
+	logger := gone.NewMockLogger(controller)
+	logger.EXPECT().Warnf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	m := &idempotencyMiddleware{
+		logger:             logger,
+		responser:          &responser{wrappedDataFunc: wrapFunc, returnWrappedData: true},
+		enabled:            true,
+		header:             "Idempotency-Key",
+		routes:             "/orders,/payments/*",
+		methods:            "post,put",
+		ttl:                time.Hour,
+		lockTimeout:        time.Minute,
+		maxBodySize:        1024,
+		maxRequestBodySize: 1024,
+		memoryMaxEntries:   100,
+	}
+	m.Init()
+	return m
+}
+
+func idempotentRequest(engine http.Handler, method, target, key string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader("{}"))
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func Test_idempotencyMiddleware(t *testing.T) {
+	m := newIdempotencyMiddleware(t)
+	var calls int32
+	engine := gin.New()
+	engine.Use(func(ctx *gin.Context) { ctx.Header("X-Request-Id", "rid") }, m.Process)
+	handler := func(ctx *gin.Context) {
+		n := atomic.AddInt32(&calls, 1)
+		ctx.Header("Location", "/orders/1")
+		if ctx.Query("fail") != "" {
+			ctx.String(http.StatusInternalServerError, "failed")
+			return
+		}
+		ctx.String(http.StatusCreated, "created %d", n)
+	}
+	engine.POST("/orders", handler)
+	engine.POST("/payments/:id", handler)
+	engine.POST("/others", handler)
+	engine.PUT("/orders", handler)
+
+	w := idempotentRequest(engine, http.MethodPost, "/orders", "k1")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "created 1", w.Body.String())
+	assert.Empty(t, w.Header().Get(idempotencyReplayed))
+
+	w = idempotentRequest(engine, http.MethodPost, "/orders", "k1")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "created 1", w.Body.String())
+	assert.Equal(t, "/orders/1", w.Header().Get("Location"))
+	assert.Equal(t, []string{"rid"}, w.Header().Values("X-Request-Id"))
+	assert.Equal(t, "true", w.Header().Get(idempotencyReplayed))
+
+	// keys are scoped by method and route
+	assert.Equal(t, "created 2", idempotentRequest(engine, http.MethodPut, "/orders", "k1").Body.String())
+	assert.Equal(t, "created 3", idempotentRequest(engine, http.MethodPost, "/payments/1", "k1").Body.String())
+	assert.Equal(t, "created 4", idempotentRequest(engine, http.MethodPost, "/orders", "k2").Body.String())
+
+	// not protected, or without key
+	assert.Equal(t, "created 5", idempotentRequest(engine, http.MethodPost, "/others", "k1").Body.String())
+	assert.Equal(t, "created 6", idempotentRequest(engine, http.MethodPost, "/others", "k1").Body.String())
+	assert.Equal(t, "created 7", idempotentRequest(engine, http.MethodPost, "/orders", "").Body.String())
+
+	// server errors are not stored
+	assert.Equal(t, http.StatusInternalServerError, idempotentRequest(engine, http.MethodPost, "/orders?fail=1", "k3").Code)
+	assert.Equal(t, http.StatusCreated, idempotentRequest(engine, http.MethodPost, "/orders", "k3").Code)
+	assert.Equal(t, int32(9), atomic.LoadInt32(&calls))
+
+	assert.Equal(t, http.StatusBadRequest, idempotentRequest(engine, http.MethodPost, "/orders", strings.Repeat("k", 256)).Code)
+	m.required = true
+	assert.Equal(t, http.StatusBadRequest, idempotentRequest(engine, http.MethodPost, "/orders", "").Code)
+}
+
+func Test_idempotencyMiddleware_fingerprint(t *testing.T) {
+	m := newIdempotencyMiddleware(t)
+	engine := gin.New()
+	engine.Use(m.Process)
+	engine.POST("/orders", func(ctx *gin.Context) {
+		body, _ := io.ReadAll(ctx.Request.Body)
+		ctx.String(http.StatusCreated, "created %s", body)
+	})
+	request := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		req.Header.Set("Idempotency-Key", "k1")
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(`{"amount":1}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `created {"amount":1}`, w.Body.String())
+
+	w = request(`{"amount":1}`)
+	assert.Equal(t, `created {"amount":1}`, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get(idempotencyReplayed))
+
+	// reusing the key with a different body is rejected instead of replaying the response of other request
+	w = request(`{"amount":2}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Empty(t, w.Header().Get(idempotencyReplayed))
+
+	w = request(strings.Repeat("x", 1025))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func Test_idempotencyMiddleware_inFlight(t *testing.T) {
+	m := newIdempotencyMiddleware(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	engine := gin.New()
+	engine.Use(m.Process)
+	engine.POST("/orders", func(ctx *gin.Context) {
+		close(started)
+		<-release
+		ctx.String(http.StatusOK, "done")
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- idempotentRequest(engine, http.MethodPost, "/orders", "k")
+	}()
+	<-started
+
+	w := idempotentRequest(engine, http.MethodPost, "/orders", "k")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	close(release)
+	assert.Equal(t, "done", (<-done).Body.String())
+
+	w = idempotentRequest(engine, http.MethodPost, "/orders", "k")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get(idempotencyReplayed))
+}
+
+func Test_idempotencyMiddleware_principal(t *testing.T) {
+	m := newIdempotencyMiddleware(t)
+	var calls int32
+	engine := gin.New()
+	engine.Use(func(ctx *gin.Context) {
+		auth.SetPrincipal(ctx, &auth.Principal{Subject: ctx.GetHeader("X-User")})
+	}, m.Process)
+	engine.POST("/orders", func(ctx *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		ctx.Status(http.StatusNoContent)
+	})
+
+	for _, user := range []string{"a", "b", "a"} {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		req.Header.Set("X-User", user)
+		req.Header.Set("Idempotency-Key", "k")
+		engine.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func Test_idempotencyMiddleware_store(t *testing.T) {
+	controller := gomock.NewController(t)
+	m := newIdempotencyMiddleware(t)
+	locker := gMock.NewMockTryLocker(controller)
+	store := gMock.NewMockResponseCacheStore(controller)
+	m.locker, m.store = locker, store
+
+	engine := gin.New()
+	engine.Use(m.Process)
+	engine.POST("/orders", func(ctx *gin.Context) { ctx.String(http.StatusOK, "ok") })
+
+	var unlocked bool
+	store.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, errors.New("err")).Times(2)
+	locker.EXPECT().TryLock(gomock.Any(), time.Minute).Return(func() { unlocked = true }, nil)
+	store.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), time.Hour).Return(errors.New("err"))
+	assert.Equal(t, http.StatusOK, idempotentRequest(engine, http.MethodPost, "/orders", "k").Code)
+	assert.True(t, unlocked)
+
+	store.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, nil)
+	locker.EXPECT().TryLock(gomock.Any(), gomock.Any()).Return(nil, errors.New("locked"))
+	assert.Equal(t, http.StatusConflict, idempotentRequest(engine, http.MethodPost, "/orders", "k").Code)
+}
+
+func Test_memoryLocker(t *testing.T) {
+	l := &memoryLocker{locks: make(map[string]time.Time)}
+	unlock, err := l.TryLock("k", time.Minute)
+	assert.Nil(t, err)
+	_, err = l.TryLock("k", time.Minute)
+	assert.Equal(t, errKeyLocked, err)
+	unlock()
+
+	_, err = l.TryLock("k", time.Nanosecond)
+	assert.Nil(t, err)
+	time.Sleep(time.Millisecond)
+	unlock, err = l.TryLock("k", time.Minute)
+	assert.Nil(t, err)
+	unlock()
+	assert.Empty(t, l.locks)
+}
+
+type orderCtr struct {
+	gone.Flag
+	r           IRouter     `gone:"*"`
+	idempotency Idempotency `gone:"*"`
+	calls       int32
+}
+
+func (c *orderCtr) Mount() MountError {
+	orders := c.r.Group("/orders")
+	c.idempotency.Protect(orders)
+	orders.POST("", func() (int32, error) {
+		return atomic.AddInt32(&c.calls, 1), nil
+	})
+	return nil
+}
+
+func Test_idempotencyMiddleware_withLoad(t *testing.T) {
+	t.Setenv("GONE_SERVER_PORT", "0")
+	t.Setenv("GONE_SERVER_IDEMPOTENCY_ENABLED", "true")
+
+	ctr := &orderCtr{}
+	gone.
+		NewApp(Load).
+		Load(ctr).
+		Run(func(s *server) {
+			for i := 0; i < 2; i++ {
+				req, _ := http.NewRequest(http.MethodPost, "http://"+s.getAddress()+"/orders", nil)
+				req.Header.Set("Idempotency-Key", "order-1")
+				res, err := http.DefaultClient.Do(req)
+				assert.Nil(t, err)
+				_ = res.Body.Close()
+				assert.Equal(t, http.StatusOK, res.StatusCode)
+			}
+			assert.Equal(t, int32(1), atomic.LoadInt32(&ctr.calls))
+		})
+}
@@ -1,6 +1,10 @@
 package gin
 
 import (
+	"context"
+	"net"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/gone-io/gone/v2"
 	"github.com/gone-io/goner/g"
@@ -31,6 +35,19 @@ type RouteGroup interface {
 // RouterGroupName Router group name
 type RouterGroupName string
 
+// RouteInfo describes a route mounted through IRouter, Handlers contains the handlers of the route group and the route itself.
+type RouteInfo struct {
+	Method   string
+	Path     string
+	Handlers []HandlerFunc
+}
+
+// RouteLister lists the routes mounted through IRouter, it is used for generating api documents.
+// Inject default RouteLister using Id: gone-gin-router (`gin.IdGoneGinRouter`)
+type RouteLister interface {
+	Routes() []RouteInfo
+}
+
 type OriginContent = gin.Context
 
 type MountError = g.MountError
@@ -56,6 +73,11 @@ type WrappedDataFuncSetter interface {
 	SetWrappedDataFunc(wrappedDataFunc WrappedDataFunc)
 }
 
+// WrappedDataFuncGetter return the WrappedDataFunc used by Responser, return nil if the response data is not wrapped.
+type WrappedDataFuncGetter interface {
+	GetWrappedDataFunc() WrappedDataFunc
+}
+
 // Responser Response handler
 // Inject default response handler using Id: gone-gin-responser (`gone.IdGoneGinResponser`)
 type Responser interface {
@@ -69,19 +91,121 @@ type Responser interface {
 // allowing the same interface to have the ability to return different business codes and business data in special cases
 type BusinessError = gone.BusinessError
 
+type HealthChecker = g.HealthChecker
+
+// HealthProbe liveness and readiness of the http server, the probes are served by SysMiddleware.
+// Inject default HealthProbe using Id: gone-gin-health-probe (`gin.IdGoneGinHealthProbe`)
+type HealthProbe interface {
+	// Liveness report whether the process is alive, dependencies are not checked.
+	Liveness() HealthReport
+
+	// Readiness check all HealthChecker loaded; it fails when any of them fails or the server is draining.
+	Readiness(ctx context.Context) HealthReport
+
+	// SetDraining mark the server as draining, it is called by the server before stopping.
+	SetDraining(draining bool)
+}
+
 type Middleware interface {
 	Process(ctx *gin.Context)
 }
 
+// Cors CORS middleware, which is enabled for all routes by `server.cors.enabled`.
+// Inject default Cors using Id: gone-gin-cors (`gin.IdGoneGinCors`)
+type Cors interface {
+	Middleware
+
+	// Override use the policy for the routes of group instead of the global one, preflight requests included.
+	Override(group RouteGroup, policy CorsPolicy)
+}
+
+// SecurityHeaders middleware adding security response headers, which is enabled for all routes by `server.security-headers.enabled`.
+// Inject default SecurityHeaders using Id: gone-gin-security-headers (`gin.IdGoneGinSecurityHeaders`)
+type SecurityHeaders interface {
+	Middleware
+
+	// Override use the policy for the routes of group instead of the global one.
+	Override(group RouteGroup, policy SecurityHeadersPolicy)
+}
+
+// Csrf CSRF middleware, which is enabled by `server.csrf.enabled`; requests with unsafe methods are rejected with 403
+// if the token issued is not sent back in header or form field.
+// Inject default Csrf using Id: gone-gin-csrf (`gin.IdGoneGinCsrf`)
+type Csrf interface {
+	Middleware
+
+	// Token return the token of current request, which can be rendered into the page.
+	Token(ctx *gin.Context) string
+
+	// Exempt skip checking token for the routes of group, eg: webhooks called by other services.
+	Exempt(group RouteGroup)
+}
+
+// ResponseCache caches the responses of GET routes, which are stored in g.ResponseCacheStore (in memory LRU if not loaded);
+// load `redis.LoadResponseCacheStore` to share cached responses between instances.
+// Inject default ResponseCache using Id: gone-gin-response-cache (`gin.IdGoneGinResponseCache`)
+type ResponseCache interface {
+	// Cache return a handler caching responses of the route for ttl, which is mounted before the handlers of route,
+	// eg: `router.GET("/items/:id", cache.Cache(time.Minute, gin.CacheTags("item:{id}")), ctr.getItem)`
+	Cache(ttl time.Duration, options ...CacheOption) gin.HandlerFunc
+
+	// Invalidate remove the cached responses tagged with any of tags.
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+// Idempotency Idempotency-Key middleware, which is enabled by `server.idempotency.enabled` for the routes in
+// `server.idempotency.routes` or protected by Protect; the response of the first request is stored and replayed for
+// retries with the same key, and 409 is returned while the first request is in flight.
+// The key is locked with g.TryLocker and responses are stored in g.ResponseCacheStore, both are in memory if not loaded;
+// load `redis.Load` and `redis.LoadResponseCacheStore` to share them between instances.
+// Inject default Idempotency using Id: gone-gin-idempotency (`gin.IdGoneGinIdempotency`)
+type Idempotency interface {
+	Middleware
+
+	// Protect require idempotency keys for the routes of group.
+	Protect(group RouteGroup)
+}
+
+// Versioning API versioning of route groups: routes of a version are mounted under `/<version>` of the group, and the
+// requests without version in path are routed by the version in header `server.versioning.header` (`X-API-Version` by
+// default) or in the media type of `Accept`, such as `application/json; version=2` and `application/vnd.example.v2+json`,
+// otherwise by the version with DefaultVersion option.
+// Responses of the deprecated versions carry `Deprecation`, `Sunset` and `Link` headers, and the requests are logged
+// and counted by the metric `http.server.deprecated.requests`.
+// Inject default Versioning using Id: gone-gin-versioning (`gin.IdGoneGinVersioning`)
+type Versioning interface {
+	// Version return the route group of version under group, eg: `versioning.Version(api, "v1", gin.Deprecated(at))`;
+	// calling it again for the same version returns the same group, and the options are applied to the version.
+	Version(group RouteGroup, version string, options ...VersionOption) RouteGroup
+
+	// Versions return a route group mounting routes on all the versions; mounting the same route on the group of
+	// one version afterward overrides the handlers for that version.
+	Versions(group RouteGroup, versions ...string) RouteGroup
+}
+
+// TestTransport replaces the TCP listener of the server with an in-memory one when the application runs in test mode
+// (`gone.TestFlag` is loaded), it is provided by package `gintest`.
+type TestTransport interface {
+	Listener() net.Listener
+}
+
 const (
 	// IdGoneGin , IdGoneGinRouter , IdGoneGinProcessor, IdGoneGinProxy, IdGoneGinResponser, IdHttpInjector;
 	// The GonerIds of Goners in goner/gin, which integrates gin framework for web request.
-	IdGoneGin              = "gone-gin"
-	IdGoneGinRouter        = "gone-gin-router"
-	IdGoneGinSysMiddleware = "gone-gin-sys-middleware"
-	IdGoneGinProxy         = "gone-gin-proxy"
-	IdGoneGinResponser     = "gone-gin-responser"
-	IdHttpInjector         = "http"
+	IdGoneGin                = "gone-gin"
+	IdGoneGinRouter          = "gone-gin-router"
+	IdGoneGinSysMiddleware   = "gone-gin-sys-middleware"
+	IdGoneGinProxy           = "gone-gin-proxy"
+	IdGoneGinResponser       = "gone-gin-responser"
+	IdGoneGinHealthProbe     = "gone-gin-health-probe"
+	IdGoneGinWebSocket       = "gone-gin-websocket"
+	IdGoneGinCors            = "gone-gin-cors"
+	IdGoneGinSecurityHeaders = "gone-gin-security-headers"
+	IdGoneGinCsrf            = "gone-gin-csrf"
+	IdGoneGinResponseCache   = "gone-gin-response-cache"
+	IdGoneGinIdempotency     = "gone-gin-idempotency"
+	IdGoneGinVersioning      = "gone-gin-versioning"
+	IdHttpInjector           = "http"
 )
 
 type RequestBody[T any] struct {
@@ -99,3 +223,11 @@ type Query[T any] struct {
 func (q *Query[T]) Get() T {
 	return q.v
 }
+
+type Form[T any] struct {
+	v T `gone:"http,form=*"`
+}
+
+func (f *Form[T]) Get() T {
+	return f.v
+}
@@ -0,0 +1,48 @@
+package gin
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/goner/g"
+)
+
+// LimitRule rate limit rule, configured by `server.req.limit-rules`; KeyBy of g.LimitRule can be:
+// `ip` groups by client ip; `header:<name>` groups by the value of header;
+// other values are treated as the name of LimitKeyExtractor
+type LimitRule struct {
+	g.LimitRule `mapstructure:",squash"`
+
+	// Path route pattern matched with `gin.Context.FullPath()`, eg: `/api/users/:id`;
+	// pattern ends with `*` matches request path by prefix; empty matches all requests
+	Path string `mapstructure:"path" json:"path"`
+
+	// Method http method, empty matches all methods
+	Method string `mapstructure:"method" json:"method"`
+}
+
+// LimitKeyExtractor extract the key of rate limit from request, it is referenced by `key-by` of LimitRule with its name.
+// Requests are not limited by the rule if the key extracted is empty.
+type LimitKeyExtractor interface {
+	Name() string
+	Extract(ctx *gin.Context) string
+}
+
+const (
+	limitKeyByIp     = "ip"
+	limitKeyByHeader = "header:"
+)
+
+func (r *LimitRule) match(ctx *gin.Context) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, ctx.Request.Method) {
+		return false
+	}
+	switch {
+	case r.Path == "":
+		return true
+	case strings.HasSuffix(r.Path, "*"):
+		return strings.HasPrefix(ctx.Request.URL.Path, strings.TrimSuffix(r.Path, "*"))
+	default:
+		return r.Path == ctx.FullPath()
+	}
+}
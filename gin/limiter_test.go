@@ -0,0 +1,143 @@
+package gin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	gMock "github.com/gone-io/goner/g/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type apiKeyExtractor struct {
+	gone.Flag
+}
+
+func (e *apiKeyExtractor) Name() string {
+	return "api-key"
+}
+
+func (e *apiKeyExtractor) Extract(ctx *gin.Context) string {
+	return ctx.Query("api-key")
+}
+
+func Test_SysMiddleware_limitRules(t *testing.T) {
+	newEngine := func(m *SysMiddleware) *gin.Engine {
+		assert.Nil(t, m.Init())
+		engine := gin.New()
+		engine.Use(m.Process)
+		engine.GET("/api/users/:id", func(ctx *gin.Context) {
+			ctx.String(http.StatusOK, "ok")
+		})
+		engine.GET("/api/orders", func(ctx *gin.Context) {
+			ctx.String(http.StatusOK, "ok")
+		})
+		return engine
+	}
+
+	request := func(engine *gin.Engine, path string, header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("limit by route and header", func(t *testing.T) {
+		engine := newEngine(&SysMiddleware{
+			resHandler: &responser{wrappedDataFunc: wrapFunc, returnWrappedData: true},
+			limitRules: []LimitRule{
+				{Path: "/api/users/:id", LimitRule: g.LimitRule{Limit: 1, Burst: 1}},
+				{Path: "/api/orders", LimitRule: g.LimitRule{KeyBy: "header:X-Api-Key", Limit: 1, Burst: 1}},
+			},
+		})
+
+		w := request(engine, "/api/users/1", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+		w = request(engine, "/api/users/2", nil)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+		assert.NotContains(t, w.Body.String(), "ok")
+
+		assert.Equal(t, http.StatusOK, request(engine, "/api/orders", http.Header{"X-Api-Key": {"a"}}).Code)
+		assert.Equal(t, http.StatusOK, request(engine, "/api/orders", http.Header{"X-Api-Key": {"b"}}).Code)
+		assert.Equal(t, http.StatusTooManyRequests, request(engine, "/api/orders", http.Header{"X-Api-Key": {"a"}}).Code)
+
+		// requests without key are not limited
+		assert.Equal(t, http.StatusOK, request(engine, "/api/orders", nil).Code)
+		assert.Equal(t, http.StatusOK, request(engine, "/api/orders", nil).Code)
+	})
+
+	t.Run("limit by key extractor and prefix", func(t *testing.T) {
+		engine := newEngine(&SysMiddleware{
+			resHandler:    &responser{wrappedDataFunc: wrapFunc, returnWrappedData: true},
+			keyExtractors: []LimitKeyExtractor{&apiKeyExtractor{}},
+			limitRules: []LimitRule{
+				{Path: "/api/*", LimitRule: g.LimitRule{KeyBy: "api-key", Algorithm: g.SlidingWindow, Limit: 1, Window: time.Minute}},
+			},
+		})
+		assert.Equal(t, http.StatusOK, request(engine, "/api/orders?api-key=x", nil).Code)
+		assert.Equal(t, http.StatusTooManyRequests, request(engine, "/api/users/1?api-key=x", nil).Code)
+	})
+
+	t.Run("limit by ip", func(t *testing.T) {
+		m := &SysMiddleware{
+			resHandler: &responser{wrappedDataFunc: wrapFunc, returnWrappedData: true},
+			limitRules: []LimitRule{{LimitRule: g.LimitRule{KeyBy: "ip", Limit: 1, Burst: 1}}},
+		}
+		engine := newEngine(m)
+
+		// forged `X-Forwarded-For` does not change the key without trusted proxies
+		assert.Equal(t, http.StatusOK, request(engine, "/api/orders", http.Header{"X-Forwarded-For": {"1.1.1.1"}}).Code)
+		assert.Equal(t, http.StatusTooManyRequests, request(engine, "/api/orders", http.Header{"X-Forwarded-For": {"2.2.2.2"}}).Code)
+
+		m = &SysMiddleware{
+			resHandler:     &responser{wrappedDataFunc: wrapFunc, returnWrappedData: true},
+			limitRules:     []LimitRule{{LimitRule: g.LimitRule{KeyBy: "ip", Limit: 1, Burst: 1}}},
+			trustedProxies: "192.0.2.1",
+		}
+		engine = newEngine(m)
+		assert.Nil(t, engine.SetTrustedProxies([]string{"192.0.2.1"}))
+
+		// the right-most ip not added by trusted proxies is the key
+		assert.Equal(t, http.StatusOK, request(engine, "/api/orders", http.Header{"X-Forwarded-For": {"9.9.9.9, 1.1.1.1"}}).Code)
+		assert.Equal(t, http.StatusTooManyRequests, request(engine, "/api/orders", http.Header{"X-Forwarded-For": {"8.8.8.8, 1.1.1.1"}}).Code)
+		assert.Equal(t, http.StatusOK, request(engine, "/api/orders", http.Header{"X-Forwarded-For": {"2.2.2.2"}}).Code)
+	})
+
+	t.Run("key extractor not found", func(t *testing.T) {
+		m := &SysMiddleware{limitRules: []LimitRule{{LimitRule: g.LimitRule{KeyBy: "x", Limit: 1}}}}
+		assert.Error(t, m.Init())
+	})
+
+	t.Run("distributed limiter error", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		rateLimiter := gMock.NewMockRateLimiter(controller)
+		rateLimiter.EXPECT().
+			Allow(gomock.Any(), "rate-limit:0:-", g.RateLimitRule{Algorithm: g.TokenBucket, Limit: 1, Burst: 1}).
+			Return(g.RateLimitResult{}, errors.New("err"))
+
+		logger := gone.NewMockLogger(controller)
+		logger.EXPECT().Errorf(gomock.Any(), gomock.Any()).AnyTimes()
+
+		engine := newEngine(&SysMiddleware{
+			logger:      logger,
+			rateLimiter: rateLimiter,
+			limitRules:  []LimitRule{{LimitRule: g.LimitRule{Limit: 1}}},
+		})
+		assert.Equal(t, http.StatusOK, request(engine, "/api/orders", nil).Code)
+	})
+}
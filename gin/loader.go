@@ -3,6 +3,8 @@ package gin
 import (
 	"github.com/gone-io/gone/v2"
 	"github.com/gone-io/goner/g"
+	"github.com/gone-io/goner/gin/auth"
+	"github.com/gone-io/goner/gin/codec"
 	"net/http"
 )
 
@@ -14,11 +16,31 @@ func Load(loader gone.Loader) error {
 				new(IRouter),
 				new(http.Handler),
 				new(g.IRoutes),
+				new(RouteLister),
 			),
 		).
 		MustLoad(&SysMiddleware{}).
+		MustLoad(&corsMiddleware{}, gone.IsDefault(new(Cors))).
+		MustLoad(&securityHeadersMiddleware{}, gone.IsDefault(new(SecurityHeaders))).
+		MustLoad(&csrfMiddleware{}, gone.IsDefault(new(Csrf))).
+		MustLoad(&authMiddleware{}).
+		MustLoad(&idempotencyMiddleware{}, gone.IsDefault(new(Idempotency))).
+		MustLoad(&apiVersioning{}, gone.IsDefault(new(Versioning))).
+		MustLoad(&responseCache{}, gone.IsDefault(new(ResponseCache))).
+		MustLoad(&healthProbe{}, gone.IsDefault(new(HealthProbe))).
 		MustLoad(&proxy{}, gone.IsDefault(new(HandleProxyToGin))).
+		MustLoad(&webSocket{}).
 		MustLoad(NewGinResponser()).
+		MustLoadX(auth.Load).
 		MustLoadX(LoadGinHttpInjector)
 	return loader.Load(NewGinServer())
 }
+
+// LoadCodec load gin with content negotiation: responses are encoded by the codec matching `Accept`, and request bodies
+// are decoded by the codec matching `Content-Type`; without it, JSON is rendered and bodies are bound by gin.
+func LoadCodec(loader gone.Loader) error {
+	loader.
+		MustLoadX(codec.Load).
+		MustLoadX(Load)
+	return nil
+}
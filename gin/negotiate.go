@@ -0,0 +1,68 @@
+package gin
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/goner/gin/codec"
+)
+
+// negotiate return a XContext which renders `JSON` by the codec negotiated with `Accept` header;
+// raw is the data before being wrapped, it is rendered instead when the codec can not encode the wrapped one, eg: proto.Message.
+func (r *responser) negotiate(ctx XContext, raw any) XContext {
+	c, ok := ctx.(*gin.Context)
+	if !ok || r.negotiator == nil || c.Request == nil {
+		return ctx
+	}
+	codecs := r.negotiator.Accepted(c.GetHeader("Accept"))
+	if len(codecs) == 0 {
+		return ctx
+	}
+	return &negotiatedContext{Context: c, codecs: codecs, raw: raw}
+}
+
+type negotiatedContext struct {
+	*gin.Context
+	codecs []codec.Codec
+	raw    any
+}
+
+func (c *negotiatedContext) JSON(code int, obj any) {
+	for _, cd := range c.codecs {
+		for _, v := range []any{obj, c.raw} {
+			if v != nil && cd.Encodable(v) {
+				c.Render(code, codecRender{codec: cd, data: v})
+				return
+			}
+		}
+	}
+	c.Context.JSON(code, obj)
+}
+
+type codecRender struct {
+	codec codec.Codec
+	data  any
+}
+
+func (r codecRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return r.codec.Encode(w, r.data)
+}
+
+func (r codecRender) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if len(header["Content-Type"]) > 0 {
+		return
+	}
+	contentType := r.codec.MediaTypes()[0]
+	switch contentType {
+	case codec.MIMEJSON, codec.MIMEXML, codec.MIMEYAML:
+		contentType += "; charset=utf-8"
+	default:
+		if strings.HasPrefix(contentType, "text/") {
+			contentType += "; charset=utf-8"
+		}
+	}
+	header["Content-Type"] = []string{contentType}
+}
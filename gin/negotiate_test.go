@@ -0,0 +1,88 @@
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin/codec"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_responser_negotiate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	logger := gone.NewMockLogger(controller)
+	logger.EXPECT().Errorf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	type user struct {
+		Name string `json:"name" xml:"name" yaml:"name"`
+	}
+
+	gone.
+		NewApp(codec.Load).
+		Run(func(negotiator codec.Negotiator) {
+			request := func(r *responser, accept string, fn func(ctx *gin.Context)) *httptest.ResponseRecorder {
+				w := httptest.NewRecorder()
+				ctx, _ := gin.CreateTestContext(w)
+				ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+				ctx.Request.Header.Set("Accept", accept)
+				fn(ctx)
+				return w
+			}
+
+			wrapped := &responser{Logger: logger, wrappedDataFunc: wrapFunc, returnWrappedData: true, negotiator: negotiator}
+			raw := &responser{Logger: logger, wrappedDataFunc: wrapFunc, negotiator: negotiator}
+
+			t.Run("json by default", func(t *testing.T) {
+				w := request(wrapped, "", func(ctx *gin.Context) { wrapped.Success(ctx, user{Name: "gone"}) })
+				assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+				assert.JSONEq(t, `{"code":0,"data":{"name":"gone"}}`, w.Body.String())
+			})
+
+			t.Run("xml", func(t *testing.T) {
+				w := request(wrapped, "application/xml", func(ctx *gin.Context) { wrapped.Success(ctx, user{Name: "gone"}) })
+				assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+				assert.Equal(t, "<response><code>0</code><data><name>gone</name></data></response>", w.Body.String())
+
+				// map can not be encoded as xml, fallback to json
+				w = request(raw, "application/xml, application/json;q=0.1", func(ctx *gin.Context) { raw.Success(ctx, map[string]int{"a": 1}) })
+				assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+			})
+
+			t.Run("yaml error", func(t *testing.T) {
+				w := request(wrapped, "application/x-yaml", func(ctx *gin.Context) {
+					wrapped.Failed(ctx, gone.NewParameterError("bad"))
+				})
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				var body map[string]any
+				assert.Nil(t, yaml.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, "bad", body["msg"])
+			})
+
+			t.Run("protobuf", func(t *testing.T) {
+				// the wrapped data is not proto.Message, the raw one is rendered
+				w := request(wrapped, "application/x-protobuf", func(ctx *gin.Context) { wrapped.Success(ctx, wrapperspb.String("gone")) })
+				assert.Equal(t, "application/x-protobuf", w.Header().Get("Content-Type"))
+				msg := &wrapperspb.StringValue{}
+				assert.Nil(t, proto.Unmarshal(w.Body.Bytes(), msg))
+				assert.Equal(t, "gone", msg.GetValue())
+
+				// not proto.Message, no acceptable codec, fallback to json
+				w = request(wrapped, "application/x-protobuf", func(ctx *gin.Context) { wrapped.Success(ctx, user{Name: "gone"}) })
+				assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+			})
+
+			t.Run("without negotiator", func(t *testing.T) {
+				r := &responser{wrappedDataFunc: wrapFunc, returnWrappedData: true}
+				w := request(r, "application/xml", func(ctx *gin.Context) { r.Success(ctx, user{Name: "gone"}) })
+				assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+			})
+		})
+}
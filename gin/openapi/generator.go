@@ -0,0 +1,450 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin"
+)
+
+const IdGoneGinOpenAPI = "gone-gin-openapi"
+
+// Generator generate OpenAPI document for the routes mounted through gin.IRouter
+type Generator interface {
+	Document() *Document
+}
+
+var _ Generator = (*generator)(nil)
+var _ gin.Controller = (*generator)(nil)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+type wrapMarker struct{}
+
+type generator struct {
+	gone.Flag
+	lister    gin.RouteLister `gone:"*"`
+	router    gin.IRouter     `gone:"*"`
+	responser gin.Responser   `gone:"*"`
+
+	serviceName string `gone:"config,server.service-name"`
+	title       string `gone:"config,server.openapi.title"`
+	description string `gone:"config,server.openapi.description"`
+	version     string `gone:"config,server.openapi.version,default=1.0.0"`
+
+	// path the path of OpenAPI document, config key `server.openapi.path`
+	path string `gone:"config,server.openapi.path,default=/openapi.json"`
+
+	// uiPath the path of Swagger UI, config key `server.openapi.ui-path`; Swagger UI is disabled when it is empty
+	uiPath string `gone:"config,server.openapi.ui-path"`
+
+	once sync.Once
+	doc  *Document
+}
+
+func (g *generator) GonerName() string {
+	return IdGoneGinOpenAPI
+}
+
+// Mount mount the document routes to the origin gin router, so they are not listed in the document.
+func (g *generator) Mount() gin.MountError {
+	g.router.GetGinRouter().GET(g.path, func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, g.Document())
+	})
+	if g.uiPath != "" {
+		g.router.GetGinRouter().GET(g.uiPath, func(ctx *gin.Context) {
+			ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(fmt.Sprintf(swaggerUI, g.getTitle(), g.path)))
+		})
+	}
+	return nil
+}
+
+func (g *generator) getTitle() string {
+	if g.title != "" {
+		return g.title
+	}
+	if g.serviceName != "" {
+		return g.serviceName
+	}
+	return "API"
+}
+
+func (g *generator) getWrappedDataFunc() gin.WrappedDataFunc {
+	if getter, ok := g.responser.(gin.WrappedDataFuncGetter); ok {
+		return getter.GetWrappedDataFunc()
+	}
+	return nil
+}
+
+// Document return the document, it is generated at first call, and all routes should have been mounted before.
+func (g *generator) Document() *Document {
+	g.once.Do(func() {
+		g.doc = g.generate()
+	})
+	return g.doc
+}
+
+func (g *generator) generate() *Document {
+	b := newSchemaBuilder()
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       g.getTitle(),
+			Description: g.description,
+			Version:     g.version,
+		},
+		Paths: make(map[string]PathItem),
+	}
+
+	wrappedDataFunc := g.getWrappedDataFunc()
+	for _, route := range g.lister.Routes() {
+		p, pathParams := convertPath(route.Path)
+		item, ok := doc.Paths[p]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[p] = item
+		}
+		item[strings.ToLower(route.Method)] = buildOperation(b, route, pathParams, wrappedDataFunc)
+	}
+
+	if len(b.schemas) > 0 {
+		doc.Components = &Components{Schemas: b.schemas}
+	}
+	return doc
+}
+
+// convertPath convert gin path(`/users/:id/*file`) to OpenAPI path(`/users/{id}/{file}`)
+func convertPath(ginPath string) (p string, params []string) {
+	segments := strings.Split(ginPath, "/")
+	for i, seg := range segments {
+		if len(seg) > 1 && (seg[0] == ':' || seg[0] == '*') {
+			params = append(params, seg[1:])
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func buildOperation(b *schemaBuilder, route gin.RouteInfo, pathParams []string, wrappedDataFunc gin.WrappedDataFunc) *Operation {
+	op := &Operation{
+		OperationID: operationID(route.Method, route.Path),
+		Responses:   make(map[string]*Response),
+	}
+
+	for _, h := range route.Handlers {
+		t := reflect.TypeOf(h)
+		if t == nil || t.Kind() != reflect.Func {
+			continue
+		}
+		for i := 0; i < t.NumIn(); i++ {
+			collectParameters(b, op, t.In(i))
+		}
+	}
+
+	for _, name := range pathParams {
+		if findParameter(op, name, "path") == nil {
+			op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+
+	if n := len(route.Handlers); n > 0 {
+		last := route.Handlers[n-1]
+		op.Summary = shortFuncName(last)
+		buildResponses(b, op, reflect.TypeOf(last), wrappedDataFunc)
+	}
+	return op
+}
+
+func operationID(method, p string) string {
+	p = strings.Trim(strings.NewReplacer(":", "", "*", "").Replace(p), "/")
+	return strings.Trim(strings.ToLower(method)+"_"+invalidNameChar.ReplaceAllString(p, "_"), "_")
+}
+
+func shortFuncName(f any) string {
+	name := gone.GetFuncName(f)
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+func findParameter(op *Operation, name, in string) *Parameter {
+	for _, p := range op.Parameters {
+		if p.Name == name && p.In == in {
+			return p
+		}
+	}
+	return nil
+}
+
+func addParameter(op *Operation, p *Parameter) {
+	if findParameter(op, p.Name, p.In) == nil {
+		op.Parameters = append(op.Parameters, p)
+	}
+}
+
+// collectParameters collect parameters from the struct fields tagged by `gone:"http,..."`
+func collectParameters(b *schemaBuilder, op *Operation, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		injectorName, conf := gone.ParseGoneTag(field.Tag.Get("gone"))
+		if injectorName != gin.IdHttpInjector {
+			continue
+		}
+		keyMap, keys := gone.TagStringParse(conf)
+		if len(keys) == 0 || keys[0] == "" {
+			continue
+		}
+		kind := keys[0]
+		key, all := keyMap[kind], false
+		if key == "" {
+			key, all = field.Name, true
+		}
+
+		switch kind {
+		case "body":
+			op.RequestBody = buildRequestBody(b, field.Type)
+		case "query":
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if all || key == "*" {
+				if ft.Kind() == reflect.Struct {
+					collectQueryStruct(b, op, ft)
+				}
+				continue
+			}
+			p := &Parameter{Name: key, In: "query", Required: isRequired(field), Schema: b.Build(field.Type)}
+			if ft.Kind() == reflect.Slice {
+				explode := true
+				p.Explode = &explode
+			}
+			addParameter(op, p)
+		case "header", "cookie":
+			addParameter(op, &Parameter{Name: key, In: kind, Required: isRequired(field), Schema: b.Build(field.Type)})
+		case "param":
+			addParameter(op, &Parameter{Name: key, In: "path", Required: true, Schema: b.Build(field.Type)})
+		case "form":
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if all || key == "*" {
+				if ft.Kind() == reflect.Struct && ft.PkgPath() != "mime/multipart" {
+					collectFormStruct(b, multipartSchema(op), ft)
+				}
+				continue
+			}
+			addFormProperty(multipartSchema(op), key, b.Build(field.Type), isRequired(field))
+		case "file":
+			schema := &Schema{Type: "string", Format: "binary"}
+			if field.Type.Kind() == reflect.Slice && field.Type != bytesType {
+				schema = &Schema{Type: "array", Items: schema}
+			}
+			addFormProperty(multipartSchema(op), key, schema, isRequired(field))
+		}
+	}
+}
+
+// collectQueryStruct collect query parameters from the struct bound by `ShouldBindQuery`, which uses `form` tag
+func collectQueryStruct(b *schemaBuilder, op *Operation, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("form"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if field.Anonymous && ft.Kind() == reflect.Struct {
+			collectQueryStruct(b, op, ft)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		addParameter(op, &Parameter{Name: name, In: "query", Required: isRequired(field), Schema: b.Build(field.Type)})
+	}
+}
+
+// multipartSchema return the schema of multipart form body of operation, which is created if absent
+func multipartSchema(op *Operation) *Schema {
+	if op.RequestBody == nil {
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{}}
+	}
+	media := op.RequestBody.Content["multipart/form-data"]
+	if media == nil {
+		media = &MediaType{Schema: &Schema{Type: "object", Properties: map[string]*Schema{}}}
+		op.RequestBody.Content["multipart/form-data"] = media
+	}
+	return media.Schema
+}
+
+func addFormProperty(form *Schema, name string, schema *Schema, required bool) {
+	if _, ok := form.Properties[name]; ok {
+		return
+	}
+	form.Properties[name] = schema
+	if required {
+		form.Required = append(form.Required, name)
+	}
+}
+
+// collectFormStruct collect properties of multipart form from the struct bound by gin, which uses `form` tag
+func collectFormStruct(b *schemaBuilder, form *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("form"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if field.Anonymous && ft.Kind() == reflect.Struct {
+			collectFormStruct(b, form, ft)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		addFormProperty(form, name, b.Build(field.Type), isRequired(field))
+	}
+}
+
+func buildRequestBody(b *schemaBuilder, t reflect.Type) *RequestBody {
+	contentType := "application/json"
+	switch {
+	case t == bytesType || t.Kind() == reflect.Interface && t.Implements(readerType):
+		contentType = "application/octet-stream"
+	case t.Kind() == reflect.String:
+		contentType = "text/plain"
+	}
+	return &RequestBody{
+		Required: true,
+		Content: map[string]*MediaType{
+			contentType: {Schema: b.Build(t)},
+		},
+	}
+}
+
+func buildResponses(b *schemaBuilder, op *Operation, t reflect.Type, wrappedDataFunc gin.WrappedDataFunc) {
+	if t == nil || t.Kind() != reflect.Func {
+		return
+	}
+
+	var outs []reflect.Type
+	for i := 0; i < t.NumOut(); i++ {
+		out := t.Out(i)
+		if out.Implements(errorType) {
+			continue
+		}
+		switch {
+		case out.Kind() == reflect.Chan:
+			op.Responses["200"] = &Response{
+				Description: "event stream",
+				Content:     map[string]*MediaType{"text/event-stream": {Schema: b.Build(out.Elem())}},
+			}
+			return
+		case out.Kind() == reflect.Interface && out.Implements(readerType):
+			op.Responses["200"] = &Response{
+				Description: "binary data",
+				Content:     map[string]*MediaType{"application/octet-stream": {Schema: b.Build(out)}},
+			}
+			return
+		}
+		outs = append(outs, out)
+	}
+
+	var data *Schema
+	switch len(outs) {
+	case 0:
+	case 1:
+		data = b.Build(outs[0])
+	default:
+		data = &Schema{Type: "array"}
+	}
+
+	if wrappedDataFunc == nil {
+		contentType := "application/json"
+		if data == nil || data.Type == "string" || data.Type == "integer" || data.Type == "number" || data.Type == "boolean" {
+			contentType = "text/plain"
+		}
+		op.Responses["200"] = &Response{Description: "success", Content: map[string]*MediaType{contentType: {Schema: data}}}
+		op.Responses["default"] = &Response{Description: "error", Content: map[string]*MediaType{"text/plain": {Schema: &Schema{Type: "string"}}}}
+		return
+	}
+
+	op.Responses["200"] = &Response{
+		Description: "success",
+		Content:     map[string]*MediaType{"application/json": {Schema: wrap(b, wrappedDataFunc, data)}},
+	}
+	op.Responses["default"] = &Response{
+		Description: "error",
+		Content:     map[string]*MediaType{"application/json": {Schema: wrap(b, wrappedDataFunc, nil)}},
+	}
+}
+
+// wrap build the schema of wrapped data by calling wrappedDataFunc with a marker,
+// and the field holding the marker is replaced with the schema of data.
+func wrap(b *schemaBuilder, wrappedDataFunc gin.WrappedDataFunc, data *Schema) *Schema {
+	marker := &wrapMarker{}
+	wrapped := wrappedDataFunc(0, "", marker)
+	if wrapped == any(marker) {
+		return data
+	}
+
+	v := reflect.ValueOf(wrapped)
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return data
+	}
+
+	t := v.Type()
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, skip := jsonFieldName(field)
+		if skip || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Interface && !fv.IsNil() && fv.Interface() == any(marker) {
+			if data != nil {
+				s.Properties[name] = data
+			}
+			continue
+		}
+		s.Properties[name] = b.Build(field.Type)
+	}
+	return s
+}
@@ -0,0 +1,151 @@
+package openapi
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type User struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name" binding:"required"`
+	Tags []string
+}
+
+type ListReq struct {
+	Page int    `form:"page"`
+	Size int    `form:"size" binding:"required"`
+	Skip string `form:"-"`
+}
+
+type ctr struct {
+	gone.Flag
+	gin.IRouter `gone:"*"`
+}
+
+func (c *ctr) Mount() gin.MountError {
+	g := c.Group("/api", func(in struct {
+		token string `gone:"http,header=Authorization"`
+	}) {
+	})
+	g.GET("/users/:id", c.get)
+	g.GET("/users", c.list)
+	g.POST("/users", c.create)
+	g.GET("/events", c.events)
+	g.POST("/users/:id/photos", c.upload)
+	return nil
+}
+
+func (c *ctr) get(in struct {
+	id int64 `gone:"http,param=id"`
+}) (*User, error) {
+	return nil, nil
+}
+
+func (c *ctr) list(in struct {
+	req ListReq `gone:"http,query"`
+}) ([]User, error) {
+	return nil, nil
+}
+
+func (c *ctr) create(in gin.RequestBody[User]) error {
+	return nil
+}
+
+type PhotoMeta struct {
+	Title string `form:"title" binding:"required"`
+}
+
+func (c *ctr) upload(in struct {
+	meta   PhotoMeta               `gone:"http,form"`
+	album  int64                   `gone:"http,form=album"`
+	photos []*multipart.FileHeader `gone:"http,file=photos" binding:"required"`
+	cover  *gin.FileStream         `gone:"http,file=cover"`
+}) error {
+	return nil
+}
+
+func (c *ctr) events() <-chan string {
+	return nil
+}
+
+func TestGenerator_Document(t *testing.T) {
+	t.Setenv("GONE_SERVER_PORT", "0")
+
+	gone.
+		NewApp(Load).
+		Load(&ctr{}).
+		Run(func(g Generator, router gin.IRouter) {
+			doc := g.Document()
+			assert.Equal(t, Version, doc.OpenAPI)
+
+			get := doc.Paths["/api/users/{id}"]["get"]
+			assert.NotNil(t, get)
+			assert.Equal(t, "get_api_users_id", get.OperationID)
+			assert.Equal(t, "get", get.Summary)
+			assert.Len(t, get.Parameters, 2)
+			assert.Equal(t, "Authorization", get.Parameters[0].Name)
+			assert.Equal(t, "header", get.Parameters[0].In)
+			assert.Equal(t, "id", get.Parameters[1].Name)
+			assert.Equal(t, "path", get.Parameters[1].In)
+			assert.Equal(t, "integer", get.Parameters[1].Schema.Type)
+
+			schema := get.Responses["200"].Content["application/json"].Schema
+			assert.Equal(t, "integer", schema.Properties["code"].Type)
+			assert.Equal(t, "#/components/schemas/User", schema.Properties["data"].Ref)
+			assert.Nil(t, get.Responses["default"].Content["application/json"].Schema.Properties["data"])
+
+			user := doc.Components.Schemas["User"]
+			assert.Equal(t, []string{"name"}, user.Required)
+			assert.Equal(t, "array", user.Properties["Tags"].Type)
+
+			list := doc.Paths["/api/users"]["get"]
+			assert.NotNil(t, findParameter(list, "page", "query"))
+			assert.True(t, findParameter(list, "size", "query").Required)
+			assert.Nil(t, findParameter(list, "Skip", "query"))
+			assert.Equal(t, "array", list.Responses["200"].Content["application/json"].Schema.Properties["data"].Type)
+
+			create := doc.Paths["/api/users"]["post"]
+			assert.Equal(t, "#/components/schemas/User", create.RequestBody.Content["application/json"].Schema.Ref)
+
+			form := doc.Paths["/api/users/{id}/photos"]["post"].RequestBody.Content["multipart/form-data"].Schema
+			assert.Equal(t, "object", form.Type)
+			assert.Equal(t, []string{"title", "photos"}, form.Required)
+			assert.Equal(t, "integer", form.Properties["album"].Type)
+			assert.Equal(t, "binary", form.Properties["photos"].Items.Format)
+			assert.Equal(t, "binary", form.Properties["cover"].Format)
+
+			events := doc.Paths["/api/events"]["get"]
+			assert.Equal(t, "string", events.Responses["200"].Content["text/event-stream"].Schema.Type)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+			router.(http.Handler).ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var got map[string]any
+			assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, Version, got["openapi"])
+			assert.NotContains(t, got["paths"], "/openapi.json")
+		})
+}
+
+func Test_convertPath(t *testing.T) {
+	p, params := convertPath("/files/:dir/*name")
+	assert.Equal(t, "/files/{dir}/{name}", p)
+	assert.Equal(t, []string{"dir", "name"}, params)
+}
+
+func Test_wrap(t *testing.T) {
+	b := newSchemaBuilder()
+	s := wrap(b, func(code int, msg string, data any) any {
+		return data
+	}, &Schema{Type: "string"})
+	assert.Equal(t, "string", s.Type)
+}
@@ -0,0 +1,14 @@
+package openapi
+
+import (
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin"
+)
+
+// Load OpenAPI document generator, which serves the document at `server.openapi.path`
+func Load(loader gone.Loader) error {
+	loader.
+		MustLoad(&generator{}, gone.IsDefault(new(Generator))).
+		MustLoadX(gin.Load)
+	return nil
+}
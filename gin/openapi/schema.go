@@ -0,0 +1,161 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+var bytesType = reflect.TypeOf([]byte{})
+var readerType = reflect.TypeOf((*io.Reader)(nil)).Elem()
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+var fileHeaderType = reflect.TypeOf(multipart.FileHeader{})
+
+var invalidNameChar = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// schemaBuilder converts go types to JSON Schema, named struct types are put into components and referenced by `$ref`.
+type schemaBuilder struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+func (b *schemaBuilder) componentName(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+
+	base := strings.Trim(invalidNameChar.ReplaceAllString(t.Name(), "_"), "_")
+	name := base
+	for i := 2; ; i++ {
+		if _, ok := b.schemas[name]; !ok {
+			break
+		}
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	b.names[t] = name
+	return name
+}
+
+// Build return the schema of type t
+func (b *schemaBuilder) Build(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == bytesType:
+		return &Schema{Type: "string", Format: "byte"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t == fileHeaderType:
+		return &Schema{Type: "string", Format: "binary"}
+	case t.Kind() == reflect.Interface && t.Implements(readerType):
+		return &Schema{Type: "string", Format: "binary"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var zero float64
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: b.Build(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.Build(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.buildStruct(t)
+		}
+		if name, ok := b.names[t]; ok {
+			return &Schema{Ref: "#/components/schemas/" + name}
+		}
+		name := b.componentName(t)
+		b.schemas[name] = &Schema{}
+		*b.schemas[name] = *b.buildStruct(t)
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+func (b *schemaBuilder) buildStruct(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	b.fillStruct(s, t)
+	return s
+}
+
+func (b *schemaBuilder) fillStruct(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		ft := field.Type
+		if field.Anonymous && name == "" {
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.fillStruct(s, ft)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		s.Properties[name] = b.Build(field.Type)
+		if isRequired(field) {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// isRequired return true when the field is declared as required by `binding` tag
+func isRequired(field reflect.StructField) bool {
+	for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+		if strings.TrimSpace(rule) == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonFieldName(field reflect.StructField) (name string, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	return strings.Split(tag, ",")[0], false
+}
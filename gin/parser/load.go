@@ -1,6 +1,8 @@
 package parser
 
-import "github.com/gone-io/gone/v2"
+import (
+	"github.com/gone-io/gone/v2"
+)
 
 func Load(loader gone.Loader) error {
 	loader.
@@ -9,6 +11,10 @@ func Load(loader gone.Loader) error {
 		MustLoad(&headerNameParser{}).
 		MustLoad(&paramNameParser{}).
 		MustLoad(&queryNameParser{}).
+		MustLoad(&principalNameParser{}).
+		MustLoad(&multipartForm{}).
+		MustLoad(&fileNameParser{}).
+		MustLoad(&formNameParser{}).
 		MustLoad(&ginContextTypeParser{}).
 		MustLoad(&httpRequestTypeParser{}).
 		MustLoad(&httpHeaderTypeParser{}).
@@ -26,7 +26,7 @@ func TestLoad(t *testing.T) {
 	gone.
 		NewApp(Load).
 		Run(func(nameParser []NameParser[*gin.Context], typeParsers []TypeParser[*gin.Context]) {
-			assert.Equal(t, 5, len(nameParser))
+			assert.Equal(t, 8, len(nameParser))
 			assert.Equal(t, 6, len(typeParsers))
 		})
 }
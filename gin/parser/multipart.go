@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+)
+
+// multipartForm read multipart request body for fileNameParser and formNameParser with the same settings.
+type multipartForm struct {
+	gone.Flag
+
+	// maxMemory 解析multipart表单时保存在内存中的最大字节数，超出部分的文件写入临时文件，对应配置项为：`server.multipart.max-memory`
+	maxMemory int64 `gone:"config,server.multipart.max-memory,default=33554432"`
+
+	// maxRequestSize multipart请求体的最大字节数，超出时返回413，对应配置项为：`server.multipart.max-request-size`；为0时不限制
+	maxRequestSize int64 `gone:"config,server.multipart.max-request-size,default=0"`
+}
+
+// parse the multipart form of request once, files exceeding maxMemory are spilled to temp files,
+// which are removed by net/http after the request is handled.
+func (m *multipartForm) parse(context *gin.Context) error {
+	if context.Request.MultipartForm != nil {
+		return nil
+	}
+	m.limit(context)
+	if err := context.Request.ParseMultipartForm(m.maxMemory); err != nil {
+		if errors.Is(err, http.ErrNotMultipart) {
+			return err
+		}
+		return multipartError(err)
+	}
+	return nil
+}
+
+// reader return the reader of parts, the form is not parsed
+func (m *multipartForm) reader(context *gin.Context) (*multipart.Reader, error) {
+	m.limit(context)
+	reader, err := context.Request.MultipartReader()
+	if err != nil {
+		return nil, gone.NewParameterError(fmt.Sprintf("read multipart form error: %s", err.Error()))
+	}
+	return reader, nil
+}
+
+func (m *multipartForm) limit(context *gin.Context) {
+	if m.maxRequestSize > 0 && context.Request.Body != nil {
+		context.Request.Body = http.MaxBytesReader(context.Writer, context.Request.Body, m.maxRequestSize)
+	}
+}
+
+func multipartError(err error) error {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return gone.NewError(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body is larger than %d bytes", maxBytesError.Limit), http.StatusRequestEntityTooLarge)
+	}
+	return gone.NewParameterError(fmt.Sprintf("parse multipart form error: %s", err.Error()))
+}
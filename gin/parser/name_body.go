@@ -1,9 +1,11 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin/codec"
 	"io"
 	"net/http"
 	"reflect"
@@ -12,6 +14,7 @@ import (
 // for body parser
 type bodyNameParser struct {
 	gone.Flag
+	negotiator codec.Negotiator `gone:"*" option:"allowNil"`
 }
 
 var bytesType = reflect.TypeOf([]byte{})
@@ -38,8 +41,8 @@ func (b bodyNameParser) BuildParser(_ map[string]string, field reflect.StructFie
 	case t == anyType || t.Kind() == reflect.Struct || t.Kind() == reflect.Map || t.Kind() == reflect.Slice:
 		return func(context *gin.Context) (reflect.Value, error) {
 			value := reflect.New(t)
-			if err := context.ShouldBind(value.Interface()); err != nil {
-				return emptyValue, gone.NewParameterError(fmt.Sprintf("bind body error: %s", err.Error()))
+			if err := b.bind(context, value.Interface()); err != nil {
+				return emptyValue, bindError("body", value.Interface(), err)
 			}
 			return value.Elem(), nil
 		}, nil
@@ -58,8 +61,8 @@ func (b bodyNameParser) BuildParser(_ map[string]string, field reflect.StructFie
 			if t.Elem().Kind() == reflect.Struct {
 				return func(context *gin.Context) (reflect.Value, error) {
 					value := reflect.New(t.Elem())
-					if err := context.ShouldBind(value.Interface()); err != nil {
-						return emptyValue, gone.NewParameterError(fmt.Sprintf("bind body error: %s", err.Error()))
+					if err := b.bind(context, value.Interface()); err != nil {
+						return emptyValue, bindError("body", value.Interface(), err)
 					}
 					return value, nil
 				}, nil
@@ -74,3 +77,20 @@ func (b bodyNameParser) BuildParser(_ map[string]string, field reflect.StructFie
 func (b bodyNameParser) Name() string {
 	return "body"
 }
+
+// bind decode body with the codec matched by `Content-Type` and validate it, the same as response content negotiation;
+// the body is bound by gin if no codec supports the `Content-Type`, eg: form.
+func (b bodyNameParser) bind(context *gin.Context, v any) error {
+	if b.negotiator != nil && context.Request.Method != http.MethodGet {
+		if c := b.negotiator.ForContentType(context.ContentType()); c != nil {
+			if context.Request.Body == nil {
+				return errors.New("invalid request")
+			}
+			if err := c.Decode(context.Request.Body, v); err != nil {
+				return err
+			}
+			return validateValue(v)
+		}
+	}
+	return context.ShouldBind(v)
+}
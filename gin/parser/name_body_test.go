@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin/codec"
 	"github.com/stretchr/testify/assert"
 	"io"
 	"net/http"
@@ -348,3 +350,54 @@ func Test_bodyNameParser_Name(t *testing.T) {
 		})
 	}
 }
+
+func Test_bodyNameParser_negotiation(t *testing.T) {
+	type Req struct {
+		Name string `json:"name" yaml:"name" form:"name" binding:"required"`
+		Age  int    `json:"age" yaml:"age" binding:"gte=0"`
+	}
+	field := reflect.StructField{Name: "req", Type: reflect.TypeOf(&Req{})}
+
+	gone.
+		NewApp(codec.Load).
+		Run(func(negotiator codec.Negotiator) {
+			b := bodyNameParser{negotiator: negotiator}
+			parse, err := b.BuildParser(nil, field)
+			assert.Nil(t, err)
+
+			request := func(contentType, body string) (*Req, error) {
+				ctx := &gin.Context{Request: &http.Request{
+					Method: http.MethodPost,
+					Header: http.Header{"Content-Type": {contentType}},
+					Body:   io.NopCloser(bytes.NewBufferString(body)),
+				}}
+				v, err := parse(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return v.Interface().(*Req), nil
+			}
+
+			req, err := request("application/x-yaml", "name: gone\nage: 3\n")
+			assert.Nil(t, err)
+			assert.Equal(t, &Req{Name: "gone", Age: 3}, req)
+
+			req, err = request("application/json; charset=utf-8", `{"name":"gone"}`)
+			assert.Nil(t, err)
+			assert.Equal(t, "gone", req.Name)
+
+			_, err = request("application/x-yaml", "age: -1\n")
+			var vErr *ValidationError
+			assert.True(t, errors.As(err, &vErr))
+			assert.Len(t, vErr.Fields, 2)
+
+			_, err = request("application/json", `{"age":"x"}`)
+			assert.True(t, errors.As(err, &vErr))
+			assert.Equal(t, "body.age", vErr.Fields[0].Field)
+
+			// not supported by codecs, bound by gin
+			req, err = request("application/x-www-form-urlencoded", "name=gone")
+			assert.Nil(t, err)
+			assert.Equal(t, "gone", req.Name)
+		})
+}
@@ -15,6 +15,7 @@ func (s *cookeNameParser) BuildParser(keyMap map[string]string, field reflect.St
 	t := field.Type
 	mainKey := keyMap[s.Name()]
 
+	validate := buildVarValidator(field, "cookie."+mainKey)
 	parser, err := BuildParser(t)
 	if err != nil {
 		return nil, gone.ToErrorWithMsg(err, fmt.Sprintf("build parser failed for field(name=%s)", field.Name))
@@ -26,7 +27,9 @@ func (s *cookeNameParser) BuildParser(keyMap map[string]string, field reflect.St
 		}
 
 		if v, err := parser(cookie); err != nil {
-			return emptyValue, gone.NewParameterError(fmt.Sprintf("parse cooke[name=%s] error: %s", mainKey, err.Error()))
+			return emptyValue, gone.NewParameterError(fmt.Sprintf("parse cookie[name=%s] error: %s", mainKey, err.Error()))
+		} else if validate != nil {
+			return v, validate(v)
 		} else {
 			return v, nil
 		}
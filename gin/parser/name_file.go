@@ -0,0 +1,288 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+)
+
+// FileStream a file of multipart form which is read as stream, it is neither buffered in memory nor spilled to disk.
+type FileStream struct {
+	io.ReadCloser
+
+	// Filename the filename sent by client
+	Filename string
+
+	// Header the MIME header of the part
+	Header textproto.MIMEHeader
+
+	// ContentType the content type sniffed from the first 512 bytes, instead of the one sent by client
+	ContentType string
+}
+
+// fileNameParser inject the files of multipart form:
+// `gone:"http,file=avatar"` for `*multipart.FileHeader`, `[]*multipart.FileHeader` or `[]byte`;
+// `*parser.FileStream`, `io.Reader` and `io.ReadCloser` read the file as stream without parsing the whole form,
+// so that they can not be used with other `file` or `form` fields in one request.
+// Options: `max-size=10MB` limits the size of every file, 413 if exceeded;
+// `types=image/png|image/*` limits the content type sniffed from file, 415 if not matched.
+type fileNameParser struct {
+	gone.Flag
+
+	form *multipartForm `gone:"*"`
+}
+
+var fileHeaderType = reflect.TypeOf(&multipart.FileHeader{})
+var fileHeadersType = reflect.TypeOf([]*multipart.FileHeader{})
+var fileStreamType = reflect.TypeOf(&FileStream{})
+
+func (s *fileNameParser) BuildParser(keyMap map[string]string, field reflect.StructField) (func(context *gin.Context) (reflect.Value, error), error) {
+	t := field.Type
+	mainKey := keyMap[s.Name()]
+
+	maxSize, err := parseSize(keyMap["max-size"])
+	if err != nil {
+		return nil, gone.NewInnerError(fmt.Sprintf("invalid max-size(field=%s): %s", field.Name, err.Error()), gone.InjectError)
+	}
+	check := &fileCheck{name: mainKey, maxSize: maxSize, types: splitTypes(keyMap["types"])}
+	validate := buildVarValidator(field, "file."+mainKey)
+
+	var parse func(context *gin.Context) (reflect.Value, error)
+	switch {
+	case t == fileHeaderType:
+		parse = func(context *gin.Context) (reflect.Value, error) {
+			files, err := s.files(context, check)
+			if err != nil || len(files) == 0 {
+				return reflect.Zero(t), err
+			}
+			return reflect.ValueOf(files[0]), nil
+		}
+	case t == fileHeadersType:
+		parse = func(context *gin.Context) (reflect.Value, error) {
+			files, err := s.files(context, check)
+			return reflect.ValueOf(files), err
+		}
+	case t == bytesType:
+		parse = func(context *gin.Context) (reflect.Value, error) {
+			files, err := s.files(context, check)
+			if err != nil || len(files) == 0 {
+				return reflect.Zero(t), err
+			}
+			f, err := files[0].Open()
+			if err != nil {
+				return emptyValue, gone.ToErrorWithMsg(err, fmt.Sprintf("open file[name=%s] failed", mainKey))
+			}
+			defer f.Close()
+			all, err := io.ReadAll(f)
+			if err != nil {
+				return emptyValue, gone.ToErrorWithMsg(err, fmt.Sprintf("read file[name=%s] failed", mainKey))
+			}
+			return reflect.ValueOf(all), nil
+		}
+	case t == fileStreamType || t == readerType || t == readCloserType:
+		parse = func(context *gin.Context) (reflect.Value, error) {
+			stream, err := s.stream(context, check)
+			if err != nil || stream == nil {
+				return reflect.Zero(t), err
+			}
+			if t == fileStreamType {
+				return reflect.ValueOf(stream), nil
+			}
+			return reflect.ValueOf(stream.ReadCloser), nil
+		}
+	default:
+		return nil, gone.NewInnerError(fmt.Sprintf("unsupported type %s(field=%s) ", gone.GetTypeName(t), field.Name), http.StatusInternalServerError)
+	}
+
+	if validate == nil {
+		return parse, nil
+	}
+	return func(context *gin.Context) (reflect.Value, error) {
+		v, err := parse(context)
+		if err != nil {
+			return v, err
+		}
+		return v, validate(v)
+	}, nil
+}
+
+func (s *fileNameParser) Name() string {
+	return "file"
+}
+
+// files return the files of name in parsed multipart form, nil if the request is not multipart
+func (s *fileNameParser) files(context *gin.Context, check *fileCheck) ([]*multipart.FileHeader, error) {
+	if err := s.form.parse(context); err != nil {
+		if errors.Is(err, http.ErrNotMultipart) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	files := context.Request.MultipartForm.File[check.name]
+	for _, f := range files {
+		if err := check.checkHeader(f); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// stream skip the parts before the file of name, and return it as stream; nil if the file is not found
+func (s *fileNameParser) stream(context *gin.Context, check *fileCheck) (*FileStream, error) {
+	reader, err := s.form.reader(context)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, multipartError(err)
+		}
+		if part.FormName() == check.name && part.FileName() != "" {
+			return check.checkStream(part)
+		}
+	}
+}
+
+type fileCheck struct {
+	name    string
+	maxSize int64
+	types   []string
+}
+
+func (c *fileCheck) tooLarge() error {
+	return gone.NewError(http.StatusRequestEntityTooLarge, fmt.Sprintf("file[name=%s] is larger than %d bytes", c.name, c.maxSize), http.StatusRequestEntityTooLarge)
+}
+
+func (c *fileCheck) checkType(head []byte) (string, error) {
+	contentType := http.DetectContentType(head)
+	if len(c.types) == 0 {
+		return contentType, nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	for _, t := range c.types {
+		if t == mediaType || strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(t, "*")) {
+			return contentType, nil
+		}
+	}
+	return "", gone.NewError(http.StatusUnsupportedMediaType, fmt.Sprintf("content type(%s) of file[name=%s] is not allowed", mediaType, c.name), http.StatusUnsupportedMediaType)
+}
+
+func (c *fileCheck) checkHeader(f *multipart.FileHeader) error {
+	if c.maxSize > 0 && f.Size > c.maxSize {
+		return c.tooLarge()
+	}
+	if len(c.types) == 0 {
+		return nil
+	}
+	file, err := f.Open()
+	if err != nil {
+		return gone.ToErrorWithMsg(err, fmt.Sprintf("open file[name=%s] failed", c.name))
+	}
+	defer file.Close()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	_, err = c.checkType(head[:n])
+	return err
+}
+
+func (c *fileCheck) checkStream(part *multipart.Part) (*FileStream, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(part, head)
+	if err != nil && err != io.EOF && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, multipartError(err)
+	}
+	head = head[:n]
+	if c.maxSize > 0 && int64(n) > c.maxSize {
+		return nil, c.tooLarge()
+	}
+	contentType, err := c.checkType(head)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader = io.MultiReader(bytes.NewReader(head), part)
+	if c.maxSize > 0 {
+		reader = &limitedReader{reader: reader, remaining: c.maxSize, check: c}
+	}
+	return &FileStream{
+		ReadCloser:  &partReader{Reader: reader, part: part},
+		Filename:    part.FileName(),
+		Header:      part.Header,
+		ContentType: contentType,
+	}, nil
+}
+
+// limitedReader fails with 413 once more than remaining bytes are read
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+	check     *fileCheck
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.reader.Read(p)
+	if int64(n) > r.remaining {
+		return int(r.remaining), r.check.tooLarge()
+	}
+	r.remaining -= int64(n)
+	return n, err
+}
+
+type partReader struct {
+	io.Reader
+	part *multipart.Part
+}
+
+func (r *partReader) Close() error {
+	return r.part.Close()
+}
+
+func splitTypes(s string) (types []string) {
+	for _, t := range strings.Split(s, "|") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, strings.ToLower(t))
+		}
+	}
+	return types
+}
+
+// parseSize parse size like `1024`, `512KB`, `10MB` or `1GB`, units are in 1024
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return 0, nil
+	}
+	unit := int64(1)
+	for _, u := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size: %s", s)
+	}
+	return n * unit, nil
+}
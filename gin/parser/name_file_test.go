@@ -0,0 +1,205 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var pngHead = []byte("\x89PNG\r\n\x1a\n0000000000")
+
+type part struct {
+	name, filename string
+	content        []byte
+}
+
+func newMultipartContext(parts ...part) *gin.Context {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	for _, p := range parts {
+		if p.filename == "" {
+			_ = w.WriteField(p.name, string(p.content))
+			continue
+		}
+		f, _ := w.CreateFormFile(p.name, p.filename)
+		_, _ = f.Write(p.content)
+	}
+	_ = w.Close()
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/upload", body)
+	ctx.Request.Header.Set("Content-Type", w.FormDataContentType())
+	return ctx
+}
+
+// fieldKeyMap parse the `gone` tag of field like the http injector
+func fieldKeyMap(field reflect.StructField) map[string]string {
+	_, conf := gone.ParseGoneTag(field.Tag.Get("gone"))
+	keyMap, keys := gone.TagStringParse(conf)
+	if keyMap[keys[0]] == "" {
+		keyMap[anyName] = "true"
+		keyMap[keys[0]] = field.Name
+	}
+	return keyMap
+}
+
+func statusOf(err error) int {
+	var gErr gone.Error
+	if errors.As(err, &gErr) {
+		return gErr.GetStatusCode()
+	}
+	return 0
+}
+
+func Test_fileNameParser_BuildParser(t *testing.T) {
+	type X struct {
+		Avatar    *multipart.FileHeader   `gone:"http,file"`
+		Required  *multipart.FileHeader   `gone:"http,file=avatar" binding:"required"`
+		Images    []*multipart.FileHeader `gone:"http,file=images,max-size=1KB,types=image/png|image/gif"`
+		Content   []byte                  `gone:"http,file=doc,max-size=4"`
+		Stream    *FileStream             `gone:"http,file=video,types=video/*"`
+		Reader    io.Reader               `gone:"http,file=video,max-size=600"`
+		Text      io.ReadCloser           `gone:"http,file=video,types=text/*"`
+		BadSize   []byte                  `gone:"http,file=doc,max-size=big"`
+		Unsupport string                  `gone:"http,file"`
+	}
+	rt := reflect.TypeOf(X{})
+	s := &fileNameParser{form: &multipartForm{maxMemory: 1024}}
+	build := func(name string) func(*gin.Context) (reflect.Value, error) {
+		field, _ := rt.FieldByName(name)
+		fn, err := s.BuildParser(fieldKeyMap(field), field)
+		assert.Nil(t, err)
+		return fn
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		for _, name := range []string{"BadSize", "Unsupport"} {
+			field, _ := rt.FieldByName(name)
+			_, err := s.BuildParser(fieldKeyMap(field), field)
+			assert.Error(t, err)
+		}
+	})
+
+	t.Run("file header", func(t *testing.T) {
+		ctx := newMultipartContext(part{"Avatar", "a.png", pngHead}, part{"name", "", []byte("jim")})
+		v, err := build("Avatar")(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, "a.png", v.Interface().(*multipart.FileHeader).Filename)
+
+		v, err = build("Required")(ctx)
+		var vErr *ValidationError
+		assert.True(t, errors.As(err, &vErr))
+		assert.Equal(t, "file.avatar", vErr.Fields[0].Field)
+		assert.True(t, v.IsNil())
+	})
+
+	t.Run("not multipart", func(t *testing.T) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/upload", bytes.NewBufferString("a=1"))
+		ctx.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		v, err := build("Images")(ctx)
+		assert.Nil(t, err)
+		assert.Len(t, v.Interface(), 0)
+	})
+
+	t.Run("size and type", func(t *testing.T) {
+		ctx := newMultipartContext(part{"images", "a.png", pngHead}, part{"images", "b.png", pngHead})
+		v, err := build("Images")(ctx)
+		assert.Nil(t, err)
+		assert.Len(t, v.Interface(), 2)
+
+		ctx = newMultipartContext(part{"images", "a.png", pngHead}, part{"images", "b.txt", []byte("hello")})
+		_, err = build("Images")(ctx)
+		assert.Equal(t, http.StatusUnsupportedMediaType, statusOf(err))
+
+		ctx = newMultipartContext(part{"images", "a.png", append(pngHead, make([]byte, 1024)...)})
+		_, err = build("Images")(ctx)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, statusOf(err))
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		ctx := newMultipartContext(part{"doc", "a.txt", []byte("abc")})
+		v, err := build("Content")(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("abc"), v.Interface())
+
+		_, err = build("Content")(newMultipartContext(part{"doc", "a.txt", []byte("abcde")}))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, statusOf(err))
+
+		v, err = build("Content")(newMultipartContext())
+		assert.Nil(t, err)
+		assert.Nil(t, v.Interface())
+	})
+
+	t.Run("request too large", func(t *testing.T) {
+		s.form.maxRequestSize = 100
+		defer func() { s.form.maxRequestSize = 0 }()
+		ctx := newMultipartContext(part{"doc", "a.txt", make([]byte, 200)})
+		_, err := build("Content")(ctx)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, statusOf(err))
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		mp4 := []byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom")
+		ctx := newMultipartContext(part{"name", "", []byte("jim")}, part{"video", "a.mp4", mp4})
+		v, err := build("Stream")(ctx)
+		assert.Nil(t, err)
+		stream := v.Interface().(*FileStream)
+		assert.Equal(t, "a.mp4", stream.Filename)
+		assert.Equal(t, "video/mp4", stream.ContentType)
+		all, err := io.ReadAll(stream)
+		assert.Nil(t, err)
+		assert.Equal(t, mp4, all)
+		assert.Nil(t, stream.Close())
+
+		_, err = build("Text")(newMultipartContext(part{"video", "a.mp4", mp4}))
+		assert.Equal(t, http.StatusUnsupportedMediaType, statusOf(err))
+
+		v, err = build("Reader")(newMultipartContext(part{"video", "a.mp4", mp4}))
+		assert.Nil(t, err)
+		all, err = io.ReadAll(v.Interface().(io.Reader))
+		assert.Nil(t, err)
+		assert.Equal(t, mp4, all)
+
+		v, err = build("Reader")(newMultipartContext(part{"video", "a.mp4", make([]byte, 1000)}))
+		assert.Nil(t, err)
+		all, err = io.ReadAll(v.Interface().(io.Reader))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, statusOf(err))
+		assert.Len(t, all, 600)
+
+		v, err = build("Text")(newMultipartContext(part{"other", "a.txt", []byte("a")}))
+		assert.Nil(t, err)
+		assert.True(t, v.IsNil())
+
+		ctx, _ = gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/upload", nil)
+		_, err = build("Stream")(ctx)
+		assert.Error(t, err)
+	})
+}
+
+func Test_parseSize(t *testing.T) {
+	tests := map[string]int64{"": 0, "10": 10, "1kb": 1024, "2MB": 2 << 20, "1G": 1 << 30, "3 B": 3}
+	for s, want := range tests {
+		got, err := parseSize(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, want, got, s)
+	}
+	_, err := parseSize("-1")
+	assert.Error(t, err)
+	_, err = parseSize("1TB")
+	assert.Error(t, err)
+}
+
+func Test_fileNameParser_Name(t *testing.T) {
+	assert.Equal(t, "file", (&fileNameParser{}).Name())
+}
@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gone-io/gone/v2"
+)
+
+// formNameParser inject the values of form, which is multipart or url encoded:
+// `gone:"http,form=name"` for a value or values of name, which are parsed like query;
+// `gone:"http,form=*"` or `gone:"http,form"` for struct or map bound by gin with `form` tags, including `*multipart.FileHeader` fields;
+// `*multipart.Form` for the parsed multipart form, and `*multipart.Reader` to read parts as stream without parsing the form.
+type formNameParser struct {
+	gone.Flag
+
+	form *multipartForm `gone:"*"`
+}
+
+var multipartFormType = reflect.TypeOf(&multipart.Form{})
+var multipartReaderType = reflect.TypeOf(&multipart.Reader{})
+
+func (s *formNameParser) BuildParser(keyMap map[string]string, field reflect.StructField) (func(context *gin.Context) (reflect.Value, error), error) {
+	t := field.Type
+	mainKey := keyMap[s.Name()]
+
+	whole := keyMap[anyName] == "true" || mainKey == "*"
+	switch {
+	case t == multipartFormType:
+		return func(context *gin.Context) (reflect.Value, error) {
+			if err := s.form.parse(context); err != nil {
+				if errors.Is(err, http.ErrNotMultipart) {
+					return reflect.Zero(t), nil
+				}
+				return emptyValue, err
+			}
+			return reflect.ValueOf(context.Request.MultipartForm), nil
+		}, nil
+
+	case t == multipartReaderType:
+		return func(context *gin.Context) (reflect.Value, error) {
+			reader, err := s.form.reader(context)
+			if err != nil {
+				return emptyValue, err
+			}
+			return reflect.ValueOf(reader), nil
+		}, nil
+
+	case whole && (t.Kind() == reflect.Struct || t.Kind() == reflect.Map || t == anyType):
+		return func(context *gin.Context) (reflect.Value, error) {
+			value := reflect.New(t)
+			if t.Kind() == reflect.Map {
+				value.Elem().Set(reflect.MakeMap(t))
+			}
+			if err := s.bind(context, value.Interface()); err != nil {
+				return emptyValue, err
+			}
+			return value.Elem(), nil
+		}, nil
+
+	case whole && t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct:
+		return func(context *gin.Context) (reflect.Value, error) {
+			value := reflect.New(t.Elem())
+			if err := s.bind(context, value.Interface()); err != nil {
+				return emptyValue, err
+			}
+			return value, nil
+		}, nil
+	}
+
+	validate := buildVarValidator(field, "form."+mainKey)
+	if t.Kind() == reflect.Slice {
+		parser, err := BuildParser(t.Elem())
+		if err != nil {
+			return nil, gone.ToErrorWithMsg(err, fmt.Sprintf("build parser failed for field(name=%s)", field.Name))
+		}
+		return func(context *gin.Context) (reflect.Value, error) {
+			if err := s.form.parse(context); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+				return emptyValue, err
+			}
+			arr := context.PostFormArray(mainKey)
+			slice := reflect.MakeSlice(t, 0, len(arr))
+			for _, param := range arr {
+				if v, err := parser(param); err != nil {
+					return emptyValue, gone.NewParameterError(fmt.Sprintf("parse form[name=%s] error: %s", mainKey, err.Error()))
+				} else {
+					slice = reflect.Append(slice, v)
+				}
+			}
+			if validate != nil {
+				if err := validate(slice); err != nil {
+					return emptyValue, err
+				}
+			}
+			return slice, nil
+		}, nil
+	}
+
+	parser, err := BuildParser(t)
+	if err != nil {
+		return nil, gone.ToErrorWithMsg(err, fmt.Sprintf("build parser failed for field(name=%s)", field.Name))
+	}
+	return func(context *gin.Context) (reflect.Value, error) {
+		if err := s.form.parse(context); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return emptyValue, err
+		}
+		if v, err := parser(context.PostForm(mainKey)); err != nil {
+			return emptyValue, gone.NewParameterError(fmt.Sprintf("parse form[name=%s] error: %s", mainKey, err.Error()))
+		} else if validate != nil {
+			return v, validate(v)
+		} else {
+			return v, nil
+		}
+	}, nil
+}
+
+func (s *formNameParser) Name() string {
+	return "form"
+}
+
+func (s *formNameParser) bind(context *gin.Context, v any) error {
+	b := binding.Form
+	if err := s.form.parse(context); err == nil {
+		b = binding.FormMultipart
+	} else if !errors.Is(err, http.ErrNotMultipart) {
+		return err
+	}
+	if err := context.ShouldBindWith(v, b); err != nil {
+		return bindError("form", v, err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_formNameParser_BuildParser(t *testing.T) {
+	type Profile struct {
+		Name   string                `form:"name" binding:"required"`
+		Age    int                   `form:"age"`
+		Avatar *multipart.FileHeader `form:"avatar"`
+	}
+	type X struct {
+		Name    string            `gone:"http,form=name"`
+		Age     int               `gone:"http,form=age" binding:"min=18"`
+		Tags    []string          `gone:"http,form=tag"`
+		Ids     []int             `gone:"http,form=id"`
+		Profile Profile           `gone:"http,form"`
+		Ptr     *Profile          `gone:"http,form=*"`
+		Values  map[string]string `gone:"http,form=*"`
+		Form    *multipart.Form   `gone:"http,form"`
+		Reader  *multipart.Reader `gone:"http,form=*"`
+		Chan    chan int          `gone:"http,form=c"`
+	}
+	rt := reflect.TypeOf(X{})
+	s := &formNameParser{form: &multipartForm{maxMemory: 1024}}
+	build := func(name string) func(*gin.Context) (reflect.Value, error) {
+		field, _ := rt.FieldByName(name)
+		fn, err := s.BuildParser(fieldKeyMap(field), field)
+		assert.Nil(t, err)
+		return fn
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		field, _ := rt.FieldByName("Chan")
+		_, err := s.BuildParser(fieldKeyMap(field), field)
+		assert.Error(t, err)
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		newCtx := func() *gin.Context {
+			return newMultipartContext(
+				part{"name", "", []byte("jim")},
+				part{"age", "", []byte("20")},
+				part{"tag", "", []byte("a")},
+				part{"tag", "", []byte("b")},
+				part{"avatar", "a.png", pngHead},
+			)
+		}
+		ctx := newCtx()
+		v, err := build("Name")(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, "jim", v.Interface())
+		v, err = build("Age")(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 20, v.Interface())
+		v, err = build("Tags")(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "b"}, v.Interface())
+
+		v, err = build("Profile")(ctx)
+		assert.Nil(t, err)
+		profile := v.Interface().(Profile)
+		assert.Equal(t, "jim", profile.Name)
+		assert.Equal(t, "a.png", profile.Avatar.Filename)
+
+		v, err = build("Ptr")(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 20, v.Interface().(*Profile).Age)
+
+		v, err = build("Form")(ctx)
+		assert.Nil(t, err)
+		assert.Len(t, v.Interface().(*multipart.Form).File["avatar"], 1)
+
+		_, err = build("Reader")(ctx)
+		assert.Error(t, err, "form is parsed")
+
+		v, err = build("Reader")(newCtx())
+		assert.Nil(t, err)
+		p, err := v.Interface().(*multipart.Reader).NextPart()
+		assert.Nil(t, err)
+		assert.Equal(t, "name", p.FormName())
+	})
+
+	t.Run("url encoded", func(t *testing.T) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/form", bytes.NewBufferString("name=tom&age=10&id=1&id=x"))
+		ctx.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		v, err := build("Name")(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, "tom", v.Interface())
+
+		_, err = build("Age")(ctx)
+		var vErr *ValidationError
+		assert.True(t, errors.As(err, &vErr))
+		assert.Equal(t, "form.age", vErr.Fields[0].Field)
+
+		_, err = build("Ids")(ctx)
+		assert.Error(t, err)
+
+		v, err = build("Values")(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, "tom", v.Interface().(map[string]string)["name"])
+
+		v, err = build("Form")(ctx)
+		assert.Nil(t, err)
+		assert.True(t, v.IsNil())
+	})
+
+	t.Run("validate struct", func(t *testing.T) {
+		ctx := newMultipartContext(part{"age", "", []byte("20")})
+		_, err := build("Profile")(ctx)
+		var vErr *ValidationError
+		assert.True(t, errors.As(err, &vErr))
+		assert.Equal(t, "form.name", vErr.Fields[0].Field)
+	})
+
+	t.Run("request too large", func(t *testing.T) {
+		s.form.maxRequestSize = 10
+		defer func() { s.form.maxRequestSize = 0 }()
+		_, err := build("Name")(newMultipartContext(part{"name", "", []byte("jim")}))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, statusOf(err))
+	})
+}
+
+func Test_formNameParser_Name(t *testing.T) {
+	assert.Equal(t, "form", (&formNameParser{}).Name())
+}
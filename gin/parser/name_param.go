@@ -15,6 +15,7 @@ func (s *paramNameParser) BuildParser(keyMap map[string]string, field reflect.St
 	t := field.Type
 	mainKey := keyMap[s.Name()]
 
+	validate := buildVarValidator(field, "param."+mainKey)
 	parser, err := BuildParser(t)
 	if err != nil {
 		return nil, gone.ToErrorWithMsg(err, fmt.Sprintf("build parser failed for field(name=%s)", field.Name))
@@ -23,7 +24,9 @@ func (s *paramNameParser) BuildParser(keyMap map[string]string, field reflect.St
 		param := context.Param(mainKey)
 
 		if v, err := parser(param); err != nil {
-			return emptyValue, gone.NewParameterError(fmt.Sprintf("parse cooke[name=%s] error: %s", mainKey, err.Error()))
+			return emptyValue, gone.NewParameterError(fmt.Sprintf("parse param[name=%s] error: %s", mainKey, err.Error()))
+		} else if validate != nil {
+			return v, validate(v)
 		} else {
 			return v, nil
 		}
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin/auth"
+	"reflect"
+)
+
+// principalNameParser inject the principal authenticated by auth middleware:
+// `gone:"http,principal"` for `*auth.Principal`, `auth.Principal` or `map[string]any` (all claims);
+// `gone:"http,principal=<claim>"` for a claim, which is converted to the type of field.
+// If the request is not authenticated, `*auth.Principal` is nil, others fail with 401.
+type principalNameParser struct {
+	gone.Flag
+}
+
+var principalType = reflect.TypeOf(auth.Principal{})
+var claimsType = reflect.TypeOf(map[string]any{})
+
+func (s *principalNameParser) BuildParser(keyMap map[string]string, field reflect.StructField) (func(context *gin.Context) (reflect.Value, error), error) {
+	t := field.Type
+	mainKey := keyMap[s.Name()]
+
+	if keyMap[anyName] == "true" {
+		switch t {
+		case reflect.PointerTo(principalType):
+			return func(context *gin.Context) (reflect.Value, error) {
+				p, _ := auth.FromContext(context)
+				return reflect.ValueOf(p), nil
+			}, nil
+		case principalType:
+			return func(context *gin.Context) (reflect.Value, error) {
+				p, ok := auth.FromContext(context)
+				if !ok {
+					return emptyValue, auth.Unauthorized
+				}
+				return reflect.ValueOf(*p), nil
+			}, nil
+		case claimsType:
+			return func(context *gin.Context) (reflect.Value, error) {
+				p, ok := auth.FromContext(context)
+				if !ok {
+					return emptyValue, auth.Unauthorized
+				}
+				return reflect.ValueOf(p.Claims), nil
+			}, nil
+		}
+	}
+
+	validate := buildVarValidator(field, "principal."+mainKey)
+	return func(context *gin.Context) (reflect.Value, error) {
+		p, ok := auth.FromContext(context)
+		if !ok {
+			return emptyValue, auth.Unauthorized
+		}
+		v, err := convertClaim(p.Claim(mainKey), t)
+		if err != nil {
+			return emptyValue, gone.NewInnerError(fmt.Sprintf("convert claim(%s) to %s failed: %s", mainKey, gone.GetTypeName(t), err.Error()), gone.InjectError)
+		}
+		if validate != nil {
+			return v, validate(v)
+		}
+		return v, nil
+	}, nil
+}
+
+// convertClaim convert claim to type t by JSON, numbers and booleans are formatted if t is string
+func convertClaim(claim any, t reflect.Type) (reflect.Value, error) {
+	value := reflect.New(t)
+	if claim == nil {
+		return value.Elem(), nil
+	}
+	if t.Kind() == reflect.String {
+		if _, ok := claim.(string); !ok {
+			value.Elem().SetString(fmt.Sprintf("%v", claim))
+			return value.Elem(), nil
+		}
+	}
+	b, err := json.Marshal(claim)
+	if err != nil {
+		return emptyValue, err
+	}
+	if err = json.Unmarshal(b, value.Interface()); err != nil {
+		return emptyValue, err
+	}
+	return value.Elem(), nil
+}
+
+func (s *principalNameParser) Name() string {
+	return "principal"
+}
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/goner/gin/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_principalNameParser_BuildParser(t *testing.T) {
+	type Profile struct {
+		Name string `json:"name"`
+	}
+	type Req struct {
+		P       *auth.Principal `gone:"http,principal"`
+		V       auth.Principal  `gone:"http,principal"`
+		Claims  map[string]any  `gone:"http,principal"`
+		Email   string          `gone:"http,principal=email"`
+		Age     int             `gone:"http,principal=age" binding:"min=18"`
+		Tenant  string          `gone:"http,principal=tenant"`
+		Profile Profile         `gone:"http,principal=profile"`
+	}
+	rt := reflect.TypeOf(Req{})
+	s := &principalNameParser{}
+	assert.Equal(t, "principal", s.Name())
+
+	build := func(name string, keyMap map[string]string) func(*gin.Context) (reflect.Value, error) {
+		field, _ := rt.FieldByName(name)
+		fn, err := s.BuildParser(keyMap, field)
+		assert.Nil(t, err)
+		return fn
+	}
+	whole := map[string]string{"principal": "P", anyName: "true"}
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", "/", nil)
+
+	v, err := build("P", whole)(ctx)
+	assert.Nil(t, err)
+	assert.True(t, v.IsNil())
+	_, err = build("V", whole)(ctx)
+	assert.True(t, errors.Is(err, auth.Unauthorized))
+	_, err = build("Email", map[string]string{"principal": "email"})(ctx)
+	assert.True(t, errors.Is(err, auth.Unauthorized))
+
+	p := &auth.Principal{Subject: "u1", Claims: map[string]any{
+		"email":   "a@b.c",
+		"age":     20.0,
+		"tenant":  7.0,
+		"profile": map[string]any{"name": "n"},
+	}}
+	auth.SetPrincipal(ctx, p)
+
+	v, _ = build("P", whole)(ctx)
+	assert.Equal(t, p, v.Interface())
+	v, _ = build("V", whole)(ctx)
+	assert.Equal(t, "u1", v.Interface().(auth.Principal).Subject)
+	v, _ = build("Claims", whole)(ctx)
+	assert.Equal(t, p.Claims, v.Interface())
+	v, _ = build("Email", map[string]string{"principal": "email"})(ctx)
+	assert.Equal(t, "a@b.c", v.Interface())
+	v, _ = build("Tenant", map[string]string{"principal": "tenant"})(ctx)
+	assert.Equal(t, "7", v.Interface())
+	v, _ = build("Profile", map[string]string{"principal": "profile"})(ctx)
+	assert.Equal(t, Profile{Name: "n"}, v.Interface())
+
+	v, err = build("Age", map[string]string{"principal": "age"})(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 20, v.Interface())
+	p.Claims["age"] = 10
+	_, err = build("Age", map[string]string{"principal": "age"})(ctx)
+	var vErr *ValidationError
+	assert.True(t, errors.As(err, &vErr))
+	assert.Equal(t, "principal.age", vErr.Fields[0].Field)
+}
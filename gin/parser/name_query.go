@@ -29,7 +29,7 @@ func (s *queryNameParser) BuildParser(keyMap map[string]string, field reflect.St
 		return func(context *gin.Context) (reflect.Value, error) {
 			value := reflect.New(t)
 			if err := context.ShouldBindQuery(value.Interface()); err != nil {
-				return emptyValue, gone.NewParameterError(fmt.Sprintf("bind query error: %s", err.Error()))
+				return emptyValue, bindError("query", value.Interface(), err)
 			}
 			return value.Elem(), nil
 		}, nil
@@ -38,12 +38,13 @@ func (s *queryNameParser) BuildParser(keyMap map[string]string, field reflect.St
 		return func(context *gin.Context) (reflect.Value, error) {
 			value := reflect.New(t.Elem())
 			if err := context.ShouldBindQuery(value.Interface()); err != nil {
-				return emptyValue, gone.NewParameterError(fmt.Sprintf("bind query error: %s", err.Error()))
+				return emptyValue, bindError("query", value.Interface(), err)
 			}
 			return value, nil
 		}, nil
 	}
 
+	validate := buildVarValidator(field, "query."+mainKey)
 	if t.Kind() == reflect.Slice {
 		parser, err := BuildParser(t.Elem())
 		if err != nil {
@@ -54,11 +55,16 @@ func (s *queryNameParser) BuildParser(keyMap map[string]string, field reflect.St
 			slice := reflect.MakeSlice(t, 0, len(arr))
 			for _, param := range arr {
 				if v, err := parser(param); err != nil {
-					return emptyValue, gone.NewParameterError(fmt.Sprintf("parse cooke[name=%s] error: %s", mainKey, err.Error()))
+					return emptyValue, gone.NewParameterError(fmt.Sprintf("parse query[name=%s] error: %s", mainKey, err.Error()))
 				} else {
 					slice = reflect.Append(slice, v)
 				}
 			}
+			if validate != nil {
+				if err := validate(slice); err != nil {
+					return emptyValue, err
+				}
+			}
 			return slice, nil
 		}, nil
 	} else {
@@ -71,7 +77,9 @@ func (s *queryNameParser) BuildParser(keyMap map[string]string, field reflect.St
 			param := context.Query(mainKey)
 
 			if v, err := parser(param); err != nil {
-				return emptyValue, gone.NewParameterError(fmt.Sprintf("parse cooke[name=%s] error: %s", mainKey, err.Error()))
+				return emptyValue, gone.NewParameterError(fmt.Sprintf("parse query[name=%s] error: %s", mainKey, err.Error()))
+			} else if validate != nil {
+				return v, validate(v)
 			} else {
 				return v, nil
 			}
@@ -0,0 +1,244 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gone-io/gone/v2"
+)
+
+// FieldError describes a field which failed validation.
+type FieldError struct {
+	// Field path of the field, which starts with the source of the value, eg: `body.user.name`, `query.page`, `header.X-Token`
+	Field string `json:"field"`
+
+	// Rule the validation rule which failed, eg: `required`, `min`; `type` means the value can not be parsed
+	Rule string `json:"rule"`
+
+	// Param the parameter of the rule, eg: `10` for `min=10`
+	Param string `json:"param,omitempty"`
+
+	// Message default english message
+	Message string `json:"message"`
+
+	// Key i18n key of the message, in format of `validation.<rule>`
+	Key string `json:"key"`
+}
+
+// ValidationError parameter error with structured field errors.
+// It implements gone.BusinessError, so the field errors are rendered as `data` by `Responser.Failed` with http status 400.
+type ValidationError struct {
+	err    gone.Error
+	Fields []FieldError
+}
+
+var _ gone.BusinessError = (*ValidationError)(nil)
+
+func (e *ValidationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ValidationError) Msg() string {
+	return e.err.Msg()
+}
+
+func (e *ValidationError) SetMsg(msg string) {
+	e.err.SetMsg(msg)
+}
+
+func (e *ValidationError) Code() int {
+	return e.err.Code()
+}
+
+func (e *ValidationError) GetStatusCode() int {
+	return e.err.GetStatusCode()
+}
+
+func (e *ValidationError) Data() any {
+	return e.Fields
+}
+
+func (e *ValidationError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"code":   e.Code(),
+		"msg":    e.Msg(),
+		"fields": e.Fields,
+	})
+}
+
+// NewValidationError create a ValidationError with the field errors
+func NewValidationError(fields ...FieldError) *ValidationError {
+	list := make([]string, 0, len(fields))
+	for _, f := range fields {
+		list = append(list, f.Message)
+	}
+	return &ValidationError{
+		err:    gone.NewParameterError(fmt.Sprintf("validation failed: %s", strings.Join(list, "; "))),
+		Fields: fields,
+	}
+}
+
+var messages = map[string]string{
+	"required": "%s is required",
+	"min":      "%s must be at least %s",
+	"max":      "%s must be at most %s",
+	"len":      "%s must have length %s",
+	"gt":       "%s must be greater than %s",
+	"gte":      "%s must be greater than or equal to %s",
+	"lt":       "%s must be less than %s",
+	"lte":      "%s must be less than or equal to %s",
+	"eq":       "%s must be equal to %s",
+	"ne":       "%s must not be equal to %s",
+	"oneof":    "%s must be one of [%s]",
+	"email":    "%s must be a valid email address",
+	"url":      "%s must be a valid url",
+	"uuid":     "%s must be a valid uuid",
+	"type":     "%s must be of type %s",
+}
+
+// NewFieldError create a FieldError, Message and Key are generated from rule.
+func NewFieldError(field, rule, param string) FieldError {
+	var msg string
+	if tpl, ok := messages[rule]; ok {
+		if strings.Count(tpl, "%s") == 2 {
+			msg = fmt.Sprintf(tpl, field, param)
+		} else {
+			msg = fmt.Sprintf(tpl, field)
+		}
+	} else {
+		msg = fmt.Sprintf("%s failed on the '%s' rule", field, rule)
+	}
+	return FieldError{
+		Field:   field,
+		Rule:    rule,
+		Param:   param,
+		Message: msg,
+		Key:     "validation." + rule,
+	}
+}
+
+// validate the validator owned by parser, the rules are declared by `binding` tag like gin's default validator;
+// gin's binding.Validator is not changed, it still validates the values bound by gin, eg: query and form.
+var validate = newValidate()
+
+func newValidate() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	return v
+}
+
+// validateValue validate the struct, or the structs in slice or array, the same as gin's default validator
+func validateValue(obj any) error {
+	value := reflect.ValueOf(obj)
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil
+		}
+		return validateValue(value.Elem().Interface())
+	case reflect.Struct:
+		return validate.Struct(obj)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := validateValue(value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// tagName the name of field taken from `json` or `form` tag
+func tagName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		if name := strings.Split(field.Tag.Get(tag), ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// fieldPath convert the struct namespace of field error, eg: `User.Items[0].Name`, to the path of names taken from
+// `json` or `form` tag, eg: `items[0].name`; t is the type of the value validated.
+func fieldPath(t reflect.Type, namespace string) string {
+	parts := strings.Split(namespace, ".")[1:]
+	t = elemType(t, -1)
+	for i, part := range parts {
+		name, index, _ := strings.Cut(part, "[")
+		if t.Kind() != reflect.Struct {
+			break
+		}
+		f, ok := t.FieldByName(name)
+		if !ok {
+			break
+		}
+		parts[i] = tagName(f)
+		if index != "" {
+			parts[i] += "[" + index
+		}
+		t = elemType(f.Type, strings.Count(index, "]"))
+	}
+	return strings.Join(parts, ".")
+}
+
+// elemType dereference pointers of t, and take the element type of slice, array or map n times, or all if n < 0
+func elemType(t reflect.Type, n int) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr:
+			t = t.Elem()
+		case reflect.Slice, reflect.Array, reflect.Map:
+			if n == 0 {
+				return t
+			}
+			n--
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+// bindError convert the error returned by binding obj to ValidationError if it is caused by validation or type mismatch,
+// otherwise to parameter error.
+func bindError(source string, obj any, err error) error {
+	var vErrs validator.ValidationErrors
+	if errors.As(err, &vErrs) {
+		fields := make([]FieldError, 0, len(vErrs))
+		for _, fe := range vErrs {
+			fields = append(fields, NewFieldError(source+"."+fieldPath(reflect.TypeOf(obj), fe.StructNamespace()), fe.Tag(), fe.Param()))
+		}
+		return NewValidationError(fields...)
+	}
+
+	var tErr *json.UnmarshalTypeError
+	if errors.As(err, &tErr) && tErr.Field != "" {
+		return NewValidationError(NewFieldError(source+"."+tErr.Field, "type", tErr.Type.String()))
+	}
+	return gone.NewParameterError(fmt.Sprintf("bind %s error: %s", source, err.Error()))
+}
+
+// buildVarValidator build a function to validate the value parsed for single field by rules in `binding` tag;
+// return nil if the field has no rules.
+func buildVarValidator(field reflect.StructField, path string) func(v reflect.Value) error {
+	rules := field.Tag.Get("binding")
+	if rules == "" {
+		return nil
+	}
+	return func(v reflect.Value) error {
+		err := validate.Var(v.Interface(), rules)
+		var vErrs validator.ValidationErrors
+		if errors.As(err, &vErrs) {
+			fields := make([]FieldError, 0, len(vErrs))
+			for _, fe := range vErrs {
+				fields = append(fields, NewFieldError(path, fe.Tag(), fe.Param()))
+			}
+			return NewValidationError(fields...)
+		}
+		return err
+	}
+}
@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/gone-io/gone/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_bindError(t *testing.T) {
+	type User struct {
+		Name string `json:"name" binding:"required"`
+		Age  int    `json:"age" binding:"gte=18"`
+	}
+
+	type IN struct {
+		user  User   `gone:"http,body"`
+		page  int    `gone:"http,query=page" binding:"min=1"`
+		token string `gone:"http,header=X-Token" binding:"required"`
+	}
+
+	of := reflect.TypeOf(IN{})
+	userField, _ := of.FieldByName("user")
+	pageField, _ := of.FieldByName("page")
+	tokenField, _ := of.FieldByName("token")
+
+	t.Run("body validation", func(t *testing.T) {
+		parser, err := bodyNameParser{}.BuildParser(nil, userField)
+		assert.Nil(t, err)
+
+		_, err = parser(&gin.Context{
+			Request: &http.Request{
+				Body:   io.NopCloser(bytes.NewBufferString(`{"age":10}`)),
+				Header: http.Header{"Content-Type": {"application/json"}},
+			},
+		})
+		var vErr *ValidationError
+		assert.True(t, errors.As(err, &vErr))
+		assert.Equal(t, http.StatusBadRequest, vErr.GetStatusCode())
+		assert.Equal(t, []FieldError{
+			NewFieldError("body.name", "required", ""),
+			NewFieldError("body.age", "gte", "18"),
+		}, vErr.Data())
+		assert.Equal(t, "validation.required", vErr.Fields[0].Key)
+		assert.Equal(t, "body.age must be greater than or equal to 18", vErr.Fields[1].Message)
+	})
+
+	t.Run("body type mismatch", func(t *testing.T) {
+		parser, err := bodyNameParser{}.BuildParser(nil, userField)
+		assert.Nil(t, err)
+
+		_, err = parser(&gin.Context{
+			Request: &http.Request{
+				Body:   io.NopCloser(bytes.NewBufferString(`{"name":"x","age":"x"}`)),
+				Header: http.Header{"Content-Type": {"application/json"}},
+			},
+		})
+		var vErr *ValidationError
+		assert.True(t, errors.As(err, &vErr))
+		assert.Equal(t, "body.age", vErr.Fields[0].Field)
+		assert.Equal(t, "type", vErr.Fields[0].Rule)
+	})
+
+	t.Run("query validation", func(t *testing.T) {
+		parser, err := (&queryNameParser{}).BuildParser(map[string]string{"query": "page"}, pageField)
+		assert.Nil(t, err)
+
+		u, _ := url.Parse("http://localhost/?page=0")
+		_, err = parser(&gin.Context{Request: &http.Request{URL: u}})
+		var vErr *ValidationError
+		assert.True(t, errors.As(err, &vErr))
+		assert.Equal(t, []FieldError{NewFieldError("query.page", "min", "1")}, vErr.Fields)
+
+		u, _ = url.Parse("http://localhost/?page=2")
+		v, err := parser(&gin.Context{Request: &http.Request{URL: u}})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, v.Interface())
+	})
+
+	t.Run("header validation", func(t *testing.T) {
+		parser, err := (&headerNameParser{}).BuildParser(map[string]string{"header": "X-Token"}, tokenField)
+		assert.Nil(t, err)
+
+		_, err = parser(&gin.Context{Request: &http.Request{Header: http.Header{}}})
+		var vErr *ValidationError
+		assert.True(t, errors.As(err, &vErr))
+		assert.Equal(t, "header.X-Token is required", vErr.Fields[0].Message)
+	})
+
+	t.Run("other error", func(t *testing.T) {
+		err := bindError("body", nil, errors.New("EOF"))
+		assert.Equal(t, "bind body error: EOF", err.(gone.Error).Msg())
+	})
+}
+
+func Test_fieldPath(t *testing.T) {
+	type Item struct {
+		Name string `form:"item_name"`
+	}
+	type Order struct {
+		Items  []*Item         `json:"items"`
+		Groups map[string]Item `json:"groups"`
+		Owner  struct {
+			ID int
+		} `json:"-"`
+	}
+
+	of := reflect.TypeOf(&[]Order{})
+	assert.Equal(t, "items[0].item_name", fieldPath(of, "Order.Items[0].Name"))
+	assert.Equal(t, "groups[a].item_name", fieldPath(of, "Order.Groups[a].Name"))
+	assert.Equal(t, "Owner.ID", fieldPath(of, "Order.Owner.ID"))
+	assert.Equal(t, "items[0].Unknown", fieldPath(of, "Order.Items[0].Unknown"))
+}
+
+func Test_validate_notShared(t *testing.T) {
+	type User struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	// the field name reported by gin's validator is not changed by parser
+	var vErrs validator.ValidationErrors
+	assert.True(t, errors.As(binding.Validator.ValidateStruct(&User{}), &vErrs))
+	assert.Equal(t, "Name", vErrs[0].Field())
+
+	assert.NotSame(t, binding.Validator.Engine(), validate)
+	assert.Error(t, validateValue(&[]User{{Name: "a"}, {}}))
+	assert.Nil(t, validateValue(&[]User{{Name: "a"}}))
+	assert.Nil(t, validateValue((*User)(nil)))
+}
+
+func TestValidationError_MarshalJSON(t *testing.T) {
+	err := NewValidationError(NewFieldError("query.x", "unknown", ""))
+	data, e := err.MarshalJSON()
+	assert.Nil(t, e)
+	assert.JSONEq(t, `{"code":400,"msg":"validation failed: query.x failed on the 'unknown' rule","fields":[{"field":"query.x","rule":"unknown","message":"query.x failed on the 'unknown' rule","key":"validation.unknown"}]}`, string(data))
+}
@@ -15,6 +15,7 @@ type proxy struct {
 	funcInjector gone.FuncInjector                        `gone:"*"`
 	responser    Responser                                `gone:"*"`
 	injector     injector.DelayBindInjector[*gin.Context] `gone:"*"`
+	webSocket    *webSocket                               `gone:"*" option:"allowNil"`
 	stat         bool                                     `gone:"config,server.proxy.stat,default=false"`
 }
 
@@ -50,6 +51,9 @@ func (p *proxy) proxyOne(x HandlerFunc, last bool) gin.HandlerFunc {
 		}
 		values, err := prepare(context)
 		if err != nil {
+			if p.webSocket != nil && p.webSocket.processResults(context, last, []any{err}) {
+				return
+			}
 			p.responser.Failed(context, err)
 			context.Abort()
 			return
@@ -75,5 +79,8 @@ func (p *proxy) resultProcess(values []reflect.Value, context *gin.Context, func
 			results = append(results, arg.Interface())
 		}
 	}
+	if p.webSocket != nil && p.webSocket.processResults(context, last, results) {
+		return
+	}
 	p.responser.ProcessResults(context, context.Writer, last, funcName, results...)
 }
@@ -1,13 +1,17 @@
 package gin
 
 import (
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin/codec"
+	"github.com/gone-io/goner/gin/parser"
 	"io"
 	"net/http"
 	"reflect"
+	"time"
 )
 
 func NewGinResponser() gone.Goner {
@@ -17,9 +21,10 @@ func NewGinResponser() gone.Goner {
 }
 
 type res[T any] struct {
-	Code int    `json:"code"`
-	Msg  string `json:"msg,omitempty"`
-	Data T      `json:"data,omitempty"`
+	XMLName xml.Name `json:"-" yaml:"-" xml:"response"`
+	Code    int      `json:"code" xml:"code"`
+	Msg     string   `json:"msg,omitempty" xml:"msg,omitempty"`
+	Data    T        `json:"data,omitempty" xml:"data,omitempty"`
 }
 
 const InternalServerError = "Internal Server Error"
@@ -35,12 +40,32 @@ type responser struct {
 	wrappedDataFunc           WrappedDataFunc
 	returnWrappedData         bool `gone:"config,server.return.wrapped-data,default=true"`
 	doNotShowInnerErrorDetail bool `gone:"config,server.do-not-show-inner-error-detail=true"`
+
+	// sseHeartbeatInterval SSE心跳注释的发送间隔，对应配置项为：`server.sse.heartbeat-interval`，0表示不发送
+	sseHeartbeatInterval time.Duration `gone:"config,server.sse.heartbeat-interval,default=0s"`
+
+	// sseDrainTimeout 客户端断开后继续接收并丢弃通道数据的最长时间，对应配置项为：`server.sse.drain-timeout`，0表示不接收
+	sseDrainTimeout time.Duration `gone:"config,server.sse.drain-timeout,default=30s"`
+
+	// sseRetry 建议客户端的重连等待时间，对应配置项为：`server.sse.retry`，0表示不发送
+	sseRetry time.Duration `gone:"config,server.sse.retry,default=0s"`
+
+	sseReplayer SseReplayer `gone:"*" option:"allowNil"`
+
+	negotiator codec.Negotiator `gone:"*" option:"allowNil"`
 }
 
 func (r *responser) SetWrappedDataFunc(wrappedDataFunc WrappedDataFunc) {
 	r.wrappedDataFunc = wrappedDataFunc
 }
 
+func (r *responser) GetWrappedDataFunc() WrappedDataFunc {
+	if !r.returnWrappedData {
+		return nil
+	}
+	return r.wrappedDataFunc
+}
+
 func noneWrappedData(ctx XContext, data any, status int) {
 	if data == nil {
 		ctx.String(status, "")
@@ -70,6 +95,7 @@ func noneWrappedData(ctx XContext, data any, status int) {
 }
 
 func (r *responser) Success(ctx XContext, data any) {
+	ctx = r.negotiate(ctx, data)
 	if !r.returnWrappedData {
 		noneWrappedData(ctx, data, http.StatusOK)
 		return
@@ -91,6 +117,7 @@ func (r *responser) innerErrorMsg(iErr gone.InnerError) string {
 }
 
 func (r *responser) Failed(ctx XContext, oErr error) {
+	ctx = r.negotiate(ctx, nil)
 	err := ToError(oErr)
 	if !r.returnWrappedData {
 		var iErr gone.InnerError
@@ -103,6 +130,11 @@ func (r *responser) Failed(ctx XContext, oErr error) {
 			r.Errorf("inner Error: %s(code=%d)\n%s", iErr.Msg(), iErr.Code(), iErr.Stack())
 			return
 		}
+		var vErr *parser.ValidationError
+		if errors.As(err, &vErr) {
+			ctx.JSON(vErr.GetStatusCode(), vErr)
+			return
+		}
 		noneWrappedData(ctx, err, err.GetStatusCode())
 		return
 	}
@@ -155,7 +187,7 @@ func (r *responser) ProcessResults(context XContext, writer gin.ResponseWriter,
 	for _, result := range results {
 		of := reflect.TypeOf(result)
 		if of.Kind() == reflect.Chan {
-			r.processChan(result, writer)
+			r.processChan(context, result, writer)
 			return
 		}
 
@@ -182,51 +214,124 @@ func (r *responser) ProcessResults(context XContext, writer gin.ResponseWriter,
 	}
 }
 
-func (r *responser) processChan(ch any, writer gin.ResponseWriter) {
-	sse := NewSSE(writer)
+func (r *responser) processChan(context XContext, ch any, writer gin.ResponseWriter) {
+	sse := &Sse{Writer: writer}
 	sse.Start()
 
-	of := reflect.ValueOf(ch)
+	if r.sseRetry > 0 {
+		if err := sse.WriteEvent(SseEvent{Retry: r.sseRetry}); err != nil {
+			r.Errorf("write 'retry' error: %v", err)
+		}
+	}
+
+	// case with zero Chan is ignored by reflect.Select
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)},
+		{Dir: reflect.SelectRecv},
+		{Dir: reflect.SelectRecv},
+	}
+	if c, ok := context.(*gin.Context); ok && c.Request != nil {
+		cases[1] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c.Request.Context().Done())}
+		r.replay(c, sse)
+	}
+	if r.sseHeartbeatInterval > 0 {
+		ticker := time.NewTicker(r.sseHeartbeatInterval)
+		defer ticker.Stop()
+		cases[2] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ticker.C)}
+	}
 
 	for {
-		if data, ok := of.Recv(); !ok {
-			err := sse.End()
-			if err != nil {
-				r.Errorf("write 'end' error: %v", err)
+		chosen, data, ok := reflect.Select(cases)
+		switch chosen {
+		case 1:
+			r.Debugf("sse client disconnected")
+			if r.sseDrainTimeout > 0 {
+				go drainChan(cases[0].Chan, r.sseDrainTimeout)
 			}
-			break
-		} else {
-			var err error
-			i := data.Interface()
-
-			switch t := i.(type) {
-			case gone.InnerError:
-				err = sse.Write(map[string]any{
-					"code": t.Code(),
-					"msg":  r.innerErrorMsg(t),
-				})
-			case gone.BusinessError:
-				err = sse.Write(map[string]any{
-					"code": t.Code(),
-					"msg":  t.Msg(),
-					"data": t.Data(),
-				})
-			case gone.Error:
-				err = sse.Write(map[string]any{
-					"code": t.Code(),
-					"msg":  t.Msg(),
-				})
-			case error:
-				err = sse.Write(map[string]any{
-					"code": http.StatusInternalServerError,
-					"msg":  t.Error(),
-				})
-			default:
-				err = sse.Write(i)
+			return
+		case 2:
+			if err := sse.Heartbeat(); err != nil {
+				r.Errorf("write heartbeat error: %v", err)
 			}
+			continue
+		}
+
+		if !ok {
+			err := sse.End()
 			if err != nil {
-				r.Errorf("write data error: %v", err)
+				r.Errorf("write 'end' error: %v", err)
 			}
+			return
+		}
+		err := sse.Write(r.streamData(data.Interface()))
+		if err != nil {
+			r.Errorf("write data error: %v", err)
+		}
+	}
+}
+
+// replay write the events after `Last-Event-ID` provided by SseReplayer
+func (r *responser) replay(ctx *gin.Context, sse SseEventWriter) {
+	lastEventId := ctx.GetHeader(LastEventIdHeader)
+	if r.sseReplayer == nil || lastEventId == "" {
+		return
+	}
+	events, err := r.sseReplayer.Replay(ctx, lastEventId)
+	if err != nil {
+		r.Warnf("replay sse events after %s failed: %v", lastEventId, err)
+		return
+	}
+	for _, event := range events {
+		if err = sse.WriteEvent(event); err != nil {
+			r.Errorf("write replayed event error: %v", err)
+			return
+		}
+	}
+}
+
+// drainChan keep receiving from ch until it is closed or timeout is reached, so that the sender not watching the request
+// context is not blocked after the client disconnected. Senders still blocked after timeout are leaked, so they must
+// close the channel or stop sending when the request context is done.
+func drainChan(ch reflect.Value, timeout time.Duration) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: ch},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(timer.C)},
+	}
+	for {
+		if chosen, _, ok := reflect.Select(cases); chosen == 1 || !ok {
+			return
 		}
 	}
 }
+
+// streamData convert the item received from the channel returned by handler to the data written to stream,
+// errors are converted to `code` and `msg`.
+func (r *responser) streamData(i any) any {
+	switch t := i.(type) {
+	case gone.InnerError:
+		return map[string]any{
+			"code": t.Code(),
+			"msg":  r.innerErrorMsg(t),
+		}
+	case gone.BusinessError:
+		return map[string]any{
+			"code": t.Code(),
+			"msg":  t.Msg(),
+			"data": t.Data(),
+		}
+	case gone.Error:
+		return map[string]any{
+			"code": t.Code(),
+			"msg":  t.Msg(),
+		}
+	case error:
+		return map[string]any{
+			"code": http.StatusInternalServerError,
+			"msg":  t.Error(),
+		}
+	default:
+		return i
+	}
+}
@@ -1,9 +1,15 @@
 package gin
 
 import (
+	"context"
+	"github.com/gin-gonic/gin"
 	mock "github.com/gone-io/gone/v2"
 	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gone-io/gone/v2"
 	"github.com/stretchr/testify/assert"
@@ -124,7 +130,7 @@ func Test_responser_processChan_DifferentTypes(t *testing.T) {
 	mockLogger.EXPECT().Errorf(gomock.Any(), gomock.Any()).AnyTimes()
 
 	// 处理通道
-	r.processChan(ch1, w)
+	r.processChan(nil, ch1, w)
 
 	// 验证响应包含预期的数据
 	assert.Contains(t, response, "event: data")
@@ -141,7 +147,7 @@ func Test_responser_processChan_DifferentTypes(t *testing.T) {
 	close(ch2)
 
 	// 处理通道
-	r.processChan(ch2, w)
+	r.processChan(nil, ch2, w)
 
 	// 验证响应包含预期的数据
 	assert.Contains(t, response, "event: data")
@@ -159,7 +165,7 @@ func Test_responser_processChan_DifferentTypes(t *testing.T) {
 	close(ch3)
 
 	// 处理通道
-	r.processChan(ch3, w)
+	r.processChan(nil, ch3, w)
 
 	// 验证响应包含预期的数据
 	assert.Contains(t, response, "event: data")
@@ -176,7 +182,7 @@ func Test_responser_processChan_DifferentTypes(t *testing.T) {
 	close(ch4)
 
 	// 处理通道
-	r.processChan(ch4, w)
+	r.processChan(nil, ch4, w)
 
 	// 验证响应包含预期的数据
 	assert.Contains(t, response, "event: data")
@@ -193,7 +199,7 @@ func Test_responser_processChan_DifferentTypes(t *testing.T) {
 	close(ch5)
 
 	// 处理通道
-	r.processChan(ch5, w)
+	r.processChan(nil, ch5, w)
 
 	// 验证响应包含预期的数据
 	assert.Contains(t, response, "event: data")
@@ -235,5 +241,137 @@ func Test_responser_processChan_WriteError(t *testing.T) {
 	mockLogger.EXPECT().Errorf("write 'end' error: %v", http.ErrBodyNotAllowed).Times(1)
 
 	// 处理通道
-	r.processChan(ch, w)
+	r.processChan(nil, ch, w)
+}
+
+type testReplayer struct {
+	err error
+}
+
+func (r *testReplayer) Replay(_ *gin.Context, lastEventId string) ([]SseEvent, error) {
+	return []SseEvent{{Id: lastEventId + "-1", Data: "replayed"}}, r.err
+}
+
+// 测试 responser.processChan 方法 - 心跳、重连提示、断线检测与 Last-Event-ID 重放
+func Test_responser_processChan_Stream(t *testing.T) {
+	newWriter := func(controller *gomock.Controller, response *string) gin.ResponseWriter {
+		w := NewMockResponseWriter(controller)
+		w.EXPECT().Header().Return(http.Header{}).AnyTimes()
+		w.EXPECT().Flush().AnyTimes()
+		w.EXPECT().CloseNotify().AnyTimes()
+		w.EXPECT().WriteString(gomock.Any()).DoAndReturn(func(data string) (int, error) {
+			*response += data
+			return len(data), nil
+		}).AnyTimes()
+		return w
+	}
+
+	newContext := func(lastEventId string) (*gin.Context, context.CancelFunc) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c, cancel := context.WithCancel(context.Background())
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(c)
+		if lastEventId != "" {
+			ctx.Request.Header.Set(LastEventIdHeader, lastEventId)
+		}
+		return ctx, cancel
+	}
+
+	t.Run("heartbeat, retry and replay", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		var response string
+		r := &responser{
+			sseHeartbeatInterval: 10 * time.Millisecond,
+			sseRetry:             time.Second,
+			sseReplayer:          &testReplayer{},
+		}
+		ctx, cancel := newContext("5")
+		defer cancel()
+
+		ch := make(chan SseEvent)
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			ch <- SseEvent{Id: "6", Event: "delta", Data: "new"}
+			close(ch)
+		}()
+		r.processChan(ctx, ch, newWriter(controller, &response))
+
+		assert.True(t, strings.HasPrefix(response, "retry: 1000\n\nid: 5-1\nevent: data\ndata: replayed\n\n"))
+		assert.Contains(t, response, ": heartbeat\n\n")
+		assert.Contains(t, response, "id: 6\nevent: delta\ndata: new\n\n")
+		assert.True(t, strings.HasSuffix(response, "event: done\ndata: [DONE]\n\n"))
+	})
+
+	t.Run("replay error", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		logger := mock.NewMockLogger(controller)
+		logger.EXPECT().Warnf(gomock.Any(), "5", assert.AnError)
+
+		var response string
+		r := &responser{Logger: logger, sseReplayer: &testReplayer{err: assert.AnError}}
+		ctx, cancel := newContext("5")
+		defer cancel()
+
+		ch := make(chan string)
+		close(ch)
+		r.processChan(ctx, ch, newWriter(controller, &response))
+		assert.NotContains(t, response, "replayed")
+	})
+
+	t.Run("client disconnected", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		logger := mock.NewMockLogger(controller)
+		logger.EXPECT().Debugf(gomock.Any())
+
+		var response string
+		r := &responser{Logger: logger, sseDrainTimeout: time.Second}
+		ctx, cancel := newContext("")
+
+		ch := make(chan string)
+		sent := make(chan struct{})
+		go func() {
+			cancel()
+			time.Sleep(10 * time.Millisecond)
+			// the sender is not blocked after the client disconnected
+			ch <- "late"
+			close(ch)
+			close(sent)
+		}()
+		r.processChan(ctx, ch, newWriter(controller, &response))
+
+		select {
+		case <-sent:
+		case <-time.After(time.Second):
+			t.Fatal("sender is blocked")
+		}
+		assert.NotContains(t, response, "event: done")
+	})
+}
+
+func Test_drainChan(t *testing.T) {
+	ch := make(chan string)
+	done := make(chan struct{})
+	go func() {
+		drainChan(reflect.ValueOf(ch), 20*time.Millisecond)
+		close(done)
+	}()
+	ch <- "a"
+	ch <- "b"
+
+	// the channel never closed is not drained forever
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("drainChan is not returned after timeout")
+	}
+	select {
+	case ch <- "c":
+		t.Fatal("channel is drained after timeout")
+	default:
+	}
 }
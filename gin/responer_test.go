@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/gin/parser"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
@@ -224,6 +225,11 @@ func Test_responser_Failed_NonWrappedData(t *testing.T) {
 	pErr := gone.NewParameterError("parameter error")
 	mockContext.EXPECT().String(http.StatusBadRequest, "GoneError(code=400); parameter error").Times(1)
 	r.Failed(mockContext, pErr)
+
+	// 测试参数校验错误
+	vErr := NewValidationError(parser.NewFieldError("query.page", "required", ""))
+	mockContext.EXPECT().JSON(http.StatusBadRequest, vErr).Times(1)
+	r.Failed(mockContext, vErr)
 }
 
 // 测试 ProcessResults 方法 - 处理错误
@@ -448,7 +454,7 @@ func Test_responser_processChan(t *testing.T) {
 	w1.EXPECT().CloseNotify().Times(1)
 
 	// 处理通道
-	r.processChan(ch1, w1)
+	r.processChan(nil, ch1, w1)
 
 	// 验证响应
 	assert.Contains(t, response1, "event: data")
@@ -481,7 +487,7 @@ func Test_responser_processChan(t *testing.T) {
 	mockLogger.EXPECT().Errorf(gomock.Any(), gomock.Any()).AnyTimes()
 
 	// 处理通道
-	r.processChan(ch2, w2)
+	r.processChan(nil, ch2, w2)
 
 	// 验证响应
 	assert.Contains(t, response2, "event: data")
@@ -0,0 +1,493 @@
+package gin
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"github.com/gone-io/goner/gin/auth"
+)
+
+// CacheOption options of the route cached by ResponseCache.Cache
+type CacheOption func(route *cacheRoute)
+
+// CacheTags tag the cached responses, so that they can be invalidated by ResponseCache.Invalidate;
+// `{name}` in tag is replaced by the path parameter, eg: `item:{id}`.
+func CacheTags(tags ...string) CacheOption {
+	return func(route *cacheRoute) {
+		route.tags = append(route.tags, tags...)
+	}
+}
+
+// CacheVary the request headers which make responses different, in addition to `server.cache.vary`
+func CacheVary(headers ...string) CacheOption {
+	return func(route *cacheRoute) {
+		route.vary = append(route.vary, headers...)
+	}
+}
+
+// CacheStaleWhileRevalidate serve the stale response for d after it expires, while refreshing it in background
+func CacheStaleWhileRevalidate(d time.Duration) CacheOption {
+	return func(route *cacheRoute) {
+		route.staleWhileRevalidate = d
+	}
+}
+
+// CacheAuthorized cache the responses of authenticated requests too, they are kept apart by the principal subject,
+// or by the `Authorization` header when no principal is resolved; without it, authenticated requests bypass the cache.
+func CacheAuthorized() CacheOption {
+	return func(route *cacheRoute) {
+		route.authorized = true
+	}
+}
+
+// AddCacheTags tag the response of current request in handler, eg: tag a list by the ids of items in it
+func AddCacheTags(ctx *gin.Context, tags ...string) {
+	ctx.Set(cacheTagsKey, append(ctx.GetStringSlice(cacheTagsKey), tags...))
+}
+
+const (
+	cacheTagsKey   = "gone-gin-cache-tags"
+	cacheKeyPrefix = "gone-gin-cache#"
+
+	CacheHit   = "HIT"
+	CacheMiss  = "MISS"
+	CacheStale = "STALE"
+)
+
+type cacheRoute struct {
+	ttl                  time.Duration
+	staleWhileRevalidate time.Duration
+	tags                 []string
+	vary                 []string
+	authorized           bool
+}
+
+// revalidateKey marks the request refreshing a stale response in background
+type revalidateKey struct{}
+
+type responseCache struct {
+	gone.Flag
+	logger  gone.Logger          `gone:"*"`
+	store   g.ResponseCacheStore `gone:"*" option:"allowNil"`
+	handler http.Handler         `gone:"*" option:"allowNil"`
+
+	// disable 关闭响应缓存，对应配置项为：`server.cache.disable`
+	disable bool `gone:"config,server.cache.disable,default=false"`
+
+	// maxBodySize 可缓存的最大响应体，对应配置项为：`server.cache.max-body-size`
+	maxBodySize int `gone:"config,server.cache.max-body-size,default=1048576"`
+
+	// vary 参与缓存key计算的请求头，多个以逗号分隔，对应配置项为：`server.cache.vary`
+	vary string `gone:"config,server.cache.vary,default=Accept"`
+
+	// staleWhileRevalidate 缓存过期后继续使用旧响应并在后台刷新的时长，对应配置项为：`server.cache.stale-while-revalidate`
+	staleWhileRevalidate time.Duration `gone:"config,server.cache.stale-while-revalidate,default=0s"`
+
+	// statusHeader 标识缓存命中情况的响应头，对应配置项为：`server.cache.status-header`；为空时不设置
+	statusHeader string `gone:"config,server.cache.status-header,default=X-Cache"`
+
+	// memoryMaxEntries 未加载`g.ResponseCacheStore`时，内存LRU缓存的最大条目数，对应配置项为：`server.cache.memory.max-entries`
+	memoryMaxEntries int `gone:"config,server.cache.memory.max-entries,default=10000"`
+
+	revalidating sync.Map
+	now          func() time.Time
+}
+
+func (c *responseCache) GonerName() string {
+	return IdGoneGinResponseCache
+}
+
+func (c *responseCache) Init() {
+	if c.now == nil {
+		c.now = time.Now
+	}
+	if c.store == nil {
+		c.store = newMemoryCacheStore(c.memoryMaxEntries)
+	}
+}
+
+func (c *responseCache) Cache(ttl time.Duration, options ...CacheOption) gin.HandlerFunc {
+	route := &cacheRoute{
+		ttl:                  ttl,
+		staleWhileRevalidate: c.staleWhileRevalidate,
+		vary:                 splitList(c.vary),
+	}
+	for _, option := range options {
+		option(route)
+	}
+	return func(ctx *gin.Context) {
+		c.process(ctx, route)
+	}
+}
+
+func (c *responseCache) Invalidate(ctx context.Context, tags ...string) error {
+	return c.store.InvalidateTags(ctx, tags...)
+}
+
+func (c *responseCache) process(ctx *gin.Context, route *cacheRoute) {
+	method := ctx.Request.Method
+	if c.disable || method != http.MethodGet && method != http.MethodHead {
+		return
+	}
+
+	principal, authenticated := c.principal(ctx)
+	if authenticated && !route.authorized {
+		return
+	}
+
+	key := c.key(ctx, route, principal)
+	revalidating := ctx.Request.Context().Value(revalidateKey{}) != nil
+	if !revalidating && !hasCacheDirective(ctx.GetHeader("Cache-Control"), "no-cache") {
+		response, err := c.store.Get(ctx, key)
+		if err != nil {
+			c.logger.Warnf("get cached response failed: %v", err)
+		}
+		if response != nil {
+			age := c.now().Sub(response.StoredAt)
+			if age <= response.TTL {
+				c.serve(ctx, response, age, CacheHit)
+				return
+			}
+			if age <= response.TTL+route.staleWhileRevalidate {
+				c.serve(ctx, response, age, CacheStale)
+				c.revalidate(ctx, key)
+				return
+			}
+		}
+	}
+	if method == http.MethodHead {
+		return
+	}
+	c.capture(ctx, route, key)
+}
+
+// principal identify who the request is authenticated as: the principal subject, or the `Authorization` header
+func (c *responseCache) principal(ctx *gin.Context) (string, bool) {
+	if p, ok := auth.FromContext(ctx); ok {
+		return "principal: " + p.Subject, true
+	}
+	if authorization := ctx.GetHeader("Authorization"); authorization != "" {
+		return "authorization: " + authorization, true
+	}
+	return "", false
+}
+
+// key of response: method, path, query, the principal, and the request headers in vary
+func (c *responseCache) key(ctx *gin.Context, route *cacheRoute, principal string) string {
+	h := sha256.New()
+	h.Write([]byte(http.MethodGet + " " + ctx.Request.URL.Path + "?" + ctx.Request.URL.Query().Encode()))
+	if principal != "" {
+		h.Write([]byte("\n" + principal))
+	}
+	for _, name := range route.vary {
+		h.Write([]byte("\n" + http.CanonicalHeaderKey(name) + ": " + ctx.GetHeader(name)))
+	}
+	return cacheKeyPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *responseCache) serve(ctx *gin.Context, response *g.CachedResponse, age time.Duration, status string) {
+	header := ctx.Writer.Header()
+	for k, v := range response.Header {
+		header[k] = slices.Clone(v)
+	}
+	header.Set("Age", strconv.Itoa(int(age.Seconds())))
+	if c.statusHeader != "" {
+		header.Set(c.statusHeader, status)
+	}
+
+	if etag := header.Get("ETag"); etag != "" && etagMatch(ctx.GetHeader("If-None-Match"), etag) {
+		ctx.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+
+	ctx.Status(response.Status)
+	if ctx.Request.Method == http.MethodHead {
+		ctx.Writer.WriteHeaderNow()
+	} else if _, err := ctx.Writer.Write(response.Body); err != nil {
+		c.logger.Warnf("write cached response failed: %v", err)
+	}
+	ctx.Abort()
+}
+
+// revalidate refresh the stale response in background by serving a copy of the request
+func (c *responseCache) revalidate(ctx *gin.Context, key string) {
+	if c.handler == nil {
+		return
+	}
+	if _, loaded := c.revalidating.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+
+	req := ctx.Request.Clone(context.WithValue(context.Background(), revalidateKey{}, true))
+	go func() {
+		defer c.revalidating.Delete(key)
+		defer func() {
+			if r := recover(); r != nil {
+				c.logger.Errorf("revalidate cached response panic: %v", r)
+			}
+		}()
+		c.handler.ServeHTTP(&discardResponseWriter{header: http.Header{}}, req)
+	}()
+}
+
+// capture the response written by the following handlers, and store it if cacheable
+func (c *responseCache) capture(ctx *gin.Context, route *cacheRoute, key string) {
+	if c.statusHeader != "" {
+		ctx.Header(c.statusHeader, CacheMiss)
+	}
+	before := ctx.Writer.Header().Clone()
+	w := &cacheWriter{ResponseWriter: ctx.Writer, limit: c.maxBodySize}
+	ctx.Writer = w
+	ctx.Next()
+	cacheable := w.cacheable() && !hasCacheDirective(ctx.GetHeader("Cache-Control"), "no-store")
+	if cacheable && w.Header().Get("ETag") == "" {
+		sum := sha256.Sum256(w.body.Bytes())
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	}
+	w.flush()
+	ctx.Writer = w.ResponseWriter
+
+	if !cacheable {
+		return
+	}
+
+	// only the headers set by the handlers of route are stored, those set by middlewares before are not
+	header := changedHeader(before, w.Header())
+
+	response := &g.CachedResponse{
+		Status:   w.Status(),
+		Header:   header,
+		Body:     w.body.Bytes(),
+		StoredAt: c.now(),
+		TTL:      route.ttl,
+		Tags:     c.tags(ctx, route),
+	}
+	if err := c.store.Set(ctx, key, response, route.ttl+route.staleWhileRevalidate); err != nil {
+		c.logger.Warnf("store response in cache failed: %v", err)
+	}
+}
+
+func (c *responseCache) tags(ctx *gin.Context, route *cacheRoute) []string {
+	tags := make([]string, 0, len(route.tags))
+	for _, tag := range route.tags {
+		for _, p := range ctx.Params {
+			tag = strings.ReplaceAll(tag, "{"+p.Key+"}", p.Value)
+		}
+		tags = append(tags, tag)
+	}
+	return append(tags, ctx.GetStringSlice(cacheTagsKey)...)
+}
+
+// cacheWriter buffer the response body, so that the ETag can be sent with it; the buffered body is written through
+// and buffering stops when the body exceeds the limit or the handler flushes, then the response is not cacheable.
+type cacheWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	limit     int
+	streaming bool
+}
+
+func (w *cacheWriter) Write(b []byte) (int, error) {
+	if w.buffer(b) {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	if w.buffer([]byte(s)) {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *cacheWriter) Flush() {
+	w.flush()
+	w.ResponseWriter.Flush()
+}
+
+// buffer the body, return false when the body should be written through
+func (w *cacheWriter) buffer(b []byte) bool {
+	if w.streaming {
+		return false
+	}
+	if w.body.Len()+len(b) > w.limit {
+		w.flush()
+		return false
+	}
+	w.body.Write(b)
+	return true
+}
+
+// flush write the buffered body through and stop buffering
+func (w *cacheWriter) flush() {
+	if w.streaming {
+		return
+	}
+	w.streaming = true
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
+
+func (w *cacheWriter) cacheable() bool {
+	header := w.Header()
+	return w.Status() == http.StatusOK &&
+		!w.streaming &&
+		header.Get("Set-Cookie") == "" &&
+		!strings.HasPrefix(header.Get("Content-Type"), "text/event-stream") &&
+		!hasCacheDirective(header.Get("Cache-Control"), "no-store") &&
+		!hasCacheDirective(header.Get("Cache-Control"), "private")
+}
+
+// changedHeader return the headers which are set or changed in after
+func changedHeader(before, after http.Header) map[string][]string {
+	header := make(map[string][]string)
+	for k, v := range after {
+		if !slices.Equal(before[k], v) {
+			header[k] = slices.Clone(v)
+		}
+	}
+	return header
+}
+
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(int) {}
+
+func hasCacheDirective(cacheControl, directive string) bool {
+	for _, d := range strings.Split(cacheControl, ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(d), "=")
+		if strings.EqualFold(name, directive) {
+			return true
+		}
+	}
+	return false
+}
+
+// etagMatch weak comparison of `If-None-Match`
+func etagMatch(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// memoryCacheStore in memory LRU implementation of g.ResponseCacheStore, which is used when no g.ResponseCacheStore is loaded.
+type memoryCacheStore struct {
+	lock       sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+	tags       map[string]map[string]struct{}
+	now        func() time.Time
+}
+
+type memoryCacheItem struct {
+	key      string
+	response *g.CachedResponse
+	expireAt time.Time
+}
+
+func newMemoryCacheStore(maxEntries int) *memoryCacheStore {
+	return &memoryCacheStore{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		tags:       make(map[string]map[string]struct{}),
+		now:        time.Now,
+	}
+}
+
+func (s *memoryCacheStore) Get(_ context.Context, key string) (*g.CachedResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	e, ok := s.items[key]
+	if !ok {
+		return nil, nil
+	}
+	item := e.Value.(*memoryCacheItem)
+	if !s.now().Before(item.expireAt) {
+		s.remove(e)
+		return nil, nil
+	}
+	s.ll.MoveToFront(e)
+	return item.response, nil
+}
+
+func (s *memoryCacheStore) Set(_ context.Context, key string, response *g.CachedResponse, ttl time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if e, ok := s.items[key]; ok {
+		s.remove(e)
+	}
+	s.items[key] = s.ll.PushFront(&memoryCacheItem{key: key, response: response, expireAt: s.now().Add(ttl)})
+	for _, tag := range response.Tags {
+		if s.tags[tag] == nil {
+			s.tags[tag] = make(map[string]struct{})
+		}
+		s.tags[tag][key] = struct{}{}
+	}
+
+	for s.maxEntries > 0 && s.ll.Len() > s.maxEntries {
+		s.remove(s.ll.Back())
+	}
+	return nil
+}
+
+func (s *memoryCacheStore) InvalidateTags(_ context.Context, tags ...string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, tag := range tags {
+		for key := range s.tags[tag] {
+			if e, ok := s.items[key]; ok {
+				s.remove(e)
+			}
+		}
+		delete(s.tags, tag)
+	}
+	return nil
+}
+
+func (s *memoryCacheStore) remove(e *list.Element) {
+	item := s.ll.Remove(e).(*memoryCacheItem)
+	delete(s.items, item.key)
+	for _, tag := range item.response.Tags {
+		delete(s.tags[tag], item.key)
+		if len(s.tags[tag]) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package gin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	gMock "github.com/gone-io/goner/g/mock"
+	"github.com/gone-io/goner/gin/auth"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newResponseCache(t *testing.T, store g.ResponseCacheStore) (*responseCache, *fakeClock) {
+	controller := gomock.NewController(t)
+	logger := gone.NewMockLogger(controller)
+	logger.EXPECT().Warnf(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Errorf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := &responseCache{
+		logger:           logger,
+		store:            store,
+		maxBodySize:      1024,
+		vary:             "Accept",
+		statusHeader:     "X-Cache",
+		memoryMaxEntries: 100,
+		now:              clock.Now,
+	}
+	c.Init()
+	if s, ok := c.store.(*memoryCacheStore); ok {
+		s.now = clock.Now
+	}
+	return c, clock
+}
+
+func serveCached(engine http.Handler, method, target string, header map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func Test_responseCache_hitAndNotModified(t *testing.T) {
+	c, clock := newResponseCache(t, nil)
+	var calls int32
+	engine := gin.New()
+	engine.Use(func(ctx *gin.Context) { ctx.Header("X-Trace", "trace") })
+	handler := func(ctx *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		ctx.Header("X-Item", ctx.Param("id"))
+		ctx.String(http.StatusOK, "item "+ctx.Param("id"))
+	}
+	engine.GET("/items/:id", c.Cache(time.Minute), handler)
+	engine.HEAD("/items/:id", c.Cache(time.Minute), handler)
+
+	w := serveCached(engine, http.MethodGet, "/items/1?b=2&a=1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, CacheMiss, w.Header().Get("X-Cache"))
+	missEtag := w.Header().Get("ETag")
+	assert.NotEmpty(t, missEtag)
+
+	clock.now = clock.now.Add(10 * time.Second)
+	w = serveCached(engine, http.MethodGet, "/items/1?a=1&b=2", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "item 1", w.Body.String())
+	assert.Equal(t, CacheHit, w.Header().Get("X-Cache"))
+	assert.Equal(t, "10", w.Header().Get("Age"))
+	assert.Equal(t, "1", w.Header().Get("X-Item"))
+	assert.Equal(t, "trace", w.Header().Get("X-Trace"))
+	assert.Equal(t, []string{"trace"}, w.Header().Values("X-Trace"))
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, missEtag, etag)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	w = serveCached(engine, http.MethodGet, "/items/1?a=1&b=2", map[string]string{"If-None-Match": "W/" + etag})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w = serveCached(engine, http.MethodHead, "/items/1?a=1&b=2", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, CacheHit, w.Header().Get("X-Cache"))
+	assert.Empty(t, w.Body.String())
+
+	// different query or vary header
+	serveCached(engine, http.MethodGet, "/items/1", nil)
+	serveCached(engine, http.MethodGet, "/items/1?a=1&b=2", map[string]string{"Accept": "application/xml"})
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// request with no-cache is not served from cache
+	w = serveCached(engine, http.MethodGet, "/items/1", map[string]string{"Cache-Control": "no-cache"})
+	assert.Equal(t, CacheMiss, w.Header().Get("X-Cache"))
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+
+	// expired
+	clock.now = clock.now.Add(time.Minute)
+	w = serveCached(engine, http.MethodGet, "/items/1?a=1&b=2", nil)
+	assert.Equal(t, CacheMiss, w.Header().Get("X-Cache"))
+	assert.Equal(t, int32(5), atomic.LoadInt32(&calls))
+}
+
+func Test_responseCache_notCacheable(t *testing.T) {
+	c, _ := newResponseCache(t, nil)
+	var calls int32
+	engine := gin.New()
+	handler := func(ctx *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		switch ctx.Query("case") {
+		case "cookie":
+			ctx.SetCookie("session", "1", 0, "/", "", false, true)
+		case "no-store":
+			ctx.Header("Cache-Control", "no-store")
+		case "private":
+			ctx.Header("Cache-Control", "private, max-age=60")
+		case "error":
+			ctx.String(http.StatusInternalServerError, "error")
+			return
+		case "large":
+			ctx.String(http.StatusOK, string(make([]byte, 2048)))
+			return
+		case "flush":
+			ctx.Writer.WriteString("partial ")
+			ctx.Writer.Flush()
+		}
+		ctx.String(http.StatusOK, "ok")
+	}
+	engine.GET("/data", c.Cache(time.Minute), handler)
+	engine.POST("/data", c.Cache(time.Minute), handler)
+
+	for _, target := range []string{"/data?case=cookie", "/data?case=no-store", "/data?case=private", "/data?case=error", "/data?case=large", "/data?case=flush"} {
+		atomic.StoreInt32(&calls, 0)
+		serveCached(engine, http.MethodGet, target, nil)
+		w := serveCached(engine, http.MethodGet, target, nil)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls), target)
+		assert.Empty(t, w.Header().Get("ETag"), target)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	serveCached(engine, http.MethodPost, "/data", nil)
+	w := serveCached(engine, http.MethodPost, "/data", nil)
+	assert.Empty(t, w.Header().Get("X-Cache"))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// HEAD is not stored
+	atomic.StoreInt32(&calls, 0)
+	engine.HEAD("/data", c.Cache(time.Minute), handler)
+	serveCached(engine, http.MethodHead, "/data?case=head", nil)
+	serveCached(engine, http.MethodHead, "/data?case=head", nil)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func Test_responseCache_authorized(t *testing.T) {
+	c, _ := newResponseCache(t, nil)
+	var calls int32
+	engine := gin.New()
+	engine.Use(func(ctx *gin.Context) {
+		if subject := ctx.GetHeader("X-Subject"); subject != "" {
+			auth.SetPrincipal(ctx, &auth.Principal{Subject: subject})
+		}
+	})
+	handler := func(ctx *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		ctx.String(http.StatusOK, "hello "+ctx.GetHeader("X-Subject")+ctx.GetHeader("Authorization"))
+	}
+	engine.GET("/public", c.Cache(time.Minute), handler)
+	engine.GET("/me", c.Cache(time.Minute, CacheAuthorized()), handler)
+
+	// authenticated requests bypass the cache by default
+	for _, header := range []map[string]string{{"Authorization": "Bearer a"}, {"X-Subject": "a"}} {
+		atomic.StoreInt32(&calls, 0)
+		serveCached(engine, http.MethodGet, "/public", header)
+		w := serveCached(engine, http.MethodGet, "/public", header)
+		assert.Empty(t, w.Header().Get("X-Cache"))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	}
+
+	// cached apart by principal when the route opts in
+	atomic.StoreInt32(&calls, 0)
+	for _, header := range []map[string]string{{"X-Subject": "a"}, {"X-Subject": "b"}, {"Authorization": "Bearer a"}, {"Authorization": "Bearer b"}, nil} {
+		assert.Equal(t, CacheMiss, serveCached(engine, http.MethodGet, "/me", header).Header().Get("X-Cache"))
+	}
+	w := serveCached(engine, http.MethodGet, "/me", map[string]string{"X-Subject": "b"})
+	assert.Equal(t, CacheHit, w.Header().Get("X-Cache"))
+	assert.Equal(t, "hello b", w.Body.String())
+	w = serveCached(engine, http.MethodGet, "/me", map[string]string{"Authorization": "Bearer a"})
+	assert.Equal(t, CacheHit, w.Header().Get("X-Cache"))
+	assert.Equal(t, "hello Bearer a", w.Body.String())
+	assert.Equal(t, int32(5), atomic.LoadInt32(&calls))
+}
+
+func Test_responseCache_Invalidate(t *testing.T) {
+	c, _ := newResponseCache(t, nil)
+	var calls int32
+	engine := gin.New()
+	engine.GET("/items/:id", c.Cache(time.Minute, CacheTags("item:{id}")), func(ctx *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		ctx.String(http.StatusOK, ctx.Param("id"))
+	})
+	engine.GET("/items", c.Cache(time.Minute, CacheTags("items")), func(ctx *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		AddCacheTags(ctx, "item:1", "item:2")
+		ctx.String(http.StatusOK, "1,2")
+	})
+
+	for i := 0; i < 2; i++ {
+		serveCached(engine, http.MethodGet, "/items/1", nil)
+		serveCached(engine, http.MethodGet, "/items/2", nil)
+		serveCached(engine, http.MethodGet, "/items", nil)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	assert.Nil(t, c.Invalidate(context.Background(), "item:1"))
+	assert.Equal(t, CacheMiss, serveCached(engine, http.MethodGet, "/items/1", nil).Header().Get("X-Cache"))
+	assert.Equal(t, CacheHit, serveCached(engine, http.MethodGet, "/items/2", nil).Header().Get("X-Cache"))
+	assert.Equal(t, CacheMiss, serveCached(engine, http.MethodGet, "/items", nil).Header().Get("X-Cache"))
+}
+
+func Test_responseCache_staleWhileRevalidate(t *testing.T) {
+	c, clock := newResponseCache(t, nil)
+	var version int32
+	engine := gin.New()
+	engine.GET("/data", c.Cache(time.Minute, CacheStaleWhileRevalidate(time.Minute), CacheVary("X-Tenant")), func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "v%d", atomic.AddInt32(&version, 1))
+	})
+	c.handler = engine
+
+	header := map[string]string{"X-Tenant": "a"}
+	assert.Equal(t, "v1", serveCached(engine, http.MethodGet, "/data", header).Body.String())
+
+	clock.now = clock.now.Add(90 * time.Second)
+	w := serveCached(engine, http.MethodGet, "/data", header)
+	assert.Equal(t, CacheStale, w.Header().Get("X-Cache"))
+	assert.Equal(t, "v1", w.Body.String())
+
+	assert.Eventually(t, func() bool {
+		w := serveCached(engine, http.MethodGet, "/data", header)
+		return w.Header().Get("X-Cache") == CacheHit && w.Body.String() == "v2"
+	}, time.Second, 10*time.Millisecond)
+
+	// too stale
+	clock.now = clock.now.Add(3 * time.Minute)
+	w = serveCached(engine, http.MethodGet, "/data", header)
+	assert.Equal(t, CacheMiss, w.Header().Get("X-Cache"))
+	assert.Equal(t, "v3", w.Body.String())
+}
+
+func Test_responseCache_storeError(t *testing.T) {
+	controller := gomock.NewController(t)
+	store := gMock.NewMockResponseCacheStore(controller)
+	store.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, errors.New("get error"))
+	store.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), 2*time.Minute).
+		DoAndReturn(func(_ context.Context, _ string, response *g.CachedResponse, _ time.Duration) error {
+			assert.Equal(t, []byte("ok"), response.Body)
+			assert.Equal(t, time.Minute, response.TTL)
+			return errors.New("set error")
+		})
+	store.EXPECT().InvalidateTags(gomock.Any(), "a").Return(nil)
+
+	c, _ := newResponseCache(t, store)
+	c.staleWhileRevalidate = time.Minute
+	engine := gin.New()
+	engine.GET("/data", c.Cache(time.Minute), func(ctx *gin.Context) { ctx.String(http.StatusOK, "ok") })
+
+	w := serveCached(engine, http.MethodGet, "/data", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ok", w.Body.String())
+	assert.Nil(t, c.Invalidate(context.Background(), "a"))
+}
+
+func Test_memoryCacheStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	s := newMemoryCacheStore(2)
+	s.now = func() time.Time { return now }
+
+	response := func(tags ...string) *g.CachedResponse {
+		return &g.CachedResponse{Status: http.StatusOK, Tags: tags}
+	}
+	assert.Nil(t, s.Set(ctx, "a", response("x"), time.Minute))
+	assert.Nil(t, s.Set(ctx, "b", response("x", "y"), time.Second))
+	_, _ = s.Get(ctx, "a")
+	assert.Nil(t, s.Set(ctx, "c", response("y"), time.Minute))
+
+	// b is the least recently used
+	got, err := s.Get(ctx, "b")
+	assert.Nil(t, err)
+	assert.Nil(t, got)
+	assert.Equal(t, map[string]map[string]struct{}{"x": {"a": {}}, "y": {"c": {}}}, s.tags)
+
+	assert.Nil(t, s.Set(ctx, "c", response("z"), time.Second))
+	assert.Equal(t, map[string]map[string]struct{}{"x": {"a": {}}, "z": {"c": {}}}, s.tags)
+
+	now = now.Add(time.Second)
+	got, _ = s.Get(ctx, "c")
+	assert.Nil(t, got)
+	got, _ = s.Get(ctx, "a")
+	assert.NotNil(t, got)
+
+	assert.Nil(t, s.InvalidateTags(ctx, "x", "unknown"))
+	got, _ = s.Get(ctx, "a")
+	assert.Nil(t, got)
+	assert.Empty(t, s.items)
+	assert.Empty(t, s.tags)
+}
+
+func Test_etagMatch(t *testing.T) {
+	assert.False(t, etagMatch("", `"a"`))
+	assert.True(t, etagMatch("*", `"a"`))
+	assert.True(t, etagMatch(`"b", W/"a"`, `"a"`))
+	assert.True(t, etagMatch(`"a"`, `W/"a"`))
+	assert.False(t, etagMatch(`"b"`, `"a"`))
+}
+
+type cacheCtr struct {
+	gone.Flag
+	r     IRouter       `gone:"*"`
+	cache ResponseCache `gone:"*"`
+	calls int32
+}
+
+func (c *cacheCtr) Mount() MountError {
+	c.r.GET("/cached", c.cache.Cache(time.Minute), func() string {
+		atomic.AddInt32(&c.calls, 1)
+		return "ok"
+	})
+	return nil
+}
+
+func Test_responseCache_withLoad(t *testing.T) {
+	t.Setenv("GONE_SERVER_PORT", "0")
+
+	ctr := &cacheCtr{}
+	gone.
+		NewApp(Load).
+		Load(ctr).
+		Run(func(s *server) {
+			for i := 0; i < 2; i++ {
+				res, err := http.Get("http://" + s.getAddress() + "/cached")
+				assert.Nil(t, err)
+				_ = res.Body.Close()
+				assert.Equal(t, http.StatusOK, res.StatusCode)
+			}
+			assert.Equal(t, int32(1), atomic.LoadInt32(&ctr.calls))
+		})
+}
@@ -0,0 +1,89 @@
+package gin
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routePolicies policies applied to part of routes instead of the global one, which are configured by rules or
+// registered for a RouteGroup. When more than one policy matches a request, the one with the longest pattern wins.
+type routePolicies[T any] struct {
+	lock sync.RWMutex
+	list []routePolicy[T]
+}
+
+type routePolicy[T any] struct {
+	pattern string
+	policy  T
+}
+
+func (p *routePolicies[T]) add(pattern string, policy T) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.list = append(p.list, routePolicy[T]{pattern: pattern, policy: policy})
+	sort.SliceStable(p.list, func(i, j int) bool {
+		return len(p.list[i].pattern) > len(p.list[j].pattern)
+	})
+}
+
+func (p *routePolicies[T]) match(ctx *gin.Context) (policy T, ok bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	for _, item := range p.list {
+		if matchRoute(item.pattern, ctx) {
+			return item.policy, true
+		}
+	}
+	return
+}
+
+// matchRoute match request with route pattern: pattern ends with `*` matches request path by prefix; other patterns
+// are compared with `gin.Context.FullPath()`, or matched with request path segment by segment if no route matched,
+// eg: the preflight request of CORS.
+func matchRoute(pattern string, ctx *gin.Context) bool {
+	if strings.HasSuffix(pattern, "*") {
+		prefix := strings.TrimSuffix(pattern, "*")
+		// `/group/*` matches the root route of group `/group` too
+		return strings.HasPrefix(ctx.Request.URL.Path, prefix) || ctx.Request.URL.Path+"/" == prefix
+	}
+	if fullPath := ctx.FullPath(); fullPath != "" {
+		return fullPath == pattern
+	}
+
+	patterns := strings.Split(strings.Trim(pattern, "/"), "/")
+	segments := strings.Split(strings.Trim(ctx.Request.URL.Path, "/"), "/")
+	for i, p := range patterns {
+		if strings.HasPrefix(p, "*") {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if !strings.HasPrefix(p, ":") && p != segments[i] {
+			return false
+		}
+	}
+	return len(patterns) == len(segments)
+}
+
+// groupPattern the pattern matching all routes of the group
+func groupPattern(group RouteGroup) string {
+	base := "/"
+	if r, ok := group.(*router); ok {
+		base = r.basePath()
+	}
+	return joinPaths(base, "/") + "*"
+}
+
+// splitList split comma separated config value, blank items are ignored
+func splitList(s string) (list []string) {
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
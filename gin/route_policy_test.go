@@ -0,0 +1,43 @@
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_matchRoute(t *testing.T) {
+	ctx := func(path string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodOptions, path, nil)
+		return c
+	}
+
+	assert.True(t, matchRoute("/api/*", ctx("/api/users")))
+	assert.False(t, matchRoute("/api/*", ctx("/apiv2")))
+	assert.True(t, matchRoute("/api/*", ctx("/api")))
+	assert.True(t, matchRoute("/api/users/:id", ctx("/api/users/1")))
+	assert.False(t, matchRoute("/api/users/:id", ctx("/api/users/1/orders")))
+	assert.False(t, matchRoute("/api/users/:id", ctx("/api/users")))
+	assert.True(t, matchRoute("/static/*filepath", ctx("/static/js/app.js")))
+}
+
+func Test_routePolicies(t *testing.T) {
+	var p routePolicies[string]
+	p.add("/*", "root")
+	p.add("/api/*", "api")
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/x", nil)
+	policy, ok := p.match(c)
+	assert.True(t, ok)
+	assert.Equal(t, "api", policy)
+
+	r := &router{Engine: gin.New(), HandleProxyToGin: &proxy{}}
+	assert.Equal(t, "/*", groupPattern(r))
+	assert.Equal(t, "/v1/users/*", groupPattern(r.Group("/v1").Group("users")))
+	assert.Equal(t, []string{"a", "b"}, splitList(" a, ,b "))
+}
@@ -6,6 +6,9 @@ import (
 	"github.com/gone-io/goner/g"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"net/http"
+	"path"
+	"strings"
+	"sync"
 )
 
 var incr = 0
@@ -19,12 +22,45 @@ type router struct {
 	isOtelLogLoaded g.IsOtelTracerLoaded `gone:"*" option:"allowNil"`
 	logger          gone.Logger          `gone:"*"`
 	middlewares     []Middleware         `gone:"*"`
+	versioning      *apiVersioning       `gone:"*" option:"allowNil"`
 
 	htmlTpl     string `gone:"config,server.html-tpl-pattern"`
 	mode        string `gone:"config,server.mode,default=release"`
 	serviceName string `gone:"config,server.service-name=gin"`
 
+	// trustedProxies 可信代理的IP或CIDR，以逗号分隔，`gin.Context.ClientIP()` 只信任这些代理添加的 `X-Forwarded-For`；
+	// 为空时保持gin的默认设置，对应配置项为：`server.trusted-proxies`
+	trustedProxies string `gone:"config,server.trusted-proxies"`
+
 	HandleProxyToGin `gone:"gone-gin-proxy"`
+
+	routes    *routeTable
+	handlers  []HandlerFunc
+	versioned *versionedRoutes
+}
+
+type routeTable struct {
+	lock sync.Mutex
+	list []RouteInfo
+}
+
+func (t *routeTable) add(info RouteInfo) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.list = append(t.list, info)
+}
+
+// set replace the route with the same method and path, or add it if not exists.
+func (t *routeTable) set(info RouteInfo) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for i := range t.list {
+		if t.list[i].Method == info.Method && t.list[i].Path == info.Path {
+			t.list[i] = info
+			return
+		}
+	}
+	t.list = append(t.list, info)
 }
 
 type logWriter struct {
@@ -63,11 +99,16 @@ func (r *router) GonerName() string {
 	return IdGoneGinRouter
 }
 
-func (r *router) Init() {
+func (r *router) Init() error {
 	if r.Engine == nil {
 		gin.SetMode(r.mode)
 		r.Engine = gin.New()
 	}
+	if r.trustedProxies != "" {
+		if err := r.Engine.SetTrustedProxies(strings.Split(r.trustedProxies, ",")); err != nil {
+			return gone.NewInnerErrorWithParams(gone.ConfigError, "invalid server.trusted-proxies(%s): %v", r.trustedProxies, err)
+		}
+	}
 
 	if r.isOtelLogLoaded {
 		r.Engine.Use(otelgin.Middleware(r.serviceName))
@@ -81,6 +122,15 @@ func (r *router) Init() {
 
 	gin.DefaultWriter = debugWriter(r.logger)
 	gin.DefaultErrorWriter = errorWriter(r.logger)
+	return nil
+}
+
+// ServeHTTP route the requests, the version requested by header or `Accept` is resolved before routing.
+func (r *router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.versioning != nil {
+		r.versioning.rewrite(req)
+	}
+	r.Engine.ServeHTTP(w, req)
 }
 
 func (r *router) GetGinRouter() gin.IRouter {
@@ -94,8 +144,79 @@ func (r *router) getR() gin.IRouter {
 	return r.r
 }
 
+func (r *router) getRoutes() *routeTable {
+	if r.routes == nil {
+		r.routes = &routeTable{}
+	}
+	return r.routes
+}
+
+// Routes return all routes mounted through the router and its groups.
+func (r *router) Routes() []RouteInfo {
+	t := r.getRoutes()
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]RouteInfo(nil), t.list...)
+}
+
+func (r *router) basePath() string {
+	return basePathOf(r.getR())
+}
+
+func basePathOf(group gin.IRouter) string {
+	if g, ok := group.(interface{ BasePath() string }); ok {
+		return g.BasePath()
+	}
+	return "/"
+}
+
+func (r *router) handle(httpMethod, relativePath string, handlers ...HandlerFunc) {
+	r.mount(httpMethod, relativePath, false, handlers...)
+}
+
+// handleShared mount the route on a version group for several versions, see versionedRoutes.
+func (r *router) handleShared(httpMethod, relativePath string, handlers ...HandlerFunc) {
+	r.mount(httpMethod, relativePath, true, handlers...)
+}
+
+func (r *router) mount(httpMethod, relativePath string, shared bool, handlers ...HandlerFunc) {
+	fullPath := joinPaths(r.basePath(), relativePath)
+	all := make([]HandlerFunc, 0, len(r.handlers)+len(handlers))
+	all = append(all, r.handlers...)
+	all = append(all, handlers...)
+	info := RouteInfo{
+		Method:   httpMethod,
+		Path:     fullPath,
+		Handlers: all,
+	}
+
+	proxied := r.Proxy(handlers...)
+	if r.versioned != nil {
+		// the middlewares of group are kept as now, like routes registered to gin immediately
+		route := &versionedRoute{group: r.getR().Group(""), httpMethod: httpMethod, relativePath: relativePath, handlers: proxied}
+		if r.versioned.mount(route, shared) {
+			r.getRoutes().set(info)
+		}
+		return
+	}
+	r.getR().Handle(httpMethod, relativePath, proxied...)
+	r.getRoutes().add(info)
+}
+
+func joinPaths(absolutePath, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+	finalPath := path.Join(absolutePath, relativePath)
+	if relativePath[len(relativePath)-1] == '/' && finalPath[len(finalPath)-1] != '/' {
+		return finalPath + "/"
+	}
+	return finalPath
+}
+
 func (r *router) Use(middleware ...HandlerFunc) IRoutes {
 	r.getR().Use(r.ProxyForMiddleware(middleware...)...)
+	r.handlers = append(r.handlers, middleware...)
 	return r
 }
 
@@ -106,50 +227,54 @@ func (r *router) Group(relativePath string, handlers ...HandlerFunc) RouteGroup
 		r:                r.getR().Group(relativePath, r.ProxyForMiddleware(handlers...)...),
 		Engine:           r.Engine,
 		HandleProxyToGin: r.HandleProxyToGin,
+		routes:           r.getRoutes(),
+		handlers:         append(append([]HandlerFunc(nil), r.handlers...), handlers...),
+		versioned:        r.versioned,
 	}
 }
 
 func (r *router) Handle(httpMethod, relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.getR().Handle(httpMethod, relativePath, r.Proxy(handlers...)...)
+	r.handle(httpMethod, relativePath, handlers...)
 	return r
 }
+
+// anyMethods the methods of routes mounted by Any
+var anyMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodHead,
+	http.MethodOptions, http.MethodDelete, http.MethodConnect, http.MethodTrace,
+}
+
 func (r *router) Any(relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.getR().Handle(http.MethodGet, relativePath, r.Proxy(handlers...)...)
-	r.getR().Handle(http.MethodPost, relativePath, r.Proxy(handlers...)...)
-	r.getR().Handle(http.MethodPut, relativePath, r.Proxy(handlers...)...)
-	r.getR().Handle(http.MethodPatch, relativePath, r.Proxy(handlers...)...)
-	r.getR().Handle(http.MethodHead, relativePath, r.Proxy(handlers...)...)
-	r.getR().Handle(http.MethodOptions, relativePath, r.Proxy(handlers...)...)
-	r.getR().Handle(http.MethodDelete, relativePath, r.Proxy(handlers...)...)
-	r.getR().Handle(http.MethodConnect, relativePath, r.Proxy(handlers...)...)
-	r.getR().Handle(http.MethodTrace, relativePath, r.Proxy(handlers...)...)
+	for _, method := range anyMethods {
+		r.handle(method, relativePath, handlers...)
+	}
 	return r
 }
 func (r *router) GET(relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.getR().Handle(http.MethodGet, relativePath, r.Proxy(handlers...)...)
+	r.handle(http.MethodGet, relativePath, handlers...)
 	return r
 }
 func (r *router) POST(relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.getR().Handle(http.MethodPost, relativePath, r.Proxy(handlers...)...)
+	r.handle(http.MethodPost, relativePath, handlers...)
 	return r
 }
 func (r *router) DELETE(relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.getR().Handle(http.MethodDelete, relativePath, r.Proxy(handlers...)...)
+	r.handle(http.MethodDelete, relativePath, handlers...)
 	return r
 }
 func (r *router) PATCH(relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.getR().Handle(http.MethodPatch, relativePath, r.Proxy(handlers...)...)
+	r.handle(http.MethodPatch, relativePath, handlers...)
 	return r
 }
 func (r *router) PUT(relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.getR().Handle(http.MethodPut, relativePath, r.Proxy(handlers...)...)
+	r.handle(http.MethodPut, relativePath, handlers...)
 	return r
 }
 func (r *router) OPTIONS(relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.getR().Handle(http.MethodOptions, relativePath, r.Proxy(handlers...)...)
+	r.handle(http.MethodOptions, relativePath, handlers...)
 	return r
 }
 func (r *router) HEAD(relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.getR().Handle(http.MethodHead, relativePath, r.Proxy(handlers...)...)
+	r.handle(http.MethodHead, relativePath, handlers...)
 	return r
 }
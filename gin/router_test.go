@@ -3,6 +3,7 @@ package gin
 import (
 	mock "github.com/gone-io/gone/v2"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -36,6 +37,16 @@ func Test_router_Init(t *testing.T) {
 
 	r.Init()
 	assert.NotNil(t, r.Engine)
+
+	r = &router{logger: mockLogger, mode: "test", trustedProxies: "10.0.0.0/8,192.0.2.1"}
+	assert.Nil(t, r.Init())
+	c := gin.CreateTestContextOnly(httptest.NewRecorder(), r.Engine)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("X-Forwarded-For", "1.1.1.1, 2.2.2.2")
+	assert.Equal(t, "2.2.2.2", c.ClientIP())
+
+	r = &router{logger: mockLogger, mode: "test", trustedProxies: "x"}
+	assert.Error(t, r.Init())
 }
 
 func Test_router_GetGinRouter(t *testing.T) {
@@ -296,3 +307,40 @@ func Test_errorWriter(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 4, n)
 }
+
+func Test_router_Routes(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockLogger := mock.NewMockLogger(controller)
+	mockProxy := NewMockHandleProxyToGin(controller)
+
+	handlers := []gin.HandlerFunc{func(c *gin.Context) {}}
+	mockProxy.EXPECT().Proxy(gomock.Any()).Return(handlers).AnyTimes()
+	mockProxy.EXPECT().ProxyForMiddleware(gomock.Any()).Return(handlers).AnyTimes()
+
+	r := &router{
+		logger:           mockLogger,
+		HandleProxyToGin: mockProxy,
+		mode:             "test",
+	}
+	r.Init()
+
+	middleware := func() {}
+	handler := func() {}
+
+	r.GET("/", handler)
+	group := r.Group("/api", middleware)
+	group.POST("/users/", handler)
+	group.Group("v1").PUT("users/:id", handler)
+
+	routes := r.Routes()
+	assert.Equal(t, 3, len(routes))
+	assert.Equal(t, http.MethodGet, routes[0].Method)
+	assert.Equal(t, "/", routes[0].Path)
+	assert.Equal(t, 1, len(routes[0].Handlers))
+	assert.Equal(t, "/api/users/", routes[1].Path)
+	assert.Equal(t, 2, len(routes[1].Handlers))
+	assert.Equal(t, http.MethodPut, routes[2].Method)
+	assert.Equal(t, "/api/v1/users/:id", routes[2].Path)
+}
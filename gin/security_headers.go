@@ -0,0 +1,152 @@
+package gin
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+)
+
+// SecurityHeadersPolicy security response headers, empty field means the header is not set
+type SecurityHeadersPolicy struct {
+	// Path route pattern of rule configured by `server.security-headers.rules`, see LimitRule.Path
+	Path string `mapstructure:"path" json:"path"`
+
+	// HstsMaxAge `max-age` of `Strict-Transport-Security`, which is only sent over https
+	HstsMaxAge            time.Duration `mapstructure:"hsts-max-age" json:"hsts-max-age"`
+	HstsIncludeSubdomains bool          `mapstructure:"hsts-include-subdomains" json:"hsts-include-subdomains"`
+	HstsPreload           bool          `mapstructure:"hsts-preload" json:"hsts-preload"`
+
+	ContentSecurityPolicy   string `mapstructure:"content-security-policy" json:"content-security-policy"`
+	FrameOptions            string `mapstructure:"frame-options" json:"frame-options"`
+	ContentTypeOptions      string `mapstructure:"content-type-options" json:"content-type-options"`
+	ReferrerPolicy          string `mapstructure:"referrer-policy" json:"referrer-policy"`
+	PermissionsPolicy       string `mapstructure:"permissions-policy" json:"permissions-policy"`
+	CrossOriginOpenerPolicy string `mapstructure:"cross-origin-opener-policy" json:"cross-origin-opener-policy"`
+}
+
+func (p *SecurityHeadersPolicy) headers() map[string]string {
+	headers := map[string]string{
+		"Content-Security-Policy":    p.ContentSecurityPolicy,
+		"X-Frame-Options":            p.FrameOptions,
+		"X-Content-Type-Options":     p.ContentTypeOptions,
+		"Referrer-Policy":            p.ReferrerPolicy,
+		"Permissions-Policy":         p.PermissionsPolicy,
+		"Cross-Origin-Opener-Policy": p.CrossOriginOpenerPolicy,
+	}
+	for k, v := range headers {
+		if v == "" {
+			delete(headers, k)
+		}
+	}
+	return headers
+}
+
+func (p *SecurityHeadersPolicy) hsts() string {
+	if p.HstsMaxAge <= 0 {
+		return ""
+	}
+	value := "max-age=" + strconv.Itoa(int(p.HstsMaxAge.Seconds()))
+	if p.HstsIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	if p.HstsPreload {
+		value += "; preload"
+	}
+	return value
+}
+
+type securityHeadersPolicy struct {
+	headers map[string]string
+	hsts    string
+}
+
+func newSecurityHeadersPolicy(p SecurityHeadersPolicy) *securityHeadersPolicy {
+	return &securityHeadersPolicy{headers: p.headers(), hsts: p.hsts()}
+}
+
+type securityHeadersMiddleware struct {
+	gone.Flag
+
+	// enabled 是否对所有路由添加安全响应头，对应配置项为：`server.security-headers.enabled`
+	enabled bool `gone:"config,server.security-headers.enabled,default=false"`
+
+	// hstsMaxAge `Strict-Transport-Security`的max-age，只在https请求中发送，0表示不发送；
+	// 对应配置项为：`server.security-headers.hsts-max-age`
+	hstsMaxAge            time.Duration `gone:"config,server.security-headers.hsts-max-age,default=4320h"`
+	hstsIncludeSubdomains bool          `gone:"config,server.security-headers.hsts-include-subdomains,default=false"`
+	hstsPreload           bool          `gone:"config,server.security-headers.hsts-preload,default=false"`
+
+	contentSecurityPolicy   string `gone:"config,server.security-headers.content-security-policy"`
+	frameOptions            string `gone:"config,server.security-headers.frame-options,default=DENY"`
+	contentTypeOptions      string `gone:"config,server.security-headers.content-type-options,default=nosniff"`
+	referrerPolicy          string `gone:"config,server.security-headers.referrer-policy,default=strict-origin-when-cross-origin"`
+	permissionsPolicy       string `gone:"config,server.security-headers.permissions-policy"`
+	crossOriginOpenerPolicy string `gone:"config,server.security-headers.cross-origin-opener-policy"`
+
+	// rules 按路由覆盖的安全响应头，对应配置项为：`server.security-headers.rules`
+	rules []SecurityHeadersPolicy `gone:"config,server.security-headers.rules"`
+
+	// isAfterProxy 服务是否在代理之后，是则根据`X-Forwarded-Proto`判断是否为https请求
+	isAfterProxy bool `gone:"config,server.is-after-proxy,default=false"`
+
+	global    *securityHeadersPolicy
+	overrides routePolicies[*securityHeadersPolicy]
+}
+
+func (m *securityHeadersMiddleware) GonerName() string {
+	return IdGoneGinSecurityHeaders
+}
+
+func (m *securityHeadersMiddleware) Init() error {
+	m.global = newSecurityHeadersPolicy(SecurityHeadersPolicy{
+		HstsMaxAge:              m.hstsMaxAge,
+		HstsIncludeSubdomains:   m.hstsIncludeSubdomains,
+		HstsPreload:             m.hstsPreload,
+		ContentSecurityPolicy:   m.contentSecurityPolicy,
+		FrameOptions:            m.frameOptions,
+		ContentTypeOptions:      m.contentTypeOptions,
+		ReferrerPolicy:          m.referrerPolicy,
+		PermissionsPolicy:       m.permissionsPolicy,
+		CrossOriginOpenerPolicy: m.crossOriginOpenerPolicy,
+	})
+
+	for i, rule := range m.rules {
+		if rule.Path == "" {
+			return gone.NewInnerErrorWithParams(gone.ConfigError, "path of security headers rule(%d) is empty", i)
+		}
+		m.overrides.add(rule.Path, newSecurityHeadersPolicy(rule))
+	}
+	return nil
+}
+
+func (m *securityHeadersMiddleware) Override(group RouteGroup, policy SecurityHeadersPolicy) {
+	m.overrides.add(groupPattern(group), newSecurityHeadersPolicy(policy))
+}
+
+func (m *securityHeadersMiddleware) isHttps(ctx *gin.Context) bool {
+	if ctx.Request.TLS != nil {
+		return true
+	}
+	return m.isAfterProxy && strings.EqualFold(ctx.GetHeader("X-Forwarded-Proto"), "https")
+}
+
+func (m *securityHeadersMiddleware) Process(ctx *gin.Context) {
+	policy, ok := m.overrides.match(ctx)
+	if !ok {
+		if !m.enabled {
+			return
+		}
+		policy = m.global
+	}
+
+	header := ctx.Writer.Header()
+	for k, v := range policy.headers {
+		header.Set(k, v)
+	}
+	if policy.hsts != "" && m.isHttps(ctx) {
+		header.Set("Strict-Transport-Security", policy.hsts)
+	}
+}
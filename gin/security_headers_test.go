@@ -0,0 +1,69 @@
+package gin
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_securityHeadersMiddleware(t *testing.T) {
+	m := &securityHeadersMiddleware{
+		enabled:               true,
+		hstsMaxAge:            time.Hour,
+		hstsIncludeSubdomains: true,
+		frameOptions:          "DENY",
+		contentTypeOptions:    "nosniff",
+		referrerPolicy:        "no-referrer",
+		isAfterProxy:          true,
+		rules: []SecurityHeadersPolicy{
+			{Path: "/embed/*", FrameOptions: "SAMEORIGIN"},
+		},
+	}
+	assert.Nil(t, m.Init())
+
+	engine := gin.New()
+	engine.Use(m.Process)
+	r := &router{Engine: engine, HandleProxyToGin: &proxy{}}
+	m.Override(r.Group("/docs"), SecurityHeadersPolicy{ContentSecurityPolicy: "default-src 'self'"})
+
+	request := func(path string, fn func(req *http.Request)) http.Header {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if fn != nil {
+			fn(req)
+		}
+		engine.ServeHTTP(w, req)
+		return w.Header()
+	}
+
+	h := request("/api", nil)
+	assert.Equal(t, "DENY", h.Get("X-Frame-Options"))
+	assert.Equal(t, "nosniff", h.Get("X-Content-Type-Options"))
+	assert.Equal(t, "no-referrer", h.Get("Referrer-Policy"))
+	assert.Empty(t, h.Get("Content-Security-Policy"))
+	assert.Empty(t, h.Get("Strict-Transport-Security"))
+
+	h = request("/api", func(req *http.Request) { req.TLS = &tls.ConnectionState{} })
+	assert.Equal(t, "max-age=3600; includeSubDomains", h.Get("Strict-Transport-Security"))
+	h = request("/api", func(req *http.Request) { req.Header.Set("X-Forwarded-Proto", "https") })
+	assert.Equal(t, "max-age=3600; includeSubDomains", h.Get("Strict-Transport-Security"))
+
+	h = request("/embed/video", nil)
+	assert.Equal(t, "SAMEORIGIN", h.Get("X-Frame-Options"))
+	assert.Empty(t, h.Get("X-Content-Type-Options"))
+
+	h = request("/docs/index.html", nil)
+	assert.Equal(t, "default-src 'self'", h.Get("Content-Security-Policy"))
+	assert.Empty(t, h.Get("X-Frame-Options"))
+
+	m.enabled = false
+	h = request("/api", nil)
+	assert.Empty(t, h.Get("X-Frame-Options"))
+
+	assert.Error(t, (&securityHeadersMiddleware{rules: []SecurityHeadersPolicy{{}}}).Init())
+}
@@ -2,6 +2,7 @@ package gin
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"github.com/gone-io/gone/v2"
@@ -46,6 +47,11 @@ type server struct {
 	cMuxServer  g.Cmux            `gone:"*" option:"allowNil"`
 	tracer      g.Tracer          `gone:"*" option:"allowNil"`
 	registry    g.ServiceRegistry `gone:"*" option:"allowNil"`
+	healthProbe HealthProbe       `gone:"*" option:"allowNil"`
+	webSocket   *webSocket        `gone:"*" option:"allowNil"`
+	testFlag    gone.TestFlag     `gone:"*" option:"allowNil"`
+	transport   TestTransport     `gone:"*" option:"allowNil"`
+	versioning  *apiVersioning    `gone:"*" option:"allowNil"`
 
 	controllers []Controller `gone:"*"`
 
@@ -59,6 +65,12 @@ type server struct {
 	serviceUseSubNet  string        `gone:"config,server.service-use-subnet,default=0.0.0.0/0"`
 	maxWaitBeforeStop time.Duration `gone:"config,server.max-wait-before-stop=5s"`
 
+	// drainWait 停止前的排空时间：就绪探针失败并注销服务后，等待负载均衡和注册中心摘除本实例，再关闭服务器
+	drainWait time.Duration `gone:"config,server.drain-wait=0s"`
+
+	// tlsConfig TLS配置，开启后以HTTPS提供服务，使用cmux时由cmux处理TLS；对应配置项为：`server.tls`
+	tlsConfig g.TLSConfig `gone:"config,server.tls"`
+
 	createListener func(*server) error
 	unRegService   func() error
 }
@@ -78,9 +90,16 @@ func (s *server) Start() error {
 	}
 
 	s.stopFlag = false
+	if s.healthProbe != nil {
+		s.healthProbe.SetDraining(false)
+	}
 	s.httpServer = &http.Server{
 		Handler: s.httpHandler,
 	}
+	if s.webSocket != nil {
+		// hijacked connections are not closed by http.Server.Shutdown
+		s.httpServer.RegisterOnShutdown(s.webSocket.closeAll)
+	}
 
 	s.logger.Infof("Server Listen At %s", s.getAddress())
 	if s.tracer == nil {
@@ -126,11 +145,26 @@ func (s *server) regService() func() error {
 }
 
 func (s *server) initListener() error {
+	if s.testFlag != nil && s.transport != nil {
+		s.listener = s.transport.Listener()
+		return nil
+	}
 	if s.cMuxServer != nil {
 		s.listener = s.cMuxServer.MatchFor(g.HTTP1)
 		return nil
 	}
-	return s.createListener(s)
+	if err := s.createListener(s); err != nil {
+		return err
+	}
+	if s.tlsConfig.Enabled {
+		config, err := s.tlsConfig.ServerTLSConfig(s.logger)
+		if err != nil {
+			_ = s.listener.Close()
+			return err
+		}
+		s.listener = tls.NewListener(s.listener, config)
+	}
+	return nil
 }
 
 func (s *server) serve() {
@@ -159,13 +193,27 @@ func (s *server) Stop() (err error) {
 	s.lock.Lock()
 	s.stopFlag = true
 	s.lock.Unlock()
+	err = s.drain()
+	s.stop()
+	return
+}
+
+// drain make the readiness probe fail and deregister the service, then wait for the change to propagate,
+// so that no new request is routed to this instance when it is shut down.
+func (s *server) drain() (err error) {
+	if s.healthProbe != nil {
+		s.healthProbe.SetDraining(true)
+	}
 	if s.unRegService != nil {
 		err = s.unRegService()
 		if err != nil {
 			s.logger.Errorf("unregister service error: %v", err)
 		}
 	}
-	s.stop()
+	if s.drainWait > 0 {
+		s.logger.Infof("gin server draining, wait %s before shutdown", s.drainWait)
+		time.Sleep(s.drainWait)
+	}
 	return
 }
 
@@ -192,5 +240,8 @@ func (s *server) mount() error {
 			return err
 		}
 	}
+	if s.versioning != nil {
+		s.versioning.resolve()
+	}
 	return nil
 }
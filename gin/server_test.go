@@ -1,8 +1,10 @@
 package gin
 
 import (
+	"context"
 	mock "github.com/gone-io/gone/v2"
 	gMock "github.com/gone-io/goner/g/mock"
+	"io"
 	"net/http"
 	"testing"
 	"time"
@@ -181,6 +183,36 @@ func Test_server_Stop(t *testing.T) {
 	assert.True(t, s.stopFlag)
 }
 
+func Test_server_Stop_Drain(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockLogger := mock.NewMockLogger(controller)
+	mockLogger.EXPECT().Warnf(gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Infof(gomock.Any(), gomock.Any()).AnyTimes()
+
+	probe := &healthProbe{}
+	var unRegistered bool
+	s := &server{
+		logger:            mockLogger,
+		httpServer:        &http.Server{},
+		healthProbe:       probe,
+		maxWaitBeforeStop: 100 * time.Millisecond,
+		drainWait:         50 * time.Millisecond,
+		unRegService: func() error {
+			assert.Equal(t, HealthStatusDraining, probe.Readiness(context.Background()).Status)
+			unRegistered = true
+			return nil
+		},
+	}
+
+	start := time.Now()
+	err := s.Stop()
+	assert.Nil(t, err)
+	assert.True(t, unRegistered)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
 func Test_server_Stop_NilServer(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -264,3 +296,53 @@ func Test_createListener(t *testing.T) {
 	// Clean up
 	s.listener.Close()
 }
+
+func Test_server_TLS(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockLogger := mock.NewMockLogger(controller)
+	mockLogger.EXPECT().Infof(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warnf(gomock.Any()).AnyTimes()
+
+	s := &server{
+		logger: mockLogger,
+		httpHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.Proto))
+		}),
+		createListener:    createListener,
+		host:              "127.0.0.1",
+		maxWaitBeforeStop: time.Second,
+		tlsConfig: g.TLSConfig{
+			Enabled:    true,
+			CertFile:   "testdata/tls/server.crt",
+			KeyFile:    "testdata/tls/server.key",
+			CAFile:     "testdata/tls/ca.crt",
+			ClientAuth: g.TLSClientAuthRequireAndVerify,
+		},
+	}
+	assert.Nil(t, s.Start())
+	defer s.stop()
+
+	clientConfig, err := (&g.TLSConfig{
+		CertFile: "testdata/tls/client.crt",
+		KeyFile:  "testdata/tls/client.key",
+		CAFile:   "testdata/tls/ca.crt",
+	}).ClientTLSConfig(nil)
+	assert.Nil(t, err)
+	transport := &http.Transport{TLSClientConfig: clientConfig, ForceAttemptHTTP2: true}
+	defer transport.CloseIdleConnections()
+	client := &http.Client{Transport: transport}
+
+	res, err := client.Get("https://" + s.getAddress())
+	if assert.Nil(t, err) {
+		body, _ := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		assert.Equal(t, "HTTP/2.0", string(body))
+	}
+
+	_, err = http.Get("http://" + s.getAddress())
+	assert.Nil(t, err, "plain http request is answered with 400 by tls server")
+
+	s.tlsConfig.CertFile = "testdata/tls/missing.crt"
+	assert.Error(t, s.initListener())
+}
@@ -2,9 +2,12 @@ package gin
 
 import (
 	"fmt"
+	"io"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/gone-io/goner/gin/internal/json"
-	"io"
 )
 
 func NewSSE(writer gin.ResponseWriter) SSE {
@@ -17,10 +20,48 @@ type SSE interface {
 	End() error
 }
 
+// SseEventWriter is optionally implemented by SSE to write the fields of events and heartbeats, eg: *Sse returned by
+// NewSSE. It is separated from SSE, so that the existing implementations of SSE are not broken.
+type SseEventWriter interface {
+	// WriteEvent write an event with id, name and retry hint
+	WriteEvent(event SseEvent) error
+
+	// Heartbeat write a comment line, which keeps the idle stream alive through proxies
+	Heartbeat() error
+}
+
+// SseEvent an event of Server-Sent Events. Handlers can send SseEvent (or *SseEvent) through the returned channel
+// to set the fields of event; other values are written as `event: data` with JSON.
+type SseEvent struct {
+	// Id the event id, which is sent back by reconnecting client in header `Last-Event-ID`
+	Id string
+
+	// Event the event name, default is `data`
+	Event string
+
+	// Data string and []byte are written as is, other values are written as JSON; multiline data is split into multiple `data:` lines.
+	// If Data is nil, only `id` and `retry` fields are written, which does not dispatch an event on client.
+	Data any
+
+	// Retry the reconnection time hint for client, ignored if not greater than 0
+	Retry time.Duration
+}
+
+// SseReplayer is called when a client reconnects to an SSE stream with header `Last-Event-ID`,
+// the events returned are written before the events from the channel returned by handler.
+// Load a goner implementing it to make streams resumable.
+type SseReplayer interface {
+	Replay(ctx *gin.Context, lastEventId string) ([]SseEvent, error)
+}
+
+const LastEventIdHeader = "Last-Event-ID"
+
 type Sse struct {
 	Writer gin.ResponseWriter
 }
 
+var _ SseEventWriter = (*Sse)(nil)
+
 func (s *Sse) Start() {
 	s.Writer.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
 	s.Writer.Header().Set("Cache-Control", "no-cache")
@@ -30,24 +71,81 @@ func (s *Sse) Start() {
 }
 
 func (s *Sse) Write(delta any) error {
+	switch e := delta.(type) {
+	case SseEvent:
+		return s.WriteEvent(e)
+	case *SseEvent:
+		return s.WriteEvent(*e)
+	}
+
 	jsonStr, err := json.Marshal(delta)
 	if err != nil {
 		return err
 	}
-	_, err = io.WriteString(s.Writer, fmt.Sprintf("event: data\ndata: %s\n\n", jsonStr))
+	return s.write(fmt.Sprintf("event: data\ndata: %s\n\n", jsonStr))
+}
+
+func (s *Sse) WriteEvent(event SseEvent) error {
+	var data string
+	switch d := event.Data.(type) {
+	case nil:
+	case string:
+		data = d
+	case []byte:
+		data = string(d)
+	default:
+		jsonStr, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		data = string(jsonStr)
+	}
+
+	var b strings.Builder
+	if event.Id != "" {
+		b.WriteString("id: " + singleLine(event.Id) + "\n")
+	}
+	if event.Retry > 0 {
+		b.WriteString(fmt.Sprintf("retry: %d\n", event.Retry.Milliseconds()))
+	}
+	if event.Data != nil {
+		name := singleLine(event.Event)
+		if name == "" {
+			name = "data"
+		}
+		b.WriteString("event: " + name + "\n")
+		for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+			b.WriteString("data: " + line + "\n")
+		}
+	}
+	b.WriteString("\n")
+	return s.write(b.String())
+}
+
+func (s *Sse) Heartbeat() error {
+	return s.write(": heartbeat\n\n")
+}
+
+func (s *Sse) End() error {
+	_, err := io.WriteString(s.Writer, "event: done\ndata: [DONE]\n\n")
 	if err != nil {
 		return err
 	}
 	s.Writer.Flush()
+	s.Writer.CloseNotify()
 	return nil
 }
 
-func (s *Sse) End() error {
-	_, err := io.WriteString(s.Writer, "event: done\ndata: [DONE]\n\n")
+func (s *Sse) write(str string) error {
+	_, err := io.WriteString(s.Writer, str)
 	if err != nil {
 		return err
 	}
 	s.Writer.Flush()
-	s.Writer.CloseNotify()
 	return nil
 }
+
+// singleLine remove line breaks, which are not allowed in `id` and `event` field
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -193,3 +194,38 @@ func TestSse_Integration(t *testing.T) {
 	assert.True(t, strings.Contains(output, "event: data\ndata: \"message 2\"\n\n"))
 	assert.True(t, strings.Contains(output, "event: done\ndata: [DONE]\n\n"))
 }
+
+func TestSse_WriteEvent(t *testing.T) {
+	hw := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(hw)
+	sse := &Sse{Writer: c.Writer}
+
+	tests := []struct {
+		name     string
+		event    SseEvent
+		expected string
+	}{
+		{"default name", SseEvent{Data: map[string]int{"a": 1}}, "event: data\ndata: {\"a\":1}\n\n"},
+		{"id and name", SseEvent{Id: "7", Event: "delta", Data: "hi"}, "id: 7\nevent: delta\ndata: hi\n\n"},
+		{"multiline", SseEvent{Event: "msg", Data: []byte("a\r\nb")}, "event: msg\ndata: a\ndata: b\n\n"},
+		{"retry only", SseEvent{Retry: 3 * time.Second}, "retry: 3000\n\n"},
+		{"line breaks in id", SseEvent{Id: "1\n2", Data: "x"}, "id: 12\nevent: data\ndata: x\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hw.Body.Reset()
+			assert.Nil(t, sse.WriteEvent(tt.event))
+			assert.Equal(t, tt.expected, hw.Body.String())
+		})
+	}
+
+	hw.Body.Reset()
+	assert.Nil(t, sse.Write(&SseEvent{Id: "1", Data: "x"}))
+	assert.Equal(t, "id: 1\nevent: data\ndata: x\n\n", hw.Body.String())
+
+	hw.Body.Reset()
+	assert.Nil(t, sse.Heartbeat())
+	assert.Equal(t, ": heartbeat\n\n", hw.Body.String())
+
+	assert.Error(t, sse.WriteEvent(SseEvent{Data: make(chan int)}))
+}
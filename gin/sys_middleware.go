@@ -12,7 +12,9 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/time/rate"
 	"io"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -35,6 +37,8 @@ type SysMiddleware struct {
 	// 配置后，能够在该路劲提供一个http-status等于200的空响应
 	healthCheckUrl string `gone:"config,server.health-check"`
 
+	healthProbe *healthProbe `gone:"*" option:"allowNil"`
+
 	logFormat string `gone:"config,server.log.format,default=console"`
 
 	// showRequestTime 展示请求时间
@@ -59,14 +63,25 @@ type SysMiddleware struct {
 
 	isAfterProxy bool `gone:"config,server.is-after-proxy,default=false"`
 
+	// trustedProxies 可信代理，配置后 `key-by: ip` 的限流规则使用gin按可信代理解析出的客户端IP，
+	// 否则使用连接的IP，对应配置项为：`server.trusted-proxies`
+	trustedProxies string `gone:"config,server.trusted-proxies"`
+
 	enableLimit bool    `gone:"config,server.req.enable-limit,default=false"`
 	limit       float64 `gone:"config,server.req.limit,default=100"`
 	burst       int     `gone:"config,server.req.limit-burst,default=300"`
 
+	// limitRules 按路由、客户端IP、请求头或自定义key的限流规则
+	// 对应配置项为：`server.req.limit-rules`
+	limitRules    []LimitRule         `gone:"config,server.req.limit-rules"`
+	rateLimiter   g.RateLimiter       `gone:"*" option:"allowNil"`
+	keyExtractors []LimitKeyExtractor `gone:"*"`
+
 	requestIdKey string `gone:"config,server.req.x-request-id-key=X-Request-Id"`
 	tracerIdKey  string `gone:"config,server.req.x-trace-id-key=X-Trace-Id"`
 
-	limiter *rate.Limiter
+	limiter      *rate.Limiter
+	extractorMap map[string]LimitKeyExtractor
 }
 
 func (m *SysMiddleware) GonerName() string {
@@ -78,6 +93,25 @@ func (m *SysMiddleware) Init() error {
 		m.limiter = rate.NewLimiter(rate.Limit(m.limit), m.burst)
 	}
 
+	m.extractorMap = make(map[string]LimitKeyExtractor)
+	for _, extractor := range m.keyExtractors {
+		m.extractorMap[extractor.Name()] = extractor
+	}
+
+	for i := range m.limitRules {
+		rule := &m.limitRules[i]
+		if err := rule.Init(i); err != nil {
+			return err
+		}
+		if rule.KeyBy != "" && rule.KeyBy != limitKeyByIp && !strings.HasPrefix(rule.KeyBy, limitKeyByHeader) {
+			if _, ok := m.extractorMap[rule.KeyBy]; !ok {
+				return gone.NewInnerErrorWithParams(gone.ConfigError, "LimitKeyExtractor(%s) of rate limit rule(%s) not found", rule.KeyBy, rule.Name)
+			}
+		}
+	}
+	if len(m.limitRules) > 0 && m.rateLimiter == nil {
+		m.rateLimiter = g.NewMemoryRateLimiter()
+	}
 	return nil
 }
 
@@ -88,6 +122,76 @@ func (m *SysMiddleware) allow() bool {
 	return true
 }
 
+func (m *SysMiddleware) remoteIp(context *gin.Context) string {
+	if m.isAfterProxy {
+		return context.GetHeader("X-Forwarded-For")
+	}
+	return context.RemoteIP()
+}
+
+// clientIp the ip of client used as the rate limit key. `X-Forwarded-For` can be forged by clients, so it is only
+// resolved by gin with the proxies trusted by `server.trusted-proxies`; otherwise the ip of connection is used.
+func (m *SysMiddleware) clientIp(context *gin.Context) string {
+	if m.trustedProxies != "" {
+		return context.ClientIP()
+	}
+	return context.RemoteIP()
+}
+
+func (m *SysMiddleware) limitKey(context *gin.Context, rule *LimitRule) string {
+	switch {
+	case rule.KeyBy == "":
+		return "-"
+	case rule.KeyBy == limitKeyByIp:
+		return m.clientIp(context)
+	case strings.HasPrefix(rule.KeyBy, limitKeyByHeader):
+		return context.GetHeader(strings.TrimPrefix(rule.KeyBy, limitKeyByHeader))
+	default:
+		return m.extractorMap[rule.KeyBy].Extract(context)
+	}
+}
+
+// allowByRules check the request with all rules matched, and set `X-RateLimit-*` headers with the most restrictive result;
+// when rejected, `Retry-After` header is set.
+func (m *SysMiddleware) allowByRules(context *gin.Context) bool {
+	var last *g.RateLimitResult
+	for i := range m.limitRules {
+		rule := &m.limitRules[i]
+		if !rule.match(context) {
+			continue
+		}
+		key := m.limitKey(context, rule)
+		if key == "" {
+			continue
+		}
+
+		result, err := m.rateLimiter.Allow(context.Request.Context(), "rate-limit:"+rule.Name+":"+key, rule.RateLimitRule())
+		if err != nil {
+			m.logger.Errorf("rate limit for rule(%s) error: %v", rule.Name, err)
+			continue
+		}
+		if last == nil || !result.Allowed || result.Remaining < last.Remaining {
+			last = &result
+		}
+		if !result.Allowed {
+			break
+		}
+	}
+
+	if last == nil {
+		return true
+	}
+
+	header := context.Writer.Header()
+	header.Set("X-RateLimit-Limit", strconv.Itoa(last.Limit))
+	header.Set("X-RateLimit-Remaining", strconv.Itoa(last.Remaining))
+	header.Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(last.ResetAfter.Seconds()))))
+	if !last.Allowed {
+		header.Set("Retry-After", strconv.Itoa(int(math.Ceil(last.RetryAfter.Seconds()))))
+	}
+	return last.Allowed
+}
+
 const TooManyRequests = "Too Many Requests"
 
 func (m *SysMiddleware) Process(ginCtx *gin.Context) {
@@ -101,8 +205,13 @@ func (m *SysMiddleware) Process(ginCtx *gin.Context) {
 		return
 	}
 
-	if !m.allow() {
+	if m.healthProbe != nil && m.healthProbe.serve(ginCtx) {
+		return
+	}
+
+	if !m.allow() || len(m.limitRules) > 0 && !m.allowByRules(ginCtx) {
 		m.resHandler.Failed(ginCtx, gone.NewError(http.StatusTooManyRequests, TooManyRequests, http.StatusTooManyRequests))
+		ginCtx.Abort()
 		return
 	}
 
@@ -157,13 +266,7 @@ func (m *SysMiddleware) requestLog(context *gin.Context) {
 		}
 
 		if m.logRemoteIp {
-			var remoteIP string
-			if m.isAfterProxy {
-				remoteIP = context.GetHeader("X-Forwarded-For")
-			} else {
-				remoteIP = context.RemoteIP()
-			}
-			logMap["ip"] = remoteIP
+			logMap["ip"] = m.remoteIp(context)
 		}
 
 		logMap["method"] = context.Request.Method
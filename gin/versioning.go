@@ -0,0 +1,456 @@
+package gin
+
+import (
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	deprecationHeader = "Deprecation"
+	sunsetHeader      = "Sunset"
+	meterName         = "github.com/gone-io/goner/gin"
+)
+
+// VersionOption options of the API version created by Versioning.Version
+type VersionOption func(version *apiVersion)
+
+// DefaultVersion serve the requests to the group which don't specify a version by this version.
+func DefaultVersion() VersionOption {
+	return func(version *apiVersion) {
+		version.isDefault = true
+	}
+}
+
+// Deprecated mark the version deprecated since at, which is sent in the `Deprecation` header; zero at means the time
+// is unknown. Every request of a deprecated version is logged and counted by the metric `http.server.deprecated.requests`.
+func Deprecated(at time.Time) VersionOption {
+	return func(version *apiVersion) {
+		version.deprecated = true
+		version.deprecatedAt = at
+	}
+}
+
+// Sunset the time after which the version will be unavailable, which is sent in the `Sunset` header.
+func Sunset(at time.Time) VersionOption {
+	return func(version *apiVersion) {
+		version.sunset = at
+	}
+}
+
+// DeprecationLink the document about the deprecation of the version, which is sent in the `Link` header.
+func DeprecationLink(url string) VersionOption {
+	return func(version *apiVersion) {
+		version.link = url
+	}
+}
+
+type apiVersion struct {
+	name         string
+	isDefault    bool
+	deprecated   bool
+	deprecatedAt time.Time
+	sunset       time.Time
+	link         string
+}
+
+// versionBase the versions created under a route group
+type versionBase struct {
+	path     string
+	versions map[string]*apiVersion
+	groups   map[string]*router
+}
+
+func (b *versionBase) defaultVersion() *apiVersion {
+	for _, version := range b.versions {
+		if version.isDefault {
+			return version
+		}
+	}
+	return nil
+}
+
+// rest return the path relative to the base, ok is false if path is not under the base.
+func (b *versionBase) rest(path string) (rest string, ok bool) {
+	base := strings.TrimSuffix(b.path, "/")
+	if path == base {
+		return "", true
+	}
+	if strings.HasPrefix(path, base+"/") {
+		return path[len(base):], true
+	}
+	return "", false
+}
+
+// apiVersioning mounts routes of a group under `/<version>` for each version; the requests without version in path
+// are routed by the version in header `server.versioning.header` or in the media type of `Accept`, such as
+// `application/json; version=2` or `application/vnd.example.v2+json`, otherwise by the default version.
+type apiVersioning struct {
+	gone.Flag
+	logger gone.Logger `gone:"*"`
+
+	// header 指定API版本的请求头，对应配置项为：`server.versioning.header`
+	header string `gone:"config,server.versioning.header,default=X-API-Version"`
+
+	// mediaTypeParam `Accept`中指定API版本的媒体类型参数，对应配置项为：`server.versioning.media-type-param`
+	mediaTypeParam string `gone:"config,server.versioning.media-type-param,default=version"`
+
+	lock     sync.RWMutex
+	bases    []*versionBase
+	routes   versionedRoutes
+	requests metric.Int64Counter
+}
+
+func (v *apiVersioning) GonerName() string {
+	return IdGoneGinVersioning
+}
+
+func (v *apiVersioning) Init() error {
+	var err error
+	v.requests, err = otel.Meter(meterName).Int64Counter(
+		"http.server.deprecated.requests",
+		metric.WithDescription("Number of requests to deprecated API versions."),
+		metric.WithUnit("{request}"),
+	)
+	return err
+}
+
+// resolve register the routes mounted on several versions, it's called by the server after mounting controllers.
+func (v *apiVersioning) resolve() {
+	v.routes.resolve()
+}
+
+func (v *apiVersioning) Version(group RouteGroup, version string, options ...VersionOption) RouteGroup {
+	base, ok := group.(*router)
+	if !ok {
+		return group.Group("/" + version)
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	b := v.base(base.basePath())
+	key := versionKey(version)
+	if g, ok := b.groups[key]; ok {
+		for _, option := range options {
+			option(b.versions[key])
+		}
+		return g
+	}
+
+	apiVer := &apiVersion{name: version}
+	for _, option := range options {
+		option(apiVer)
+	}
+	incr++
+	g := &router{
+		id:               incr,
+		r:                base.getR().Group("/"+version, v.deprecation(apiVer)),
+		Engine:           base.Engine,
+		HandleProxyToGin: base.HandleProxyToGin,
+		routes:           base.getRoutes(),
+		handlers:         append([]HandlerFunc(nil), base.handlers...),
+		versioned:        &v.routes,
+	}
+	b.versions[key] = apiVer
+	b.groups[key] = g
+	return g
+}
+
+func (v *apiVersioning) Versions(group RouteGroup, versions ...string) RouteGroup {
+	set := make(versionSet, 0, len(versions))
+	for _, version := range versions {
+		set = append(set, v.Version(group, version))
+	}
+	return set
+}
+
+func (v *apiVersioning) base(path string) *versionBase {
+	for _, b := range v.bases {
+		if b.path == path {
+			return b
+		}
+	}
+	b := &versionBase{path: path, versions: make(map[string]*apiVersion), groups: make(map[string]*router)}
+	v.bases = append(v.bases, b)
+	sort.SliceStable(v.bases, func(i, j int) bool {
+		return len(v.bases[i].path) > len(v.bases[j].path)
+	})
+	return b
+}
+
+// rewrite insert the version requested by header or `Accept` into the request path, so the request is routed to the
+// routes of the version; it's called before routing. Requests with version in path are kept unchanged, except that
+// the version is matched case-insensitively, eg: `/api/V1/users` is routed to `/api/v1/users`.
+func (v *apiVersioning) rewrite(req *http.Request) {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+
+	for _, b := range v.bases {
+		rest, ok := b.rest(req.URL.Path)
+		if !ok {
+			continue
+		}
+
+		var target *apiVersion
+		inPath := false
+		segment, _, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+		if version, ok := b.versions[versionKey(segment)]; ok && segment != "" {
+			if segment == version.name {
+				return
+			}
+			target, inPath = version, true
+		} else if requested := v.requested(req); requested != "" {
+			target = b.versions[versionKey(requested)]
+		} else {
+			target = b.defaultVersion()
+		}
+		if target == nil {
+			return
+		}
+
+		prefix := strings.TrimSuffix(b.path, "/") + "/" + target.name
+		req.URL.Path = prefix + stripVersion(rest, inPath)
+		if req.URL.RawPath != "" {
+			if rawRest, ok := b.rest(req.URL.RawPath); ok {
+				req.URL.RawPath = prefix + stripVersion(rawRest, inPath)
+			} else {
+				req.URL.RawPath = ""
+			}
+		}
+		return
+	}
+}
+
+// stripVersion remove the first segment of rest if it is the version
+func stripVersion(rest string, inPath bool) string {
+	if !inPath {
+		return rest
+	}
+	if i := strings.Index(rest[1:], "/"); i >= 0 {
+		return rest[i+1:]
+	}
+	return ""
+}
+
+var vendorVersion = regexp.MustCompile(`^[^/]+/vnd\.[^+]*\.(v[0-9][^.+]*)(\+.*)?$`)
+
+// requested return the version requested by header, or by the media type of `Accept`.
+func (v *apiVersioning) requested(req *http.Request) string {
+	if version := strings.TrimSpace(req.Header.Get(v.header)); version != "" {
+		return version
+	}
+	for _, accept := range req.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			}
+			if version := params[v.mediaTypeParam]; version != "" {
+				return version
+			}
+			if m := vendorVersion.FindStringSubmatch(mediaType); m != nil {
+				return m[1]
+			}
+		}
+	}
+	return ""
+}
+
+// deprecation return the handler of version group, which sends the deprecation headers, logs and counts the requests
+// of deprecated version.
+func (v *apiVersioning) deprecation(version *apiVersion) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		header := ctx.Writer.Header()
+		if version.deprecated {
+			if version.deprecatedAt.IsZero() {
+				header.Set(deprecationHeader, "true")
+			} else {
+				header.Set(deprecationHeader, "@"+strconv.FormatInt(version.deprecatedAt.Unix(), 10))
+			}
+			v.logger.Warnf("deprecated api version(%s) is requested: %s %s, user-agent: %s",
+				version.name, ctx.Request.Method, ctx.Request.URL.Path, ctx.Request.UserAgent())
+			v.requests.Add(ctx, 1, metric.WithAttributes(
+				attribute.String("api.version", version.name),
+				attribute.String("http.request.method", ctx.Request.Method),
+				attribute.String("http.route", ctx.FullPath()),
+			))
+		}
+		if !version.sunset.IsZero() {
+			header.Set(sunsetHeader, version.sunset.UTC().Format(http.TimeFormat))
+		}
+		if version.link != "" {
+			header.Add("Link", "<"+version.link+`>; rel="deprecation"`)
+		}
+	}
+}
+
+// versionKey normalize version, so `v2`, `V2` and `2` are the same version.
+func versionKey(version string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "v")
+}
+
+// versionedRoute a route mounted on a version group
+type versionedRoute struct {
+	group        gin.IRouter
+	httpMethod   string
+	relativePath string
+	handlers     []gin.HandlerFunc
+}
+
+func (r *versionedRoute) key() string {
+	return r.httpMethod + " " + joinPaths(basePathOf(r.group), r.relativePath)
+}
+
+func (r *versionedRoute) register() {
+	r.group.Handle(r.httpMethod, r.relativePath, r.handlers...)
+}
+
+// versionedRoutes the routes mounted on version groups. A route mounted on one version is registered to gin at once;
+// a route mounted on several versions by Versioning.Versions is registered when the mounting is resolved, before the
+// server starts, unless the version mounts its own route, so that one version can override a route of all versions.
+// Routes are registered with their own handlers, so the handlers calling `ctx.Next()`, such as response cache, work as
+// in other routes.
+type versionedRoutes struct {
+	lock     sync.Mutex
+	resolved bool
+	own      map[string]bool
+	keys     []string
+	shared   map[string]*versionedRoute
+}
+
+// mount the route, shared is true if it's mounted on several versions; it returns false if the route is overridden by
+// the route mounted on its version only.
+func (t *versionedRoutes) mount(route *versionedRoute, shared bool) (mounted bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	key := route.key()
+	if !shared {
+		if t.own == nil {
+			t.own = make(map[string]bool)
+		}
+		t.own[key] = true
+		delete(t.shared, key)
+		route.register()
+		return true
+	}
+	if t.own[key] {
+		return false
+	}
+	if t.resolved {
+		route.register()
+		return true
+	}
+	if t.shared == nil {
+		t.shared = make(map[string]*versionedRoute)
+	}
+	if _, ok := t.shared[key]; !ok {
+		t.keys = append(t.keys, key)
+	}
+	t.shared[key] = route
+	return true
+}
+
+// resolve register the routes mounted on several versions and not overridden, it's called after the controllers are
+// mounted; routes mounted later are registered at once.
+func (t *versionedRoutes) resolve() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, key := range t.keys {
+		if route, ok := t.shared[key]; ok {
+			route.register()
+		}
+	}
+	t.keys, t.shared, t.resolved = nil, nil, true
+}
+
+// versionSet mounts routes on the groups of several versions.
+type versionSet []RouteGroup
+
+func (s versionSet) Use(handlers ...HandlerFunc) IRoutes {
+	for _, group := range s {
+		group.Use(handlers...)
+	}
+	return s
+}
+
+func (s versionSet) Handle(httpMethod, relativePath string, handlers ...HandlerFunc) IRoutes {
+	for _, group := range s {
+		if r, ok := group.(*router); ok && r.versioned != nil {
+			r.handleShared(httpMethod, relativePath, handlers...)
+		} else {
+			group.Handle(httpMethod, relativePath, handlers...)
+		}
+	}
+	return s
+}
+
+func (s versionSet) Any(relativePath string, handlers ...HandlerFunc) IRoutes {
+	for _, method := range anyMethods {
+		s.Handle(method, relativePath, handlers...)
+	}
+	return s
+}
+
+func (s versionSet) GET(relativePath string, handlers ...HandlerFunc) IRoutes {
+	return s.Handle(http.MethodGet, relativePath, handlers...)
+}
+
+func (s versionSet) POST(relativePath string, handlers ...HandlerFunc) IRoutes {
+	return s.Handle(http.MethodPost, relativePath, handlers...)
+}
+
+func (s versionSet) DELETE(relativePath string, handlers ...HandlerFunc) IRoutes {
+	return s.Handle(http.MethodDelete, relativePath, handlers...)
+}
+
+func (s versionSet) PATCH(relativePath string, handlers ...HandlerFunc) IRoutes {
+	return s.Handle(http.MethodPatch, relativePath, handlers...)
+}
+
+func (s versionSet) PUT(relativePath string, handlers ...HandlerFunc) IRoutes {
+	return s.Handle(http.MethodPut, relativePath, handlers...)
+}
+
+func (s versionSet) OPTIONS(relativePath string, handlers ...HandlerFunc) IRoutes {
+	return s.Handle(http.MethodOptions, relativePath, handlers...)
+}
+
+func (s versionSet) HEAD(relativePath string, handlers ...HandlerFunc) IRoutes {
+	return s.Handle(http.MethodHead, relativePath, handlers...)
+}
+
+// GetGinRouter return the gin router of the first version.
+func (s versionSet) GetGinRouter() gin.IRouter {
+	if len(s) == 0 {
+		return nil
+	}
+	return s[0].GetGinRouter()
+}
+
+func (s versionSet) Group(relativePath string, handlers ...HandlerFunc) RouteGroup {
+	set := make(versionSet, 0, len(s))
+	for _, group := range s {
+		set = append(set, group.Group(relativePath, handlers...))
+	}
+	return set
+}
+
+func (s versionSet) LoadHTMLGlob(pattern string) {
+	if len(s) > 0 {
+		s[0].LoadHTMLGlob(pattern)
+	}
+}
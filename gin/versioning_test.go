@@ -0,0 +1,242 @@
+package gin
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/mock/gomock"
+)
+
+func newVersioningRouter(t *testing.T) (*router, *apiVersioning) {
+	controller := gomock.NewController(t)
+	logger := gone.NewMockLogger(controller)
+	logger.EXPECT().Warnf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	v := &apiVersioning{
+		logger:         logger,
+		header:         "X-API-Version",
+		mediaTypeParam: "version",
+	}
+	v.requests, _ = noop.NewMeterProvider().Meter("test").Int64Counter("test")
+
+	toGin := func(handlers ...HandlerFunc) (list []gin.HandlerFunc) {
+		for _, h := range handlers {
+			list = append(list, h.(gin.HandlerFunc))
+		}
+		return list
+	}
+	mockProxy := NewMockHandleProxyToGin(controller)
+	mockProxy.EXPECT().Proxy(gomock.Any()).DoAndReturn(toGin).AnyTimes()
+	mockProxy.EXPECT().ProxyForMiddleware(gomock.Any()).DoAndReturn(toGin).AnyTimes()
+	r := &router{
+		Engine:           gin.New(),
+		HandleProxyToGin: mockProxy,
+		versioning:       v,
+	}
+	return r, v
+}
+
+func versionedRequest(r http.Handler, target string, header map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func Test_apiVersioning_rewrite(t *testing.T) {
+	r, v := newVersioningRouter(t)
+	api := &router{r: r.Engine.Group("/api"), Engine: r.Engine}
+	v.Version(api, "v1", DefaultVersion())
+	v.Version(api, "v2")
+
+	tests := []struct {
+		path   string
+		header map[string]string
+		want   string
+	}{
+		{path: "/api/users", want: "/api/v1/users"},
+		{path: "/api", want: "/api/v1"},
+		{path: "/api/v2/users", want: "/api/v2/users"},
+		{path: "/api/V1/users", want: "/api/v1/users"},
+		{path: "/api/1", want: "/api/v1"},
+		{path: "/api/users", header: map[string]string{"X-API-Version": "2"}, want: "/api/v2/users"},
+		{path: "/api/users", header: map[string]string{"Accept": "application/json; version=v2"}, want: "/api/v2/users"},
+		{path: "/api/users", header: map[string]string{"Accept": "text/html, application/vnd.example.v2+json"}, want: "/api/v2/users"},
+		{path: "/api/users", header: map[string]string{"X-API-Version": "v9"}, want: "/api/users"},
+		{path: "/others", want: "/others"},
+		{path: "/apis", want: "/apis"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		for k, value := range tt.header {
+			req.Header.Set(k, value)
+		}
+		v.rewrite(req)
+		assert.Equal(t, tt.want, req.URL.Path, tt.path)
+	}
+}
+
+func Test_apiVersioning(t *testing.T) {
+	r, v := newVersioningRouter(t)
+
+	deprecatedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	api := r.Group("/api")
+	v.Version(api, "v1", Deprecated(deprecatedAt), Sunset(sunset), DeprecationLink("https://example.com/migrate"))
+	v.Version(api, "v2", DefaultVersion())
+
+	users := v.Versions(api, "v1", "v2").Group("/users")
+	users.GET("", gin.HandlerFunc(func(ctx *gin.Context) { ctx.String(http.StatusOK, "users") }))
+	users.GET("/:id", gin.HandlerFunc(func(ctx *gin.Context) { ctx.String(http.StatusOK, "user "+ctx.Param("id")) }))
+	v.Version(api, "v2").GET("/users/:id", gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "user v2 "+ctx.Param("id"))
+	}))
+	v.resolve()
+
+	w := versionedRequest(r, "/api/users/1", nil)
+	assert.Equal(t, "user v2 1", w.Body.String())
+	assert.Empty(t, w.Header().Get(deprecationHeader))
+
+	w = versionedRequest(r, "/api/v1/users/1", nil)
+	assert.Equal(t, "user 1", w.Body.String())
+	assert.Equal(t, "@1767225600", w.Header().Get(deprecationHeader))
+	assert.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", w.Header().Get(sunsetHeader))
+	assert.Equal(t, `<https://example.com/migrate>; rel="deprecation"`, w.Header().Get("Link"))
+
+	w = versionedRequest(r, "/api/users", map[string]string{"X-API-Version": "1"})
+	assert.Equal(t, "users", w.Body.String())
+	assert.NotEmpty(t, w.Header().Get(deprecationHeader))
+
+	w = versionedRequest(r, "/api/users", map[string]string{"X-API-Version": "3"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var routes []string
+	for _, route := range r.Routes() {
+		routes = append(routes, route.Method+" "+route.Path)
+	}
+	assert.Equal(t, []string{
+		"GET /api/v1/users", "GET /api/v2/users", "GET /api/v1/users/:id", "GET /api/v2/users/:id",
+	}, routes)
+}
+
+func Test_versionedRoutes(t *testing.T) {
+	var routes versionedRoutes
+	engine := gin.New()
+	var calls []string
+	handler := func(name string) gin.HandlerFunc {
+		return func(ctx *gin.Context) {
+			calls = append(calls, name)
+			ctx.Next()
+			calls = append(calls, name+" done")
+		}
+	}
+	serve := func(path string) int {
+		calls = nil
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+	route := func(group, path string, names ...string) *versionedRoute {
+		var handlers []gin.HandlerFunc
+		for _, name := range names {
+			handlers = append(handlers, handler(name))
+		}
+		return &versionedRoute{group: engine.Group(group), httpMethod: http.MethodGet, relativePath: path, handlers: handlers}
+	}
+
+	// the route of one version is registered at once, and the shared one is not registered to it
+	assert.True(t, routes.mount(route("/v1", "/a", "a", "b"), true))
+	assert.True(t, routes.mount(route("/v2", "/a", "a", "b"), true))
+	assert.True(t, routes.mount(route("/v2", "/a", "c", "d"), false))
+	assert.False(t, routes.mount(route("/v2", "/a", "e"), true))
+	assert.Len(t, engine.Routes(), 1)
+
+	// routes are registered as a real chain, so `ctx.Next()` runs the rest handlers of the route
+	assert.Equal(t, http.StatusOK, serve("/v2/a"))
+	assert.Equal(t, []string{"c", "d", "d done", "c done"}, calls)
+	assert.Equal(t, http.StatusNotFound, serve("/v1/a"))
+
+	routes.resolve()
+	assert.Len(t, engine.Routes(), 2)
+	assert.Equal(t, http.StatusOK, serve("/v1/a"))
+	assert.Equal(t, []string{"a", "b", "b done", "a done"}, calls)
+
+	// shared routes mounted after resolving are registered at once
+	assert.True(t, routes.mount(route("/v1", "/b", "f"), true))
+	assert.False(t, routes.mount(route("/v2", "/a", "g"), true))
+	assert.Equal(t, http.StatusOK, serve("/v1/b"))
+	assert.Equal(t, []string{"f", "f done"}, calls)
+}
+
+func Test_apiVersioning_middleware(t *testing.T) {
+	r, v := newVersioningRouter(t)
+	cache, _ := newResponseCache(t, nil)
+	api := r.Group("/api")
+	v1 := v.Version(api, "v1", DefaultVersion())
+	v1.GET("/x", cache.Cache(time.Minute), gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "hello")
+	}))
+
+	w := versionedRequest(r, "/api/x", nil)
+	assert.Equal(t, "hello", w.Body.String())
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+
+	w = versionedRequest(r, "/api/V1/x", nil)
+	assert.Equal(t, "hello", w.Body.String())
+	assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+}
+
+type bookCtr struct {
+	gone.Flag
+	r          IRouter    `gone:"*"`
+	versioning Versioning `gone:"*"`
+}
+
+func (c *bookCtr) Mount() MountError {
+	api := c.r.Group("/api")
+	c.versioning.Version(api, "v1", Deprecated(time.Time{}))
+	c.versioning.Version(api, "v2", DefaultVersion())
+	books := c.versioning.Versions(api, "v1", "v2")
+	books.GET("/books", func() string { return "books" })
+	c.versioning.Version(api, "v2").GET("/books", func() string { return "books v2" })
+	return nil
+}
+
+func Test_apiVersioning_withLoad(t *testing.T) {
+	t.Setenv("GONE_SERVER_PORT", "0")
+
+	get := func(s *server, path, version string) (string, string) {
+		req, _ := http.NewRequest(http.MethodGet, "http://"+s.getAddress()+path, nil)
+		if version != "" {
+			req.Header.Set("X-API-Version", version)
+		}
+		res, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		defer res.Body.Close()
+		all, _ := io.ReadAll(res.Body)
+		return string(all), res.Header.Get(deprecationHeader)
+	}
+
+	gone.
+		NewApp(Load).
+		Load(&bookCtr{}).
+		Run(func(s *server) {
+			body, deprecation := get(s, "/api/books", "")
+			assert.Contains(t, body, "books v2")
+			assert.Empty(t, deprecation)
+
+			body, deprecation = get(s, "/api/books", "v1")
+			assert.NotContains(t, body, "v2")
+			assert.Equal(t, "true", deprecation)
+		})
+}
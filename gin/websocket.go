@@ -0,0 +1,435 @@
+package gin
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"github.com/gone-io/goner/gin/internal/json"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	TextMessage   = websocket.TextMessage
+	BinaryMessage = websocket.BinaryMessage
+)
+
+// WebSocketConn upgraded WebSocket connection, inject it into handler to serve WebSocket:
+//
+//	func (c *ctr) chat(conn gin.WebSocketConn) error {
+//		for {
+//			var msg Message
+//			if err := conn.ReadJSON(&msg); err != nil {
+//				return nil
+//			}
+//			...
+//		}
+//	}
+//
+// The connection is closed when the handler returns, an error returned by the handler is sent as the reason of close frame.
+// It is safe to write messages concurrently, but reading must be done in one goroutine.
+type WebSocketConn interface {
+	// ReadMessage read next message, messageType is TextMessage or BinaryMessage
+	ReadMessage() (messageType int, data []byte, err error)
+	ReadJSON(v any) error
+	WriteMessage(messageType int, data []byte) error
+	WriteJSON(v any) error
+
+	// Context the context of the request, which carries the trace id
+	Context() context.Context
+	TraceId() string
+
+	// Close send close frame with code and reason, and close the connection
+	Close(code int, reason string) error
+}
+
+const webSocketConnKey = "gone-gin-websocket-conn"
+
+// webSocket upgrades http request to WebSocket connection, it serves the handlers injecting WebSocketConn
+// and the handlers returning channels for WebSocket upgrade requests.
+type webSocket struct {
+	gone.Flag
+	logger    gone.Logger `gone:"*"`
+	tracer    g.Tracer    `gone:"*" option:"allowNil"`
+	responser Responser   `gone:"*"`
+
+	readBufferSize    int           `gone:"config,server.websocket.read-buffer-size,default=4096"`
+	writeBufferSize   int           `gone:"config,server.websocket.write-buffer-size,default=4096"`
+	maxMessageSize    int64         `gone:"config,server.websocket.max-message-size,default=1048576"`
+	pingInterval      time.Duration `gone:"config,server.websocket.ping-interval,default=30s"`
+	pongWait          time.Duration `gone:"config,server.websocket.pong-wait,default=60s"`
+	writeTimeout      time.Duration `gone:"config,server.websocket.write-timeout,default=10s"`
+	enableCompression bool          `gone:"config,server.websocket.enable-compression,default=false"`
+
+	// allowedOrigins 允许跨域的Origin，多个以逗号分隔，`*`表示允许所有；默认为空，只允许同源请求
+	allowedOrigins string `gone:"config,server.websocket.allowed-origins"`
+	tracerIdKey    string `gone:"config,server.req.x-trace-id-key=X-Trace-Id"`
+
+	upgrader websocket.Upgrader
+	conns    sync.Map
+}
+
+func (w *webSocket) GonerName() string {
+	return IdGoneGinWebSocket
+}
+
+func (w *webSocket) Init() error {
+	if w.pongWait > 0 && w.pingInterval >= w.pongWait {
+		return gone.NewInnerErrorWithParams(gone.ConfigError, "server.websocket.ping-interval(%s) must be less than server.websocket.pong-wait(%s)", w.pingInterval, w.pongWait)
+	}
+	w.upgrader = websocket.Upgrader{
+		ReadBufferSize:    w.readBufferSize,
+		WriteBufferSize:   w.writeBufferSize,
+		EnableCompression: w.enableCompression,
+		// the error is responded by Responser
+		Error: func(http.ResponseWriter, *http.Request, int, error) {},
+	}
+	if w.allowedOrigins != "" {
+		origins := strings.Split(w.allowedOrigins, ",")
+		w.upgrader.CheckOrigin = func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			for _, o := range origins {
+				if o = strings.TrimSpace(o); o == "*" || strings.EqualFold(o, origin) {
+					return true
+				}
+			}
+			return origin == ""
+		}
+	}
+	return nil
+}
+
+// Parse upgrade the request and return the WebSocketConn, the same connection is returned if it has been upgraded.
+func (w *webSocket) Parse(ctx *gin.Context) (reflect.Value, error) {
+	conn, err := w.upgrade(ctx)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(conn), nil
+}
+
+func (w *webSocket) Type() reflect.Type {
+	return gone.GetInterfaceType(new(WebSocketConn))
+}
+
+func (w *webSocket) upgrade(ctx *gin.Context) (*webSocketConn, error) {
+	if v, ok := ctx.Get(webSocketConnKey); ok {
+		return v.(*webSocketConn), nil
+	}
+
+	var traceId string
+	if v, ok := ctx.Request.Context().Value(w.tracerIdKey).(string); ok {
+		traceId = v
+	}
+	header := http.Header{}
+	if traceId != "" {
+		header.Set(w.tracerIdKey, traceId)
+	}
+
+	c, err := w.upgrader.Upgrade(ctx.Writer, ctx.Request, header)
+	if err != nil {
+		return nil, gone.NewError(http.StatusBadRequest, "websocket upgrade failed: "+err.Error(), http.StatusBadRequest)
+	}
+
+	conn := &webSocketConn{
+		conn:         c,
+		ctx:          ctx.Request.Context(),
+		traceId:      traceId,
+		writeTimeout: w.writeTimeout,
+		done:         make(chan struct{}),
+	}
+	if w.maxMessageSize > 0 {
+		c.SetReadLimit(w.maxMessageSize)
+	}
+	if w.pongWait > 0 {
+		_ = c.SetReadDeadline(time.Now().Add(w.pongWait))
+		c.SetPongHandler(func(string) error {
+			return c.SetReadDeadline(time.Now().Add(w.pongWait))
+		})
+	}
+	if w.pingInterval > 0 {
+		w.goWithTracer(func() {
+			conn.keepalive(w.pingInterval)
+		})
+	}
+
+	w.conns.Store(conn, struct{}{})
+	ctx.Set(webSocketConnKey, conn)
+	return conn, nil
+}
+
+// goWithTracer start goroutine which inherits the trace id of the handler
+func (w *webSocket) goWithTracer(fn func()) {
+	if w.tracer == nil {
+		go fn()
+		return
+	}
+	w.tracer.Go(fn)
+}
+
+// processResults serve WebSocket with the results of handler, return true if the request is served as WebSocket:
+// the connection injected is closed when the last handler returns;
+// for WebSocket upgrade requests, the channels returned by the last handler are served over an upgraded connection.
+func (w *webSocket) processResults(ctx *gin.Context, last bool, results []any) bool {
+	var handlerErr error
+	for _, result := range results {
+		if err, ok := result.(error); ok {
+			handlerErr = err
+			break
+		}
+	}
+
+	if v, ok := ctx.Get(webSocketConnKey); ok {
+		if last || handlerErr != nil {
+			w.close(v.(*webSocketConn), handlerErr)
+		}
+		return true
+	}
+
+	if !last || handlerErr != nil || !websocket.IsWebSocketUpgrade(ctx.Request) {
+		return false
+	}
+
+	var out, in reflect.Value
+	for _, result := range results {
+		v := reflect.ValueOf(result)
+		if v.Kind() != reflect.Chan {
+			continue
+		}
+		if v.Type().ChanDir() == reflect.SendDir {
+			if !in.IsValid() {
+				in = v
+			}
+		} else if !out.IsValid() {
+			out = v
+		}
+	}
+	if !out.IsValid() && !in.IsValid() {
+		return false
+	}
+
+	conn, err := w.upgrade(ctx)
+	if err != nil {
+		w.responser.Failed(ctx, err)
+		return true
+	}
+	w.serveChannels(conn, out, in)
+	return true
+}
+
+// serveChannels write the items received from out to the connection, and send the messages read from connection to in.
+// It returns when out is closed, or the connection is closed by client.
+func (w *webSocket) serveChannels(conn *webSocketConn, out, in reflect.Value) {
+	readDone := make(chan struct{})
+	w.goWithTracer(func() {
+		defer close(readDone)
+		w.readTo(conn, in)
+	})
+
+	var err error
+	if out.IsValid() {
+		cases := []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: out},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(readDone)},
+		}
+		for {
+			chosen, v, ok := reflect.Select(cases)
+			if chosen == 1 || !ok {
+				break
+			}
+			if err = w.write(conn, v.Interface()); err != nil {
+				w.logger.Warnf("write websocket message failed: %v", err)
+				break
+			}
+		}
+	} else {
+		<-readDone
+	}
+	w.close(conn, nil)
+}
+
+func (w *webSocket) write(conn *webSocketConn, item any) error {
+	switch t := item.(type) {
+	case []byte:
+		return conn.WriteMessage(BinaryMessage, t)
+	case string:
+		return conn.WriteMessage(TextMessage, []byte(t))
+	}
+	if s, ok := w.responser.(interface{ streamData(any) any }); ok {
+		item = s.streamData(item)
+	}
+	return conn.WriteJSON(item)
+}
+
+// readTo read messages until the connection is closed, messages are decoded to the element type of in
+// unless it is []byte or string; in is closed when reading ends.
+func (w *webSocket) readTo(conn *webSocketConn, in reflect.Value) {
+	if in.IsValid() {
+		defer in.Close()
+	}
+	elemType := reflect.Type(nil)
+	if in.IsValid() {
+		elemType = in.Type().Elem()
+	}
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+				w.logger.Debugf("read websocket message ended: %v", err)
+			}
+			return
+		}
+		if elemType == nil {
+			continue
+		}
+
+		var v reflect.Value
+		switch elemType.Kind() {
+		case reflect.String:
+			v = reflect.ValueOf(string(data)).Convert(elemType)
+		case reflect.Slice:
+			if elemType.Elem().Kind() == reflect.Uint8 {
+				v = reflect.ValueOf(data).Convert(elemType)
+				break
+			}
+			fallthrough
+		default:
+			p := reflect.New(elemType)
+			if err = json.Unmarshal(data, p.Interface()); err != nil {
+				w.logger.Warnf("decode websocket message failed: %v", err)
+				continue
+			}
+			v = p.Elem()
+		}
+
+		chosen, _, _ := reflect.Select([]reflect.SelectCase{
+			{Dir: reflect.SelectSend, Chan: in, Send: v},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(conn.done)},
+		})
+		if chosen == 1 {
+			return
+		}
+	}
+}
+
+func (w *webSocket) close(conn *webSocketConn, err error) {
+	w.conns.Delete(conn)
+	code, reason := websocket.CloseNormalClosure, ""
+	if err != nil {
+		gErr := ToError(err)
+		code, reason = websocket.CloseInternalServerErr, gErr.Msg()
+		if iErr, ok := gErr.(gone.InnerError); ok {
+			w.logger.Errorf("websocket handler error: %v\n%s", iErr.Msg(), iErr.Stack())
+			reason = InternalServerError
+		}
+	}
+	if cErr := conn.Close(code, reason); cErr != nil {
+		w.logger.Debugf("close websocket failed: %v", cErr)
+	}
+}
+
+// closeAll send close frame to all connections, it is called when the server is shutting down.
+func (w *webSocket) closeAll() {
+	w.conns.Range(func(key, _ any) bool {
+		conn := key.(*webSocketConn)
+		if err := conn.goingAway(); err != nil {
+			w.logger.Debugf("close websocket failed: %v", err)
+		}
+		return true
+	})
+}
+
+type webSocketConn struct {
+	conn         *websocket.Conn
+	ctx          context.Context
+	traceId      string
+	writeTimeout time.Duration
+	writeLock    sync.Mutex
+	closeOnce    sync.Once
+	done         chan struct{}
+}
+
+// maxCloseReasonLen close reason must fit into a control frame with 2 bytes code
+const maxCloseReasonLen = 123
+
+// closeWait the time waiting for the client to reply the close frame
+const closeWait = time.Second
+
+func (c *webSocketConn) ReadMessage() (int, []byte, error) {
+	return c.conn.ReadMessage()
+}
+
+func (c *webSocketConn) ReadJSON(v any) error {
+	_, data, err := c.conn.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (c *webSocketConn) WriteMessage(messageType int, data []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	if c.writeTimeout > 0 {
+		_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	}
+	return c.conn.WriteMessage(messageType, data)
+}
+
+func (c *webSocketConn) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(TextMessage, data)
+}
+
+func (c *webSocketConn) Context() context.Context {
+	return c.ctx
+}
+
+func (c *webSocketConn) TraceId() string {
+	return c.traceId
+}
+
+func (c *webSocketConn) Close(code int, reason string) (err error) {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		if len(reason) > maxCloseReasonLen {
+			reason = reason[:maxCloseReasonLen]
+		}
+		_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(closeWait))
+		err = c.conn.Close()
+	})
+	return
+}
+
+// goingAway notify the client that server is going away, and make the blocked reading return after closeWait,
+// so that the handler can return and the connection is closed.
+func (c *webSocketConn) goingAway() error {
+	err := c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server stopping"), time.Now().Add(closeWait))
+	_ = c.conn.SetReadDeadline(time.Now().Add(closeWait))
+	return err
+}
+
+func (c *webSocketConn) keepalive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(closeWait)); err != nil {
+				return
+			}
+		}
+	}
+}
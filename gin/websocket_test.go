@@ -0,0 +1,252 @@
+package gin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gone-io/gone/v2"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestWebSocket(t *testing.T) *webSocket {
+	controller := gomock.NewController(t)
+	logger := gone.NewMockLogger(controller)
+	logger.EXPECT().Warnf(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Debugf(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Errorf(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	w := &webSocket{
+		logger:         logger,
+		responser:      &responser{wrappedDataFunc: wrapFunc, returnWrappedData: true},
+		maxMessageSize: 64,
+		pingInterval:   time.Second,
+		pongWait:       2 * time.Second,
+		writeTimeout:   time.Second,
+		tracerIdKey:    "X-Trace-Id",
+	}
+	assert.Nil(t, w.Init())
+	return w
+}
+
+// serve emulate the proxy: inject WebSocketConn if need, call the handler and process its results
+func serve(w *webSocket, inject bool, handler func(ctx *gin.Context, conn WebSocketConn) []any) *httptest.Server {
+	engine := gin.New()
+	engine.GET("/ws", func(ctx *gin.Context) {
+		ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), "X-Trace-Id", "trace-1"))
+		var conn WebSocketConn
+		if inject {
+			v, err := w.Parse(ctx)
+			if err != nil {
+				w.processResults(ctx, true, []any{err})
+				w.responser.Failed(ctx, err)
+				return
+			}
+			conn = v.Interface().(WebSocketConn)
+		}
+		results := handler(ctx, conn)
+		if !w.processResults(ctx, true, results) {
+			w.responser.ProcessResults(ctx, ctx.Writer, true, "", results...)
+		}
+	})
+	return httptest.NewServer(engine)
+}
+
+func dial(t *testing.T, server *httptest.Server) (*websocket.Conn, *http.Response) {
+	conn, res, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	assert.Nil(t, err)
+	return conn, res
+}
+
+func Test_webSocket_inject(t *testing.T) {
+	w := newTestWebSocket(t)
+	server := serve(w, true, func(ctx *gin.Context, conn WebSocketConn) []any {
+		assert.Equal(t, "trace-1", conn.TraceId())
+		for {
+			var msg map[string]string
+			if err := conn.ReadJSON(&msg); err != nil {
+				return nil
+			}
+			if msg["text"] == "boom" {
+				return []any{gone.NewParameterError("boom")}
+			}
+			assert.Nil(t, conn.WriteJSON(msg))
+		}
+	})
+	defer server.Close()
+
+	conn, res := dial(t, server)
+	defer conn.Close()
+	assert.Equal(t, "trace-1", res.Header.Get("X-Trace-Id"))
+
+	assert.Nil(t, conn.WriteJSON(map[string]string{"text": "hello"}))
+	var echo map[string]string
+	assert.Nil(t, conn.ReadJSON(&echo))
+	assert.Equal(t, "hello", echo["text"])
+
+	assert.Nil(t, conn.WriteJSON(map[string]string{"text": "boom"}))
+	_, _, err := conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	assert.True(t, errors.As(err, &closeErr))
+	assert.Equal(t, websocket.CloseInternalServerErr, closeErr.Code)
+	assert.Equal(t, "boom", closeErr.Text)
+}
+
+func Test_webSocket_messageTooLarge(t *testing.T) {
+	w := newTestWebSocket(t)
+	readErr := make(chan error, 1)
+	server := serve(w, true, func(ctx *gin.Context, conn WebSocketConn) []any {
+		_, _, err := conn.ReadMessage()
+		readErr <- err
+		return nil
+	})
+	defer server.Close()
+
+	conn, _ := dial(t, server)
+	defer conn.Close()
+	assert.Nil(t, conn.WriteMessage(websocket.TextMessage, []byte(strings.Repeat("x", 100))))
+	assert.ErrorIs(t, <-readErr, websocket.ErrReadLimit)
+}
+
+func Test_webSocket_channels(t *testing.T) {
+	w := newTestWebSocket(t)
+	server := serve(w, false, func(ctx *gin.Context, _ WebSocketConn) []any {
+		in := make(chan map[string]int)
+		out := make(chan any)
+		go func() {
+			defer close(out)
+			out <- "welcome"
+			for msg := range in {
+				if msg["n"] < 0 {
+					out <- gone.NewParameterError("negative")
+					return
+				}
+				out <- map[string]int{"n": msg["n"] * 2}
+			}
+		}()
+		return []any{(<-chan any)(out), (chan<- map[string]int)(in)}
+	})
+	defer server.Close()
+
+	conn, _ := dial(t, server)
+	defer conn.Close()
+
+	_, data, err := conn.ReadMessage()
+	assert.Nil(t, err)
+	assert.Equal(t, "welcome", string(data))
+
+	assert.Nil(t, conn.WriteJSON(map[string]int{"n": 2}))
+	_, data, err = conn.ReadMessage()
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"n":4}`, string(data))
+
+	assert.Nil(t, conn.WriteJSON(map[string]int{"n": -1}))
+	_, data, err = conn.ReadMessage()
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"code":400,"msg":"negative"}`, string(data))
+
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+}
+
+func Test_webSocket_channelsWithoutUpgrade(t *testing.T) {
+	w := newTestWebSocket(t)
+	server := serve(w, false, func(ctx *gin.Context, _ WebSocketConn) []any {
+		out := make(chan string, 1)
+		out <- "x"
+		close(out)
+		return []any{out}
+	})
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/ws")
+	assert.Nil(t, err)
+	defer res.Body.Close()
+	assert.Contains(t, res.Header.Get("Content-Type"), "text/event-stream")
+}
+
+func Test_webSocket_closeAll(t *testing.T) {
+	w := newTestWebSocket(t)
+	returned := make(chan struct{})
+	server := serve(w, true, func(ctx *gin.Context, conn WebSocketConn) []any {
+		defer close(returned)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return nil
+			}
+		}
+	})
+	defer server.Close()
+
+	conn, _ := dial(t, server)
+	defer conn.Close()
+	assert.Eventually(t, func() bool {
+		n := 0
+		w.conns.Range(func(any, any) bool { n++; return true })
+		return n == 1
+	}, time.Second, 10*time.Millisecond)
+
+	w.closeAll()
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway))
+
+	select {
+	case <-returned:
+	case <-time.After(3 * time.Second):
+		t.Fatal("handler should return after server going away")
+	}
+}
+
+func Test_webSocket_Init(t *testing.T) {
+	w := &webSocket{pingInterval: time.Minute, pongWait: time.Second}
+	assert.Error(t, w.Init())
+
+	w = &webSocket{allowedOrigins: "https://a.com, https://b.com"}
+	assert.Nil(t, w.Init())
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://b.com")
+	assert.True(t, w.upgrader.CheckOrigin(req))
+	req.Header.Set("Origin", "https://c.com")
+	assert.False(t, w.upgrader.CheckOrigin(req))
+}
+
+type wsCtr struct {
+	gone.Flag
+	r IRouter `gone:"*"`
+}
+
+func (c *wsCtr) Mount() MountError {
+	c.r.GET("/ws", func(conn WebSocketConn, in struct {
+		name string `gone:"http,query"`
+	}) error {
+		return conn.WriteJSON(map[string]string{"hello": in.name})
+	})
+	return nil
+}
+
+func Test_webSocket_withProxy(t *testing.T) {
+	t.Setenv("GONE_SERVER_PORT", "0")
+
+	gone.
+		NewApp(Load).
+		Load(&wsCtr{}).
+		Run(func(s *server) {
+			conn, _, err := websocket.DefaultDialer.Dial("ws://"+s.getAddress()+"/ws?name=gone", nil)
+			assert.Nil(t, err)
+			defer conn.Close()
+
+			var msg map[string]string
+			assert.Nil(t, conn.ReadJSON(&msg))
+			assert.Equal(t, "gone", msg["hello"])
+
+			_, _, err = conn.ReadMessage()
+			assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+		})
+}
@@ -0,0 +1,33 @@
+package gorm
+
+import (
+	"context"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"gorm.io/gorm"
+)
+
+// healthChecker checks the default database with ping, it is used by the readiness probe of goner/gin.
+type healthChecker struct {
+	gone.Flag
+	db *gorm.DB `gone:"*"`
+}
+
+var _ g.HealthChecker = (*healthChecker)(nil)
+
+func (h *healthChecker) GonerName() string {
+	return "gone-gorm-health-checker"
+}
+
+func (h *healthChecker) HealthCheckName() string {
+	return "gorm"
+}
+
+func (h *healthChecker) CheckHealth(ctx context.Context) error {
+	db, err := h.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.PingContext(ctx)
+}
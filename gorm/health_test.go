@@ -0,0 +1,44 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestLoadHealthChecker(t *testing.T) {
+	sqlDb, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	assert.Nil(t, err)
+	defer sqlDb.Close()
+	// gorm pings the database when it is opened
+	mock.ExpectPing()
+
+	controller := gomock.NewController(t)
+	dialector := NewMockDialector(controller)
+	dialector.EXPECT().Initialize(gomock.Any()).DoAndReturn(func(db *gorm.DB) error {
+		db.ConnPool = sqlDb
+		return nil
+	})
+
+	gone.
+		NewApp(LoadHealthChecker, g.NamedThirdComponentLoadFunc[gorm.Dialector]("", dialector)).
+		Run(func(in struct {
+			checkers []g.HealthChecker `gone:"*"`
+		}) {
+			assert.Len(t, in.checkers, 1)
+			checker := in.checkers[0]
+			assert.Equal(t, "gorm", checker.HealthCheckName())
+
+			mock.ExpectPing()
+			assert.Nil(t, checker.CheckHealth(context.Background()))
+			mock.ExpectPing().WillReturnError(errors.New("refused"))
+			assert.Error(t, checker.CheckHealth(context.Background()))
+		})
+}
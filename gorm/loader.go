@@ -11,3 +11,12 @@ func Load(loader gone.Loader) error {
 		MustLoad(&dbProvider{})
 	return nil
 }
+
+// LoadHealthChecker load gorm and the g.HealthChecker pinging the default database, which is checked by the
+// readiness probe of goner/gin.
+func LoadHealthChecker(loader gone.Loader) error {
+	loader.
+		MustLoad(&healthChecker{}).
+		MustLoadX(Load)
+	return nil
+}
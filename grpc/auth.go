@@ -0,0 +1,95 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Principal the authenticated caller of RPC, which is injected into the context of handler by the auth interceptor.
+type Principal struct {
+	Subject string
+	Scopes  []string
+	Claims  map[string]any
+}
+
+// HasScopes return true if the principal has all the scopes
+func (p *Principal) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		found := false
+		for _, s := range p.Scopes {
+			if s == scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+type principalKey struct{}
+
+// WithPrincipal return a copy of ctx carrying the principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext return the principal authenticated by the auth interceptor.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// authenticate the token in metadata `server.grpc.auth.metadata-key`, the `Bearer ` prefix is optional;
+// methods matching `server.grpc.auth.skip-methods` are not authenticated.
+func (i *serverInterceptor) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if !i.authEnabled || matchMethods(i.authSkipMethods, method) {
+		return ctx, nil
+	}
+	var token string
+	if values := metadata.ValueFromIncomingContext(ctx, i.authMetadataKey); len(values) > 0 {
+		token = values[0]
+	}
+	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+		token = token[7:]
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ctx, status.Error(codes.Unauthenticated, "missing token")
+	}
+
+	principal, err := i.authenticator.Authenticate(ctx, token)
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return ctx, err
+		}
+		return ctx, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if principal == nil {
+		return ctx, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return WithPrincipal(ctx, principal), nil
+}
+
+func (i *serverInterceptor) authUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := i.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (i *serverInterceptor) authStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := i.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, withContext(ss, ctx))
+}
@@ -0,0 +1,85 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestPrincipal(t *testing.T) {
+	p := &Principal{Subject: "u1", Scopes: []string{"read", "write"}}
+	assert.True(t, p.HasScopes("read", "write"))
+	assert.False(t, p.HasScopes("read", "admin"))
+
+	_, ok := PrincipalFromContext(context.Background())
+	assert.False(t, ok)
+	got, ok := PrincipalFromContext(WithPrincipal(context.Background(), p))
+	assert.True(t, ok)
+	assert.Equal(t, p, got)
+}
+
+func Test_serverInterceptor_auth(t *testing.T) {
+	controller := gomock.NewController(t)
+	authenticator := NewMockAuthenticator(controller)
+	i := &serverInterceptor{
+		authenticator:   authenticator,
+		authEnabled:     true,
+		authMetadataKey: "authorization",
+		authSkip:        "/grpc.health.v1.Health/*, /pkg.Svc/Public",
+	}
+	assert.Nil(t, i.Init())
+
+	withToken := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", token))
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Get"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		p, _ := PrincipalFromContext(ctx)
+		return p, nil
+	}
+
+	_, err := i.authUnary(context.Background(), nil, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	authenticator.EXPECT().Authenticate(gomock.Any(), "t1").Return(&Principal{Subject: "u1"}, nil)
+	resp, err := i.authUnary(withToken("Bearer t1"), nil, info, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, "u1", resp.(*Principal).Subject)
+
+	authenticator.EXPECT().Authenticate(gomock.Any(), "t2").Return(nil, errors.New("expired"))
+	_, err = i.authUnary(withToken("t2"), nil, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	authenticator.EXPECT().Authenticate(gomock.Any(), "t3").Return(nil, status.Error(codes.PermissionDenied, "banned"))
+	_, err = i.authUnary(withToken("t3"), nil, info, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	authenticator.EXPECT().Authenticate(gomock.Any(), "t4").Return(nil, nil)
+	_, err = i.authUnary(withToken("t4"), nil, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	resp, err = i.authUnary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Public"}, handler)
+	assert.Nil(t, err)
+	assert.Nil(t, resp)
+
+	authenticator.EXPECT().Authenticate(gomock.Any(), "t1").Return(&Principal{Subject: "u1"}, nil)
+	ss := &fakeServerStream{ctx: withToken("t1")}
+	err = i.authStream(nil, ss, &grpc.StreamServerInfo{FullMethod: "/pkg.Svc/Watch"}, func(srv any, stream grpc.ServerStream) error {
+		p, ok := PrincipalFromContext(stream.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "u1", p.Subject)
+		return nil
+	})
+	assert.Nil(t, err)
+	err = i.authStream(nil, &fakeServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/pkg.Svc/Watch"}, nil)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	assert.Error(t, (&serverInterceptor{authEnabled: true}).Init())
+}
@@ -0,0 +1,123 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// BreakByTarget calls to the same target share a circuit
+	BreakByTarget = "target"
+
+	// BreakByService calls to the same service of target share a circuit
+	BreakByService = "service"
+
+	// BreakByMethod calls to the same method of target share a circuit
+	BreakByMethod = "method"
+)
+
+var meterName = "grpc"
+
+// ErrCircuitOpen is returned when a call is rejected by an open circuit, check it by errors.Is
+var ErrCircuitOpen = status.Error(codes.Unavailable, g.ErrCircuitOpen.Msg())
+
+// failureCodes the codes counted as failures by circuit breaker, which indicate the downstream is unhealthy
+var failureCodes = map[codes.Code]bool{
+	codes.Unknown:           true,
+	codes.DeadlineExceeded:  true,
+	codes.ResourceExhausted: true,
+	codes.Internal:          true,
+	codes.Unavailable:       true,
+	codes.DataLoss:          true,
+}
+
+// Fallback is called when a unary call is rejected by an open circuit, err is ErrCircuitOpen; it can fill reply with
+// cached or default data and return nil.
+type Fallback func(ctx context.Context, method string, req, reply any, err error) error
+
+type fallbackKey struct{}
+
+// WithFallback return a context carrying the fallback for unary calls.
+func WithFallback(ctx context.Context, fallback Fallback) context.Context {
+	return context.WithValue(ctx, fallbackKey{}, fallback)
+}
+
+type circuitBreaker struct {
+	*g.ClientCircuitBreaker
+}
+
+func newCircuitBreaker(config g.CircuitBreakerConfig, logger gone.Logger) (*circuitBreaker, error) {
+	breaker, err := config.NewClientCircuitBreaker("server.grpc.client.circuit-breaker", "rpc.client", logger, BreakByTarget, BreakByService, BreakByMethod)
+	if err != nil {
+		return nil, err
+	}
+	return &circuitBreaker{ClientCircuitBreaker: breaker}, breaker.ObserveMetrics(meterName)
+}
+
+// key of the circuit, method is the full method name, eg: `/package.Service/Method`
+func (b *circuitBreaker) key(target, method string) string {
+	switch b.KeyBy {
+	case BreakByService:
+		if i := strings.LastIndex(method, "/"); i > 0 {
+			return target + method[:i]
+		}
+		return target + method
+	case BreakByMethod:
+		return target + method
+	default:
+		return target
+	}
+}
+
+func (b *circuitBreaker) allow(cc *grpc.ClientConn, method string) (func(failed bool), error) {
+	key := b.key(cc.Target(), method)
+	done, err := b.Allow(key)
+	if err != nil {
+		return nil, ErrCircuitOpen
+	}
+	return done, nil
+}
+
+func (b *circuitBreaker) unaryInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	done, err := b.allow(cc, method)
+	if err != nil {
+		if fallback, ok := ctx.Value(fallbackKey{}).(Fallback); ok {
+			return fallback(ctx, method, req, reply, err)
+		}
+		return err
+	}
+	err = invoker(ctx, method, req, reply, cc, opts...)
+	done(failureCodes[status.Code(err)])
+	return err
+}
+
+// streamInterceptor only the result of establishing streams is counted
+func (b *circuitBreaker) streamInterceptor(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	done, err := b.allow(cc, method)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	done(failureCodes[status.Code(err)])
+	return stream, err
+}
@@ -0,0 +1,103 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func Test_circuitBreaker_key(t *testing.T) {
+	tests := []struct {
+		keyBy string
+		want  string
+	}{
+		{keyBy: "", want: "dns:///user"},
+		{keyBy: BreakByService, want: "dns:///user/user.User"},
+		{keyBy: BreakByMethod, want: "dns:///user/user.User/Get"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keyBy, func(t *testing.T) {
+			b, err := newCircuitBreaker(g.CircuitBreakerConfig{KeyBy: tt.keyBy}, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, b.key("dns:///user", "/user.User/Get"))
+		})
+	}
+
+	_, err := newCircuitBreaker(g.CircuitBreakerConfig{KeyBy: "host"}, nil)
+	assert.Error(t, err)
+	_, err = newCircuitBreaker(g.CircuitBreakerConfig{MinRequests: -1}, nil)
+	assert.Error(t, err)
+}
+
+func Test_clientRegister_circuitBreaker(t *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	service := &flakyHealth{failures: 2}
+	healthpb.RegisterHealthServer(server, service)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	register := clientRegister{
+		logger:      gone.GetDefaultLogger(),
+		connections: make(map[string]*grpc.ClientConn),
+		grpcOptions: []grpc.DialOption{
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		},
+		insecure:    true,
+		tracerIdKey: "X-Trace-Id",
+		breakerConfig: g.CircuitBreakerConfig{
+			Enabled:     true,
+			KeyBy:       BreakByMethod,
+			MinRequests: 2,
+			OpenTimeout: time.Hour,
+		},
+	}
+	assert.Nil(t, register.Init())
+	conn := register.getConn("bufnet", "passthrough:///bufnet")
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	}
+
+	// the downstream recovers, but calls are rejected without reaching it
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int32(2), service.calls.Load())
+
+	ctx := WithFallback(context.Background(), func(_ context.Context, method string, _, reply any, err error) error {
+		assert.Equal(t, "/grpc.health.v1.Health/Check", method)
+		assert.Equal(t, ErrCircuitOpen, err)
+		reply.(*healthpb.HealthCheckResponse).Status = healthpb.HealthCheckResponse_UNKNOWN
+		return nil
+	})
+	res, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_UNKNOWN, res.Status)
+
+	// circuits of other methods are not affected
+	stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+	assert.NotNil(t, stream)
+
+	register.breaker.CircuitBreaker, _ = (&g.CircuitBreakerConfig{MinRequests: 1}).NewCircuitBreaker(nil)
+	done, _ := register.breaker.Allow(register.breaker.key(conn.Target(), "/grpc.health.v1.Health/Watch"))
+	done(true)
+	_, err = client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+}
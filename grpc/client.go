@@ -7,6 +7,7 @@ import (
 	"github.com/gone-io/goner/g"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/resolver"
@@ -14,12 +15,15 @@ import (
 
 type clientRegister struct {
 	gone.Flag
-	logger             gone.Logger          `gone:"*"`
-	clients            []Client             `gone:"*"`
-	grpcOptions        []grpc.DialOption    `gone:"*"`
-	tracer             g.Tracer             `gone:"*" option:"allowNil"`
-	discovery          g.ServiceDiscovery   `gone:"*" option:"allowNil"`
-	isOtelTracerLoaded g.IsOtelTracerLoaded `gone:"*" option:"allowNil"`
+	logger             gone.Logger           `gone:"*"`
+	clients            []Client              `gone:"*"`
+	grpcOptions        []grpc.DialOption     `gone:"*"`
+	tracer             g.Tracer              `gone:"*" option:"allowNil"`
+	discovery          g.ServiceDiscovery    `gone:"*" option:"allowNil"`
+	feedback           g.LoadBalanceFeedback `gone:"*" option:"allowNil"`
+	router             g.LoadBalanceRouter   `gone:"*" option:"allowNil"`
+	selector           g.LoadBalanceSelector `gone:"*" option:"allowNil"`
+	isOtelTracerLoaded g.IsOtelTracerLoaded  `gone:"*" option:"allowNil"`
 
 	connections map[string]*grpc.ClientConn
 	rb          resolver.Builder
@@ -29,48 +33,144 @@ type clientRegister struct {
 	insecure            bool           `gone:"config,server.grpc.insecure=true"`
 	requestIdKey        string         `gone:"config,server.grpc.x-request-id-key=X-Request-Id"`
 	tracerIdKey         string         `gone:"config,server.grpc.x-trace-id-key=X-Trace-Id"`
+
+	// tlsConfig 客户端TLS配置，开启后忽略`server.grpc.insecure`，对应配置项为：`server.grpc.client.tls`
+	tlsConfig g.TLSConfig `gone:"config,server.grpc.client.tls"`
+
+	// targets 按目标服务的客户端配置，对应配置项为：`server.grpc.client.targets`
+	targets []ClientConfig `gone:"config,server.grpc.client.targets"`
+
+	// breakerConfig 熔断配置，对应配置项为：`server.grpc.client.circuit-breaker`
+	breakerConfig g.CircuitBreakerConfig `gone:"config,server.grpc.client.circuit-breaker"`
+	breaker       *circuitBreaker
+
+	credentials credentials.TransportCredentials
+	targetMap   map[string]*ClientConfig
 }
 
-func (s *clientRegister) Init() {
+func (s *clientRegister) Init() error {
 	if s.discovery != nil {
-		s.rb = NewResolverBuilder(s.discovery, s.logger)
+		s.rb = &resolverBuilder{discovery: s.discovery, logger: s.logger, feedback: s.feedback, router: s.router, selector: s.selector}
+	}
+	if s.tlsConfig.Enabled {
+		config, err := s.tlsConfig.ClientTLSConfig(s.logger)
+		if err != nil {
+			return err
+		}
+		s.credentials = credentials.NewTLS(config)
 	}
+	if s.breakerConfig.Enabled {
+		var err error
+		if s.breaker, err = newCircuitBreaker(s.breakerConfig, s.logger); err != nil {
+			return err
+		}
+	}
+	s.targetMap = make(map[string]*ClientConfig)
+	for i := range s.targets {
+		target := &s.targets[i]
+		if err := target.init(); err != nil {
+			return err
+		}
+		if _, ok := s.targetMap[target.Name]; ok {
+			return gone.NewInnerErrorWithParams(gone.ConfigError, "duplicate gRPC client target(%s)", target.Name)
+		}
+		s.targetMap[target.Name] = target
+	}
+	return nil
 }
 
 func NewRegister() gone.Goner {
 	return &clientRegister{connections: make(map[string]*grpc.ClientConn)}
 }
 
+// outgoingContext append the trace ID to the outgoing metadata.
+func (s *clientRegister) outgoingContext(ctx context.Context) context.Context {
+	tracerId, _ := ctx.Value(s.tracerIdKey).(string)
+	if s.tracer != nil {
+		tracerId = s.tracer.GetTraceId()
+	}
+	return metadata.AppendToOutgoingContext(ctx, s.tracerIdKey, tracerId)
+}
+
 func (s *clientRegister) traceInterceptor(
 	ctx context.Context,
 	method string,
 	req, reply interface{},
 	cc *grpc.ClientConn,
 	invoker grpc.UnaryInvoker,
-	_ ...grpc.CallOption,
+	opts ...grpc.CallOption,
 ) error {
-	tracerId, _ := ctx.Value(s.tracerIdKey).(string)
-	if s.tracer != nil {
-		tracerId = s.tracer.GetTraceId()
+	return invoker(s.outgoingContext(ctx), method, req, reply, cc, opts...)
+}
+
+func (s *clientRegister) traceStreamInterceptor(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	return streamer(s.outgoingContext(ctx), desc, cc, method, opts...)
+}
+
+// unaryMarkInterceptor mark unary calls, which are picked by LoadBalanceSelector in RouteLBPolicy, and whose results
+// are reported to LoadBalanceFeedback
+func (s *clientRegister) unaryMarkInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	return invoker(context.WithValue(ctx, unaryCallKey{}, true), method, req, reply, cc, opts...)
+}
+
+// transportOptions the transport credentials configured by `server.grpc.client.tls` or `server.grpc.insecure`
+func (s *clientRegister) transportOptions() []grpc.DialOption {
+	if s.credentials != nil {
+		return []grpc.DialOption{grpc.WithTransportCredentials(s.credentials)}
+	} else if s.insecure {
+		return []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
 	}
-	ctx = metadata.AppendToOutgoingContext(ctx, s.tracerIdKey, tracerId)
-	return invoker(ctx, method, req, reply, cc)
+	return nil
 }
 
-func (s *clientRegister) createConn(address string) (conn *grpc.ClientConn, err error) {
-	var options = append(s.grpcOptions, grpc.WithChainUnaryInterceptor(s.traceInterceptor))
-	if s.insecure {
-		options = append(options, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// createConn create the connection to address, target is the per-target configuration which can be nil.
+func (s *clientRegister) createConn(address string, target *ClientConfig) (conn *grpc.ClientConn, err error) {
+	var options = append(
+		s.grpcOptions[:len(s.grpcOptions):len(s.grpcOptions)],
+		grpc.WithChainUnaryInterceptor(s.traceInterceptor),
+		grpc.WithChainStreamInterceptor(s.traceStreamInterceptor),
+	)
+	if s.breaker != nil {
+		options = append(options,
+			grpc.WithChainUnaryInterceptor(s.breaker.unaryInterceptor),
+			grpc.WithChainStreamInterceptor(s.breaker.streamInterceptor),
+		)
+	}
+	if s.selector != nil && s.rb != nil {
+		options = append(options, grpc.WithChainUnaryInterceptor(s.unaryMarkInterceptor))
 	}
+	options = append(options, s.transportOptions()...)
 	if s.isOtelTracerLoaded {
 		options = append(options, grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
 	}
 
+	var lbPolicy string
 	if s.rb != nil {
-		options = append(options,
-			grpc.WithResolvers(s.rb),
-			grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingPolicy":"%s"}`, s.loadBalancingPolicy)),
-		)
+		lbPolicy = s.loadBalancingPolicy
+		if s.router != nil && lbPolicy == roundRobinLBPolicy {
+			lbPolicy = RouteLBPolicy
+		}
+		options = append(options, grpc.WithResolvers(s.rb))
+	}
+	if target != nil {
+		options = append(options, target.dialOptions()...)
+	}
+	if serviceConfig := target.serviceConfig(lbPolicy); serviceConfig != "" {
+		options = append(options, grpc.WithDefaultServiceConfig(serviceConfig))
 	}
 
 	return grpc.NewClient(
@@ -79,20 +179,25 @@ func (s *clientRegister) createConn(address string) (conn *grpc.ClientConn, err
 	)
 }
 
-// getConn 根据不同的地址创建 grpc.ClientConn
-func (s *clientRegister) getConn(address string) (conn *grpc.ClientConn) {
-	conn = s.connections[address]
+// getConn 根据不同的目标和地址创建 grpc.ClientConn，name 用于匹配`server.grpc.client.targets`中的配置
+func (s *clientRegister) getConn(name, address string) (conn *grpc.ClientConn) {
+	target := s.targetMap[name]
+	key := address
+	if target != nil {
+		key = name + "|" + address
+	}
+	conn = s.connections[key]
 	if conn == nil {
 		var err error
-		conn, err = s.createConn(address)
+		conn, err = s.createConn(address, target)
 		g.PanicIfErr(gone.ToErrorWithMsg(err, fmt.Sprintf("gRPC createConn for %s", address)))
-		s.connections[address] = conn
+		s.connections[key] = conn
 	}
 	return
 }
 
 func (s *clientRegister) register(client Client) {
-	conn := s.getConn(client.Address())
+	conn := s.getConn(client.Address(), client.Address())
 	client.Stub(conn)
 }
 
@@ -100,14 +205,16 @@ func (s *clientRegister) register(client Client) {
 func (s *clientRegister) Provide(tagConf string) (*grpc.ClientConn, error) {
 	m, _ := gone.TagStringParse(tagConf)
 	address := m["address"]
+	name := address
 	if configKey, ok := m["config"]; ok {
+		name = configKey
 		err := s.configure.Get(configKey, &address, address)
 		g.PanicIfErr(gone.ToErrorWithMsg(err, "get address from configure err"))
 	}
 	if address == "" {
 		return nil, gone.ToError("address is empty")
 	}
-	return s.getConn(address), nil
+	return s.getConn(name, address), nil
 }
 
 func (s *clientRegister) Start() error {
@@ -0,0 +1,325 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ClientConfig per-target configuration of gRPC client, configured by `server.grpc.client.targets`.
+// The target is matched by Name, which is the config key of `config` tag or the address of `address` tag used by
+// `*grpc.ClientConn` injection, or the Address() of Client.
+type ClientConfig struct {
+	Name string `mapstructure:"name" json:"name"`
+
+	// LoadBalancingPolicy overrides `server.grpc.lb-policy`, eg: `round_robin`, `pick_first`
+	LoadBalancingPolicy string `mapstructure:"lb-policy" json:"lb-policy"`
+
+	// Timeout default timeout of calls which do not have a shorter deadline
+	Timeout time.Duration `mapstructure:"timeout" json:"timeout"`
+
+	// WaitForReady whether calls wait for the connection to be ready instead of failing fast
+	WaitForReady bool `mapstructure:"wait-for-ready" json:"wait-for-ready"`
+
+	// MaxSendMsgSize MaxRecvMsgSize max size of request and response message in bytes, 0 means the default of gRPC
+	MaxSendMsgSize int `mapstructure:"max-send-msg-size" json:"max-send-msg-size"`
+	MaxRecvMsgSize int `mapstructure:"max-recv-msg-size" json:"max-recv-msg-size"`
+
+	// Compression compressor of requests, eg: `gzip`
+	Compression string `mapstructure:"compression" json:"compression"`
+
+	Keepalive *KeepaliveConfig `mapstructure:"keepalive" json:"keepalive"`
+
+	// Retry and Hedging are mutually exclusive
+	Retry   *RetryPolicy   `mapstructure:"retry" json:"retry"`
+	Hedging *HedgingPolicy `mapstructure:"hedging" json:"hedging"`
+}
+
+// KeepaliveConfig keepalive parameters of connection, see keepalive.ClientParameters
+type KeepaliveConfig struct {
+	Time                time.Duration `mapstructure:"time" json:"time"`
+	Timeout             time.Duration `mapstructure:"timeout" json:"timeout"`
+	PermitWithoutStream bool          `mapstructure:"permit-without-stream" json:"permit-without-stream"`
+}
+
+// RetryPolicy retry policy of calls, see https://github.com/grpc/proposal/blob/master/A6-client-retries.md
+type RetryPolicy struct {
+	// MaxAttempts including the original call, 2~5, default is 3
+	MaxAttempts int `mapstructure:"max-attempts" json:"max-attempts"`
+
+	// InitialBackoff default is 100ms; MaxBackoff default is 1s; BackoffMultiplier default is 2
+	InitialBackoff    time.Duration `mapstructure:"initial-backoff" json:"initial-backoff"`
+	MaxBackoff        time.Duration `mapstructure:"max-backoff" json:"max-backoff"`
+	BackoffMultiplier float64       `mapstructure:"backoff-multiplier" json:"backoff-multiplier"`
+
+	// RetryableStatusCodes eg: `UNAVAILABLE`, default is [`UNAVAILABLE`]
+	RetryableStatusCodes []string `mapstructure:"retryable-status-codes" json:"retryable-status-codes"`
+}
+
+// HedgingPolicy hedging policy of unary calls: the call is sent again every HedgingDelay until a response is received
+// or MaxAttempts is reached, the first successful response is used and the others are canceled.
+// Only use it for idempotent methods.
+type HedgingPolicy struct {
+	// MaxAttempts including the original call, 2~5, default is 2
+	MaxAttempts int `mapstructure:"max-attempts" json:"max-attempts"`
+
+	// HedgingDelay the delay between attempts, 0 means sending all attempts at once
+	HedgingDelay time.Duration `mapstructure:"hedging-delay" json:"hedging-delay"`
+
+	// NonFatalStatusCodes failures with these codes do not stop other attempts, and the next attempt is sent at once
+	NonFatalStatusCodes []string `mapstructure:"non-fatal-status-codes" json:"non-fatal-status-codes"`
+
+	// Methods full method names to hedge, pattern ends with `*` matches by prefix; empty means all methods
+	Methods []string `mapstructure:"methods" json:"methods"`
+
+	nonFatal map[codes.Code]bool
+}
+
+const maxCallAttempts = 5
+
+// parseCodes parse names of status codes in place, eg: `unavailable` and `resource-exhausted` are normalized to
+// `UNAVAILABLE` and `RESOURCE_EXHAUSTED`
+func parseCodes(names []string) ([]codes.Code, error) {
+	var list []codes.Code
+	for i, name := range names {
+		var code codes.Code
+		names[i] = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(name), "-", "_"))
+		if err := code.UnmarshalJSON([]byte(strconv.Quote(names[i]))); err != nil {
+			return nil, gone.NewInnerErrorWithParams(gone.ConfigError, "invalid gRPC status code(%s)", name)
+		}
+		list = append(list, code)
+	}
+	return list, nil
+}
+
+func (c *ClientConfig) init() error {
+	if c.Name == "" {
+		return gone.NewInnerError("name of gRPC client target is required", gone.ConfigError)
+	}
+	if c.Compression != "" && encoding.GetCompressor(c.Compression) == nil {
+		return gone.NewInnerErrorWithParams(gone.ConfigError, "unsupported compression(%s) of gRPC client target(%s)", c.Compression, c.Name)
+	}
+	if c.Retry != nil && c.Hedging != nil {
+		return gone.NewInnerErrorWithParams(gone.ConfigError, "retry and hedging of gRPC client target(%s) are mutually exclusive", c.Name)
+	}
+
+	if r := c.Retry; r != nil {
+		if r.MaxAttempts == 0 {
+			r.MaxAttempts = 3
+		}
+		if r.InitialBackoff == 0 {
+			r.InitialBackoff = 100 * time.Millisecond
+		}
+		if r.MaxBackoff == 0 {
+			r.MaxBackoff = time.Second
+		}
+		if r.BackoffMultiplier == 0 {
+			r.BackoffMultiplier = 2
+		}
+		if len(r.RetryableStatusCodes) == 0 {
+			r.RetryableStatusCodes = []string{"UNAVAILABLE"}
+		}
+		if r.MaxAttempts < 2 || r.MaxAttempts > maxCallAttempts || r.InitialBackoff < 0 || r.MaxBackoff < 0 || r.BackoffMultiplier < 0 {
+			return gone.NewInnerErrorWithParams(gone.ConfigError, "invalid retry policy of gRPC client target(%s)", c.Name)
+		}
+		if _, err := parseCodes(r.RetryableStatusCodes); err != nil {
+			return err
+		}
+	}
+
+	if h := c.Hedging; h != nil {
+		if h.MaxAttempts == 0 {
+			h.MaxAttempts = 2
+		}
+		if h.MaxAttempts < 2 || h.MaxAttempts > maxCallAttempts || h.HedgingDelay < 0 {
+			return gone.NewInnerErrorWithParams(gone.ConfigError, "invalid hedging policy of gRPC client target(%s)", c.Name)
+		}
+		list, err := parseCodes(h.NonFatalStatusCodes)
+		if err != nil {
+			return err
+		}
+		h.nonFatal = make(map[codes.Code]bool)
+		for _, code := range list {
+			h.nonFatal[code] = true
+		}
+	}
+	return nil
+}
+
+// formatDuration format duration in the JSON format of google.protobuf.Duration
+func formatDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// serviceConfig render the service config of the target, lbPolicy is used if LoadBalancingPolicy is not set;
+// hedging is not supported by grpc-go, which is implemented by the interceptor.
+func (c *ClientConfig) serviceConfig(lbPolicy string) string {
+	sc := make(map[string]any)
+	if c != nil && c.LoadBalancingPolicy != "" {
+		lbPolicy = c.LoadBalancingPolicy
+	}
+	if lbPolicy != "" {
+		sc["loadBalancingPolicy"] = lbPolicy
+	}
+
+	if c != nil {
+		mc := make(map[string]any)
+		if c.Timeout > 0 {
+			mc["timeout"] = formatDuration(c.Timeout)
+		}
+		if c.WaitForReady {
+			mc["waitForReady"] = true
+		}
+		if c.MaxSendMsgSize > 0 {
+			mc["maxRequestMessageBytes"] = c.MaxSendMsgSize
+		}
+		if c.MaxRecvMsgSize > 0 {
+			mc["maxResponseMessageBytes"] = c.MaxRecvMsgSize
+		}
+		if r := c.Retry; r != nil {
+			mc["retryPolicy"] = map[string]any{
+				"maxAttempts":          r.MaxAttempts,
+				"initialBackoff":       formatDuration(r.InitialBackoff),
+				"maxBackoff":           formatDuration(r.MaxBackoff),
+				"backoffMultiplier":    r.BackoffMultiplier,
+				"retryableStatusCodes": r.RetryableStatusCodes,
+			}
+		}
+		if len(mc) > 0 {
+			mc["name"] = []map[string]any{{}}
+			sc["methodConfig"] = []any{mc}
+		}
+	}
+
+	if len(sc) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(sc)
+	return string(data)
+}
+
+// dialOptions the options of connection to the target
+func (c *ClientConfig) dialOptions() []grpc.DialOption {
+	var options []grpc.DialOption
+	if c.Keepalive != nil {
+		options = append(options, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                c.Keepalive.Time,
+			Timeout:             c.Keepalive.Timeout,
+			PermitWithoutStream: c.Keepalive.PermitWithoutStream,
+		}))
+	}
+	if c.Compression != "" {
+		options = append(options, grpc.WithDefaultCallOptions(grpc.UseCompressor(c.Compression)))
+	}
+	if c.Hedging != nil {
+		options = append(options, grpc.WithChainUnaryInterceptor(c.Hedging.interceptor))
+	}
+	return options
+}
+
+// interceptor send hedged attempts of unary calls whose reply is a proto message.
+func (h *HedgingPolicy) interceptor(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	replyMsg, ok := reply.(proto.Message)
+	if !ok || (len(h.Methods) > 0 && !matchMethods(h.Methods, method)) {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		reply proto.Message
+		err   error
+		done  func()
+	}
+	results := make(chan result, h.MaxAttempts)
+	sent, received := 0, 0
+	send := func() {
+		sent++
+		attemptReply := replyMsg.ProtoReflect().New().Interface()
+		attemptOpts, done := attemptOptions(opts)
+		go func() {
+			err := invoker(ctx, method, req, attemptReply, cc, attemptOpts...)
+			results <- result{reply: attemptReply, err: err, done: done}
+		}()
+	}
+
+	send()
+	timer := time.NewTimer(h.HedgingDelay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			if sent < h.MaxAttempts {
+				send()
+				timer.Reset(h.HedgingDelay)
+			}
+		case r := <-results:
+			received++
+			if r.err == nil {
+				r.done()
+				proto.Reset(replyMsg)
+				proto.Merge(replyMsg, r.reply)
+				return nil
+			}
+			if !h.nonFatal[status.Code(r.err)] || (sent == h.MaxAttempts && received == sent) {
+				r.done()
+				return r.err
+			}
+			if sent < h.MaxAttempts {
+				send()
+				timer.Reset(h.HedgingDelay)
+			}
+		}
+	}
+}
+
+// attemptOptions give the attempt its own peer, header and trailer, so that concurrent attempts don't write the caller's
+// ones; done copies them to the caller's options, it is called for the attempt whose result is returned.
+func attemptOptions(opts []grpc.CallOption) (attemptOpts []grpc.CallOption, done func()) {
+	attemptOpts = make([]grpc.CallOption, len(opts))
+	var copies []func()
+	for i, opt := range opts {
+		switch o := opt.(type) {
+		case grpc.HeaderCallOption:
+			md := new(metadata.MD)
+			attemptOpts[i] = grpc.Header(md)
+			copies = append(copies, func() { *o.HeaderAddr = *md })
+		case grpc.TrailerCallOption:
+			md := new(metadata.MD)
+			attemptOpts[i] = grpc.Trailer(md)
+			copies = append(copies, func() { *o.TrailerAddr = *md })
+		case grpc.PeerCallOption:
+			p := new(peer.Peer)
+			attemptOpts[i] = grpc.Peer(p)
+			copies = append(copies, func() { *o.PeerAddr = *p })
+		default:
+			attemptOpts[i] = opt
+		}
+	}
+	return attemptOpts, func() {
+		for _, c := range copies {
+			c()
+		}
+	}
+}
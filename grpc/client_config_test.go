@@ -0,0 +1,300 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestClientConfig_init(t *testing.T) {
+	c := ClientConfig{Name: "user", Retry: &RetryPolicy{RetryableStatusCodes: []string{"unavailable", "resource-exhausted"}}}
+	assert.Nil(t, c.init())
+	assert.Equal(t, RetryPolicy{
+		MaxAttempts:          3,
+		InitialBackoff:       100 * time.Millisecond,
+		MaxBackoff:           time.Second,
+		BackoffMultiplier:    2,
+		RetryableStatusCodes: []string{"UNAVAILABLE", "RESOURCE_EXHAUSTED"},
+	}, *c.Retry)
+
+	c = ClientConfig{Name: "user", Compression: "gzip", Hedging: &HedgingPolicy{NonFatalStatusCodes: []string{"UNAVAILABLE"}}}
+	assert.Nil(t, c.init())
+	assert.Equal(t, 2, c.Hedging.MaxAttempts)
+	assert.True(t, c.Hedging.nonFatal[codes.Unavailable])
+
+	tests := []struct {
+		name   string
+		config ClientConfig
+	}{
+		{name: "name missing", config: ClientConfig{}},
+		{name: "bad compression", config: ClientConfig{Name: "x", Compression: "lz4"}},
+		{name: "retry with hedging", config: ClientConfig{Name: "x", Retry: &RetryPolicy{}, Hedging: &HedgingPolicy{}}},
+		{name: "too many retry attempts", config: ClientConfig{Name: "x", Retry: &RetryPolicy{MaxAttempts: 6}}},
+		{name: "bad retry code", config: ClientConfig{Name: "x", Retry: &RetryPolicy{RetryableStatusCodes: []string{"BROKEN"}}}},
+		{name: "too few hedging attempts", config: ClientConfig{Name: "x", Hedging: &HedgingPolicy{MaxAttempts: 1}}},
+		{name: "bad hedging code", config: ClientConfig{Name: "x", Hedging: &HedgingPolicy{NonFatalStatusCodes: []string{"BROKEN"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.config.init())
+		})
+	}
+}
+
+func TestClientConfig_serviceConfig(t *testing.T) {
+	var c *ClientConfig
+	assert.Equal(t, "", c.serviceConfig(""))
+	assert.JSONEq(t, `{"loadBalancingPolicy":"round_robin"}`, c.serviceConfig("round_robin"))
+
+	c = &ClientConfig{Name: "user", LoadBalancingPolicy: "pick_first", Keepalive: &KeepaliveConfig{Time: time.Minute}}
+	assert.JSONEq(t, `{"loadBalancingPolicy":"pick_first"}`, c.serviceConfig("round_robin"))
+
+	c = &ClientConfig{
+		Name:           "user",
+		Timeout:        1500 * time.Millisecond,
+		WaitForReady:   true,
+		MaxSendMsgSize: 1024,
+		MaxRecvMsgSize: 2048,
+		Retry:          &RetryPolicy{},
+	}
+	assert.Nil(t, c.init())
+	assert.JSONEq(t, `{
+		"methodConfig": [{
+			"name": [{}],
+			"timeout": "1.5s",
+			"waitForReady": true,
+			"maxRequestMessageBytes": 1024,
+			"maxResponseMessageBytes": 2048,
+			"retryPolicy": {
+				"maxAttempts": 3,
+				"initialBackoff": "0.1s",
+				"maxBackoff": "1s",
+				"backoffMultiplier": 2,
+				"retryableStatusCodes": ["UNAVAILABLE"]
+			}
+		}]
+	}`, c.serviceConfig(""))
+}
+
+func TestHedgingPolicy_interceptor(t *testing.T) {
+	const method = "/grpc.health.v1.Health/Check"
+	type attempt struct {
+		delay  time.Duration
+		status healthpb.HealthCheckResponse_ServingStatus
+		err    error
+	}
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+	internal := status.Error(codes.Internal, "internal")
+
+	tests := []struct {
+		name     string
+		policy   HedgingPolicy
+		attempts []attempt
+		wantSent int32
+		want     healthpb.HealthCheckResponse_ServingStatus
+		wantErr  error
+	}{
+		{
+			name:     "first attempt is fast",
+			policy:   HedgingPolicy{MaxAttempts: 3, HedgingDelay: 50 * time.Millisecond},
+			attempts: []attempt{{status: healthpb.HealthCheckResponse_SERVING}},
+			wantSent: 1,
+			want:     healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			name:   "hedged attempt wins",
+			policy: HedgingPolicy{MaxAttempts: 2, HedgingDelay: 10 * time.Millisecond},
+			attempts: []attempt{
+				{delay: time.Second, status: healthpb.HealthCheckResponse_NOT_SERVING},
+				{status: healthpb.HealthCheckResponse_SERVING},
+			},
+			wantSent: 2,
+			want:     healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			name:     "fatal error",
+			policy:   HedgingPolicy{MaxAttempts: 3, HedgingDelay: time.Second},
+			attempts: []attempt{{err: internal}},
+			wantSent: 1,
+			wantErr:  internal,
+		},
+		{
+			name:   "non-fatal error sends next attempt at once",
+			policy: HedgingPolicy{MaxAttempts: 2, HedgingDelay: time.Second, nonFatal: map[codes.Code]bool{codes.Unavailable: true}},
+			attempts: []attempt{
+				{err: unavailable},
+				{status: healthpb.HealthCheckResponse_SERVING},
+			},
+			wantSent: 2,
+			want:     healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			name:     "all attempts failed",
+			policy:   HedgingPolicy{MaxAttempts: 2, nonFatal: map[codes.Code]bool{codes.Unavailable: true}},
+			attempts: []attempt{{err: unavailable}, {err: unavailable}},
+			wantSent: 2,
+			wantErr:  unavailable,
+		},
+		{
+			name:     "method is not hedged",
+			policy:   HedgingPolicy{MaxAttempts: 2, Methods: []string{"/user.User/*"}, nonFatal: map[codes.Code]bool{codes.Unavailable: true}},
+			attempts: []attempt{{err: unavailable}},
+			wantSent: 1,
+			wantErr:  unavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent atomic.Int32
+			invoker := func(ctx context.Context, _ string, _, reply any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				a := tt.attempts[sent.Add(1)-1]
+				select {
+				case <-time.After(a.delay):
+				case <-ctx.Done():
+					return status.FromContextError(ctx.Err()).Err()
+				}
+				if a.err != nil {
+					return a.err
+				}
+				reply.(*healthpb.HealthCheckResponse).Status = a.status
+				return nil
+			}
+
+			reply := &healthpb.HealthCheckResponse{}
+			err := tt.policy.interceptor(context.Background(), method, &healthpb.HealthCheckRequest{}, reply, nil, invoker)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantSent, sent.Load())
+			assert.Equal(t, tt.want, reply.Status)
+		})
+	}
+
+	t.Run("attempts have their own header, trailer and peer", func(t *testing.T) {
+		var sent atomic.Int32
+		invoker := func(ctx context.Context, _ string, _, reply any, _ *grpc.ClientConn, opts ...grpc.CallOption) error {
+			n := sent.Add(1)
+			if n == 1 {
+				<-ctx.Done()
+			}
+			// the slow attempt still writes its options after the call returns
+			for _, opt := range opts {
+				switch o := opt.(type) {
+				case grpc.HeaderCallOption:
+					*o.HeaderAddr = metadata.Pairs("attempt", strconv.Itoa(int(n)))
+				case grpc.TrailerCallOption:
+					*o.TrailerAddr = metadata.Pairs("attempt", strconv.Itoa(int(n)))
+				case grpc.PeerCallOption:
+					o.PeerAddr.Addr = &net.TCPAddr{Port: int(n)}
+				}
+			}
+			if n == 1 {
+				return status.FromContextError(ctx.Err()).Err()
+			}
+			reply.(*healthpb.HealthCheckResponse).Status = healthpb.HealthCheckResponse_SERVING
+			return nil
+		}
+
+		var header, trailer metadata.MD
+		var p peer.Peer
+		policy := HedgingPolicy{MaxAttempts: 2, HedgingDelay: 10 * time.Millisecond}
+		reply := &healthpb.HealthCheckResponse{}
+		err := policy.interceptor(context.Background(), method, &healthpb.HealthCheckRequest{}, reply, nil, invoker,
+			grpc.Header(&header), grpc.Trailer(&trailer), grpc.Peer(&p), grpc.WaitForReady(true),
+		)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"2"}, header.Get("attempt"))
+		assert.Equal(t, []string{"2"}, trailer.Get("attempt"))
+		assert.Equal(t, &net.TCPAddr{Port: 2}, p.Addr)
+	})
+
+	t.Run("reply is not a proto message", func(t *testing.T) {
+		var sent atomic.Int32
+		policy := HedgingPolicy{MaxAttempts: 2}
+		err := policy.interceptor(context.Background(), method, nil, new(string), nil,
+			func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+				sent.Add(1)
+				return errors.New("error")
+			},
+		)
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), sent.Load())
+	})
+}
+
+// flakyHealth fail the first `failures` calls with UNAVAILABLE
+type flakyHealth struct {
+	healthpb.UnimplementedHealthServer
+	failures int32
+	calls    atomic.Int32
+}
+
+func (h *flakyHealth) Check(context.Context, *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if h.calls.Add(1) <= h.failures {
+		return nil, status.Error(codes.Unavailable, "unavailable")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func Test_clientRegister_target(t *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	service := &flakyHealth{failures: 2}
+	healthpb.RegisterHealthServer(server, service)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	register := clientRegister{
+		connections: make(map[string]*grpc.ClientConn),
+		grpcOptions: []grpc.DialOption{
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		},
+		insecure:    true,
+		tracerIdKey: "X-Trace-Id",
+		targets: []ClientConfig{{
+			Name:        "health",
+			Timeout:     time.Second,
+			Compression: "gzip",
+			Keepalive:   &KeepaliveConfig{Time: time.Minute, Timeout: time.Second},
+			Retry:       &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond},
+		}},
+	}
+	assert.Nil(t, register.Init())
+
+	const address = "passthrough:///bufnet"
+	conn := register.getConn("health", address)
+	defer conn.Close()
+	assert.Same(t, conn, register.getConn("health", address))
+
+	res, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.Status)
+	assert.Equal(t, int32(3), service.calls.Load())
+
+	// the connection without target configuration does not retry
+	plain := register.getConn(address, address)
+	defer plain.Close()
+	assert.NotSame(t, conn, plain)
+	service.calls.Store(0)
+	_, err = healthpb.NewHealthClient(plain).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(1), service.calls.Load())
+
+	register.targets = append(register.targets, ClientConfig{Name: "health"})
+	assert.Error(t, register.Init())
+	register.targets = []ClientConfig{{Name: "bad", Retry: &RetryPolicy{MaxAttempts: 1}}}
+	assert.Error(t, register.Init())
+}
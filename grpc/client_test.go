@@ -2,17 +2,23 @@ package grpc
 
 import (
 	"context"
+	"net"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/gone-io/gone/v2"
 	mock "github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
 	gMock "github.com/gone-io/goner/g/mock"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func (s *clientRegister) Infof(format string, args ...any) {}
@@ -175,7 +181,7 @@ func TestClientRegister_CreateConn(t *testing.T) {
 				register.Init()
 			}
 
-			conn, err := register.createConn(":0")
+			conn, err := register.createConn(":0", nil)
 			if tt.expectedError {
 				assert.Error(t, err)
 				assert.Nil(t, conn)
@@ -211,13 +217,13 @@ func TestClientRegister_GetConn(t *testing.T) {
 			}
 
 			// 第一次获取连接
-			conn1 := register.getConn(tt.address)
+			conn1 := register.getConn(tt.address, tt.address)
 			defer conn1.Close()
 
 			assert.NotNil(t, conn1)
 
 			// 第二次获取相同地址的连接
-			conn2 := register.getConn(tt.address)
+			conn2 := register.getConn(tt.address, tt.address)
 			assert.Equal(t, conn1, conn2, "应返回缓存的连接实例")
 		})
 	}
@@ -281,3 +287,90 @@ func TestClientRegister_Provide(t *testing.T) {
 		})
 	}
 }
+
+func TestClientRegister_traceStreamInterceptor(t *testing.T) {
+	ctr := gomock.NewController(t)
+	defer ctr.Finish()
+	tracer := gMock.NewMockTracer(ctr)
+	tracer.EXPECT().GetTraceId().Return("xxxx")
+
+	const tracerIdKey = "X-Trace-Id"
+	register := clientRegister{
+		tracer:      tracer,
+		tracerIdKey: tracerIdKey,
+	}
+
+	_, err := register.traceStreamInterceptor(
+		context.Background(),
+		&grpc.StreamDesc{},
+		nil,
+		"test",
+		func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			md, b := metadata.FromOutgoingContext(ctx)
+			assert.True(t, b)
+			assert.Equal(t, []string{"xxxx"}, md.Get(tracerIdKey))
+			return nil, nil
+		},
+	)
+	assert.Nil(t, err)
+}
+
+func Test_clientRegister_feedback(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, &flakyHealth{failures: 1})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	instance := g.NewService("user", host, p, nil, true, 1)
+
+	discovery := gMock.NewMockServiceDiscovery(controller)
+	discovery.EXPECT().Watch("user").Return(make(chan []g.Service), func() error { return nil }, nil)
+	discovery.EXPECT().GetInstances("user").Return([]g.Service{instance}, nil).AnyTimes()
+
+	feedback := gMock.NewMockLoadBalanceFeedback(controller)
+	feedback.EXPECT().Available("user", gomock.Any()).DoAndReturn(func(_ string, services []g.Service) []g.Service {
+		return services
+	}).AnyTimes()
+	gomock.InOrder(
+		feedback.EXPECT().Feedback("user", listener.Addr().String(), gomock.Not(gomock.Nil()), gomock.Any()),
+		feedback.EXPECT().Feedback("user", listener.Addr().String(), nil, gomock.Any()),
+	)
+
+	// only the calls selected by the strategy are reported
+	selector := gMock.NewMockLoadBalanceSelector(controller)
+	selector.EXPECT().Select(gomock.Any(), "user", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, instances []g.Service) (g.Service, error) {
+			return instances[0], nil
+		},
+	).Times(2)
+
+	register := clientRegister{
+		logger:              gone.GetDefaultLogger(),
+		connections:         make(map[string]*grpc.ClientConn),
+		discovery:           discovery,
+		feedback:            feedback,
+		router:              laneRouter{},
+		selector:            selector,
+		insecure:            true,
+		loadBalancingPolicy: roundRobinLBPolicy,
+		tracerIdKey:         "X-Trace-Id",
+	}
+	assert.Nil(t, register.Init())
+	conn := register.getConn("user", "dns:///user")
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,164 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthServer the standard `grpc.health.v1.Health` service, whose status is driven by the goners implementing
+// g.HealthChecker: all services are SERVING when every checker passes, otherwise NOT_SERVING.
+type healthServer struct {
+	gone.Flag
+	logger   gone.Logger       `gone:"*"`
+	checkers []g.HealthChecker `gone:"*"`
+
+	// enabled 是否注册`grpc.health.v1.Health`服务，对应配置项为：`server.grpc.health.enabled`
+	enabled bool `gone:"config,server.grpc.health.enabled,default=false"`
+
+	// checkInterval 依赖检查的间隔，对应配置项为：`server.grpc.health.check-interval`
+	checkInterval time.Duration `gone:"config,server.grpc.health.check-interval,default=10s"`
+
+	// checkTimeout 单个依赖检查的超时时间，对应配置项为：`server.grpc.health.check-timeout`
+	checkTimeout time.Duration `gone:"config,server.grpc.health.check-timeout,default=3s"`
+
+	server   *health.Server
+	services []string
+	draining atomic.Bool
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func (h *healthServer) GonerName() string {
+	return IdGoneGrpcHealth
+}
+
+func (h *healthServer) Init() {
+	h.server = health.NewServer()
+}
+
+// register register the health service to server, it must be called after all other services are registered,
+// so that the status of each service can be reported.
+func (h *healthServer) register(server *grpc.Server) {
+	if !h.enabled {
+		return
+	}
+	services := server.GetServiceInfo()
+	if _, ok := services[healthpb.Health_ServiceDesc.ServiceName]; ok {
+		h.logger.Warnf("%s is already registered, builtin health service is ignored", healthpb.Health_ServiceDesc.ServiceName)
+		h.enabled = false
+		return
+	}
+	for name := range services {
+		h.services = append(h.services, name)
+	}
+	healthpb.RegisterHealthServer(server, h.server)
+	h.update()
+}
+
+// start check the dependencies periodically
+func (h *healthServer) start() {
+	if !h.enabled || len(h.checkers) == 0 || h.checkInterval <= 0 {
+		return
+	}
+	h.stop = make(chan struct{})
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		ticker := time.NewTicker(h.checkInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				h.update()
+			case <-h.stop:
+				return
+			}
+		}
+	}()
+}
+
+// shutdown mark all services NOT_SERVING and stop checking, later updates are ignored.
+func (h *healthServer) shutdown() {
+	if !h.enabled || !h.draining.CompareAndSwap(false, true) {
+		return
+	}
+	if h.stop != nil {
+		close(h.stop)
+		h.wg.Wait()
+	}
+	h.server.Shutdown()
+}
+
+func (h *healthServer) update() {
+	if h.draining.Load() {
+		return
+	}
+	status := healthpb.HealthCheckResponse_SERVING
+	if !h.check(context.Background()) {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	h.server.SetServingStatus("", status)
+	for _, service := range h.services {
+		h.server.SetServingStatus(service, status)
+	}
+}
+
+// check run all checkers concurrently, return true if all of them pass
+func (h *healthServer) check(ctx context.Context) bool {
+	results := make([]bool, len(h.checkers))
+	var wg sync.WaitGroup
+	for i, checker := range h.checkers {
+		wg.Add(1)
+		go func(i int, checker g.HealthChecker) {
+			defer wg.Done()
+			results[i] = h.checkOne(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	for _, ok := range results {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// checkOne run the checker with timeout, the checker is treated as failed if it does not return in time
+func (h *healthServer) checkOne(ctx context.Context, checker g.HealthChecker) bool {
+	if h.checkTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.checkTimeout)
+		defer cancel()
+	}
+
+	ch := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				ch <- gone.NewInnerErrorWithParams(gone.PanicError, "health check panic: %v", r)
+			}
+		}()
+		ch <- checker.CheckHealth(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-ch:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		h.logger.Warnf("health check of %s failed: %v", checker.HealthCheckName(), err)
+		return false
+	}
+	return true
+}
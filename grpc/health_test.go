@@ -0,0 +1,145 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	gMock "github.com/gone-io/goner/g/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newHealthChecker(controller *gomock.Controller, name string, check func(ctx context.Context) error) g.HealthChecker {
+	checker := gMock.NewMockHealthChecker(controller)
+	checker.EXPECT().HealthCheckName().Return(name).AnyTimes()
+	checker.EXPECT().CheckHealth(gomock.Any()).DoAndReturn(check).AnyTimes()
+	return checker
+}
+
+func Test_healthServer_check(t *testing.T) {
+	controller := gomock.NewController(t)
+	logger := gone.NewMockLogger(controller)
+	logger.EXPECT().Warnf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	up := newHealthChecker(controller, "db", func(ctx context.Context) error { return nil })
+	down := newHealthChecker(controller, "redis", func(ctx context.Context) error { return errors.New("connection refused") })
+	slow := newHealthChecker(controller, "mq", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	panics := newHealthChecker(controller, "es", func(ctx context.Context) error { panic("boom") })
+
+	h := &healthServer{logger: logger, checkTimeout: 50 * time.Millisecond}
+	assert.True(t, h.check(context.Background()))
+
+	h.checkers = []g.HealthChecker{up}
+	assert.True(t, h.check(context.Background()))
+
+	for _, checker := range []g.HealthChecker{down, slow, panics} {
+		h.checkers = []g.HealthChecker{up, checker}
+		assert.False(t, h.check(context.Background()), checker.HealthCheckName())
+	}
+}
+
+func Test_healthServer(t *testing.T) {
+	controller := gomock.NewController(t)
+	logger := gone.NewMockLogger(controller)
+	logger.EXPECT().Warnf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	var healthy = true
+	checker := newHealthChecker(controller, "db", func(ctx context.Context) error {
+		if healthy {
+			return nil
+		}
+		return errors.New("down")
+	})
+	h := &healthServer{
+		logger:        logger,
+		checkers:      []g.HealthChecker{checker},
+		enabled:       true,
+		checkInterval: time.Hour,
+	}
+	h.Init()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{ServiceName: "pkg.Svc", HandlerType: (*any)(nil)}, struct{}{})
+	h.register(server)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.Nil(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+	status := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		assert.Nil(t, err)
+		return res.GetStatus()
+	}
+
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, status(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, status("pkg.Svc"))
+
+	healthy = false
+	h.update()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status("pkg.Svc"))
+
+	healthy = true
+	h.start()
+	h.shutdown()
+	h.shutdown()
+	h.update()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status("pkg.Svc"))
+}
+
+func Test_healthServer_disabled(t *testing.T) {
+	h := &healthServer{}
+	h.Init()
+	server := grpc.NewServer()
+	h.register(server)
+	h.start()
+	h.shutdown()
+	assert.Empty(t, server.GetServiceInfo())
+}
+
+func Test_healthServer_start(t *testing.T) {
+	controller := gomock.NewController(t)
+	checked := make(chan struct{}, 10)
+	checker := newHealthChecker(controller, "db", func(ctx context.Context) error {
+		checked <- struct{}{}
+		return nil
+	})
+	h := &healthServer{
+		checkers:      []g.HealthChecker{checker},
+		enabled:       true,
+		checkInterval: 10 * time.Millisecond,
+	}
+	h.Init()
+	h.register(grpc.NewServer())
+	<-checked
+
+	h.start()
+	select {
+	case <-checked:
+	case <-time.After(time.Second):
+		t.Fatal("health is not checked periodically")
+	}
+	h.shutdown()
+}
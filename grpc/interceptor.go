@@ -0,0 +1,275 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// serverInterceptor the builtin interceptors of gRPC server for both unary and streaming RPCs, which are chained by
+// server from outer to inner: trace, access log, status mapping, recovery, deadline, auth, rate limit, validation and
+// route attributes.
+type serverInterceptor struct {
+	gone.Flag
+	logger        gone.Logger   `gone:"*"`
+	tracer        g.Tracer      `gone:"*" option:"allowNil"`
+	authenticator Authenticator `gone:"*" option:"allowNil"`
+	limiter       g.RateLimiter `gone:"*" option:"allowNil"`
+
+	// accessLog 是否打印访问日志，对应配置项为：`server.grpc.log.access`
+	accessLog bool `gone:"config,server.grpc.log.access,default=true"`
+
+	// serviceName 服务名，作为错误详情`ErrorInfo`的domain，对应配置项为：`server.grpc.service-name`
+	serviceName string `gone:"config,server.grpc.service-name"`
+
+	// hideInnerError 是否隐藏内部错误的信息，隐藏时返回`Internal`和`internal error`，对应配置项为：`server.grpc.do-not-show-inner-error-detail`
+	hideInnerError bool `gone:"config,server.grpc.do-not-show-inner-error-detail,default=true"`
+
+	// defaultTimeout 请求未设置deadline时使用的超时时间，对应配置项为：`server.grpc.default-timeout`，0表示不设置
+	defaultTimeout time.Duration `gone:"config,server.grpc.default-timeout,default=0s"`
+
+	// maxTimeout 请求允许的最长超时时间，更长的deadline将被缩短，对应配置项为：`server.grpc.max-timeout`，0表示不限制
+	maxTimeout time.Duration `gone:"config,server.grpc.max-timeout,default=0s"`
+
+	// authEnabled 是否开启token认证，需要加载`grpc.Authenticator`，对应配置项为：`server.grpc.auth.enabled`
+	authEnabled bool `gone:"config,server.grpc.auth.enabled,default=false"`
+
+	// authMetadataKey 携带token的metadata，对应配置项为：`server.grpc.auth.metadata-key`
+	authMetadataKey string `gone:"config,server.grpc.auth.metadata-key,default=authorization"`
+
+	// authSkip 不需要认证的方法，多个以逗号分隔，以`*`结尾时按前缀匹配，对应配置项为：`server.grpc.auth.skip-methods`
+	authSkip string `gone:"config,server.grpc.auth.skip-methods,default=/grpc.health.v1.Health/*"`
+
+	// limitRules 按方法限流的规则，对应配置项为：`server.grpc.limit-rules`
+	limitRules []LimitRule `gone:"config,server.grpc.limit-rules"`
+
+	// validate 是否校验实现了`Validate() error`或`ValidateAll() error`的请求消息，对应配置项为：`server.grpc.validate`
+	validate bool `gone:"config,server.grpc.validate,default=true"`
+
+	// routeAttributes 是否将请求的metadata作为负载均衡路由属性传入context，对应配置项为：`server.grpc.route-attributes`
+	routeAttributes bool `gone:"config,server.grpc.route-attributes,default=true"`
+
+	authSkipMethods []string
+}
+
+func (i *serverInterceptor) GonerName() string {
+	return IdGoneGrpcServerInterceptor
+}
+
+func (i *serverInterceptor) Init() error {
+	if i.authEnabled && i.authenticator == nil {
+		return gone.NewInnerError("`server.grpc.auth.enabled` is true, but no grpc.Authenticator is loaded", gone.ConfigError)
+	}
+	i.authSkipMethods = nil
+	for _, method := range strings.Split(i.authSkip, ",") {
+		if method = strings.TrimSpace(method); method != "" {
+			i.authSkipMethods = append(i.authSkipMethods, method)
+		}
+	}
+	for idx := range i.limitRules {
+		if err := i.limitRules[idx].Init(idx); err != nil {
+			return err
+		}
+	}
+	if i.limiter == nil {
+		i.limiter = g.NewMemoryRateLimiter()
+	}
+	return nil
+}
+
+// matchMethod match full method name with pattern, pattern ends with `*` matches by prefix, empty pattern matches all.
+func matchMethod(pattern, method string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(method, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == "" || pattern == method
+}
+
+func matchMethods(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if pattern != "" && matchMethod(pattern, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// log print the access log of RPC, calls failed with server errors are logged as errors.
+func (i *serverInterceptor) log(ctx context.Context, method string, begin time.Time, err error) {
+	if !i.accessLog {
+		return
+	}
+	var remote string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remote = p.Addr.String()
+	}
+	var traceId string
+	if i.tracer != nil {
+		traceId = i.tracer.GetTraceId()
+	}
+	code := status.Code(err)
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable, codes.Unimplemented:
+		i.logger.Errorf("[grpc] method=%s|code=%s|use-time=%s|peer=%s|trace-id=%s|error=%v",
+			method, code, time.Since(begin), remote, traceId, err)
+	default:
+		i.logger.Infof("[grpc] method=%s|code=%s|use-time=%s|peer=%s|trace-id=%s",
+			method, code, time.Since(begin), remote, traceId)
+	}
+}
+
+func (i *serverInterceptor) logUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	begin := time.Now()
+	defer func() {
+		i.log(ctx, info.FullMethod, begin, err)
+	}()
+	return handler(ctx, req)
+}
+
+func (i *serverInterceptor) logStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	begin := time.Now()
+	defer func() {
+		i.log(ss.Context(), info.FullMethod, begin, err)
+	}()
+	return handler(srv, ss)
+}
+
+func (i *serverInterceptor) statusUnary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	return resp, toStatus(err, i.serviceName, i.hideInnerError)
+}
+
+func (i *serverInterceptor) statusStream(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return toStatus(handler(srv, ss), i.serviceName, i.hideInnerError)
+}
+
+// deadline set `server.grpc.default-timeout` for calls without deadline, and shorten deadlines longer than
+// `server.grpc.max-timeout`; calls whose deadline has passed are rejected at once.
+func (i *serverInterceptor) deadline(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	deadline, ok := ctx.Deadline()
+	switch {
+	case ok && !time.Now().Before(deadline):
+		return ctx, func() {}, status.Error(codes.DeadlineExceeded, "deadline exceeded before handling")
+	case !ok && i.defaultTimeout > 0:
+		ctx, cancel := context.WithTimeout(ctx, i.defaultTimeout)
+		return ctx, cancel, nil
+	case i.maxTimeout > 0 && (!ok || time.Until(deadline) > i.maxTimeout):
+		ctx, cancel := context.WithTimeout(ctx, i.maxTimeout)
+		return ctx, cancel, nil
+	}
+	return ctx, func() {}, nil
+}
+
+func (i *serverInterceptor) deadlineUnary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, cancel, err := i.deadline(ctx)
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (i *serverInterceptor) deadlineStream(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, cancel, err := i.deadline(ss.Context())
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return handler(srv, withContext(ss, ctx))
+}
+
+// validateMessage validate message implementing `ValidateAll() error` or `Validate() error`, which are generated by
+// protoc-gen-validate.
+func (i *serverInterceptor) validateMessage(m any) error {
+	if !i.validate {
+		return nil
+	}
+	var err error
+	switch v := m.(type) {
+	case interface{ ValidateAll() error }:
+		err = v.ValidateAll()
+	case interface{ Validate() error }:
+		err = v.Validate()
+	}
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+func (i *serverInterceptor) validateUnary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := i.validateMessage(req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (i *serverInterceptor) validateStream(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !i.validate {
+		return handler(srv, ss)
+	}
+	return handler(srv, &validatingStream{ServerStream: ss, validate: i.validateMessage})
+}
+
+// routeContext put the incoming metadata into ctx as route attributes, so that the downstream calls made in handlers
+// can be routed by them, eg: lane or version; reserved and binary metadata are skipped.
+func (i *serverInterceptor) routeContext(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !i.routeAttributes || !ok {
+		return ctx
+	}
+	attributes := make(map[string]string, len(md))
+	for k, v := range md {
+		if len(v) == 0 || strings.HasPrefix(k, ":") || strings.HasPrefix(k, "grpc-") || strings.HasSuffix(k, "-bin") {
+			continue
+		}
+		attributes[k] = v[0]
+	}
+	return g.WithRouteAttributes(ctx, attributes)
+}
+
+func (i *serverInterceptor) routeUnary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(i.routeContext(ctx), req)
+}
+
+func (i *serverInterceptor) routeStream(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, withContext(ss, i.routeContext(ss.Context())))
+}
+
+// validatingStream validate every message received
+type validatingStream struct {
+	grpc.ServerStream
+	validate func(m any) error
+}
+
+func (s *validatingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.validate(m)
+}
+
+// contextStream replace the context of server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func withContext(ss grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	if ctx == ss.Context() {
+		return ss
+	}
+	return &contextStream{ServerStream: ss, ctx: ctx}
+}
@@ -0,0 +1,280 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	recv []any
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeServerStream) RecvMsg(m any) error {
+	if len(s.recv) == 0 {
+		return errors.New("EOF")
+	}
+	*(m.(*validated)) = *(s.recv[0].(*validated))
+	s.recv = s.recv[1:]
+	return nil
+}
+
+type validated struct {
+	name string
+}
+
+func (v *validated) Validate() error {
+	if v.name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func Test_matchMethod(t *testing.T) {
+	assert.True(t, matchMethod("", "/pkg.Svc/Get"))
+	assert.True(t, matchMethod("/pkg.Svc/Get", "/pkg.Svc/Get"))
+	assert.True(t, matchMethod("/pkg.Svc/*", "/pkg.Svc/Get"))
+	assert.False(t, matchMethod("/pkg.Svc/List", "/pkg.Svc/Get"))
+	assert.False(t, matchMethods([]string{""}, "/pkg.Svc/Get"))
+	assert.True(t, matchMethods([]string{"/x/*", "/pkg.Svc/Get"}, "/pkg.Svc/Get"))
+}
+
+func Test_serverInterceptor_log(t *testing.T) {
+	controller := gomock.NewController(t)
+	logger := gone.NewMockLogger(controller)
+	i := &serverInterceptor{logger: logger, accessLog: true}
+
+	logger.EXPECT().Infof(gomock.Any(), "/pkg.Svc/Get", codes.OK, gomock.Any(), "", "")
+	_, _ = i.logUnary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Get"}, func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	})
+
+	logger.EXPECT().Errorf(gomock.Any(), "/pkg.Svc/Watch", codes.Internal, gomock.Any(), "", "", gomock.Any())
+	err := i.logStream(nil, &fakeServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/pkg.Svc/Watch"}, func(srv any, stream grpc.ServerStream) error {
+		return status.Error(codes.Internal, "failed")
+	})
+	assert.Error(t, err)
+
+	i.accessLog = false
+	_, _ = i.logUnary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Get"}, func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	})
+}
+
+func Test_serverInterceptor_deadline(t *testing.T) {
+	i := &serverInterceptor{}
+	remaining := func(ctx context.Context) time.Duration {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return 0
+		}
+		return time.Until(deadline).Round(time.Second)
+	}
+	call := func(ctx context.Context) (time.Duration, error) {
+		resp, err := i.deadlineUnary(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+			return remaining(ctx), nil
+		})
+		if err != nil {
+			return 0, err
+		}
+		return resp.(time.Duration), nil
+	}
+
+	d, _ := call(context.Background())
+	assert.Equal(t, time.Duration(0), d)
+
+	i.defaultTimeout = 5 * time.Second
+	d, _ = call(context.Background())
+	assert.Equal(t, 5*time.Second, d)
+
+	i.maxTimeout = 10 * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	d, _ = call(ctx)
+	assert.Equal(t, 10*time.Second, d)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	d, _ = call(ctx)
+	assert.Equal(t, 2*time.Second, d)
+
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	_, err := call(ctx)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	err = i.deadlineStream(nil, &fakeServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, func(srv any, stream grpc.ServerStream) error {
+		assert.Equal(t, 5*time.Second, remaining(stream.Context()))
+		return nil
+	})
+	assert.Nil(t, err)
+}
+
+func Test_serverInterceptor_validate(t *testing.T) {
+	i := &serverInterceptor{validate: true}
+	handler := func(ctx context.Context, req any) (any, error) { return req, nil }
+
+	_, err := i.validateUnary(context.Background(), &validated{}, &grpc.UnaryServerInfo{}, handler)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = i.validateUnary(context.Background(), &validated{name: "x"}, &grpc.UnaryServerInfo{}, handler)
+	assert.Nil(t, err)
+
+	ss := &fakeServerStream{ctx: context.Background(), recv: []any{&validated{name: "x"}, &validated{}}}
+	err = i.validateStream(nil, ss, &grpc.StreamServerInfo{}, func(srv any, stream grpc.ServerStream) error {
+		var m validated
+		assert.Nil(t, stream.RecvMsg(&m))
+		return stream.RecvMsg(&m)
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	i.validate = false
+	_, err = i.validateUnary(context.Background(), &validated{}, &grpc.UnaryServerInfo{}, handler)
+	assert.Nil(t, err)
+	err = i.validateStream(nil, ss, &grpc.StreamServerInfo{}, func(srv any, stream grpc.ServerStream) error {
+		assert.Equal(t, ss, stream)
+		return nil
+	})
+	assert.Nil(t, err)
+}
+
+type healthService struct {
+	*health.Server
+}
+
+func (h *healthService) RegisterGrpcServer(server *grpc.Server) {
+	healthpb.RegisterHealthServer(server, h)
+}
+
+// Check panics for service `panic`, and returns gone.Error for service `missing`
+func (h *healthService) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	switch req.Service {
+	case "panic":
+		panic("boom")
+	case "missing":
+		return nil, gone.NewError(404, "service missing", 404)
+	}
+	return h.Server.Check(ctx, req)
+}
+
+func Test_serverInterceptor_route(t *testing.T) {
+	i := &serverInterceptor{routeAttributes: true}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"x-lane", "gray",
+		":authority", "localhost",
+		"grpc-accept-encoding", "gzip",
+		"trace-bin", "xx",
+	))
+	want := map[string]string{"x-lane": "gray"}
+
+	_, err := i.routeUnary(ctx, nil, nil, func(ctx context.Context, _ any) (any, error) {
+		assert.Equal(t, want, g.RouteAttributesFrom(ctx))
+		return nil, nil
+	})
+	assert.Nil(t, err)
+
+	err = i.routeStream(nil, &fakeServerStream{ctx: ctx}, nil, func(_ any, ss grpc.ServerStream) error {
+		assert.Equal(t, want, g.RouteAttributesFrom(ss.Context()))
+		return nil
+	})
+	assert.Nil(t, err)
+
+	i.routeAttributes = false
+	assert.Nil(t, g.RouteAttributesFrom(i.routeContext(ctx)))
+}
+
+func Test_serverInterceptor_chain(t *testing.T) {
+	controller := gomock.NewController(t)
+	logger := gone.NewMockLogger(controller)
+	logger.EXPECT().Infof(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Errorf(gomock.Any(), gomock.Any()).AnyTimes()
+	authenticator := NewMockAuthenticator(controller)
+	authenticator.EXPECT().Authenticate(gomock.Any(), "secret").Return(&Principal{Subject: "u1"}, nil).AnyTimes()
+	authenticator.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Return(nil, errors.New("invalid token")).AnyTimes()
+
+	i := &serverInterceptor{
+		logger:          logger,
+		authenticator:   authenticator,
+		accessLog:       true,
+		hideInnerError:  true,
+		authEnabled:     true,
+		authMetadataKey: "authorization",
+		limitRules:      []LimitRule{{Method: "/grpc.health.v1.Health/Check", LimitRule: g.LimitRule{KeyBy: "principal", Limit: 0.001, Burst: 3}}},
+	}
+	assert.Nil(t, i.Init())
+
+	listener := bufconn.Listen(1 << 20)
+	s := &server{
+		logger:         logger,
+		interceptor:    i,
+		tracerIdKey:    "X-Trace-Id",
+		grpcServices:   []Service{&healthService{Server: health.NewServer()}},
+		createListener: func(string, int) net.Listener { return listener },
+	}
+	s.Init()
+	s.register()
+	go func() {
+		_ = s.grpcServer.Serve(listener)
+	}()
+	defer s.grpcServer.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.Nil(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	res, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.Status)
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "service missing", status.Convert(err).Message())
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "panic"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "internal error", status.Convert(err).Message())
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.Watch(watchCtx, &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+	res, err = stream.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.Status)
+
+	stream, err = client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
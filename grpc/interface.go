@@ -1,6 +1,10 @@
 package grpc
 
-import "google.golang.org/grpc"
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
 
 type Client interface {
 	Address() string
@@ -10,3 +14,16 @@ type Client interface {
 type Service interface {
 	RegisterGrpcServer(server *grpc.Server)
 }
+
+// Authenticator authenticates the token carried by metadata `server.grpc.auth.metadata-key` when
+// `server.grpc.auth.enabled` is true, the principal returned is injected into the context of handler and can be got
+// by PrincipalFromContext. Return a gRPC status error to reply with its code, other errors are replied as Unauthenticated.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Principal, error)
+}
+
+const (
+	IdGoneGrpcServer            = "gone-grpc-server"
+	IdGoneGrpcServerInterceptor = "gone-grpc-server-interceptor"
+	IdGoneGrpcHealth            = "gone-grpc-health"
+)
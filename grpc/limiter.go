@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/gone-io/goner/g"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// LimitRule rate limit rule of RPC methods, configured by `server.grpc.limit-rules`; KeyBy of g.LimitRule can be:
+// `peer` groups by the address of client; `principal` groups by the subject of authenticated principal;
+// `metadata:<key>` groups by the value of metadata
+type LimitRule struct {
+	g.LimitRule `mapstructure:",squash"`
+
+	// Method full method name, eg: `/helloworld.Greeter/SayHello`; pattern ends with `*` matches by prefix,
+	// eg: `/helloworld.Greeter/*`; empty matches all methods
+	Method string `mapstructure:"method" json:"method"`
+}
+
+const (
+	limitKeyByPeer      = "peer"
+	limitKeyByPrincipal = "principal"
+	limitKeyByMetadata  = "metadata:"
+)
+
+// key return the limit key of call, ok is false if the call is not limited by the rule
+func (r *LimitRule) key(ctx context.Context) (key string, ok bool) {
+	switch {
+	case r.KeyBy == "":
+		return "", true
+	case r.KeyBy == limitKeyByPeer:
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+				return host, true
+			}
+			return p.Addr.String(), true
+		}
+	case r.KeyBy == limitKeyByPrincipal:
+		if p, ok := PrincipalFromContext(ctx); ok && p.Subject != "" {
+			return p.Subject, true
+		}
+	case strings.HasPrefix(r.KeyBy, limitKeyByMetadata):
+		if values := metadata.ValueFromIncomingContext(ctx, strings.TrimPrefix(r.KeyBy, limitKeyByMetadata)); len(values) > 0 && values[0] != "" {
+			return values[0], true
+		}
+	}
+	return "", false
+}
+
+// limit check the rules matching method in order, the call is rejected with ResourceExhausted by the first rule
+// not allowing it; errors of g.RateLimiter are logged and the call is allowed.
+func (i *serverInterceptor) limit(ctx context.Context, method string) error {
+	for _, rule := range i.limitRules {
+		if !matchMethod(rule.Method, method) {
+			continue
+		}
+		key, ok := rule.key(ctx)
+		if !ok {
+			continue
+		}
+		result, err := i.limiter.Allow(ctx, "gone-grpc-limit#"+rule.Name+"#"+key, rule.RateLimitRule())
+		if err != nil {
+			i.logger.Warnf("rate limit of rule(%s) failed: %v", rule.Name, err)
+			continue
+		}
+		if !result.Allowed {
+			st, e := status.New(codes.ResourceExhausted, "too many requests").
+				WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(result.RetryAfter)})
+			if e != nil {
+				return status.Error(codes.ResourceExhausted, "too many requests")
+			}
+			return st.Err()
+		}
+	}
+	return nil
+}
+
+func (i *serverInterceptor) limitUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := i.limit(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (i *serverInterceptor) limitStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := i.limit(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	gMock "github.com/gone-io/goner/g/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestLimitRule_key(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-tenant", "t1"))
+	ctx = WithPrincipal(ctx, &Principal{Subject: "u1"})
+
+	tests := []struct {
+		keyBy string
+		ctx   context.Context
+		key   string
+		ok    bool
+	}{
+		{keyBy: "", ctx: context.Background(), key: "", ok: true},
+		{keyBy: "peer", ctx: ctx, key: "10.0.0.1", ok: true},
+		{keyBy: "peer", ctx: context.Background()},
+		{keyBy: "principal", ctx: ctx, key: "u1", ok: true},
+		{keyBy: "principal", ctx: context.Background()},
+		{keyBy: "metadata:x-tenant", ctx: ctx, key: "t1", ok: true},
+		{keyBy: "metadata:x-user", ctx: ctx},
+		{keyBy: "unknown", ctx: ctx},
+	}
+	for _, tt := range tests {
+		r := LimitRule{LimitRule: g.LimitRule{KeyBy: tt.keyBy}}
+		key, ok := r.key(tt.ctx)
+		assert.Equal(t, tt.key, key, tt.keyBy)
+		assert.Equal(t, tt.ok, ok, tt.keyBy)
+	}
+}
+
+func Test_serverInterceptor_limit(t *testing.T) {
+	i := &serverInterceptor{
+		limitRules: []LimitRule{
+			{Method: "/pkg.Svc/Get", LimitRule: g.LimitRule{Name: "get", Limit: 0.001, Burst: 1}},
+			{Method: "/pkg.Svc/*", LimitRule: g.LimitRule{Name: "tenant", KeyBy: "metadata:x-tenant", Limit: 0.001, Burst: 2}},
+		},
+	}
+	assert.Nil(t, i.Init())
+	handler := func(ctx context.Context, req any) (any, error) { return nil, nil }
+	call := func(method string, ctx context.Context) error {
+		_, err := i.limitUnary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	assert.Nil(t, call("/pkg.Svc/Get", context.Background()))
+	err := call("/pkg.Svc/Get", context.Background())
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	retry := status.Convert(err).Details()[0].(*errdetails.RetryInfo)
+	assert.True(t, retry.RetryDelay.AsDuration() > time.Minute)
+
+	tenant := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant", "t1"))
+	assert.Nil(t, call("/pkg.Svc/List", tenant))
+	assert.Nil(t, call("/pkg.Svc/List", tenant))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call("/pkg.Svc/List", tenant)))
+	assert.Nil(t, call("/pkg.Svc/List", context.Background()))
+	assert.Nil(t, call("/other.Svc/List", tenant))
+
+	err = i.limitStream(nil, &fakeServerStream{ctx: tenant}, &grpc.StreamServerInfo{FullMethod: "/pkg.Svc/Watch"}, nil)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func Test_serverInterceptor_limitWithLimiter(t *testing.T) {
+	controller := gomock.NewController(t)
+	limiter := gMock.NewMockRateLimiter(controller)
+	logger := gone.NewMockLogger(controller)
+	i := &serverInterceptor{
+		logger:     logger,
+		limiter:    limiter,
+		limitRules: []LimitRule{{LimitRule: g.LimitRule{Name: "all", Limit: 10}}},
+	}
+	assert.Nil(t, i.Init())
+
+	limiter.EXPECT().Allow(gomock.Any(), "gone-grpc-limit#all#", g.RateLimitRule{Algorithm: g.TokenBucket, Limit: 10, Burst: 10}).
+		Return(g.RateLimitResult{}, errors.New("redis down"))
+	logger.EXPECT().Warnf(gomock.Any(), gomock.Any())
+	assert.Nil(t, i.limit(context.Background(), "/pkg.Svc/Get"))
+}
@@ -6,12 +6,19 @@ import (
 
 // ServerLoad load server
 func ServerLoad(loader gone.Loader) error {
-	return loader.Load(newServer())
+	loader.
+		MustLoad(&serverInterceptor{}).
+		MustLoad(&healthServer{}).
+		MustLoad(newServer())
+	return nil
 }
 
 // ClientRegisterLoad load client register
 func ClientRegisterLoad(loader gone.Loader) error {
-	return loader.Load(NewRegister())
+	loader.
+		MustLoad(NewRegister()).
+		MustLoad(&healthProber{})
+	return nil
 }
 
 // ClientLoad @deprecated use ClientRegisterLoad instead
@@ -23,7 +30,7 @@ func ClientLoad(loader gone.Loader) error {
 // @deprecated use ServerLoad and ClientRegisterLoad instead
 func Load(loader gone.Loader) error {
 	loader.
-		MustLoad(newServer()).
-		MustLoad(NewRegister())
+		MustLoadX(ServerLoad).
+		MustLoadX(ClientRegisterLoad)
 	return nil
 }
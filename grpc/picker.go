@@ -0,0 +1,132 @@
+package grpc
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gone-io/goner/g"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RouteLBPolicy the load balancing policy applying the routing rules of g.LoadBalanceRouter(eg: `balancer.routes` of
+// goner/balancer) to every call, and picking an instance of the routed subset by g.LoadBalanceSelector(eg: the strategy
+// of service configured by `balancer.strategies`), or in round-robin if it's not loaded; it is used instead of
+// `round_robin` for the targets resolved from service discovery when g.LoadBalanceRouter is loaded.
+const RouteLBPolicy = "gone_route"
+
+const roundRobinLBPolicy = "round_robin"
+
+func init() {
+	balancer.Register(base.NewBalancerBuilder(RouteLBPolicy, routePickerBuilder{}, base.Config{HealthCheck: true}))
+}
+
+// routeTargetKey the key of routeTarget in the attributes of resolver.Address
+type routeTargetKey struct{}
+
+// routeTarget the instance of address, the router and the selector choosing instances, and the feedback receiving the
+// results of calls; it is set by the resolver for the picker, selector and feedback can be nil.
+type routeTarget struct {
+	serviceName string
+	router      g.LoadBalanceRouter
+	selector    g.LoadBalanceSelector
+	feedback    g.LoadBalanceFeedback
+	instance    g.Service
+}
+
+// unaryCallKey marks the context of unary calls, see routePicker
+type unaryCallKey struct{}
+
+// Equal compare the instance by metadata, so that the SubConn is kept when the instance is discovered again, and is
+// re-created when its metadata change.
+func (t routeTarget) Equal(o any) bool {
+	other, ok := o.(routeTarget)
+	return ok &&
+		t.serviceName == other.serviceName &&
+		t.router == other.router &&
+		t.selector == other.selector &&
+		t.feedback == other.feedback &&
+		maps.Equal(t.instance.GetMetadata(), other.instance.GetMetadata())
+}
+
+type routePickerBuilder struct{}
+
+func (routePickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	p := &routePicker{subConns: make(map[string]balancer.SubConn, len(info.ReadySCs))}
+	for sc, sci := range info.ReadySCs {
+		target, ok := sci.Address.Attributes.Value(routeTargetKey{}).(routeTarget)
+		if !ok {
+			continue
+		}
+		p.serviceName, p.router, p.selector, p.feedback = target.serviceName, target.router, target.selector, target.feedback
+		p.instances = append(p.instances, target.instance)
+		p.subConns[sci.Address.Addr] = sc
+	}
+	if len(p.instances) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	slices.SortFunc(p.instances, func(a, b g.Service) int {
+		return cmp.Or(cmp.Compare(a.GetIP(), b.GetIP()), cmp.Compare(a.GetPort(), b.GetPort()))
+	})
+	return p
+}
+
+// routePicker pick one of the ready instances routed for the call. Unary calls are picked by selector, and their results
+// are reported to feedback, so that the strategies tracking calls, eg: least-request, only receive the feedback of
+// calls they selected; streaming calls, or all calls if selector is nil, are picked in round-robin.
+type routePicker struct {
+	serviceName string
+	router      g.LoadBalanceRouter
+	selector    g.LoadBalanceSelector
+	feedback    g.LoadBalanceFeedback
+	instances   []g.Service
+	subConns    map[string]balancer.SubConn
+	next        atomic.Uint32
+}
+
+func (p *routePicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	instances := p.router.Route(info.Ctx, p.serviceName, p.instances)
+	if len(instances) == 0 {
+		return balancer.PickResult{}, balancer.ErrNoSubConnAvailable
+	}
+	selected := p.selector != nil && info.Ctx.Value(unaryCallKey{}) != nil
+	instance, err := p.pick(info.Ctx, instances, selected)
+	if err != nil {
+		return balancer.PickResult{}, status.Error(codes.Unavailable, err.Error())
+	}
+	address := net.JoinHostPort(instance.GetIP(), strconv.Itoa(instance.GetPort()))
+	sc, ok := p.subConns[address]
+	if !ok {
+		return balancer.PickResult{}, balancer.ErrNoSubConnAvailable
+	}
+	result := balancer.PickResult{SubConn: sc}
+	if selected && p.feedback != nil {
+		begin := time.Now()
+		result.Done = func(info balancer.DoneInfo) {
+			var failure error
+			if failureCodes[status.Code(info.Err)] {
+				failure = info.Err
+			}
+			p.feedback.Feedback(p.serviceName, address, failure, time.Since(begin))
+		}
+	}
+	return result, nil
+}
+
+func (p *routePicker) pick(ctx context.Context, instances []g.Service, selected bool) (g.Service, error) {
+	if selected {
+		return p.selector.Select(ctx, p.serviceName, instances)
+	}
+	return instances[(p.next.Add(1)-1)%uint32(len(instances))], nil
+}
@@ -0,0 +1,154 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	gMock "github.com/gone-io/goner/g/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// laneRouter route the calls to the instances whose `lane` metadata equals the `x-lane` attribute
+type laneRouter struct{}
+
+func (laneRouter) Route(ctx context.Context, _ string, instances []g.Service) []g.Service {
+	lane := g.RouteAttributesFrom(ctx)["x-lane"]
+	var routed []g.Service
+	for _, instance := range instances {
+		if instance.GetMetadata()["lane"] == lane {
+			routed = append(routed, instance)
+		}
+	}
+	return routed
+}
+
+func startHealthServer(t *testing.T, status healthpb.HealthCheckResponse_ServingStatus) (host string, port int) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	server := grpc.NewServer()
+	h := health.NewServer()
+	h.SetServingStatus("", status)
+	healthpb.RegisterHealthServer(server, h)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	host, p, _ := net.SplitHostPort(listener.Addr().String())
+	port, _ = strconv.Atoi(p)
+	return host, port
+}
+
+func Test_routePicker(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	stableHost, stablePort := startHealthServer(t, healthpb.HealthCheckResponse_SERVING)
+	grayHost, grayPort := startHealthServer(t, healthpb.HealthCheckResponse_NOT_SERVING)
+	instances := []g.Service{
+		g.NewService("user", stableHost, stablePort, nil, true, 1),
+		g.NewService("user", grayHost, grayPort, g.Metadata{"lane": "gray"}, true, 1),
+	}
+
+	discovery := gMock.NewMockServiceDiscovery(controller)
+	discovery.EXPECT().Watch("user").Return(make(chan []g.Service), func() error { return nil }, nil)
+	discovery.EXPECT().GetInstances("user").Return(instances, nil).AnyTimes()
+
+	register := clientRegister{
+		logger:              gone.GetDefaultLogger(),
+		connections:         make(map[string]*grpc.ClientConn),
+		discovery:           discovery,
+		router:              laneRouter{},
+		insecure:            true,
+		loadBalancingPolicy: roundRobinLBPolicy,
+		tracerIdKey:         "X-Trace-Id",
+	}
+	assert.Nil(t, register.Init())
+	conn := register.getConn("user", "dns:///user")
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	gray := g.WithRouteAttributes(context.Background(), map[string]string{"X-Lane": "gray"})
+	for i := 0; i < 4; i++ {
+		res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.GetStatus())
+
+		res, err = client.Check(gray, &healthpb.HealthCheckRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, res.GetStatus())
+	}
+}
+
+func Test_routePicker_selector(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	stableHost, stablePort := startHealthServer(t, healthpb.HealthCheckResponse_SERVING)
+	grayHost, grayPort := startHealthServer(t, healthpb.HealthCheckResponse_NOT_SERVING)
+	instances := []g.Service{
+		g.NewService("user", stableHost, stablePort, nil, true, 1),
+		g.NewService("user", grayHost, grayPort, g.Metadata{"lane": "gray"}, true, 1),
+	}
+
+	discovery := gMock.NewMockServiceDiscovery(controller)
+	discovery.EXPECT().Watch("user").Return(make(chan []g.Service), func() error { return nil }, nil)
+	discovery.EXPECT().GetInstances("user").Return(instances, nil).AnyTimes()
+
+	// the strategy of service always selects the gray instance from the instances routed
+	selector := gMock.NewMockLoadBalanceSelector(controller)
+	selector.EXPECT().Select(gomock.Any(), "user", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, instances []g.Service) (g.Service, error) {
+			for _, instance := range instances {
+				if instance.GetMetadata()["lane"] == "gray" {
+					return instance, nil
+				}
+			}
+			return nil, errors.New("no gray instance")
+		},
+	).AnyTimes()
+
+	register := clientRegister{
+		logger:              gone.GetDefaultLogger(),
+		connections:         make(map[string]*grpc.ClientConn),
+		discovery:           discovery,
+		router:              laneRouter{},
+		selector:            selector,
+		insecure:            true,
+		loadBalancingPolicy: roundRobinLBPolicy,
+		tracerIdKey:         "X-Trace-Id",
+	}
+	assert.Nil(t, register.Init())
+	conn := register.getConn("user", "dns:///user")
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	gray := g.WithRouteAttributes(context.Background(), map[string]string{"X-Lane": "gray"})
+	res, err := client.Check(gray, &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, res.GetStatus())
+
+	// the error of selector fails the call with Unavailable
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func Test_routeTarget_Equal(t *testing.T) {
+	target := routeTarget{serviceName: "user", router: laneRouter{}, instance: g.NewService("user", "127.0.0.1", 8080, g.Metadata{"lane": "gray"}, true, 1)}
+
+	assert.True(t, target.Equal(routeTarget{serviceName: "user", router: laneRouter{}, instance: g.NewService("user", "127.0.0.1", 8080, g.Metadata{"lane": "gray"}, true, 1)}))
+	assert.False(t, target.Equal(routeTarget{serviceName: "user", router: laneRouter{}, instance: g.NewService("user", "127.0.0.1", 8080, nil, true, 1)}))
+	assert.False(t, target.Equal(routeTarget{serviceName: "order", router: laneRouter{}, instance: target.instance}))
+	assert.False(t, target.Equal("user"))
+}
@@ -0,0 +1,50 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// ProbeGRPC the type of the prober checking instances by the standard `grpc.health.v1.Health` service, which can be
+// used by setting `balancer.probe.type=grpc`
+const ProbeGRPC = "grpc"
+
+var _ g.InstanceProber = (*healthProber)(nil)
+
+// healthProber the instance is healthy if `grpc.health.v1.Health/Check` responds SERVING, the connection uses the
+// same grpc.DialOption and credentials as gRPC clients.
+type healthProber struct {
+	gone.Flag
+	register *clientRegister `gone:"*"`
+}
+
+func (p *healthProber) ProbeType() string {
+	return ProbeGRPC
+}
+
+func (p *healthProber) Probe(ctx context.Context, instance g.Service) error {
+	options := append(p.register.grpcOptions[:len(p.register.grpcOptions):len(p.register.grpcOptions)], p.register.transportOptions()...)
+	conn, err := grpc.NewClient(net.JoinHostPort(instance.GetIP(), strconv.Itoa(instance.GetPort())), options...)
+	if err != nil {
+		return gone.ToError(err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	res, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return gone.ToError(fmt.Sprintf("grpc health check got status %s", res.GetStatus()))
+	}
+	return nil
+}
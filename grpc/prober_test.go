@@ -0,0 +1,40 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/gone-io/goner/g"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func Test_healthProber(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	server := grpc.NewServer()
+	healthService := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthService)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	instance := g.NewService("user", host, p, nil, true, 1)
+
+	prober := &healthProber{register: &clientRegister{insecure: true}}
+	assert.Equal(t, ProbeGRPC, prober.ProbeType())
+	assert.Nil(t, prober.Probe(context.Background(), instance))
+
+	healthService.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	assert.Error(t, prober.Probe(context.Background(), instance))
+
+	server.Stop()
+	assert.Error(t, prober.Probe(context.Background(), instance))
+}
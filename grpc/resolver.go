@@ -1,7 +1,11 @@
 package grpc
 
 import (
-	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/gone-io/gone/v2"
 	"github.com/gone-io/goner/g"
@@ -11,6 +15,10 @@ import (
 
 var _ resolver.Builder = (*resolverBuilder)(nil)
 
+// refreshInterval the interval to re-filter instances by LoadBalanceFeedback, so that ejected instances are removed
+// and recovered ones are added back in time
+const refreshInterval = time.Second
+
 func NewResolverBuilder(discovery g.ServiceDiscovery, logger gone.Logger) resolver.Builder {
 	return &resolverBuilder{discovery: discovery, logger: logger}
 }
@@ -18,14 +26,21 @@ func NewResolverBuilder(discovery g.ServiceDiscovery, logger gone.Logger) resolv
 type resolverBuilder struct {
 	discovery g.ServiceDiscovery
 	logger    gone.Logger
+	feedback  g.LoadBalanceFeedback
+	router    g.LoadBalanceRouter
+	selector  g.LoadBalanceSelector
 }
 
 func (b *resolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
 	r := &discoveryResolver{
 		discovery:   b.discovery,
 		logger:      b.logger,
+		feedback:    b.feedback,
+		router:      b.router,
+		selector:    b.selector,
 		cc:          cc,
 		serviceName: target.Endpoint(),
+		done:        make(chan struct{}),
 	}
 
 	ch, stop, err := b.discovery.Watch(target.Endpoint())
@@ -46,6 +61,9 @@ func (b *resolverBuilder) Build(target resolver.Target, cc resolver.ClientConn,
 
 	// Start watching for updates
 	go r.watch()
+	if r.feedback != nil {
+		go r.refresh()
+	}
 
 	return r, nil
 }
@@ -61,6 +79,15 @@ type discoveryResolver struct {
 	stop        func() error
 	updateCh    <-chan []g.Service
 	logger      gone.Logger
+	feedback    g.LoadBalanceFeedback
+	router      g.LoadBalanceRouter
+	selector    g.LoadBalanceSelector
+
+	lock      sync.Mutex
+	instances []g.Service
+	addresses []string
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 func (r *discoveryResolver) ResolveNow(resolver.ResolveNowOptions) {
@@ -73,6 +100,11 @@ func (r *discoveryResolver) ResolveNow(resolver.ResolveNowOptions) {
 }
 
 func (r *discoveryResolver) Close() {
+	r.closeOnce.Do(func() {
+		if r.done != nil {
+			close(r.done)
+		}
+	})
 	if r.stop != nil {
 		if err := r.stop(); err != nil {
 			r.logger.Errorf("discoveryResolver close err: %v", err)
@@ -86,15 +118,67 @@ func (r *discoveryResolver) watch() {
 	}
 }
 
+// refresh re-filter the instances periodically, the state is updated only when the available addresses change
+func (r *discoveryResolver) refresh() {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.update(nil, false)
+		case <-r.done:
+			return
+		}
+	}
+}
+
 func (r *discoveryResolver) updateState(services []g.Service) {
-	addresses := make([]resolver.Address, 0, len(services))
+	r.update(services, true)
+}
+
+// available filter out the unhealthy instances, and the ones ejected by LoadBalanceFeedback if it is provided
+func (r *discoveryResolver) available(services []g.Service) []g.Service {
+	if r.feedback != nil {
+		return r.feedback.Available(r.serviceName, services)
+	}
+	available := make([]g.Service, 0, len(services))
 	for _, svc := range services {
+		if svc.IsHealthy() {
+			available = append(available, svc)
+		}
+	}
+	return available
+}
+
+// update push the available addresses of services to gRPC; if discovered is false, the instances last discovered
+// are re-filtered and pushed only when the available addresses change
+func (r *discoveryResolver) update(services []g.Service, discovered bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if discovered {
+		r.instances = services
+	}
+	available := r.available(r.instances)
+	addresses := make([]resolver.Address, 0, len(available))
+	keys := make([]string, 0, len(available))
+	for _, svc := range available {
+		addr := net.JoinHostPort(svc.GetIP(), strconv.Itoa(svc.GetPort()))
+		keys = append(keys, addr)
+		attrs := attributes.New("weight", svc.GetWeight())
+		if r.router != nil {
+			attrs = attrs.WithValue(routeTargetKey{}, routeTarget{serviceName: r.serviceName, router: r.router, selector: r.selector, feedback: r.feedback, instance: svc})
+		}
 		addresses = append(addresses, resolver.Address{
-			Addr:       fmt.Sprintf("%s:%d", svc.GetIP(), svc.GetPort()),
+			Addr:       addr,
 			ServerName: svc.GetName(),
-			Attributes: attributes.New("weight", svc.GetWeight()),
+			Attributes: attrs,
 		})
 	}
+	if !discovered && slices.Equal(r.addresses, keys) {
+		return
+	}
+	r.addresses = keys
 
 	err := r.cc.UpdateState(resolver.State{
 		Addresses: addresses,
@@ -3,6 +3,7 @@ package grpc
 import (
 	"fmt"
 	"net/url"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	gMock "github.com/gone-io/goner/g/mock"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/attributes"
 	"google.golang.org/grpc/resolver"
 )
 
@@ -23,6 +25,7 @@ func TestResolverBuilder_Build(t *testing.T) {
 	service.EXPECT().GetPort().Return(8080).AnyTimes()
 	service.EXPECT().GetName().Return("svc1").AnyTimes()
 	service.EXPECT().GetWeight().Return(100.0).AnyTimes()
+	service.EXPECT().IsHealthy().Return(true).AnyTimes()
 
 	tests := []struct {
 		name           string
@@ -106,6 +109,7 @@ func TestDiscoveryResolver_ResolveNow(t *testing.T) {
 	service.EXPECT().GetPort().Return(8080).AnyTimes()
 	service.EXPECT().GetName().Return("svc1").AnyTimes()
 	service.EXPECT().GetWeight().Return(100.0).AnyTimes()
+	service.EXPECT().IsHealthy().Return(true).AnyTimes()
 
 	tests := []struct {
 		name           string
@@ -178,6 +182,7 @@ func TestDiscoveryResolver_Watch(t *testing.T) {
 	service.EXPECT().GetPort().Return(8080).AnyTimes()
 	service.EXPECT().GetName().Return("svc1").AnyTimes()
 	service.EXPECT().GetWeight().Return(100.0).AnyTimes()
+	service.EXPECT().IsHealthy().Return(true).AnyTimes()
 
 	tests := []struct {
 		name        string
@@ -225,3 +230,68 @@ func TestDiscoveryResolver_Watch(t *testing.T) {
 		})
 	}
 }
+
+func TestDiscoveryResolver_available(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	healthy := g.NewService("svc1", "127.0.0.1", 8080, nil, true, 1)
+	unhealthy := g.NewService("svc1", "127.0.0.1", 8081, nil, false, 1)
+
+	cc := NewMockClientConn(controller)
+	cc.EXPECT().UpdateState(resolver.State{Addresses: []resolver.Address{{
+		Addr:       "127.0.0.1:8080",
+		ServerName: "svc1",
+		Attributes: attributes.New("weight", 1.0),
+	}}})
+
+	r := &discoveryResolver{cc: cc, serviceName: "svc1"}
+	r.updateState([]g.Service{healthy, unhealthy})
+}
+
+func TestDiscoveryResolver_refresh(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	instances := []g.Service{
+		g.NewService("svc1", "127.0.0.1", 8080, nil, true, 1),
+		g.NewService("svc1", "127.0.0.1", 8081, nil, true, 1),
+	}
+
+	var ejected atomic.Bool
+	feedback := gMock.NewMockLoadBalanceFeedback(controller)
+	feedback.EXPECT().Available("svc1", gomock.Any()).DoAndReturn(func(_ string, services []g.Service) []g.Service {
+		if ejected.Load() {
+			return services[1:]
+		}
+		return services
+	}).AnyTimes()
+
+	updated := make(chan int, 10)
+	cc := NewMockClientConn(controller)
+	cc.EXPECT().UpdateState(gomock.Any()).DoAndReturn(func(state resolver.State) error {
+		updated <- len(state.Addresses)
+		return nil
+	}).AnyTimes()
+
+	discovery := gMock.NewMockServiceDiscovery(controller)
+	discovery.EXPECT().Watch("svc1").Return(make(chan []g.Service), func() error { return nil }, nil)
+	discovery.EXPECT().GetInstances("svc1").Return(instances, nil)
+
+	builder := &resolverBuilder{discovery: discovery, logger: mock.GetDefaultLogger(), feedback: feedback}
+	r, err := builder.Build(resolver.Target{URL: url.URL{Scheme: "dns", Path: "/svc1"}}, cc, resolver.BuildOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, <-updated)
+
+	// the state is updated only when the available addresses change
+	ejected.Store(true)
+	assert.Equal(t, 1, <-updated)
+	select {
+	case <-updated:
+		t.Fatal("unexpected update")
+	case <-time.After(refreshInterval + 200*time.Millisecond):
+	}
+
+	r.Close()
+	r.Close()
+}
@@ -7,11 +7,14 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"net"
 	"reflect"
+	"time"
 
 	"github.com/gone-io/gone/v2"
 	"github.com/gone-io/goner/g"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
 )
 
 func mustCreateListener(host string, port int) net.Listener {
@@ -33,6 +36,8 @@ type server struct {
 	tracer             g.Tracer             `gone:"*" option:"allowNil"`
 	registry           g.ServiceRegistry    `gone:"*" option:"allowNil"`
 	isOtelTracerLoaded g.IsOtelTracerLoaded `gone:"*" option:"allowNil"`
+	interceptor        *serverInterceptor   `gone:"*" option:"allowNil"`
+	health             *healthServer        `gone:"*" option:"allowNil"`
 
 	port             int    `gone:"config,server.grpc.port,default=9090"`
 	host             string `gone:"config,server.grpc.host,default=0.0.0.0"`
@@ -40,6 +45,19 @@ type server struct {
 	serviceUseSubNet string `gone:"config,server.grpc.service-use-subnet,default=0.0.0.0/0"`
 	tracerIdKey      string `gone:"config,server.grpc.x-trace-id-key=X-Trace-Id"`
 
+	// reflection 是否注册服务反射，对应配置项为：`server.grpc.reflection.enabled`
+	reflection bool `gone:"config,server.grpc.reflection.enabled,default=false"`
+
+	// drainWait 停止前的排空时间：健康状态置为NOT_SERVING并注销服务后，等待客户端和注册中心摘除本实例，再关闭服务器；
+	// 对应配置项为：`server.grpc.drain-wait`
+	drainWait time.Duration `gone:"config,server.grpc.drain-wait,default=0s"`
+
+	// maxWaitBeforeStop 优雅关闭等待正在处理的调用结束的最长时间，超时后强制关闭，对应配置项为：`server.grpc.max-wait-before-stop`
+	maxWaitBeforeStop time.Duration `gone:"config,server.grpc.max-wait-before-stop,default=5s"`
+
+	// tlsConfig TLS配置，对应配置项为：`server.grpc.tls`；使用cmux时由cmux处理TLS，该配置被忽略
+	tlsConfig g.TLSConfig `gone:"config,server.grpc.tls"`
+
 	grpcServer     *grpc.Server
 	listener       net.Listener
 	createListener func(host string, port int) net.Listener
@@ -48,7 +66,7 @@ type server struct {
 }
 
 func (s *server) GonerName() string {
-	return "gone-grpc-server"
+	return IdGoneGrpcServer
 }
 
 func (s *server) getAddress() string {
@@ -65,16 +83,68 @@ func (s *server) initListener() {
 	}
 	s.listener = s.createListener(s.host, s.port)
 }
-func (s *server) Init() {
+func (s *server) Init() error {
 	s.initListener()
 	options := append(
 		s.grpcOptions,
-		grpc.ChainUnaryInterceptor(s.traceInterceptor, s.recoveryInterceptor),
+		grpc.ChainUnaryInterceptor(s.unaryInterceptors()...),
+		grpc.ChainStreamInterceptor(s.streamInterceptors()...),
 	)
 	if s.isOtelTracerLoaded {
 		options = append(options, grpc.StatsHandler(otelgrpc.NewServerHandler()))
 	}
+	if s.tlsConfig.Enabled {
+		if s.cMuxServer != nil {
+			s.logger.Warnf("`server.grpc.tls` is ignored when cmux is used, please config TLS by `server.tls`")
+		} else {
+			config, err := s.tlsConfig.ServerTLSConfig(s.logger)
+			if err != nil {
+				return err
+			}
+			options = append(options, grpc.Creds(credentials.NewTLS(config)))
+		}
+	}
 	s.grpcServer = grpc.NewServer(options...)
+	return nil
+}
+
+// unaryInterceptors the chain of unary interceptors, from outer to inner: trace, access log, status mapping, recovery,
+// deadline, auth, rate limit, validation and route attributes.
+func (s *server) unaryInterceptors() []grpc.UnaryServerInterceptor {
+	i := s.interceptor
+	if i == nil {
+		return []grpc.UnaryServerInterceptor{s.traceInterceptor, s.recoveryInterceptor}
+	}
+	return []grpc.UnaryServerInterceptor{
+		s.traceInterceptor,
+		i.logUnary,
+		i.statusUnary,
+		s.recoveryInterceptor,
+		i.deadlineUnary,
+		i.authUnary,
+		i.limitUnary,
+		i.validateUnary,
+		i.routeUnary,
+	}
+}
+
+// streamInterceptors the chain of stream interceptors, in the same order as unary ones.
+func (s *server) streamInterceptors() []grpc.StreamServerInterceptor {
+	i := s.interceptor
+	if i == nil {
+		return []grpc.StreamServerInterceptor{s.traceStreamInterceptor, s.recoveryStreamInterceptor}
+	}
+	return []grpc.StreamServerInterceptor{
+		s.traceStreamInterceptor,
+		i.logStream,
+		i.statusStream,
+		s.recoveryStreamInterceptor,
+		i.deadlineStream,
+		i.authStream,
+		i.limitStream,
+		i.validateStream,
+		i.routeStream,
+	}
 }
 
 func (s *server) Provide() (*grpc.Server, error) {
@@ -89,6 +159,12 @@ func (s *server) register() {
 		s.logger.Infof("Register gRPC service %v", reflect.ValueOf(grpcService).Type().String())
 		grpcService.RegisterGrpcServer(s.grpcServer)
 	}
+	if s.health != nil {
+		s.health.register(s.grpcServer)
+	}
+	if s.reflection {
+		reflection.Register(s.grpcServer)
+	}
 }
 
 func (s *server) getPort() int {
@@ -130,6 +206,9 @@ func (s *server) regService() func() error {
 
 func (s *server) Start() error {
 	s.register()
+	if s.health != nil {
+		s.health.start()
+	}
 	if s.tracer == nil {
 		go s.server()
 	} else {
@@ -145,11 +224,57 @@ func (s *server) server() {
 }
 
 func (s *server) Stop() error {
+	s.drain()
+	s.stop()
+	return nil
+}
+
+// drain mark all services NOT_SERVING and deregister the service, then wait for the change to propagate,
+// so that no new call is routed to this instance when it is shut down.
+func (s *server) drain() {
+	if s.health != nil {
+		s.health.shutdown()
+	}
 	if s.unRegService != nil {
 		g.ErrorPrinter(s.logger, s.unRegService(), "unregister gRPC service %s failed:", s.serviceName)
 	}
-	s.grpcServer.Stop()
-	return nil
+	if s.drainWait > 0 {
+		s.logger.Infof("gRPC server draining, wait %s before stop", s.drainWait)
+		time.Sleep(s.drainWait)
+	}
+}
+
+// stop wait for the pending calls to finish, and force to stop the server if they do not finish in maxWaitBeforeStop.
+func (s *server) stop() {
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	timer := time.NewTimer(s.maxWaitBeforeStop)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		s.logger.Warnf("gRPC server graceful stop timeout after %s, force to stop", s.maxWaitBeforeStop)
+		s.grpcServer.Stop()
+		<-done
+	}
+}
+
+// traceId get the trace ID of incoming call from the span of OpenTelemetry or metadata.
+func (s *server) traceId(ctx context.Context) string {
+	if s.isOtelTracerLoaded {
+		spanContext := trace.SpanFromContext(ctx).SpanContext()
+		if spanContext.IsValid() {
+			return spanContext.TraceID().String()
+		}
+	}
+	if traceIdV := metadata.ValueFromIncomingContext(ctx, s.tracerIdKey); len(traceIdV) > 0 {
+		return traceIdV[0]
+	}
+	return ""
 }
 
 func (s *server) traceInterceptor(
@@ -158,30 +283,34 @@ func (s *server) traceInterceptor(
 	_ *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (resp interface{}, err error) {
-	var traceId string
-
-	if s.isOtelTracerLoaded {
-		span := trace.SpanFromContext(ctx)
-		spanContext := span.SpanContext()
-		if spanContext.IsValid() {
-			traceId = spanContext.TraceID().String()
-		}
+	if s.tracer == nil {
+		return handler(ctx, req)
 	}
+	s.tracer.SetTraceId(s.traceId(ctx), func() {
+		resp, err = handler(ctx, req)
+	})
+	return
+}
 
-	if traceId == "" {
-		traceIdV := metadata.ValueFromIncomingContext(ctx, s.tracerIdKey)
-		if len(traceIdV) > 0 {
-			traceId = traceIdV[0]
-		}
+func (s *server) traceStreamInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) (err error) {
+	if s.tracer == nil {
+		return handler(srv, ss)
 	}
+	s.tracer.SetTraceId(s.traceId(ss.Context()), func() {
+		err = handler(srv, ss)
+	})
+	return
+}
 
-	if s.tracer == nil {
-		return handler(ctx, req)
-	} else {
-		s.tracer.SetTraceId(traceId, func() {
-			resp, err = handler(ctx, req)
-		})
-		return
+func (s *server) recover(err *error) {
+	if e := recover(); e != nil {
+		*err = gone.NewInnerErrorSkip(fmt.Sprintf("panic: %v", e), gone.PanicError, 3)
+		s.logger.Errorf("%v", *err)
 	}
 }
 
@@ -191,11 +320,16 @@ func (s *server) recoveryInterceptor(
 	_ *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (resp interface{}, err error) {
-	defer func() {
-		if e := recover(); e != nil {
-			err = gone.NewInnerErrorSkip(fmt.Sprintf("panic: %v", e), gone.PanicError, 3)
-			s.logger.Errorf("%v", err)
-		}
-	}()
+	defer s.recover(&err)
 	return handler(ctx, req)
 }
+
+func (s *server) recoveryStreamInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) (err error) {
+	defer s.recover(&err)
+	return handler(srv, ss)
+}
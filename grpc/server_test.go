@@ -2,10 +2,19 @@ package grpc
 
 import (
 	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gone-io/gone/v2"
 	mock "github.com/gone-io/gone/v2"
+	gMock "github.com/gone-io/goner/g/mock"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc"
-	"testing"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
 
 	"github.com/gone-io/goner/g"
 	"github.com/stretchr/testify/assert"
@@ -138,3 +147,197 @@ func Test_server_recoveryInterceptor(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestServer_traceStreamInterceptor(t *testing.T) {
+	ss := &fakeServerStream{ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs("X-Trace-Id", "test-trace-id"))}
+	handler := func(srv any, stream grpc.ServerStream) error {
+		assert.Equal(t, ss, stream)
+		return nil
+	}
+
+	s := &server{tracerIdKey: "X-Trace-Id"}
+	assert.NoError(t, s.traceStreamInterceptor(nil, ss, nil, handler))
+
+	tracer := &mockTracer{}
+	s.tracer = tracer
+	assert.NoError(t, s.traceStreamInterceptor(nil, ss, nil, handler))
+	assert.True(t, tracer.called)
+	assert.Equal(t, "test-trace-id", tracer.traceId)
+}
+
+func Test_server_recoveryStreamInterceptor(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	logger := mock.NewMockLogger(controller)
+	s := server{
+		logger: logger,
+	}
+	ss := &fakeServerStream{ctx: context.Background()}
+
+	t.Run("panic", func(t *testing.T) {
+		logger.EXPECT().Errorf(gomock.Any(), gomock.Any())
+		err := s.recoveryStreamInterceptor(nil, ss, nil, func(srv any, stream grpc.ServerStream) error {
+			panic("panic")
+		})
+		assert.Error(t, err)
+	})
+	t.Run("normal", func(t *testing.T) {
+		err := s.recoveryStreamInterceptor(nil, ss, nil, func(srv any, stream grpc.ServerStream) error {
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+}
+
+func Test_server_reflection(t *testing.T) {
+	s := &server{
+		logger:       gone.GetDefaultLogger(),
+		grpcServices: []Service{&mockService{}},
+		health:       &healthServer{enabled: true},
+		reflection:   true,
+		createListener: func(host string, port int) net.Listener {
+			return bufconn.Listen(1 << 10)
+		},
+	}
+	s.health.Init()
+	s.Init()
+	s.register()
+
+	services := s.grpcServer.GetServiceInfo()
+	assert.Contains(t, services, "grpc.health.v1.Health")
+	assert.Contains(t, services, "grpc.reflection.v1.ServerReflection")
+}
+
+func Test_server_Stop(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	logger := mock.NewMockLogger(controller)
+	logger.EXPECT().Infof(gomock.Any(), gomock.Any()).AnyTimes()
+
+	listener := bufconn.Listen(1 << 20)
+	var deregistered bool
+	s := &server{
+		logger:            logger,
+		grpcServices:      []Service{&mockService{}},
+		health:            &healthServer{logger: logger, enabled: true},
+		drainWait:         10 * time.Millisecond,
+		maxWaitBeforeStop: 100 * time.Millisecond,
+		createListener: func(host string, port int) net.Listener {
+			return listener
+		},
+	}
+	s.health.Init()
+	s.Init()
+	assert.Nil(t, s.Start())
+	s.unRegService = func() error {
+		deregistered = true
+		return nil
+	}
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	// a pending stream blocks graceful stop until the hard timeout is reached
+	stream, err := healthpb.NewHealthClient(conn).Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+	_, err = stream.Recv()
+	assert.Nil(t, err)
+
+	logger.EXPECT().Warnf(gomock.Any(), gomock.Any())
+	begin := time.Now()
+	assert.Nil(t, s.Stop())
+	assert.True(t, deregistered)
+	assert.True(t, s.health.draining.Load())
+	assert.GreaterOrEqual(t, time.Since(begin), 100*time.Millisecond)
+}
+
+func Test_server_healthRegisteredByUser(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	logger := mock.NewMockLogger(controller)
+	logger.EXPECT().Infof(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Warnf(gomock.Any(), gomock.Any())
+
+	s := &server{
+		logger:       logger,
+		grpcServices: []Service{&healthService{Server: health.NewServer()}},
+		health:       &healthServer{logger: logger, enabled: true},
+		createListener: func(host string, port int) net.Listener {
+			return bufconn.Listen(1 << 10)
+		},
+	}
+	s.health.Init()
+	s.Init()
+	s.register()
+	assert.False(t, s.health.enabled)
+	s.health.shutdown()
+}
+
+func Test_server_TLS(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	logger := mock.NewMockLogger(controller)
+	logger.EXPECT().Infof(gomock.Any(), gomock.Any()).AnyTimes()
+
+	s := &server{
+		logger:            logger,
+		grpcServices:      []Service{&healthService{Server: health.NewServer()}},
+		host:              "127.0.0.1",
+		createListener:    mustCreateListener,
+		maxWaitBeforeStop: time.Second,
+		tlsConfig: g.TLSConfig{
+			Enabled:    true,
+			CertFile:   "testdata/tls/server.crt",
+			KeyFile:    "testdata/tls/server.key",
+			CAFile:     "testdata/tls/ca.crt",
+			ClientAuth: g.TLSClientAuthRequireAndVerify,
+		},
+	}
+	assert.Nil(t, s.Init())
+	assert.Nil(t, s.Start())
+	defer s.Stop()
+
+	check := func(register *clientRegister) error {
+		register.tracerIdKey = "X-Trace-Id"
+		assert.Nil(t, register.Init())
+		conn, err := register.createConn(s.getAddress(), nil)
+		assert.Nil(t, err)
+		defer conn.Close()
+		_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	assert.Nil(t, check(&clientRegister{tlsConfig: g.TLSConfig{
+		Enabled:  true,
+		CertFile: "testdata/tls/client.crt",
+		KeyFile:  "testdata/tls/client.key",
+		CAFile:   "testdata/tls/ca.crt",
+	}}))
+	assert.Error(t, check(&clientRegister{tlsConfig: g.TLSConfig{Enabled: true, CAFile: "testdata/tls/ca.crt"}}))
+	assert.Error(t, check(&clientRegister{insecure: true}))
+
+	assert.Error(t, (&clientRegister{tlsConfig: g.TLSConfig{Enabled: true, CAFile: "testdata/tls/missing.crt"}}).Init())
+	s.tlsConfig.CertFile = "testdata/tls/missing.crt"
+	assert.Error(t, s.Init())
+}
+
+func Test_server_TLSWithCmux(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	logger := mock.NewMockLogger(controller)
+	logger.EXPECT().Warnf(gomock.Any())
+	cMuxServer := gMock.NewMockCmux(controller)
+	cMuxServer.EXPECT().MatchFor(g.GRPC).Return(bufconn.Listen(1 << 10))
+
+	s := &server{
+		logger:     logger,
+		cMuxServer: cMuxServer,
+		tlsConfig:  g.TLSConfig{Enabled: true},
+	}
+	assert.Nil(t, s.Init())
+}
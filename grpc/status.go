@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gone-io/gone/v2"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ReasonBusinessError the reason of errdetails.ErrorInfo attached to the status converted from gone.BusinessError
+	ReasonBusinessError = "BUSINESS_ERROR"
+
+	// ReasonGoneError the reason of errdetails.ErrorInfo attached to the status converted from gone.Error
+	ReasonGoneError = "GONE_ERROR"
+)
+
+var httpStatusCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.AlreadyExists,
+	http.StatusPreconditionFailed:  codes.FailedPrecondition,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusRequestTimeout:      codes.DeadlineExceeded,
+	http.StatusInternalServerError: codes.Internal,
+	http.StatusNotImplemented:      codes.Unimplemented,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+}
+
+// toStatus convert the error returned by handler to gRPC status error:
+// gone.BusinessError is converted to FailedPrecondition; gone.Error is converted by its http status code, and the
+// message of gone.InnerError is hidden if hideInnerError is true; both carry errdetails.ErrorInfo with the error code.
+// Errors which are already gRPC status are returned as is.
+func toStatus(err error, domain string, hideInnerError bool) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+
+	var businessError gone.BusinessError
+	if errors.As(err, &businessError) {
+		info := &errdetails.ErrorInfo{
+			Reason:   ReasonBusinessError,
+			Domain:   domain,
+			Metadata: map[string]string{"code": strconv.Itoa(businessError.Code())},
+		}
+		if data := businessError.Data(); data != nil {
+			if bytes, e := json.Marshal(data); e == nil {
+				info.Metadata["data"] = string(bytes)
+			}
+		}
+		return withDetails(status.New(codes.FailedPrecondition, businessError.Msg()), info)
+	}
+
+	var goneError gone.Error
+	if errors.As(err, &goneError) {
+		code, ok := httpStatusCodes[goneError.GetStatusCode()]
+		if !ok {
+			code = codes.Unknown
+		}
+		msg := goneError.Msg()
+		var innerError gone.InnerError
+		if hideInnerError && errors.As(err, &innerError) {
+			code, msg = codes.Internal, "internal error"
+		}
+		return withDetails(status.New(code, msg), &errdetails.ErrorInfo{
+			Reason:   ReasonGoneError,
+			Domain:   domain,
+			Metadata: map[string]string{"code": strconv.Itoa(goneError.Code())},
+		})
+	}
+	return status.Error(codes.Unknown, err.Error())
+}
+
+func withDetails(s *status.Status, info *errdetails.ErrorInfo) error {
+	if st, err := s.WithDetails(info); err == nil {
+		return st.Err()
+	}
+	return s.Err()
+}
@@ -0,0 +1,59 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_toStatus(t *testing.T) {
+	assert.Nil(t, toStatus(nil, "svc", true))
+
+	origin := status.Error(codes.NotFound, "not found")
+	assert.Equal(t, origin, toStatus(origin, "svc", true))
+
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(toStatus(fmt.Errorf("wrap: %w", context.DeadlineExceeded), "svc", true)))
+	assert.Equal(t, codes.Canceled, status.Code(toStatus(context.Canceled, "svc", true)))
+	assert.Equal(t, codes.Unknown, status.Code(toStatus(errors.New("unknown"), "svc", true)))
+
+	tests := []struct {
+		name    string
+		err     error
+		hide    bool
+		code    codes.Code
+		msg     string
+		reason  string
+		errCode string
+	}{
+		{name: "parameter error", err: gone.NewParameterError("bad name", 1001), code: codes.InvalidArgument, msg: "bad name", reason: ReasonGoneError, errCode: "1001"},
+		{name: "unauthorized", err: gone.NewError(401, "who", 401), code: codes.Unauthenticated, msg: "who", reason: ReasonGoneError, errCode: "401"},
+		{name: "unmapped status", err: gone.NewError(1, "teapot", 418), code: codes.Unknown, msg: "teapot", reason: ReasonGoneError, errCode: "1"},
+		{name: "business error", err: gone.NewBusinessError("no stock", 2001, map[string]int{"left": 0}), code: codes.FailedPrecondition, msg: "no stock", reason: ReasonBusinessError, errCode: "2001"},
+		{name: "inner error hidden", err: gone.NewInnerError("db down", 500), hide: true, code: codes.Internal, msg: "internal error", reason: ReasonGoneError, errCode: "500"},
+		{name: "inner error shown", err: gone.NewInnerError("db down", 500), code: codes.Internal, msg: "db down", reason: ReasonGoneError, errCode: "500"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, ok := status.FromError(toStatus(tt.err, "svc", tt.hide))
+			assert.True(t, ok)
+			assert.Equal(t, tt.code, st.Code())
+			assert.Equal(t, tt.msg, st.Message())
+			if assert.Len(t, st.Details(), 1) {
+				info := st.Details()[0].(*errdetails.ErrorInfo)
+				assert.Equal(t, tt.reason, info.Reason)
+				assert.Equal(t, "svc", info.Domain)
+				assert.Equal(t, tt.errCode, info.Metadata["code"])
+			}
+		})
+	}
+
+	st, _ := status.FromError(toStatus(gone.NewBusinessError("no stock", 2001, map[string]int{"left": 0}), "", true))
+	assert.Equal(t, `{"left":0}`, st.Details()[0].(*errdetails.ErrorInfo).Metadata["data"])
+}
@@ -2,12 +2,17 @@ package grpc
 
 import (
 	"context"
+	"github.com/gone-io/gone/v2"
 	"github.com/gone-io/goner/g"
 	gMock "github.com/gone-io/goner/g/mock"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
 	"testing"
 )
 
@@ -25,7 +30,7 @@ func TestWithOpenTelemetry(t *testing.T) {
 		insecure:           true,
 	}
 
-	conn, err := register.createConn("127.0.0.1:9090")
+	conn, err := register.createConn("127.0.0.1:9090", nil)
 	assert.Nil(t, err)
 	assert.NotNil(t, conn)
 }
@@ -51,3 +56,51 @@ func TestWithTracer(t *testing.T) {
 	})
 	assert.Nil(t, err)
 }
+
+func TestWithTracer_stream(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	logger := gone.NewMockLogger(controller)
+	logger.EXPECT().Infof(gomock.Any(), gomock.Any()).AnyTimes()
+	clientTracer := gMock.NewMockTracer(controller)
+	clientTracer.EXPECT().GetTraceId().Return("xxx-0002")
+	serverTracer := &mockTracer{}
+
+	listener := bufconn.Listen(1 << 20)
+	s := &server{
+		logger:         logger,
+		tracer:         serverTracer,
+		tracerIdKey:    "X-Trace-Id",
+		grpcServices:   []Service{&healthService{Server: health.NewServer()}},
+		createListener: func(string, int) net.Listener { return listener },
+	}
+	s.Init()
+	s.register()
+	go func() {
+		_ = s.grpcServer.Serve(listener)
+	}()
+	defer s.grpcServer.Stop()
+
+	register := clientRegister{
+		connections: make(map[string]*grpc.ClientConn),
+		grpcOptions: []grpc.DialOption{
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		},
+		insecure:    true,
+		tracer:      clientTracer,
+		tracerIdKey: "X-Trace-Id",
+	}
+	conn, err := register.createConn("passthrough:///bufnet", nil)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := healthpb.NewHealthClient(conn).Watch(ctx, &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+	res, err := stream.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.Status)
+	assert.True(t, serverTracer.called)
+	assert.Equal(t, "xxx-0002", serverTracer.traceId)
+}
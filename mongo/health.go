@@ -0,0 +1,38 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// healthChecker checks the default client with ping, it is used by the readiness probe of goner/gin.
+type healthChecker struct {
+	gone.Flag
+	client *mongo.Client `gone:"*"`
+}
+
+var _ g.HealthChecker = (*healthChecker)(nil)
+
+func (h *healthChecker) GonerName() string {
+	return "gone-mongo-health-checker"
+}
+
+func (h *healthChecker) HealthCheckName() string {
+	return "mongo"
+}
+
+func (h *healthChecker) CheckHealth(ctx context.Context) error {
+	return h.client.Ping(ctx, nil)
+}
+
+// LoadHealthChecker load *mongo.Client provider and the g.HealthChecker pinging the default client, which is
+// checked by the readiness probe of goner/gin.
+func LoadHealthChecker(loader gone.Loader) error {
+	loader.
+		MustLoad(&healthChecker{}).
+		MustLoadX(Load)
+	return nil
+}
@@ -0,0 +1,36 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_healthChecker_CheckHealth(t *testing.T) {
+	client, err := mongo.Connect(context.Background(), options.Client().
+		ApplyURI("mongodb://127.0.0.1:1").
+		SetServerSelectionTimeout(100*time.Millisecond))
+	assert.Nil(t, err)
+	defer func() {
+		_ = client.Disconnect(context.Background())
+	}()
+
+	h := &healthChecker{client: client}
+	assert.Equal(t, "mongo", h.HealthCheckName())
+	assert.Error(t, h.CheckHealth(context.Background()))
+}
+
+func TestLoadHealthChecker(t *testing.T) {
+	controller := gomock.NewController(t)
+	loader := gone.NewMockLoader(controller)
+	loader.EXPECT().MustLoad(gomock.Any()).Return(loader)
+	loader.EXPECT().MustLoadX(gomock.Any()).Return(loader)
+
+	assert.Nil(t, LoadHealthChecker(loader))
+}
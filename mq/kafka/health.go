@@ -0,0 +1,82 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+
+	"github.com/IBM/sarama"
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+)
+
+// healthChecker checks the brokers of `kafka.default` by refreshing metadata, it is used by the readiness probe of
+// goner/gin.
+type healthChecker struct {
+	gone.Flag
+	configure  gone.Configure  `gone:"configure"`
+	beforeStop gone.BeforeStop `gone:"*"`
+	logger     gone.Logger     `gone:"*"`
+
+	lock   sync.Mutex
+	client sarama.Client
+}
+
+var _ g.HealthChecker = (*healthChecker)(nil)
+
+func (h *healthChecker) GonerName() string {
+	return "gone-kafka-health-checker"
+}
+
+func (h *healthChecker) Init() {
+	h.beforeStop(func() {
+		h.lock.Lock()
+		defer h.lock.Unlock()
+		if h.client != nil {
+			g.ErrorPrinter(h.logger, h.client.Close(), "close kafka health check client failed")
+			h.client = nil
+		}
+	})
+}
+
+func (h *healthChecker) HealthCheckName() string {
+	return "kafka"
+}
+
+// getClient create the client on first check, so the brokers unavailable at startup are retried by later checks
+func (h *healthChecker) getClient() (sarama.Client, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.client == nil {
+		var conf Conf
+		client, err := sarama.NewClient(conf.ReadFromConfigure("", h.configure))
+		if err != nil {
+			return nil, err
+		}
+		h.client = client
+	}
+	return h.client, nil
+}
+
+func (h *healthChecker) CheckHealth(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		client, err := h.getClient()
+		if err != nil {
+			done <- err
+			return
+		}
+		done <- client.RefreshMetadata()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// LoadHealthChecker load the g.HealthChecker of brokers configured by `kafka.default`, which is checked by the
+// readiness probe of goner/gin.
+func LoadHealthChecker(loader gone.Loader) error {
+	return loader.Load(&healthChecker{})
+}
@@ -0,0 +1,59 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/gone-io/gone/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_healthChecker_CheckHealth(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest":    sarama.NewMockMetadataResponse(t).SetBroker(broker.Addr(), broker.BrokerID()),
+	})
+
+	controller := gomock.NewController(t)
+	configure := gone.NewMockConfigure(controller)
+	configure.EXPECT().Get("kafka.default", gomock.Any(), "").DoAndReturn(func(_ string, v any, _ string) error {
+		v.(*Conf).Addrs = []string{broker.Addr()}
+		return nil
+	}).Times(2)
+
+	var stop gone.Process
+	h := &healthChecker{
+		configure:  configure,
+		logger:     gone.GetDefaultLogger(),
+		beforeStop: func(fn gone.Process) { stop = fn },
+	}
+	h.Init()
+	assert.Equal(t, "kafka", h.HealthCheckName())
+	assert.Nil(t, h.CheckHealth(context.Background()))
+	assert.Nil(t, h.CheckHealth(context.Background()))
+
+	// the check is canceled with ctx
+	broker.SetLatency(500 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, h.CheckHealth(ctx), context.DeadlineExceeded)
+
+	stop()
+	assert.Nil(t, h.client)
+	broker.Close()
+
+	// the client is created again after stopped, and fails as no broker is available
+	assert.Error(t, h.CheckHealth(context.Background()))
+}
+
+func TestLoadHealthChecker(t *testing.T) {
+	controller := gomock.NewController(t)
+	loader := gone.NewMockLoader(controller)
+	loader.EXPECT().Load(gomock.Any()).Return(nil)
+
+	assert.Nil(t, LoadHealthChecker(loader))
+}
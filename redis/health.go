@@ -0,0 +1,31 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+)
+
+// healthChecker checks redis with `PING`, it is used by the readiness probe of goner/gin.
+type healthChecker struct {
+	gone.Flag
+	*inner `gone:"gone-redis-inner"`
+}
+
+var _ g.HealthChecker = (*healthChecker)(nil)
+
+func (h *healthChecker) GonerName() string {
+	return IdGoneRedisHealthChecker
+}
+
+func (h *healthChecker) HealthCheckName() string {
+	return IdGoneRedis
+}
+
+func (h *healthChecker) CheckHealth(context.Context) error {
+	conn := h.getConn()
+	defer h.close(conn)
+	_, err := conn.Do("PING")
+	return err
+}
@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_healthChecker_CheckHealth(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	conn := NewMockConn(controller)
+	conn.EXPECT().Do("PING").Return("PONG", nil)
+	conn.EXPECT().Do("PING").Return(nil, errors.New("refused"))
+
+	mockPool := NewMockPool(controller)
+	mockPool.EXPECT().Get().Return(conn).Times(2)
+	mockPool.EXPECT().Close(conn).Times(2)
+
+	h := &healthChecker{inner: &inner{pool: mockPool}}
+	assert.Equal(t, "redis", h.HealthCheckName())
+	assert.Nil(t, h.CheckHealth(context.Background()))
+	assert.Error(t, h.CheckHealth(context.Background()))
+}
+
+// pongServer a fake redis server answering `PONG` to every command
+func pongServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if strings.HasPrefix(line, "*") {
+						_, _ = conn.Write([]byte("+PONG\r\n"))
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestLoad_healthChecker(t *testing.T) {
+	t.Setenv("GONE_REDIS_SERVER", pongServer(t))
+
+	gone.
+		NewApp(LoadHealthChecker, LoadRateLimiter, LoadResponseCacheStore).
+		Run(func(in struct {
+			checkers []g.HealthChecker `gone:"*"`
+		}) {
+			assert.Len(t, in.checkers, 1)
+			assert.Equal(t, IdGoneRedis, in.checkers[0].HealthCheckName())
+			assert.Nil(t, in.checkers[0].CheckHealth(context.Background()))
+		})
+
+	// the checker is opt-in
+	gone.
+		NewApp(Load).
+		Run(func(in struct {
+			checkers []g.HealthChecker `gone:"*"`
+		}) {
+			assert.Empty(t, in.checkers)
+		})
+}
@@ -104,6 +104,10 @@ type HashProvider interface {
 const (
 	IdGoneRedisInner = "gone-redis-inner"
 	IdGoneRedis      = "redis"
+
+	IdGoneRedisRateLimiter        = "gone-redis-rate-limiter"
+	IdGoneRedisResponseCacheStore = "gone-redis-response-cache-store"
+	IdGoneRedisHealthChecker      = "gone-redis-health-checker"
 )
 
 var (
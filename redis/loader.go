@@ -2,6 +2,7 @@ package redis
 
 import (
 	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
 )
 
 func Load(loader gone.Loader) error {
@@ -10,6 +11,34 @@ func Load(loader gone.Loader) error {
 		MustLoad(&pool{}, gone.IsDefault(new(Pool))).
 		MustLoad(&cache{}, gone.IsDefault(new(Cache), new(Key))).
 		MustLoad(&locker{}, gone.IsDefault(new(Locker))).
+		MustLoad(&tryLocker{}, gone.IsDefault(new(g.TryLocker))).
 		MustLoad(&provider{}, gone.IsDefault(new(HashProvider)))
 	return nil
 }
+
+// LoadHealthChecker load redis and the g.HealthChecker checking redis with `PING`, which is checked by the readiness
+// probe of goner/gin.
+func LoadHealthChecker(loader gone.Loader) error {
+	loader.
+		MustLoad(&healthChecker{}).
+		MustLoadX(Load)
+	return nil
+}
+
+// LoadRateLimiter load the redis implementation of g.RateLimiter, which makes the rate limit rules of gin
+// take effect across all replicas.
+func LoadRateLimiter(loader gone.Loader) error {
+	loader.
+		MustLoad(&rateLimiter{}, gone.IsDefault(new(g.RateLimiter))).
+		MustLoadX(Load)
+	return nil
+}
+
+// LoadResponseCacheStore load the redis implementation of g.ResponseCacheStore, which makes the responses cached by
+// gin.ResponseCache shared across all replicas.
+func LoadResponseCacheStore(loader gone.Loader) error {
+	loader.
+		MustLoad(&responseCacheStore{}, gone.IsDefault(new(g.ResponseCacheStore))).
+		MustLoadX(Load)
+	return nil
+}
@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"errors"
+	"github.com/gone-io/gone/v2"
 	"github.com/gone-io/goner/g"
 	"github.com/google/uuid"
 	"time"
@@ -96,3 +97,24 @@ func (r *locker) LockAndDo(key string, fn func(), lockTime, checkPeriod time.Dur
 	fn()
 	return nil
 }
+
+// tryLocker adapts Locker to g.TryLocker, which the idempotency middleware of goner/gin uses to lock keys across
+// replicas.
+type tryLocker struct {
+	gone.Flag
+	locker Locker `gone:"*"`
+}
+
+var _ g.TryLocker = (*tryLocker)(nil)
+
+func (t *tryLocker) GonerName() string {
+	return "gone-redis-try-locker"
+}
+
+func (t *tryLocker) TryLock(key string, ttl time.Duration) (func(), error) {
+	unlock, err := t.locker.TryLock(key, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return unlock, nil
+}
@@ -187,3 +187,23 @@ func Test_locker_LockAndDo(t *testing.T) {
 			assert.Nil(t, err)
 		})
 }
+
+func Test_tryLocker_TryLock(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	locker := NewMockLocker(controller)
+
+	unlocked := false
+	locker.EXPECT().TryLock("key", time.Second).Return(func() { unlocked = true }, nil)
+	locker.EXPECT().TryLock("key", time.Second).Return(nil, ErrorLockFailed)
+
+	l := &tryLocker{locker: locker}
+	unlock, err := l.TryLock("key", time.Second)
+	assert.Nil(t, err)
+	unlock()
+	assert.True(t, unlocked)
+
+	unlock, err = l.TryLock("key", time.Second)
+	assert.Equal(t, ErrorLockFailed, err)
+	assert.Nil(t, unlock)
+}
@@ -0,0 +1,103 @@
+package redis
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+)
+
+// tokenBucketLua KEYS[1]: bucket key; ARGV[1]: tokens per second; ARGV[2]: burst
+// return {allowed, remaining, retryAfterMs}
+const tokenBucketLua = `local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local data = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(data[1])
+local ts = tonumber(data[2])
+if tokens == nil or ts == nil then
+    tokens = burst
+    ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+    tokens = tokens - 1
+    allowed = 1
+else
+    retry = math.ceil((1 - tokens) * 1000 / rate)
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, math.floor(tokens), retry}`
+
+// slidingWindowLua KEYS[1]: window key prefix; ARGV[1]: window in ms; ARGV[2]: limit
+// return {allowed, remaining, retryAfterMs, resetAfterMs}
+const slidingWindowLua = `local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local idx = math.floor(now / window)
+local curKey = KEYS[1] .. ":" .. idx
+local cur = tonumber(redis.call("GET", curKey) or "0")
+local prev = tonumber(redis.call("GET", KEYS[1] .. ":" .. (idx - 1)) or "0")
+local elapsed = now - idx * window
+local estimate = prev * (window - elapsed) / window + cur
+if estimate + 1 <= limit then
+    redis.call("INCR", curKey)
+    redis.call("PEXPIRE", curKey, window * 2)
+    return {1, math.floor(limit - estimate - 1), 0, window - elapsed}
+end
+local retry = window - elapsed
+if prev > 0 and cur + 1 <= limit then
+    retry = math.ceil((1 - (limit - cur - 1) / prev) * window - elapsed)
+end
+return {0, 0, retry, window - elapsed}`
+
+// rateLimiter implements g.RateLimiter with redis, so rate limits hold across replicas.
+type rateLimiter struct {
+	gone.Flag
+	*inner `gone:"gone-redis-inner"`
+}
+
+var _ g.RateLimiter = (*rateLimiter)(nil)
+
+func (r *rateLimiter) GonerName() string {
+	return IdGoneRedisRateLimiter
+}
+
+func (r *rateLimiter) Allow(_ context.Context, key string, rule g.RateLimitRule) (result g.RateLimitResult, err error) {
+	conn := r.getConn()
+	defer r.close(conn)
+
+	key = r.buildKey(key)
+
+	var reply []int64
+	if rule.Algorithm == g.SlidingWindow {
+		result.Limit = int(rule.Limit)
+		reply, err = Int64s(conn.Do("EVAL", slidingWindowLua, 1, key, rule.Window.Milliseconds(), rule.Limit))
+	} else {
+		result.Limit = rule.Burst
+		reply, err = Int64s(conn.Do("EVAL", tokenBucketLua, 1, key, rule.Limit, rule.Burst))
+	}
+	if err != nil {
+		return result, gone.ToErrorWithMsg(err, "eval rate limit script failed")
+	}
+	if len(reply) < 3 {
+		return result, gone.NewInnerError("unexpected reply of rate limit script", gone.NotSupport)
+	}
+
+	result.Allowed = reply[0] == 1
+	result.Remaining = int(reply[1])
+	result.RetryAfter = time.Duration(reply[2]) * time.Millisecond
+	if len(reply) > 3 {
+		result.ResetAfter = time.Duration(reply[3]) * time.Millisecond
+	} else {
+		result.ResetAfter = time.Duration(math.Ceil(float64(rule.Burst-result.Remaining)/rule.Limit*1000)) * time.Millisecond
+	}
+	return result, nil
+}
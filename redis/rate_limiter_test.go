@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gone-io/goner/g"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_rateLimiter_Allow(t *testing.T) {
+	setTestEnv()
+
+	newLimiter := func(controller *gomock.Controller, conn *MockConn) *rateLimiter {
+		mockPool := NewMockPool(controller)
+		mockPool.EXPECT().Get().Return(conn)
+		mockPool.EXPECT().Close(conn)
+		return &rateLimiter{inner: &inner{pool: mockPool, cachePrefix: "pre"}}
+	}
+
+	t.Run("token bucket", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		conn := NewMockConn(controller)
+		conn.EXPECT().
+			Do("EVAL", tokenBucketLua, 1, "pre#k", float64(2), 4).
+			Return([]any{int64(1), int64(3), int64(0)}, nil)
+
+		result, err := newLimiter(controller, conn).Allow(context.Background(), "k", g.RateLimitRule{
+			Algorithm: g.TokenBucket,
+			Limit:     2,
+			Burst:     4,
+		})
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 4, result.Limit)
+		assert.Equal(t, 3, result.Remaining)
+		assert.Equal(t, 500*time.Millisecond, result.ResetAfter)
+	})
+
+	t.Run("sliding window", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		conn := NewMockConn(controller)
+		conn.EXPECT().
+			Do("EVAL", slidingWindowLua, 1, "pre#k", int64(60000), float64(10)).
+			Return([]any{int64(0), int64(0), int64(1200), int64(3000)}, nil)
+
+		result, err := newLimiter(controller, conn).Allow(context.Background(), "k", g.RateLimitRule{
+			Algorithm: g.SlidingWindow,
+			Limit:     10,
+			Window:    time.Minute,
+		})
+		assert.Nil(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 10, result.Limit)
+		assert.Equal(t, 1200*time.Millisecond, result.RetryAfter)
+		assert.Equal(t, 3*time.Second, result.ResetAfter)
+	})
+
+	t.Run("eval error", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		conn := NewMockConn(controller)
+		conn.EXPECT().Do(gomock.Any(), gomock.Any()).Return(nil, errors.New("err"))
+
+		_, err := newLimiter(controller, conn).Allow(context.Background(), "k", g.RateLimitRule{Limit: 1, Burst: 1})
+		assert.Error(t, err)
+	})
+
+	t.Run("unexpected reply", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		conn := NewMockConn(controller)
+		conn.EXPECT().Do(gomock.Any(), gomock.Any()).Return([]any{int64(1)}, nil)
+
+		_, err := newLimiter(controller, conn).Allow(context.Background(), "k", g.RateLimitRule{Limit: 1, Burst: 1})
+		assert.Error(t, err)
+	})
+}
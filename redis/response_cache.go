@@ -0,0 +1,120 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"github.com/gone-io/goner/redis/internal/json"
+)
+
+const responseCacheTagPrefix = "gone-gin-cache-tag#"
+
+// responseCacheStore implements g.ResponseCacheStore with redis, so cached responses are shared across replicas.
+// Every tag has a version which is increased by InvalidateTags; responses stored with older versions of their tags are
+// treated as missing, so invalidation costs one INCR per tag no matter how many responses are tagged.
+type responseCacheStore struct {
+	gone.Flag
+	*inner `gone:"gone-redis-inner"`
+}
+
+var _ g.ResponseCacheStore = (*responseCacheStore)(nil)
+
+type responseCacheEntry struct {
+	Response    *g.CachedResponse `json:"response"`
+	TagVersions []int64           `json:"tagVersions"`
+}
+
+func (s *responseCacheStore) GonerName() string {
+	return IdGoneRedisResponseCacheStore
+}
+
+func (s *responseCacheStore) Get(_ context.Context, key string) (*g.CachedResponse, error) {
+	conn := s.getConn()
+	defer s.close(conn)
+
+	bt, err := Bytes(conn.Do("GET", s.buildKey(key)))
+	if err != nil {
+		if errors.Is(err, ErrNil) {
+			return nil, nil
+		}
+		return nil, gone.ToErrorWithMsg(err, "get cached response failed")
+	}
+
+	var entry responseCacheEntry
+	if err = json.Unmarshal(bt, &entry); err != nil || entry.Response == nil {
+		return nil, nil
+	}
+	if len(entry.Response.Tags) > 0 {
+		versions, err := s.tagVersions(conn, entry.Response.Tags)
+		if err != nil {
+			return nil, err
+		}
+		for i, version := range versions {
+			if i >= len(entry.TagVersions) || entry.TagVersions[i] != version {
+				return nil, nil
+			}
+		}
+	}
+	return entry.Response, nil
+}
+
+func (s *responseCacheStore) Set(_ context.Context, key string, response *g.CachedResponse, ttl time.Duration) error {
+	conn := s.getConn()
+	defer s.close(conn)
+
+	entry := responseCacheEntry{Response: response}
+	if len(response.Tags) > 0 {
+		versions, err := s.tagVersions(conn, response.Tags)
+		if err != nil {
+			return err
+		}
+		entry.TagVersions = versions
+	}
+
+	bt, err := json.Marshal(entry)
+	if err != nil {
+		return gone.ToErrorWithMsg(err, "marshal cached response failed")
+	}
+	if _, err = conn.Do("SET", s.buildKey(key), bt, "PX", ttl.Milliseconds()); err != nil {
+		return gone.ToErrorWithMsg(err, "set cached response failed")
+	}
+	return nil
+}
+
+func (s *responseCacheStore) InvalidateTags(_ context.Context, tags ...string) error {
+	conn := s.getConn()
+	defer s.close(conn)
+
+	for _, tag := range tags {
+		if _, err := conn.Do("INCR", s.buildKey(responseCacheTagPrefix+tag)); err != nil {
+			return gone.ToErrorWithMsg(err, "increase version of tag failed")
+		}
+	}
+	return nil
+}
+
+func (s *responseCacheStore) tagVersions(conn redis.Conn, tags []string) ([]int64, error) {
+	keys := make([]any, 0, len(tags))
+	for _, tag := range tags {
+		keys = append(keys, s.buildKey(responseCacheTagPrefix+tag))
+	}
+	values, err := Values(conn.Do("MGET", keys...))
+	if err != nil {
+		return nil, gone.ToErrorWithMsg(err, "get versions of tags failed")
+	}
+
+	versions := make([]int64, len(tags))
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		if versions[i], err = Int64(v, nil); err != nil {
+			return nil, gone.ToErrorWithMsg(err, "parse version of tag failed")
+		}
+	}
+	return versions, nil
+}
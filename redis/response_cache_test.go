@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gone-io/goner/g"
+	"github.com/gone-io/goner/redis/internal/json"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_responseCacheStore(t *testing.T) {
+	newStore := func(controller *gomock.Controller, conn *MockConn) *responseCacheStore {
+		mockPool := NewMockPool(controller)
+		mockPool.EXPECT().Get().Return(conn)
+		mockPool.EXPECT().Close(conn)
+		return &responseCacheStore{inner: &inner{pool: mockPool, cachePrefix: "pre"}}
+	}
+	ctx := context.Background()
+	response := &g.CachedResponse{Status: 200, Body: []byte("ok"), TTL: time.Minute, Tags: []string{"a", "b"}}
+	stored, _ := json.Marshal(responseCacheEntry{Response: response, TagVersions: []int64{2, 0}})
+
+	t.Run("set with versions of tags", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		conn := NewMockConn(controller)
+		conn.EXPECT().
+			Do("MGET", "pre#gone-gin-cache-tag#a", "pre#gone-gin-cache-tag#b").
+			Return([]any{[]byte("2"), nil}, nil)
+		conn.EXPECT().Do("SET", "pre#k", stored, "PX", int64(120000)).Return("OK", nil)
+
+		assert.Nil(t, newStore(controller, conn).Set(ctx, "k", response, 2*time.Minute))
+	})
+
+	t.Run("get", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		conn := NewMockConn(controller)
+		conn.EXPECT().Do("GET", "pre#k").Return(stored, nil)
+		conn.EXPECT().Do("MGET", gomock.Any(), gomock.Any()).Return([]any{[]byte("2"), nil}, nil)
+
+		got, err := newStore(controller, conn).Get(ctx, "k")
+		assert.Nil(t, err)
+		assert.Equal(t, response.Body, got.Body)
+		assert.Equal(t, response.Tags, got.Tags)
+	})
+
+	t.Run("get invalidated", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		conn := NewMockConn(controller)
+		conn.EXPECT().Do("GET", "pre#k").Return(stored, nil)
+		conn.EXPECT().Do("MGET", gomock.Any(), gomock.Any()).Return([]any{[]byte("2"), []byte("1")}, nil)
+
+		got, err := newStore(controller, conn).Get(ctx, "k")
+		assert.Nil(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("get missing", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		conn := NewMockConn(controller)
+		conn.EXPECT().Do("GET", "pre#k").Return(nil, nil)
+
+		got, err := newStore(controller, conn).Get(ctx, "k")
+		assert.Nil(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("get error", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		conn := NewMockConn(controller)
+		conn.EXPECT().Do("GET", "pre#k").Return(nil, errors.New("err"))
+
+		_, err := newStore(controller, conn).Get(ctx, "k")
+		assert.Error(t, err)
+	})
+
+	t.Run("invalidate tags", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		conn := NewMockConn(controller)
+		conn.EXPECT().Do("INCR", "pre#gone-gin-cache-tag#a").Return(int64(3), nil)
+		conn.EXPECT().Do("INCR", "pre#gone-gin-cache-tag#b").Return(nil, errors.New("err"))
+
+		assert.Error(t, newStore(controller, conn).InvalidateTags(ctx, "a", "b"))
+	})
+}
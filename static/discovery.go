@@ -0,0 +1,152 @@
+package static
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+)
+
+// servicesKey the config key of service instances, which can be updated at runtime by dynamic configure
+const servicesKey = "discovery.static.services"
+
+var _ g.ServiceDiscovery = (*Discovery)(nil)
+
+// Instance a service instance configured statically
+type Instance struct {
+	// Ip 实例的IP或主机名
+	Ip string `mapstructure:"ip" json:"ip" yaml:"ip"`
+
+	// Port 实例的端口
+	Port int `mapstructure:"port" json:"port" yaml:"port"`
+
+	// Weight 实例的权重，默认为1
+	Weight float64 `mapstructure:"weight" json:"weight" yaml:"weight"`
+
+	// Metadata 实例的元数据
+	Metadata g.Metadata `mapstructure:"metadata" json:"metadata" yaml:"metadata"`
+
+	// Healthy 实例是否健康，默认为true
+	Healthy *bool `mapstructure:"healthy" json:"healthy" yaml:"healthy"`
+}
+
+// Discovery the g.ServiceDiscovery reading instances from configuration, changes of configuration are pushed to
+// watchers if the configure is dynamic.
+type Discovery struct {
+	gone.Flag
+	logger    gone.Logger      `gone:"*"`
+	configure gone.Configure   `gone:"configure"`
+	watcher   gone.ConfWatcher `gone:"*" option:"allowNil"`
+
+	// services 各服务的实例列表，对应配置项为：`discovery.static.services`
+	services map[string][]Instance `gone:"config,discovery.static.services"`
+
+	lock      sync.Mutex
+	instances map[string][]g.Service
+	watches   map[string]map[*watch]struct{}
+}
+
+type watch struct {
+	ch chan []g.Service
+}
+
+// notify send the latest instances, the instances not received yet are replaced
+func (w *watch) notify(instances []g.Service) {
+	select {
+	case <-w.ch:
+	default:
+	}
+	w.ch <- instances
+}
+
+func (d *Discovery) Init() error {
+	instances, err := build(d.services)
+	if err != nil {
+		return err
+	}
+	d.instances = instances
+	d.watches = make(map[string]map[*watch]struct{})
+	if d.watcher != nil {
+		d.watcher(servicesKey, func(_, _ any) {
+			d.reload()
+		})
+	}
+	return nil
+}
+
+func build(services map[string][]Instance) (map[string][]g.Service, error) {
+	m := make(map[string][]g.Service, len(services))
+	for name, instances := range services {
+		list := make([]g.Service, 0, len(instances))
+		for i, instance := range instances {
+			if instance.Ip == "" || instance.Port <= 0 || instance.Port > 65535 {
+				return nil, gone.NewInnerErrorWithParams(gone.ConfigError, "%s.%s[%d] has invalid address(%s:%d)", servicesKey, name, i, instance.Ip, instance.Port)
+			}
+			weight := instance.Weight
+			if weight == 0 {
+				weight = 1
+			}
+			healthy := instance.Healthy == nil || *instance.Healthy
+			list = append(list, g.NewService(name, instance.Ip, instance.Port, instance.Metadata, healthy, weight))
+		}
+		m[name] = list
+	}
+	return m, nil
+}
+
+// reload read `discovery.static.services` again and notify the watchers of services changed, invalid configuration
+// is logged and ignored
+func (d *Discovery) reload() {
+	var services map[string][]Instance
+	if err := d.configure.Get(servicesKey, &services, ""); err != nil {
+		d.logger.Errorf("static discovery reload %s err: %v", servicesKey, err)
+		return
+	}
+	instances, err := build(services)
+	if err != nil {
+		d.logger.Errorf("static discovery reload %s err: %v", servicesKey, err)
+		return
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	old := d.instances
+	d.instances = instances
+	for name, watches := range d.watches {
+		if reflect.DeepEqual(old[name], instances[name]) {
+			continue
+		}
+		d.logger.Infof("static discovery %s changed: %d instances", name, len(instances[name]))
+		for w := range watches {
+			w.notify(instances[name])
+		}
+	}
+}
+
+func (d *Discovery) GetInstances(serviceName string) ([]g.Service, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	instances, ok := d.instances[serviceName]
+	if !ok {
+		return nil, gone.ToError(fmt.Sprintf("service %s is not configured in %s", serviceName, servicesKey))
+	}
+	return instances, nil
+}
+
+func (d *Discovery) Watch(serviceName string) (<-chan []g.Service, func() error, error) {
+	w := &watch{ch: make(chan []g.Service, 1)}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.watches[serviceName] == nil {
+		d.watches[serviceName] = make(map[*watch]struct{})
+	}
+	d.watches[serviceName][w] = struct{}{}
+	return w.ch, func() error {
+		d.lock.Lock()
+		defer d.lock.Unlock()
+		delete(d.watches[serviceName], w)
+		return nil
+	}, nil
+}
@@ -0,0 +1,116 @@
+package static
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBuild(t *testing.T) {
+	unhealthy := false
+	m, err := build(map[string][]Instance{
+		"user": {
+			{Ip: "10.0.0.1", Port: 80},
+			{Ip: "10.0.0.2", Port: 80, Weight: 3, Healthy: &unhealthy},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, m["user"], 2)
+	assert.Equal(t, float64(1), m["user"][0].GetWeight())
+	assert.True(t, m["user"][0].IsHealthy())
+	assert.Equal(t, float64(3), m["user"][1].GetWeight())
+	assert.False(t, m["user"][1].IsHealthy())
+
+	_, err = build(map[string][]Instance{"user": {{Ip: "10.0.0.1"}}})
+	assert.Error(t, err)
+	_, err = build(map[string][]Instance{"user": {{Port: 80}}})
+	assert.Error(t, err)
+}
+
+func TestDiscovery(t *testing.T) {
+	controller := gomock.NewController(t)
+	configure := gone.NewMockConfigure(controller)
+
+	var onChange gone.ConfWatchFunc
+	d := &Discovery{
+		logger:    gone.GetDefaultLogger(),
+		configure: configure,
+		watcher: func(key string, callback gone.ConfWatchFunc) {
+			assert.Equal(t, servicesKey, key)
+			onChange = callback
+		},
+		services: map[string][]Instance{
+			"user":  {{Ip: "10.0.0.1", Port: 80}},
+			"order": {{Ip: "10.0.0.9", Port: 80}},
+		},
+	}
+	assert.Nil(t, d.Init())
+	assert.NotNil(t, onChange)
+
+	instances, err := d.GetInstances("user")
+	assert.Nil(t, err)
+	assert.Len(t, instances, 1)
+	_, err = d.GetInstances("unknown")
+	assert.Error(t, err)
+
+	userCh, stopUser, err := d.Watch("user")
+	assert.Nil(t, err)
+	orderCh, stopOrder, err := d.Watch("order")
+	assert.Nil(t, err)
+
+	reload := func(services map[string][]Instance, err error) {
+		configure.EXPECT().Get(servicesKey, gomock.Any(), "").DoAndReturn(func(_ string, v any, _ string) error {
+			*(v.(*map[string][]Instance)) = services
+			return err
+		})
+		onChange(nil, nil)
+	}
+
+	// only watchers of the changed service are notified, and pending updates are replaced by the latest one
+	reload(map[string][]Instance{
+		"user":  {{Ip: "10.0.0.1", Port: 80}, {Ip: "10.0.0.2", Port: 80}},
+		"order": {{Ip: "10.0.0.9", Port: 80}},
+	}, nil)
+	reload(map[string][]Instance{
+		"user":  {{Ip: "10.0.0.1", Port: 80}, {Ip: "10.0.0.2", Port: 80}, {Ip: "10.0.0.3", Port: 80}},
+		"order": {{Ip: "10.0.0.9", Port: 80}},
+	}, nil)
+	assert.Len(t, <-userCh, 3)
+	assert.Empty(t, orderCh)
+
+	// invalid configuration is ignored
+	reload(nil, errors.New("config error"))
+	reload(map[string][]Instance{"user": {{Ip: "10.0.0.1"}}}, nil)
+	instances, err = d.GetInstances("user")
+	assert.Nil(t, err)
+	assert.Len(t, instances, 3)
+
+	// removed services are pushed as empty lists
+	reload(map[string][]Instance{
+		"user": {{Ip: "10.0.0.1", Port: 80}},
+	}, nil)
+	assert.Len(t, <-userCh, 1)
+	assert.Empty(t, <-orderCh)
+	_, err = d.GetInstances("order")
+	assert.Error(t, err)
+
+	assert.Nil(t, stopUser())
+	assert.Nil(t, stopOrder())
+	reload(map[string][]Instance{
+		"user": {{Ip: "10.0.0.5", Port: 80}},
+	}, nil)
+	assert.Empty(t, userCh)
+}
+
+func TestDiscovery_withoutWatcher(t *testing.T) {
+	d := &Discovery{services: map[string][]Instance{"user": {{Ip: "10.0.0.1", Port: 80}}}}
+	assert.Nil(t, d.Init())
+	var _ g.ServiceDiscovery = d
+
+	d = &Discovery{services: map[string][]Instance{"user": {{Ip: "10.0.0.1", Port: 0}}}}
+	assert.Error(t, d.Init())
+}
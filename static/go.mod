@@ -0,0 +1,27 @@
+module github.com/gone-io/goner/static
+
+go 1.24.1
+
+require (
+	github.com/gone-io/gone/v2 v2.2.6
+	github.com/gone-io/goner/g v1.3.6
+	github.com/stretchr/testify v1.11.1
+	go.uber.org/mock v0.6.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel v1.38.0 // indirect
+	go.opentelemetry.io/otel/metric v1.38.0 // indirect
+	go.opentelemetry.io/otel/trace v1.38.0 // indirect
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
+	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/gone-io/goner/g => ../g
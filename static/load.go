@@ -0,0 +1,11 @@
+package static
+
+import (
+	"github.com/gone-io/gone/v2"
+)
+
+// DiscoveryLoad load the static service discovery, instances are read from `discovery.static.services`
+func DiscoveryLoad(loader gone.Loader) error {
+	loader.MustLoad(&Discovery{})
+	return nil
+}
@@ -0,0 +1,30 @@
+package static
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoveryLoad(t *testing.T) {
+	_ = os.Setenv("GONE_DISCOVERY_STATIC_SERVICES", `{"user":[{"ip":"127.0.0.1","port":8080,"metadata":{"zone":"a"}}]}`)
+	defer func() {
+		_ = os.Unsetenv("GONE_DISCOVERY_STATIC_SERVICES")
+	}()
+
+	gone.
+		NewApp(DiscoveryLoad).
+		Run(func(discovery g.ServiceDiscovery) {
+			instances, err := discovery.GetInstances("user")
+			assert.Nil(t, err)
+			assert.Len(t, instances, 1)
+			assert.Equal(t, "127.0.0.1", instances[0].GetIP())
+			assert.Equal(t, 8080, instances[0].GetPort())
+			assert.Equal(t, float64(1), instances[0].GetWeight())
+			assert.True(t, instances[0].IsHealthy())
+			assert.Equal(t, g.Metadata{"zone": "a"}, instances[0].GetMetadata())
+		})
+}
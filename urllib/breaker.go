@@ -0,0 +1,88 @@
+package urllib
+
+import (
+	"context"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"github.com/imroc/req/v3"
+)
+
+const (
+	// BreakByHost requests to the same host share a circuit
+	BreakByHost = "host"
+
+	// BreakByEndpoint requests with the same method, host and path share a circuit
+	BreakByEndpoint = "endpoint"
+)
+
+var meterName = "urllib"
+
+// Fallback is called when a request is rejected by an open circuit, err is g.ErrCircuitOpen; it can return cached or
+// default data by a response, eg: &req.Response{Response: &http.Response{StatusCode: 200, Body: ...}}, whose body is
+// unmarshalled to the success or error result of the request.
+type Fallback func(request *req.Request, err error) (*req.Response, error)
+
+type fallbackKey struct{}
+
+// WithFallback return a context carrying the fallback, set it to requests by req.Request.SetContext.
+func WithFallback(ctx context.Context, fallback Fallback) context.Context {
+	return context.WithValue(ctx, fallbackKey{}, fallback)
+}
+
+type circuitBreaker struct {
+	*g.ClientCircuitBreaker
+}
+
+func newCircuitBreaker(config g.CircuitBreakerConfig, logger gone.Logger) (*circuitBreaker, error) {
+	breaker, err := config.NewClientCircuitBreaker("urllib.circuit-breaker", "http.client", logger, BreakByHost, BreakByEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &circuitBreaker{ClientCircuitBreaker: breaker}, breaker.ObserveMetrics(meterName)
+}
+
+func (b *circuitBreaker) key(request *req.Request) string {
+	if b.KeyBy == BreakByEndpoint {
+		return request.Method + " " + request.URL.Host + request.URL.Path
+	}
+	return request.URL.Host
+}
+
+// wrap reject requests when the circuit is open; transport errors and 5xx responses are counted as failures, requests
+// canceled by callers are not.
+func (b *circuitBreaker) wrap(rt req.RoundTripper) req.RoundTripFunc {
+	return func(request *req.Request) (*req.Response, error) {
+		key := b.key(request)
+		done, err := b.Allow(key)
+		if err != nil {
+			if fallback, ok := request.Context().Value(fallbackKey{}).(Fallback); ok {
+				resp, err := fallback(request, err)
+				return fallbackResponse(request, resp, err)
+			}
+			return &req.Response{Request: request}, err
+		}
+
+		resp, err := rt.RoundTrip(request)
+		done(feedbackError(resp, err) != nil)
+		return resp, err
+	}
+}
+
+// fallbackResponse complete the response returned by Fallback, which does not go through the middlewares of client
+func fallbackResponse(request *req.Request, resp *req.Response, err error) (*req.Response, error) {
+	if resp == nil {
+		resp = &req.Response{}
+	}
+	resp.Request = request
+	if err != nil || resp.Response == nil {
+		return resp, err
+	}
+	switch {
+	case resp.IsSuccessState() && request.Result != nil:
+		err = resp.Unmarshal(request.Result)
+	case resp.IsErrorState() && request.Error != nil:
+		err = resp.Unmarshal(request.Error)
+	}
+	return resp, err
+}
@@ -0,0 +1,112 @@
+package urllib
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+	"github.com/imroc/req/v3"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	logger := gone.NewMockLogger(controller)
+	logger.EXPECT().Warnf(gomock.Any(), gomock.Any()).Times(1)
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := r{
+		logger: logger,
+		breakerConfig: g.CircuitBreakerConfig{
+			Enabled:     true,
+			MinRequests: 2,
+			OpenTimeout: time.Hour,
+		},
+	}
+	assert.Nil(t, client.Init())
+
+	for i := 0; i < 2; i++ {
+		res, err := client.R().Get(server.URL + "/fail")
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	}
+	assert.Equal(t, g.CircuitOpen, client.breaker.State(strings.TrimPrefix(server.URL, "http://")))
+
+	// requests to the host are rejected without reaching the server
+	_, err := client.R().Get(server.URL + "/ok")
+	assert.Equal(t, g.ErrCircuitOpen, err)
+	assert.Equal(t, int32(2), calls.Load())
+
+	var result string
+	res, err := client.R().
+		SetContext(WithFallback(context.Background(), func(request *req.Request, err error) (*req.Response, error) {
+			assert.Equal(t, g.ErrCircuitOpen, err)
+			return &req.Response{Response: &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`"cached"`)),
+			}}, nil
+		})).
+		SetSuccessResult(&result).
+		Get(server.URL + "/ok")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "cached", result)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCircuitBreaker_endpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	client := r{
+		logger: gone.GetDefaultLogger(),
+		breakerConfig: g.CircuitBreakerConfig{
+			Enabled:     true,
+			KeyBy:       BreakByEndpoint,
+			MinRequests: 1,
+			OpenTimeout: time.Hour,
+		},
+	}
+	assert.Nil(t, client.Init())
+
+	_, err := client.R().Get(server.URL + "/fail")
+	assert.Nil(t, err)
+	_, err = client.R().Get(server.URL + "/fail")
+	assert.Equal(t, g.ErrCircuitOpen, err)
+
+	// other endpoints of the host are not affected
+	res, err := client.R().Get(server.URL + "/ok")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestCircuitBreaker_config(t *testing.T) {
+	_, err := newCircuitBreaker(g.CircuitBreakerConfig{KeyBy: "path"}, nil)
+	assert.Error(t, err)
+	_, err = newCircuitBreaker(g.CircuitBreakerConfig{ErrorRate: 2}, nil)
+	assert.Error(t, err)
+}
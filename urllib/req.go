@@ -2,6 +2,7 @@ package urllib
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/gone-io/gone/v2"
 	"github.com/gone-io/goner/g"
@@ -12,22 +13,30 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	"net"
 	"net/http"
 	"net/http/httptrace"
 	"path/filepath"
+	"strconv"
+	"time"
 )
 
 type r struct {
 	gone.Flag
 	*req.Client
-	logger          gone.Logger          `gone:"*"`
-	tracer          g.Tracer             `gone:"*" option:"allowNil"`
-	lb              g.LoadBalancer       `gone:"*" option:"allowNil"`
-	isOtelLogLoaded g.IsOtelTracerLoaded `gone:"*" option:"allowNil"`
+	logger          gone.Logger           `gone:"*"`
+	tracer          g.Tracer              `gone:"*" option:"allowNil"`
+	lb              g.LoadBalancer        `gone:"*" option:"allowNil"`
+	feedback        g.LoadBalanceFeedback `gone:"*" option:"allowNil"`
+	isOtelLogLoaded g.IsOtelTracerLoaded  `gone:"*" option:"allowNil"`
 
 	innerServicePattern string `gone:"config,urllib.inner-service-pattern=*"`
 	requestIdKey        string `gone:"config,urllib.req.x-request-id-key=X-Request-Id"`
 	tracerIdKey         string `gone:"config,urllib.req.x-trace-id-key=X-Trace-Id"`
+
+	// breakerConfig 熔断配置，对应配置项为：`urllib.circuit-breaker`
+	breakerConfig g.CircuitBreakerConfig `gone:"config,urllib.circuit-breaker"`
+	breaker       *circuitBreaker
 }
 
 var tracerName = "urllib"
@@ -61,14 +70,16 @@ func (r *r) trip(rt req.RoundTripper) req.RoundTripFunc {
 			return nil, gone.ToErrorWithMsg(err, "match inner service err")
 		}
 
+		var serviceName string
 		if matched {
 			if r.lb != nil {
-				instance, err := r.lb.GetInstance(req.Context(), req.URL.Host)
+				instance, err := r.lb.GetInstance(routeContext(req), req.URL.Host)
 				if err != nil {
 					r.logger.Errorf("lb get instance err: %v", err)
 					return nil, gone.ToError(err)
 				}
-				req.URL.Host = fmt.Sprintf("%s:%d", instance.GetIP(), instance.GetPort())
+				serviceName = req.URL.Host
+				req.URL.Host = net.JoinHostPort(instance.GetIP(), strconv.Itoa(instance.GetPort()))
 			}
 
 			tracerId, _ := req.Context().Value(r.tracerIdKey).(string)
@@ -80,7 +91,11 @@ func (r *r) trip(rt req.RoundTripper) req.RoundTripFunc {
 			req.SetHeader(r.tracerIdKey, tracerId)
 		}
 
+		begin := time.Now()
 		resp, err = rt.RoundTrip(req)
+		if serviceName != "" && r.feedback != nil {
+			r.feedback.Feedback(serviceName, req.URL.Host, feedbackError(resp, err), time.Since(begin))
+		}
 
 		if span != nil && r.isOtelLogLoaded {
 			if err != nil {
@@ -99,7 +114,40 @@ func (r *r) trip(rt req.RoundTripper) req.RoundTripFunc {
 	}
 }
 
+// routeContext return the context carrying headers of the request as route attributes, so that the load balancer can
+// route requests by them
+func routeContext(req *req.Request) context.Context {
+	attributes := make(map[string]string, len(req.Headers))
+	for k, v := range req.Headers {
+		if len(v) > 0 {
+			attributes[k] = v[0]
+		}
+	}
+	return g.WithRouteAttributes(req.Context(), attributes)
+}
+
+// feedbackError the error reported to load balancer: transport errors and 5xx responses, requests canceled by callers
+// are not counted
+func feedbackError(resp *req.Response, err error) error {
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	}
+	if resp != nil && resp.Response != nil && resp.StatusCode >= http.StatusInternalServerError {
+		return gone.ToError(fmt.Sprintf("http status %d", resp.StatusCode))
+	}
+	return nil
+}
+
 func (r *r) Init() error {
+	if r.breakerConfig.Enabled {
+		var err error
+		if r.breaker, err = newCircuitBreaker(r.breakerConfig, r.logger); err != nil {
+			return err
+		}
+	}
 	r.Client = r.C()
 	return nil
 }
@@ -116,5 +164,9 @@ func (r *r) C() *req.Client {
 		})
 	}
 	client.WrapRoundTripFunc(r.trip)
+	if r.breaker != nil {
+		// the circuit is keyed by the host before it is resolved by load balancer
+		client.WrapRoundTripFunc(r.breaker.wrap)
+	}
 	return client
 }
@@ -1,7 +1,9 @@
 package urllib
 
 import (
+	"context"
 	"errors"
+	"net/http"
 	"net/url"
 	"testing"
 
@@ -200,3 +202,50 @@ func Test_r_trip_Error(t *testing.T) {
 			})
 		})
 }
+
+func Test_r_trip_Feedback(t *testing.T) {
+	ctr := gomock.NewController(t)
+	defer ctr.Finish()
+
+	service := mock.NewMockService(ctr)
+	service.EXPECT().GetIP().Return("192.168.1.1").AnyTimes()
+	service.EXPECT().GetPort().Return(8080).AnyTimes()
+
+	balancer := mock.NewMockLoadBalancer(ctr)
+	balancer.EXPECT().GetInstance(gomock.Any(), "internal.service").Return(service, nil).AnyTimes()
+
+	feedback := mock.NewMockLoadBalanceFeedback(ctr)
+	feedback.EXPECT().Feedback("internal.service", "192.168.1.1:8080", nil, gomock.Any())
+	feedback.EXPECT().Feedback("internal.service", "192.168.1.1:8080", gomock.Not(gomock.Nil()), gomock.Any()).Times(2)
+	feedback.EXPECT().Feedback("internal.service", "192.168.1.1:8080", nil, gomock.Any())
+
+	tripper := NewMockRoundTripper(ctr)
+	tripper.EXPECT().RoundTrip(gomock.Any()).Return(&req.Response{Response: &http.Response{StatusCode: http.StatusOK}}, nil)
+	tripper.EXPECT().RoundTrip(gomock.Any()).Return(&req.Response{Response: &http.Response{StatusCode: http.StatusBadGateway}}, nil)
+	tripper.EXPECT().RoundTrip(gomock.Any()).Return(nil, errors.New("connection refused"))
+	tripper.EXPECT().RoundTrip(gomock.Any()).Return(nil, context.Canceled)
+
+	r := &r{
+		lb:                  balancer,
+		feedback:            feedback,
+		logger:              gone.GetDefaultLogger(),
+		innerServicePattern: "internal.*",
+	}
+	for i := 0; i < 4; i++ {
+		parsedURL, _ := url.Parse("http://internal.service/api")
+		_, _ = r.trip(tripper)(&req.Request{URL: parsedURL})
+	}
+
+	// requests to external services are not reported
+	parsedURL, _ := url.Parse("http://example.com/api")
+	tripper.EXPECT().RoundTrip(gomock.Any()).Return(nil, errors.New("connection refused"))
+	_, _ = r.trip(tripper)(&req.Request{URL: parsedURL})
+}
+
+func Test_routeContext(t *testing.T) {
+	ctx := g.WithRouteAttributes(context.Background(), map[string]string{"region": "hz"})
+	request := &req.Request{Headers: http.Header{"X-Lane": []string{"gray"}}}
+	request.SetContext(ctx)
+
+	assert.Equal(t, map[string]string{"x-lane": "gray", "region": "hz"}, g.RouteAttributesFrom(routeContext(request)))
+}
@@ -0,0 +1,31 @@
+package xorm
+
+import (
+	"context"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/gone-io/goner/g"
+)
+
+// healthChecker checks the default database with ping, it is used by the readiness probe of goner/gin.
+type healthChecker struct {
+	gone.Flag
+	engine Engine `gone:"*"`
+}
+
+var _ g.HealthChecker = (*healthChecker)(nil)
+
+func (h *healthChecker) GonerName() string {
+	return "gone-xorm-health-checker"
+}
+
+func (h *healthChecker) HealthCheckName() string {
+	return "xorm"
+}
+
+func (h *healthChecker) CheckHealth(ctx context.Context) error {
+	if pinger, ok := h.engine.GetOriginEngine().(interface{ PingContext(context.Context) error }); ok {
+		return pinger.PingContext(ctx)
+	}
+	return h.engine.Ping()
+}
@@ -0,0 +1,32 @@
+package xorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gone-io/gone/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_healthChecker_CheckHealth(t *testing.T) {
+	controller := gomock.NewController(t)
+	engine := NewMockEngineInterface(controller)
+	engine.EXPECT().Ping().Return(nil)
+	engine.EXPECT().Ping().Return(errors.New("refused"))
+
+	h := &healthChecker{engine: newEng(engine, gone.GetDefaultLogger())}
+	assert.Equal(t, "xorm", h.HealthCheckName())
+	assert.Nil(t, h.CheckHealth(context.Background()))
+	assert.Error(t, h.CheckHealth(context.Background()))
+}
+
+func TestLoadHealthChecker(t *testing.T) {
+	controller := gomock.NewController(t)
+	loader := gone.NewMockLoader(controller)
+	loader.EXPECT().MustLoad(gomock.Any()).Return(loader)
+	loader.EXPECT().MustLoadX(gomock.Any()).Return(loader)
+
+	assert.Nil(t, LoadHealthChecker(loader))
+}
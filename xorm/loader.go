@@ -12,3 +12,12 @@ func Load(loader gone.Loader) error {
 		MustLoad(&engProvider{}, gone.IsDefault(new(Engine), new([]Engine)))
 	return nil
 }
+
+// LoadHealthChecker load xorm and the g.HealthChecker pinging the default database, which is checked by the
+// readiness probe of goner/gin.
+func LoadHealthChecker(loader gone.Loader) error {
+	loader.
+		MustLoad(&healthChecker{}).
+		MustLoadX(Load)
+	return nil
+}